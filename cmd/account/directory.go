@@ -0,0 +1,160 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandDirectory = cli.Command{
+	Name:      "directory",
+	Usage:     "export or verify a signed directory of the public account metadata",
+	ArgsUsage: "<keydir>",
+	Description: `
+Export the public metadata of all the accounts in a keystore directory: their
+hex and FFF addresses, key file creation times, tags and public keys, signed by
+the account of --address. No private material is included, so the directory
+can be handed to auditors or inventory systems.
+
+The keys are decrypted with the passphrase to prove their public keys. Tags
+are read from the --tags file, one "<address> <tag>[,<tag>...]" entry per line.
+
+With --verify, the signature and the public keys of a directory file are
+verified instead, and its accounts listed.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		addressFlag,
+		jsonFlag,
+		cli.StringFlag{
+			Name:  "tags",
+			Usage: "file mapping account addresses to comma separated tags",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the directory to (default = stdout)",
+		},
+		cli.StringFlag{
+			Name:  "verify",
+			Usage: "directory file to verify",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if file := ctx.String("verify"); file != "" {
+			verifyDirectory(ctx, file)
+			return nil
+		}
+		keydir := ctx.Args().First()
+		if keydir == "" {
+			utils.Fatalf("Keystore directory not specified")
+		}
+		if !ctx.IsSet(addressFlag.Name) {
+			utils.Fatalf("The --address flag is required to select the signing account")
+		}
+		var signer common.Address
+		if err := signer.UnmarshalText([]byte(ctx.String(addressFlag.Name))); err != nil {
+			utils.Fatalf("Invalid address %s: %v", ctx.String(addressFlag.Name), err)
+		}
+		tags := make(map[common.Address][]string)
+		if file := ctx.String("tags"); file != "" {
+			var err error
+			if tags, err = readTags(file); err != nil {
+				utils.Fatalf("Failed to read tags file: %v", err)
+			}
+		}
+		ks := keystore.NewKeyStore(keydir, keystore.LightScryptN, keystore.LightScryptP)
+		blob, err := ks.ExportDirectory(accounts.Account{Address: signer}, getPassphrase(ctx, false), nil, tags)
+		if err != nil {
+			utils.Fatalf("Failed to export directory: %v", err)
+		}
+		if out := ctx.String("out"); out != "" {
+			if err := ioutil.WriteFile(out, append(blob, '\n'), 0644); err != nil {
+				utils.Fatalf("Failed to write directory: %v", err)
+			}
+			return nil
+		}
+		fmt.Println(string(blob))
+		return nil
+	},
+}
+
+// verifyDirectory verifies a directory file and lists its accounts.
+func verifyDirectory(ctx *cli.Context, file string) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		utils.Fatalf("Failed to read directory: %v", err)
+	}
+	dir, err := keystore.VerifyDirectory(blob)
+	if err != nil {
+		utils.Fatalf("Directory verification failed: %v", err)
+	}
+	if ctx.Bool(jsonFlag.Name) {
+		mustPrintJSON(dir)
+		return
+	}
+	fmt.Printf("Directory signed by %s on %v\n", dir.Signer, dir.Created)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Address", "FFF", "Created", "Tags"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, entry := range dir.Accounts {
+		created := ""
+		if entry.Created != nil {
+			created = entry.Created.Format("2006-01-02 15:04:05")
+		}
+		table.Append([]string{entry.Address, entry.FFF, created, strings.Join(entry.Tags, ",")})
+	}
+	table.Render()
+}
+
+// readTags reads a file mapping account addresses to comma separated tags.
+func readTags(file string) (map[common.Address][]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	tags := make(map[common.Address][]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"<address> <tag>[,<tag>...]\"", line)
+		}
+		var address common.Address
+		if err := address.UnmarshalText([]byte(fields[0])); err != nil {
+			return nil, fmt.Errorf("line %d: invalid address: %v", line, err)
+		}
+		tags[address] = append(tags[address], strings.Split(fields[1], ",")...)
+	}
+	return tags, scanner.Err()
+}
@@ -0,0 +1,143 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/binary"
+	"math/big"
+	"os"
+	"strconv"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/common/math"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// defaultFixtureSeed is the well-known seed of the default test accounts. Keys
+// derived from it are public and must never hold real funds.
+const defaultFixtureSeed = "3fcoin test fixtures"
+
+type outputFixture struct {
+	Index      int
+	Address    string
+	AddressHex string
+	PrivateKey string
+	Balance    *math.HexOrDecimal256
+}
+
+var commandFixtures = cli.Command{
+	Name:  "fixtures",
+	Usage: "derive deterministic test-chain accounts from a seed",
+	Description: `
+Derive a reproducible set of keys from a seed string, printing their FFF and hex
+addresses, private keys and balances. The same seed always yields the same
+accounts, giving CI environments stable addresses across runs.
+
+With --genesis, a genesis alloc section funding the accounts is printed instead.
+
+The derived keys are not secret. Never use them outside of test networks.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		cli.StringFlag{
+			Name:  "seed",
+			Usage: "seed string to derive the accounts from",
+			Value: defaultFixtureSeed,
+		},
+		cli.IntFlag{
+			Name:  "count",
+			Usage: "number of accounts to derive",
+			Value: 10,
+		},
+		cli.StringFlag{
+			Name:  "balance",
+			Usage: "balance of each account in wei (decimal or 0x hex)",
+			Value: "10000000000000000000000",
+		},
+		cli.BoolFlag{
+			Name:  "genesis",
+			Usage: "print a genesis alloc section funding the accounts",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		count := ctx.Int("count")
+		if count <= 0 {
+			utils.Fatalf("Invalid account count %d", count)
+		}
+		balance, ok := math.ParseBig256(ctx.String("balance"))
+		if !ok {
+			utils.Fatalf("Invalid balance %q", ctx.String("balance"))
+		}
+		keys := deriveFixtureKeys(ctx.String("seed"), count)
+
+		if ctx.Bool("genesis") {
+			alloc := make(core.GenesisAlloc, len(keys))
+			for _, key := range keys {
+				alloc[crypto.PubkeyToAddress(key.PublicKey)] = core.GenesisAccount{Balance: new(big.Int).Set(balance)}
+			}
+			mustPrintJSON(map[string]interface{}{"alloc": alloc})
+			return nil
+		}
+		fixtures := make([]outputFixture, len(keys))
+		for i, key := range keys {
+			address := crypto.PubkeyToAddress(key.PublicKey)
+			fixtures[i] = outputFixture{
+				Index:      i,
+				Address:    address.Hex(),
+				AddressHex: hexutil.Encode(address.Bytes()),
+				PrivateKey: hexutil.Encode(crypto.FromECDSA(key)),
+				Balance:    (*math.HexOrDecimal256)(balance),
+			}
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(fixtures)
+			return nil
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"#", "Address", "Hex", "Private key", "Balance"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, fixture := range fixtures {
+			table.Append([]string{strconv.Itoa(fixture.Index), fixture.Address, fixture.AddressHex, fixture.PrivateKey, balance.String()})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// deriveFixtureKeys derives count private keys from a seed string. Key i is
+// the first valid secp256k1 scalar of keccak256(seed || i || attempt).
+func deriveFixtureKeys(seed string, count int) []*ecdsa.PrivateKey {
+	keys := make([]*ecdsa.PrivateKey, 0, count)
+	for i := 0; i < count; i++ {
+		for attempt := uint32(0); ; attempt++ {
+			var suffix [8]byte
+			binary.BigEndian.PutUint32(suffix[:4], uint32(i))
+			binary.BigEndian.PutUint32(suffix[4:], attempt)
+
+			key, err := crypto.ToECDSA(crypto.Keccak256([]byte(seed), suffix[:]))
+			if err == nil {
+				keys = append(keys, key)
+				break
+			}
+		}
+	}
+	return keys
+}
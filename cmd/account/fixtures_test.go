@@ -0,0 +1,74 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// Tests that the same seed always derives the same accounts, and a different
+// one different accounts.
+func TestFixturesDeterministic(t *testing.T) {
+	derive := func(args ...string) []outputFixture {
+		var fixtures []outputFixture
+		cmd := runAccount(t, append([]string{"fixtures", "--json", "--count", "3"}, args...)...)
+		cmd.expectJSON(&fixtures)
+		cmd.ExpectExit()
+		return fixtures
+	}
+	first, second := derive(), derive()
+	if len(first) != 3 {
+		t.Fatalf("fixture count mismatch: have %d, want 3", len(first))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("fixtures not deterministic:\n%+v\n%+v", first, second)
+	}
+	// Key i is keccak256(seed || i || attempt), checked against the spec
+	want := crypto.Keccak256([]byte(defaultFixtureSeed), []byte{0, 0, 0, 1, 0, 0, 0, 0})
+	if first[1].PrivateKey != hexutil.Encode(want) {
+		t.Errorf("key derivation mismatch: have %s, want %x", first[1].PrivateKey, want)
+	}
+	for i, fixture := range first {
+		key, err := crypto.HexToECDSA(fixture.PrivateKey[2:])
+		if err != nil {
+			t.Fatalf("fixture %d: invalid private key: %v", i, err)
+		}
+		if have := crypto.PubkeyToAddress(key.PublicKey).Hex(); have != fixture.Address {
+			t.Errorf("fixture %d: address mismatch: have %s, want %s", i, fixture.Address, have)
+		}
+		if (*big.Int)(fixture.Balance).String() != "10000000000000000000000" {
+			t.Errorf("fixture %d: balance mismatch: have %v", i, fixture.Balance)
+		}
+	}
+	other := derive("--seed", "another seed")
+	for i := range other {
+		if other[i].PrivateKey == first[i].PrivateKey {
+			t.Errorf("fixture %d: seeds derive the same key", i)
+		}
+	}
+}
+
+func TestFixturesInvalidCount(t *testing.T) {
+	fixtures := runAccount(t, "fixtures", "--count", "0")
+	fixtures.Expect("Fatal: Invalid account count 0\n")
+	fixtures.ExpectExit()
+}
@@ -0,0 +1,252 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/abi/bind"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/units"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/ethclient"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputFund struct {
+	Address string
+	Tx      string
+	Block   uint64 `json:",omitempty"`
+	Status  string
+}
+
+var commandFund = cli.Command{
+	Name:      "fund",
+	Usage:     "fund a batch of accounts from a funder key via a running node",
+	ArgsUsage: "<funder keyfile|keydir> [<address>...]",
+	Description: `
+Transfer the same amount to each of a list of accounts, signing the transfers
+with the funder key and broadcasting them to the node at --rpc. Nonces are
+assigned locally starting from the funder's pending nonce, and the command
+waits for all receipts before reporting.
+
+Targets are the FFF or hex addresses given as arguments, read from --targets
+(one per line, # starts a comment), or a batch of --generate fresh accounts
+stored into the --keydir keystore.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		keyringFlag,
+		keyringTTLFlag,
+		newPassphraseFlag,
+		jsonFlag,
+		addressFlag,
+		rpcFlag,
+		decimalsFlag,
+		localeFlag,
+		cli.StringFlag{
+			Name:  "amount",
+			Usage: "amount to send to each account (e.g. 1.5FFF, 20gwei; wei if no unit given)",
+		},
+		cli.StringFlag{
+			Name:  "targets",
+			Usage: "file listing the addresses to fund",
+		},
+		cli.IntFlag{
+			Name:  "generate",
+			Usage: "number of fresh accounts to generate and fund",
+		},
+		cli.StringFlag{
+			Name:  "keydir",
+			Usage: "keystore directory to store generated accounts in",
+		},
+		cli.StringFlag{
+			Name:  "gasprice",
+			Usage: "gas price (e.g. 5gwei; wei if no unit given, default = suggested by the node)",
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "maximum time to wait for the transactions to be mined",
+			Value: 2 * time.Minute,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) < 1 {
+			utils.Fatalf("Funder key file or keystore directory required")
+		}
+		amount, err := units.Parse(ctx.String("amount"), units.Wei, units.RoundExact)
+		if err != nil || amount.Sign() <= 0 {
+			utils.Fatalf("Invalid amount %q", ctx.String("amount"))
+		}
+		style := amountStyle(ctx, units.FFF)
+
+		targets := fundTargets(ctx)
+		if len(targets) == 0 {
+			utils.Fatalf("No accounts to fund")
+		}
+		funder := loadSigningKey(ctx, ctx.Args().First())
+
+		client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+		if err != nil {
+			utils.Fatalf("Failed to connect to %s: %v", ctx.String(rpcFlag.Name), err)
+		}
+		background := context.Background()
+
+		chainID, err := client.ChainID(background)
+		if err != nil {
+			utils.Fatalf("Failed to retrieve chain ID: %v", err)
+		}
+		var gasPrice *big.Int
+		if ctx.IsSet("gasprice") {
+			if gasPrice, err = units.Parse(ctx.String("gasprice"), units.Wei, units.RoundExact); err != nil || gasPrice.Sign() < 0 {
+				utils.Fatalf("Invalid gas price %q", ctx.String("gasprice"))
+			}
+		} else if gasPrice, err = client.SuggestGasPrice(background); err != nil {
+			utils.Fatalf("Failed to retrieve gas price: %v", err)
+		}
+		// Make sure the funder can afford the whole batch before sending any
+		cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(params.TxGas))
+		cost.Add(cost, amount)
+		cost.Mul(cost, big.NewInt(int64(len(targets))))
+
+		balance, err := client.PendingBalanceAt(background, funder.Address)
+		if err != nil {
+			utils.Fatalf("Failed to retrieve funder balance: %v", err)
+		}
+		if balance.Cmp(cost) < 0 {
+			utils.Fatalf("Insufficient funds of %s: have %s, need %s", funder.Address.Hex(), style.Format(balance), style.Format(cost))
+		}
+		nonce, err := client.PendingNonceAt(background, funder.Address)
+		if err != nil {
+			utils.Fatalf("Failed to retrieve funder nonce: %v", err)
+		}
+		// Sign and send all transfers, then wait for them to be mined
+		signer := types.LatestSignerForChainID(chainID)
+
+		txs := make([]*types.Transaction, len(targets))
+		for i, target := range targets {
+			tx, err := types.SignTx(types.NewTransaction(nonce+uint64(i), target, amount, params.TxGas, gasPrice, nil), signer, funder.PrivateKey)
+			if err != nil {
+				utils.Fatalf("Failed to sign transfer to %s: %v", target.Hex(), err)
+			}
+			if err := client.SendTransaction(background, tx); err != nil {
+				utils.Fatalf("Failed to send transfer to %s: %v", target.Hex(), err)
+			}
+			txs[i] = tx
+		}
+		timeout, cancel := context.WithTimeout(background, ctx.Duration("timeout"))
+		defer cancel()
+
+		results := make([]outputFund, len(targets))
+		failed := 0
+		for i, tx := range txs {
+			results[i] = outputFund{Address: targets[i].Hex(), Tx: tx.Hash().Hex()}
+
+			receipt, err := bind.WaitMined(timeout, client, tx)
+			switch {
+			case err != nil:
+				results[i].Status = "pending"
+				failed++
+			case receipt.Status != types.ReceiptStatusSuccessful:
+				results[i].Block, results[i].Status = receipt.BlockNumber.Uint64(), "failed"
+				failed++
+			default:
+				results[i].Block, results[i].Status = receipt.BlockNumber.Uint64(), "funded"
+			}
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(results)
+		} else {
+			fmt.Printf("Funding %d accounts with %s each at %s per gas\n", len(targets), style.Format(amount), amountStyle(ctx, units.GWei).Format(gasPrice))
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Address", "Transaction", "Block", "Status"})
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			for _, res := range results {
+				table.Append([]string{res.Address, res.Tx, strconv.FormatUint(res.Block, 10), res.Status})
+			}
+			table.Render()
+		}
+		if failed > 0 {
+			utils.Fatalf("%d of %d transfers not funded", failed, len(targets))
+		}
+		return nil
+	},
+}
+
+// fundTargets collects the accounts to fund from the command line arguments,
+// the --targets file and freshly generated accounts.
+func fundTargets(ctx *cli.Context) []common.Address {
+	var targets []common.Address
+	for _, arg := range ctx.Args().Tail() {
+		var address common.Address
+		if err := address.UnmarshalText([]byte(arg)); err != nil {
+			utils.Fatalf("Invalid address %s: %v", arg, err)
+		}
+		targets = append(targets, address)
+	}
+	if file := ctx.String("targets"); file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			utils.Fatalf("Failed to open targets file: %v", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" || strings.HasPrefix(text, "#") {
+				continue
+			}
+			var address common.Address
+			if err := address.UnmarshalText([]byte(text)); err != nil {
+				utils.Fatalf("Invalid address on line %d of %s: %v", line, file, err)
+			}
+			targets = append(targets, address)
+		}
+		if err := scanner.Err(); err != nil {
+			utils.Fatalf("Failed to read targets file: %v", err)
+		}
+	}
+	if count := ctx.Int("generate"); count > 0 {
+		keydir := ctx.String("keydir")
+		if keydir == "" {
+			utils.Fatalf("The --keydir flag is required to generate accounts")
+		}
+		passphrase := getNewPassphrase(ctx)
+
+		ks := keystore.NewKeyStore(keydir, keystore.StandardScryptN, keystore.StandardScryptP)
+		for i := 0; i < count; i++ {
+			account, err := ks.NewAccount(passphrase)
+			if err != nil {
+				utils.Fatalf("Failed to generate account: %v", err)
+			}
+			targets = append(targets, account.Address)
+		}
+	}
+	return targets
+}
@@ -0,0 +1,183 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/hsm"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	hsmModuleFlag = cli.StringFlag{
+		Name:  "module",
+		Usage: "path of the PKCS#11 library of the HSM (e.g. /usr/lib/softhsm/libsofthsm2.so)",
+	}
+	hsmSlotFlag = cli.Uint64Flag{
+		Name:  "slot",
+		Usage: "token slot to use (default = first slot with a token present)",
+	}
+	hsmPinFlag = cli.StringFlag{
+		Name:  "pinfile",
+		Usage: "the file that contains the user PIN of the token",
+	}
+)
+
+type outputHSMKey struct {
+	Address    string
+	AddressHex string
+	URL        string
+}
+
+var commandHSM = cli.Command{
+	Name:  "hsm",
+	Usage: "generate and list keys on a PKCS#11 hardware security module",
+	Description: `
+Manage secp256k1 keys stored on a token of a hardware security module, accessed
+through the vendor's PKCS#11 library given with --module. Private keys are
+generated on the token and never leave it, the tool only prints the FFF and hex
+addresses they control.`,
+	Subcommands: []cli.Command{
+		{
+			Name:  "generate",
+			Usage: "generate a new key on the token",
+			Flags: []cli.Flag{
+				jsonFlag,
+				hsmModuleFlag,
+				hsmSlotFlag,
+				hsmPinFlag,
+				cli.StringFlag{
+					Name:  "label",
+					Usage: "label of the generated key objects",
+					Value: "fff",
+				},
+			},
+			Action: generateHSMKey,
+		},
+		{
+			Name:   "list",
+			Usage:  "list the keys stored on the token",
+			Flags:  []cli.Flag{jsonFlag, hsmModuleFlag, hsmSlotFlag, hsmPinFlag},
+			Action: listHSMKeys,
+		},
+	},
+}
+
+// openHSMWallet loads the PKCS#11 library given on the command line and logs
+// into the selected token. The caller must close the wallet and the module.
+func openHSMWallet(ctx *cli.Context) (*hsm.PKCS11Module, *hsm.Wallet) {
+	path := ctx.String(hsmModuleFlag.Name)
+	if path == "" {
+		utils.Fatalf("The --%s flag is required", hsmModuleFlag.Name)
+	}
+	module, err := hsm.OpenModule(path)
+	if err != nil {
+		utils.Fatalf("Failed to load PKCS#11 module: %v", err)
+	}
+	hub, err := hsm.NewHub(module)
+	if err != nil {
+		module.Close()
+		utils.Fatalf("Failed to enumerate tokens: %v", err)
+	}
+	var wallet *hsm.Wallet
+	for _, w := range hub.Wallets() {
+		if !ctx.IsSet(hsmSlotFlag.Name) || w.URL().Path == fmt.Sprintf("slot/%d", ctx.Uint64(hsmSlotFlag.Name)) {
+			wallet = w.(*hsm.Wallet)
+			break
+		}
+	}
+	if wallet == nil {
+		module.Close()
+		utils.Fatalf("No token found in the selected slot")
+	}
+	var pin string
+	if file := ctx.String(hsmPinFlag.Name); file != "" {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			module.Close()
+			utils.Fatalf("Failed to read PIN file '%s': %v", file, err)
+		}
+		pin = strings.TrimRight(string(content), "\r\n")
+	} else {
+		pin = utils.GetPassPhrase(fmt.Sprintf("Please enter the user PIN of %s", wallet.URL()), false)
+	}
+	if err := wallet.Open(pin); err != nil {
+		module.Close()
+		utils.Fatalf("Failed to log into token: %v", err)
+	}
+	return module, wallet
+}
+
+// newOutputHSMKey renders an account on a token for output.
+func newOutputHSMKey(account accounts.Account) outputHSMKey {
+	return outputHSMKey{
+		Address:    account.Address.Hex(),
+		AddressHex: hexutil.Encode(account.Address.Bytes()),
+		URL:        account.URL.String(),
+	}
+}
+
+func generateHSMKey(ctx *cli.Context) error {
+	module, wallet := openHSMWallet(ctx)
+	defer module.Close()
+	defer wallet.Close()
+
+	account, err := wallet.NewAccount(ctx.String("label"))
+	if err != nil {
+		utils.Fatalf("Failed to generate key: %v", err)
+	}
+	out := newOutputHSMKey(account)
+	if ctx.Bool(jsonFlag.Name) {
+		mustPrintJSON(out)
+		return nil
+	}
+	fmt.Println("Address:    ", out.Address)
+	fmt.Println("Address hex:", out.AddressHex)
+	fmt.Println("URL:        ", out.URL)
+	return nil
+}
+
+func listHSMKeys(ctx *cli.Context) error {
+	module, wallet := openHSMWallet(ctx)
+	defer module.Close()
+	defer wallet.Close()
+
+	var keys []outputHSMKey
+	for _, account := range wallet.Accounts() {
+		keys = append(keys, newOutputHSMKey(account))
+	}
+	if ctx.Bool(jsonFlag.Name) {
+		mustPrintJSON(keys)
+		return nil
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Address", "Hex", "URL"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, key := range keys {
+		table.Append([]string{key.Address, key.AddressHex, key.URL})
+	}
+	table.Render()
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that HSM commands fail cleanly without a loadable PKCS#11 module.
+func TestHSMArguments(t *testing.T) {
+	dir, _, passfile, _ := tmpKeystore(t, 0)
+	defer os.RemoveAll(dir)
+
+	missing := filepath.Join(dir, "libmissing.so")
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{
+			args: []string{"hsm", "generate"},
+			want: `^Fatal: The --module flag is required\n`,
+		},
+		{
+			args: []string{"hsm", "list", "--pinfile", passfile},
+			want: `^Fatal: The --module flag is required\n`,
+		},
+		{
+			args: []string{"hsm", "generate", "--module", missing, "--pinfile", passfile},
+			want: `^Fatal: Failed to load PKCS#11 module: .+\n`,
+		},
+	}
+	for _, tt := range tests {
+		cmd := runAccount(t, tt.args...)
+		cmd.ExpectRegexp(tt.want)
+		cmd.ExpectExit()
+		if status := cmd.ExitStatus(); status != 1 {
+			t.Errorf("%v: exit status mismatch: have %d, want 1", tt.args, status)
+		}
+	}
+}
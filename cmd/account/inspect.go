@@ -0,0 +1,173 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/units"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+	"github.com/liuguodong24-8/3fcoin/core/ethclient"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputInspect struct {
+	Address string
+	Nonce   uint64
+	Pending uint64
+	Balance string
+	Wei     string
+	Genesis *bool `json:",omitempty"`
+
+	balance *big.Int
+}
+
+var commandInspect = cli.Command{
+	Name:      "inspect",
+	Usage:     "print the nonces and balances of all accounts in a keystore",
+	ArgsUsage: "<keydir>",
+	Description: `
+Query the node at --rpc for the nonce (mined and pending) and the balance of
+every account in the keystore directory. No keys are decrypted.
+
+With --genesis, the accounts are also checked against the alloc of the given
+genesis file; otherwise the genesis state of the node is queried for them,
+which requires the node to still have it.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		rpcFlag,
+		decimalsFlag,
+		localeFlag,
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "maximum time to wait for all the queries",
+			Value: time.Minute,
+		},
+		cli.StringFlag{
+			Name:  "genesis",
+			Usage: "genesis file to check the accounts against",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		keydir := ctx.Args().First()
+		if keydir == "" {
+			utils.Fatalf("Keystore directory not specified")
+		}
+		accounts := keystore.NewKeyStore(keydir, keystore.LightScryptN, keystore.LightScryptP).Accounts()
+		if len(accounts) == 0 {
+			utils.Fatalf("No accounts found in %s", keydir)
+		}
+		var alloc core.GenesisAlloc
+		if file := ctx.String("genesis"); file != "" {
+			blob, err := ioutil.ReadFile(file)
+			if err != nil {
+				utils.Fatalf("Failed to read genesis file: %v", err)
+			}
+			genesis := new(core.Genesis)
+			if err := json.Unmarshal(blob, genesis); err != nil {
+				utils.Fatalf("Invalid genesis file: %v", err)
+			}
+			alloc = genesis.Alloc
+		}
+		client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+		if err != nil {
+			utils.Fatalf("Failed to connect to %s: %v", ctx.String(rpcFlag.Name), err)
+		}
+		timeout, cancel := context.WithTimeout(context.Background(), ctx.Duration("timeout"))
+		defer cancel()
+
+		results := make([]outputInspect, len(accounts))
+		for i, account := range accounts {
+			address := account.Address
+			nonce, err := client.NonceAt(timeout, address, nil)
+			if err != nil {
+				utils.Fatalf("Failed to retrieve nonce of %s: %v", address.Hex(), err)
+			}
+			pending, err := client.PendingNonceAt(timeout, address)
+			if err != nil {
+				utils.Fatalf("Failed to retrieve pending nonce of %s: %v", address.Hex(), err)
+			}
+			balance, err := client.BalanceAt(timeout, address, nil)
+			if err != nil {
+				utils.Fatalf("Failed to retrieve balance of %s: %v", address.Hex(), err)
+			}
+			results[i] = outputInspect{
+				Address: address.Hex(),
+				Nonce:   nonce,
+				Pending: pending,
+				Balance: units.Format(balance, units.FFF),
+				Wei:     balance.String(),
+				balance: balance,
+			}
+			if alloc != nil {
+				_, ok := alloc[address]
+				results[i].Genesis = &ok
+			} else if allocated, err := genesisAllocated(timeout, client, address); err == nil {
+				results[i].Genesis = &allocated
+			}
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(results)
+			return nil
+		}
+		// Show the balances rounded as asked for, next to their exact wei
+		style, exact := amountStyle(ctx, units.FFF), amountStyle(ctx, units.Wei)
+		exact.Decimals, exact.Suffix = -1, false
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Address", "Nonce", "Pending", "Balance", "Wei", "Genesis"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, res := range results {
+			genesis := "unknown"
+			if res.Genesis != nil {
+				genesis = strconv.FormatBool(*res.Genesis)
+			}
+			table.Append([]string{res.Address, strconv.FormatUint(res.Nonce, 10), strconv.FormatUint(res.Pending, 10), style.Format(res.balance), exact.Format(res.balance), genesis})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// genesisAllocated checks via the genesis state of the node whether an account
+// was allocated in the genesis block, i.e. has any balance, nonce or code.
+func genesisAllocated(ctx context.Context, client *ethclient.Client, address common.Address) (bool, error) {
+	genesis := new(big.Int)
+	balance, err := client.BalanceAt(ctx, address, genesis)
+	if err != nil {
+		return false, err
+	}
+	nonce, err := client.NonceAt(ctx, address, genesis)
+	if err != nil {
+		return false, err
+	}
+	code, err := client.CodeAt(ctx, address, genesis)
+	if err != nil {
+		return false, err
+	}
+	return balance.Sign() > 0 || nonce > 0 || len(code) > 0, nil
+}
@@ -0,0 +1,461 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/console/prompt"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/enode"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandInteractive = cli.Command{
+	Name:      "interactive",
+	Usage:     "manage the accounts of a keystore directory in an interactive session",
+	ArgsUsage: "<keydir>",
+	Description: `
+Open an interactive session on a keystore directory to list and search its
+accounts, create new ones, export key files, sign messages, show the enode URL
+of node keys and change passwords, without a separate invocation for each.
+
+Accounts are referenced by their #index in the last listing, by their hex or
+FFF address, or by the short form of an FFF address shown in the listing.
+Tags to search accounts by are read from the --tags file, one
+"<address> <tag>[,<tag>...]" entry per line.
+
+Commands and addresses are completed with the tab key, type help within the
+session for the list of commands.`,
+	Flags: []cli.Flag{
+		keyringFlag,
+		keyringTTLFlag,
+		cli.StringFlag{
+			Name:  "tags",
+			Usage: "file mapping account addresses to comma separated tags",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		keydir := ctx.Args().First()
+		if keydir == "" {
+			utils.Fatalf("Keystore directory not specified")
+		}
+		s := &session{
+			keydir: keydir,
+			ks:     keystore.NewKeyStore(keydir, keystore.StandardScryptN, keystore.StandardScryptP),
+			tags:   make(map[common.Address][]string),
+			cache:  passphraseCache(ctx),
+		}
+		if file := ctx.String("tags"); file != "" {
+			var err error
+			if s.tags, err = readTags(file); err != nil {
+				utils.Fatalf("Failed to read tags file: %v", err)
+			}
+		}
+		s.run()
+		return nil
+	},
+}
+
+// errExit is returned by the exit command to end the session.
+var errExit = errors.New("exit")
+
+// sessionCommands are the commands of the interactive session.
+var sessionCommands = []struct {
+	name  string
+	args  string
+	usage string
+}{
+	{"list", "[<query>]", "list the accounts, or those with a tag or address matching the query"},
+	{"create", "", "create a new account"},
+	{"export", "<account> <file>", "export the key file of an account, encrypted with a new password"},
+	{"sign", "<account> <message>", "sign a message the way personal_sign does"},
+	{"enode", "<account>", "show the enode URL of an account used as node key"},
+	{"passwd", "<account>", "change the password of an account"},
+	{"help", "", "show the commands"},
+	{"exit", "", "leave the session"},
+}
+
+// session is an interactive session on a keystore directory.
+type session struct {
+	keydir string
+	ks     *keystore.KeyStore
+	tags   map[common.Address][]string
+	cache  *keystore.PassphraseCache // Keyring cache of the passphrases, nil if disabled
+
+	listed []accounts.Account // Accounts of the last listing, referenced by index
+}
+
+// run reads and executes commands until the session is left.
+func (s *session) run() {
+	prompt.Stdin.SetWordCompleter(s.complete)
+
+	fmt.Printf("Managing the keystore in %s, type help for the commands\n\n", s.keydir)
+	s.list("")
+
+	for {
+		line, err := prompt.Stdin.PromptInput("> ")
+		if err != nil {
+			// Interrupted or end of input
+			fmt.Println()
+			return
+		}
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		prompt.Stdin.AppendHistory(line)
+
+		if err := s.execute(line); err == errExit {
+			return
+		} else if err != nil {
+			fmt.Println("Error:", err)
+		}
+	}
+}
+
+// execute runs a single command line.
+func (s *session) execute(line string) error {
+	cmd, args := splitWord(line)
+	switch cmd {
+	case "list", "search":
+		s.list(args)
+		return nil
+
+	case "create":
+		return s.create()
+
+	case "export":
+		ref, file := splitWord(args)
+		if ref == "" || file == "" {
+			return errors.New("usage: export <account> <file>")
+		}
+		return s.export(ref, file)
+
+	case "sign":
+		ref, message := splitWord(args)
+		if ref == "" || message == "" {
+			return errors.New("usage: sign <account> <message>")
+		}
+		return s.sign(ref, message)
+
+	case "enode":
+		if args == "" {
+			return errors.New("usage: enode <account>")
+		}
+		return s.enode(args)
+
+	case "passwd":
+		if args == "" {
+			return errors.New("usage: passwd <account>")
+		}
+		return s.passwd(args)
+
+	case "help":
+		for _, c := range sessionCommands {
+			fmt.Printf("  %-28s %s\n", strings.TrimSpace(c.name+" "+c.args), c.usage)
+		}
+		return nil
+
+	case "exit", "quit":
+		return errExit
+
+	default:
+		return fmt.Errorf("unknown command %q, type help for the commands", cmd)
+	}
+}
+
+// list prints the accounts matching a query, all of them if it's empty. An
+// account matches if one of its tags equals the query or its hex or FFF
+// address contains it.
+func (s *session) list(query string) {
+	s.listed = s.listed[:0]
+	for _, account := range s.ks.Accounts() {
+		if query == "" || s.matches(account.Address, query) {
+			s.listed = append(s.listed, account)
+		}
+	}
+	if len(s.listed) == 0 {
+		fmt.Println("No matching accounts")
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"#", "Address", "Hex", "Tags"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for i, account := range s.listed {
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			account.Address.Short(),
+			common.AddressFormatHex.Address(account.Address),
+			strings.Join(s.tags[account.Address], ","),
+		})
+	}
+	table.Render()
+}
+
+// matches reports whether an account matches a list query.
+func (s *session) matches(address common.Address, query string) bool {
+	for _, tag := range s.tags[address] {
+		if strings.EqualFold(tag, query) {
+			return true
+		}
+	}
+	if strings.Contains(address.String(), query) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(common.AddressFormatHex.Address(address)), strings.ToLower(query))
+}
+
+// resolve looks up the account referenced by its #index in the last listing,
+// its address or the short form of its address.
+func (s *session) resolve(ref string) (accounts.Account, error) {
+	if strings.HasPrefix(ref, "#") {
+		index, err := strconv.Atoi(ref[1:])
+		if err != nil || index < 1 || index > len(s.listed) {
+			return accounts.Account{}, fmt.Errorf("no account %s in the last listing", ref)
+		}
+		return s.listed[index-1], nil
+	}
+	address, ok := common.ExpandShortAddress(ref)
+	if !ok {
+		if err := address.UnmarshalText([]byte(ref)); err != nil {
+			return accounts.Account{}, fmt.Errorf("invalid account %q: %v", ref, err)
+		}
+	}
+	return s.ks.Find(accounts.Account{Address: address})
+}
+
+// unlock runs op with the passphrase of an account, taken from the keyring if
+// cached there and prompted for otherwise. Passphrases op succeeds with are
+// cached in the keyring, if enabled.
+func (s *session) unlock(address common.Address, op func(passphrase string) error) error {
+	if s.cache != nil {
+		if passphrase, ok := s.cache.Get(address); ok {
+			err := op(passphrase)
+			if !errors.Is(err, keystore.ErrDecrypt) {
+				return err
+			}
+			s.cache.Forget(address) // Passphrase changed since cached
+		}
+	}
+	passphrase, err := prompt.Stdin.PromptPassword("Password: ")
+	if err != nil {
+		return err
+	}
+	if err := op(passphrase); err != nil {
+		return err
+	}
+	if s.cache != nil {
+		if err := s.cache.Put(address, passphrase); err != nil {
+			log.Warn("Failed to cache passphrase in keyring", "address", address, "err", err)
+		}
+	}
+	return nil
+}
+
+// create creates a new account.
+func (s *session) create() error {
+	passphrase, err := readNewPassphrase()
+	if err != nil {
+		return err
+	}
+	account, err := s.ks.NewAccount(passphrase)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Address:", account.Address)
+	fmt.Println("Keyfile:", account.URL.Path)
+	return nil
+}
+
+// export writes the key file of an account to file, encrypted with a new
+// passphrase.
+func (s *session) export(ref string, file string) error {
+	account, err := s.resolve(ref)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err == nil {
+		return fmt.Errorf("file %s already exists", file)
+	}
+	return s.unlock(account.Address, func(passphrase string) error {
+		// Check the passphrase before asking for the new one
+		if err := checkPassphrase(account, passphrase); err != nil {
+			return err
+		}
+		fmt.Println("Please provide a password for the exported key file")
+		newPassphrase, err := readNewPassphrase()
+		if err != nil {
+			return err
+		}
+		keyjson, err := s.ks.Export(account, passphrase, newPassphrase)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(file, keyjson, 0600); err != nil {
+			return err
+		}
+		fmt.Println("Exported", account.Address, "to", file)
+		return nil
+	})
+}
+
+// sign signs a message with an account the way personal_sign does.
+func (s *session) sign(ref string, message string) error {
+	account, err := s.resolve(ref)
+	if err != nil {
+		return err
+	}
+	hash := accounts.TextHash([]byte(message))
+	return s.unlock(account.Address, func(passphrase string) error {
+		signature, err := s.ks.SignHashWithPassphrase(account, passphrase, hash)
+		if err != nil {
+			return err
+		}
+		signature[crypto.RecoveryIDOffset] += 27
+
+		fmt.Println("Address:  ", account.Address)
+		fmt.Println("Hash:     ", hexutil.Encode(hash))
+		fmt.Println("Signature:", hexutil.Encode(signature))
+		return nil
+	})
+}
+
+// enode shows the enode URL of an account used as node key.
+func (s *session) enode(ref string) error {
+	account, err := s.resolve(ref)
+	if err != nil {
+		return err
+	}
+	keyjson, err := ioutil.ReadFile(account.URL.Path)
+	if err != nil {
+		return err
+	}
+	return s.unlock(account.Address, func(passphrase string) error {
+		key, err := keystore.DecryptKey(keyjson, passphrase)
+		if err != nil {
+			return err
+		}
+		fmt.Println(enode.NewV4(&key.PrivateKey.PublicKey, nil, 0, 0).URLv4())
+		return nil
+	})
+}
+
+// passwd changes the passphrase of an account.
+func (s *session) passwd(ref string) error {
+	account, err := s.resolve(ref)
+	if err != nil {
+		return err
+	}
+	var newPassphrase string
+	err = s.unlock(account.Address, func(passphrase string) error {
+		// Check the passphrase before asking for the new one
+		if err := checkPassphrase(account, passphrase); err != nil {
+			return err
+		}
+		fmt.Println("Please provide the new password")
+		if newPassphrase, err = readNewPassphrase(); err != nil {
+			return err
+		}
+		return s.ks.Update(account, passphrase, newPassphrase)
+	})
+	if err != nil {
+		return err
+	}
+	if s.cache != nil {
+		if err := s.cache.Put(account.Address, newPassphrase); err != nil {
+			log.Warn("Failed to cache passphrase in keyring", "address", account.Address, "err", err)
+		}
+	}
+	fmt.Println("Password of", account.Address, "changed")
+	return nil
+}
+
+// checkPassphrase verifies that a passphrase decrypts the key of an account.
+func checkPassphrase(account accounts.Account, passphrase string) error {
+	keyjson, err := ioutil.ReadFile(account.URL.Path)
+	if err != nil {
+		return err
+	}
+	_, err = keystore.DecryptKey(keyjson, passphrase)
+	return err
+}
+
+// complete completes the commands of the session and the addresses of the
+// accounts they operate on.
+func (s *session) complete(line string, pos int) (string, []string, string) {
+	head, tail := line[:pos], line[pos:]
+
+	start := strings.LastIndex(head, " ") + 1
+	prefix, word := head[:start], head[start:]
+
+	var candidates []string
+	if start == 0 {
+		for _, c := range sessionCommands {
+			candidates = append(candidates, c.name)
+		}
+	} else if strings.Count(strings.TrimSpace(prefix), " ") == 0 {
+		for _, account := range s.ks.Accounts() {
+			candidates = append(candidates, account.Address.String())
+		}
+	}
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, word) {
+			completions = append(completions, candidate)
+		}
+	}
+	sort.Strings(completions)
+	return prefix, completions, tail
+}
+
+// splitWord splits the first whitespace separated word off a string.
+func splitWord(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], strings.TrimSpace(s[i:])
+	}
+	return s, ""
+}
+
+// readNewPassphrase prompts for a new passphrase along with its confirmation.
+func readNewPassphrase() (string, error) {
+	passphrase, err := prompt.Stdin.PromptPassword("Password: ")
+	if err != nil {
+		return "", err
+	}
+	confirm, err := prompt.Stdin.PromptPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if passphrase != confirm {
+		return "", errors.New("passwords do not match")
+	}
+	return passphrase, nil
+}
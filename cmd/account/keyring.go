@@ -0,0 +1,68 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// passphraseCache returns the cache of passphrases in the keyring of the
+// operating system if enabled by the --keyring flag, nil otherwise or if the
+// platform has no keyring.
+func passphraseCache(ctx *cli.Context) *keystore.PassphraseCache {
+	if !ctx.Bool(keyringFlag.Name) {
+		return nil
+	}
+	ring, err := keystore.OSKeyring()
+	if err != nil {
+		log.Warn("Keyring unavailable, passphrases won't be cached", "err", err)
+		return nil
+	}
+	return keystore.NewPassphraseCache(ring, ctx.Duration(keyringTTLFlag.Name))
+}
+
+// decryptKeyfile decrypts a key file with its passphrase cached in the keyring,
+// if enabled, falling back to the one of the --passwordfile flag or prompted
+// for. Passphrases decrypting the key are cached for the next operations.
+func decryptKeyfile(ctx *cli.Context, keyjson []byte) (*keystore.Key, error) {
+	cache := passphraseCache(ctx)
+	if cache == nil {
+		return keystore.DecryptKey(keyjson, getPassphrase(ctx, false))
+	}
+	address, err := keyfileAddress(keyjson)
+	if err != nil {
+		return nil, err
+	}
+
+	if passphrase, ok := cache.Get(address); ok {
+		if key, err := keystore.DecryptKey(keyjson, passphrase); err == nil {
+			return key, nil
+		}
+		cache.Forget(address) // Passphrase changed since cached
+	}
+	passphrase := getPassphrase(ctx, false)
+	key, err := keystore.DecryptKey(keyjson, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.Put(address, passphrase); err != nil {
+		log.Warn("Failed to cache passphrase in keyring", "address", address, "err", err)
+	}
+	return key, nil
+}
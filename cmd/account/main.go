@@ -0,0 +1,106 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+// account is a command line tool to manage the FFF accounts and node keys
+// stored in a keystore directory.
+package main
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/lib/flags"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "an FFF account and node key manager")
+	app.Commands = []cli.Command{
+		commandProbe,
+		commandFixtures,
+		commandMigrate,
+		commandUpgrade,
+		commandSignMessage,
+		commandVerifyMessage,
+		commandFund,
+		commandName,
+		commandQRExport,
+		commandQRImport,
+		commandDirectory,
+		commandInspect,
+		commandRotate,
+		commandManifest,
+		commandSweep,
+		commandShow,
+		commandInteractive,
+		commandHSM,
+	}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
+
+// Commonly used command line flags.
+var (
+	passphraseFlag = cli.StringFlag{
+		Name:  "passwordfile",
+		Usage: "the file that contains the password for the keyfiles",
+	}
+	newPassphraseFlag = cli.StringFlag{
+		Name:  "newpasswordfile",
+		Usage: "the file that contains the password for the new keyfiles",
+	}
+	jsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "output JSON instead of human-readable format",
+	}
+	rpcFlag = cli.StringFlag{
+		Name:  "rpc",
+		Usage: "RPC endpoint of the node to send the transactions to",
+		Value: "http://localhost:8545",
+	}
+	keyringFlag = cli.BoolFlag{
+		Name:  "keyring",
+		Usage: "cache the passphrases of signing keys in the keyring of the operating system",
+	}
+	keyringTTLFlag = cli.DurationFlag{
+		Name:  "keyring.ttl",
+		Usage: "time passphrases stay cached in the keyring",
+		Value: 15 * time.Minute,
+	}
+	decimalsFlag = cli.IntFlag{
+		Name:  "decimals",
+		Usage: "number of fractional digits to round displayed amounts to (default = exact)",
+		Value: -1,
+	}
+	localeFlag = cli.StringFlag{
+		Name:  "locale",
+		Usage: "number format of displayed amounts (plain, en, de, fr, ch)",
+		Value: "en",
+	}
+)
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
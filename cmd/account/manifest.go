@@ -0,0 +1,285 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// manifestVersion is the version of the keystore manifest format.
+const manifestVersion = 1
+
+// manifestEntry is a single key file listed in a keystore manifest.
+type manifestEntry struct {
+	File    string `json:"file"`    // Name of the key file within the keystore directory
+	SHA256  string `json:"sha256"`  // Hex SHA-256 hash of the key file contents
+	Address string `json:"address"` // EIP-55 hex address of the key
+	FFF     string `json:"fff"`     // FFF address of the key
+}
+
+// manifestBody is the signed part of a keystore manifest.
+type manifestBody struct {
+	Version int             `json:"version"`
+	Created time.Time       `json:"created"`
+	Host    string          `json:"host,omitempty"`
+	Files   []manifestEntry `json:"files"`
+}
+
+// keystoreManifest is a signed listing of the key files of a keystore directory.
+// The signature is a personal_sign (EIP-191) one over the compact JSON encoding
+// of the body, so it can be checked with any wallet or ecrecover.
+type keystoreManifest struct {
+	manifestBody
+	Signer    string        `json:"signer"`
+	Signature hexutil.Bytes `json:"signature"`
+}
+
+type outputManifestCheck struct {
+	File    string
+	Address string
+	Status  string
+}
+
+var commandManifest = cli.Command{
+	Name:      "manifest",
+	Usage:     "create or verify a signed checksum manifest of a keystore directory",
+	ArgsUsage: "<keydir>",
+	Description: `
+Create a manifest of all the key files in a keystore directory, listing their
+SHA-256 hashes and addresses, signed with the key given by --key (a key file,
+or a keystore directory together with --address). Distributing the manifest
+along with the keystore allows every host of a fleet to check that its keys
+were neither tampered with nor drifted.
+
+The signature is made the way personal_sign does (EIP-191) over the compact
+JSON encoding of the manifest without the signer and signature fields, so it
+can be verified with any wallet or ecrecover.
+
+With --verify, the keystore directory is checked against a manifest instead.
+Modified, missing and unexpected key files are reported, and the command exits
+with a non-zero status if any is found. Giving --signer requires the manifest
+to be signed by that FFF or hex address.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		keyringFlag,
+		keyringTTLFlag,
+		addressFlag,
+		jsonFlag,
+		cli.StringFlag{
+			Name:  "key",
+			Usage: "key file or keystore directory of the key to sign the manifest with",
+		},
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the manifest to (default = stdout)",
+		},
+		cli.StringFlag{
+			Name:  "verify",
+			Usage: "manifest file to verify the keystore directory against",
+		},
+		cli.StringFlag{
+			Name:  "signer",
+			Usage: "address the verified manifest must be signed by",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		keydir := ctx.Args().First()
+		if keydir == "" {
+			utils.Fatalf("Keystore directory not specified")
+		}
+		if file := ctx.String("verify"); file != "" {
+			verifyManifest(ctx, keydir, file)
+			return nil
+		}
+		if ctx.String("key") == "" {
+			utils.Fatalf("The --key flag is required to sign the manifest")
+		}
+		files, err := manifestEntries(keydir)
+		if err != nil {
+			utils.Fatalf("Failed to hash key files: %v", err)
+		}
+		manifest := &keystoreManifest{manifestBody: manifestBody{
+			Version: manifestVersion,
+			Created: time.Now().UTC().Truncate(time.Second),
+			Files:   files,
+		}}
+		manifest.Host, _ = os.Hostname()
+
+		key := loadSigningKey(ctx, ctx.String("key"))
+		signature, err := crypto.Sign(manifest.sigHash(), key.PrivateKey)
+		if err != nil {
+			utils.Fatalf("Failed to sign manifest: %v", err)
+		}
+		signature[crypto.RecoveryIDOffset] += 27
+		manifest.Signer, manifest.Signature = common.AddressFormatHex.Address(key.Address), signature
+
+		blob, err := json.MarshalIndent(manifest, "", "  ")
+		if err != nil {
+			utils.Fatalf("Failed to encode manifest: %v", err)
+		}
+		if out := ctx.String("out"); out != "" {
+			if err := ioutil.WriteFile(out, append(blob, '\n'), 0644); err != nil {
+				utils.Fatalf("Failed to write manifest: %v", err)
+			}
+			return nil
+		}
+		fmt.Println(string(blob))
+		return nil
+	},
+}
+
+// sigHash returns the personal_sign hash the manifest signature is made over.
+func (m *keystoreManifest) sigHash() []byte {
+	blob, _ := json.Marshal(&m.manifestBody)
+	return accounts.TextHash(blob)
+}
+
+// manifestEntries hashes the key files within a keystore directory.
+func manifestEntries(keydir string) ([]manifestEntry, error) {
+	files, err := keyfiles(keydir)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]manifestEntry, 0, len(files))
+	for _, file := range files {
+		blob, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		address, err := keyfileAddress(blob)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		hash := sha256.Sum256(blob)
+
+		entries = append(entries, manifestEntry{
+			File:    filepath.Base(file),
+			SHA256:  hex.EncodeToString(hash[:]),
+			Address: common.AddressFormatHex.Address(address),
+			FFF:     address.String(),
+		})
+	}
+	return entries, nil
+}
+
+// verifyManifest checks a keystore directory against a manifest, reporting
+// every key file deviating from it.
+func verifyManifest(ctx *cli.Context, keydir string, file string) {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		utils.Fatalf("Failed to read manifest: %v", err)
+	}
+	manifest := new(keystoreManifest)
+	if err := json.Unmarshal(blob, manifest); err != nil {
+		utils.Fatalf("Invalid manifest: %v", err)
+	}
+	if manifest.Version != manifestVersion {
+		utils.Fatalf("Unsupported manifest version %d", manifest.Version)
+	}
+	pubkey, err := recoverSigner(manifest.sigHash(), manifest.Signature)
+	if err != nil {
+		utils.Fatalf("Manifest signature verification failed: %v", err)
+	}
+	signer := crypto.PubkeyToAddress(*pubkey)
+	if claimed := common.BytesToAddress(common.FromHex(manifest.Signer)); claimed != signer {
+		utils.Fatalf("Manifest signature verification failed: signed by %s, not %s", common.AddressFormatHex.Address(signer), manifest.Signer)
+	}
+	if ctx.IsSet("signer") {
+		var trusted common.Address
+		if err := trusted.UnmarshalText([]byte(ctx.String("signer"))); err != nil {
+			utils.Fatalf("Invalid address %s: %v", ctx.String("signer"), err)
+		}
+		if signer != trusted {
+			utils.Fatalf("Manifest signed by untrusted %s", common.AddressFormatHex.Address(signer))
+		}
+	}
+	actual, err := manifestEntries(keydir)
+	if err != nil {
+		utils.Fatalf("Failed to hash key files: %v", err)
+	}
+	results, drift := checkManifest(manifest.Files, actual)
+
+	if ctx.Bool(jsonFlag.Name) {
+		mustPrintJSON(results)
+	} else {
+		fmt.Printf("Manifest of %s signed by %s on %v\n", manifest.Host, common.AddressFormatHex.Address(signer), manifest.Created)
+
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"File", "Address", "Status"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, res := range results {
+			table.Append([]string{res.File, res.Address, res.Status})
+		}
+		table.Render()
+	}
+	if drift > 0 {
+		utils.Fatalf("%d key files deviate from the manifest", drift)
+	}
+}
+
+// checkManifest compares the key files listed in a manifest with the actual
+// ones, returning the status of each and the number deviating.
+func checkManifest(listed, actual []manifestEntry) ([]outputManifestCheck, int) {
+	found := make(map[string]manifestEntry, len(actual))
+	for _, entry := range actual {
+		found[entry.File] = entry
+	}
+	var (
+		results []outputManifestCheck
+		drift   int
+	)
+	for _, want := range listed {
+		res := outputManifestCheck{File: want.File, Address: want.Address, Status: "ok"}
+
+		have, ok := found[want.File]
+		switch {
+		case !ok:
+			res.Status = "missing"
+		case common.BytesToAddress(common.FromHex(have.Address)) != common.BytesToAddress(common.FromHex(want.Address)):
+			res.Status = "address mismatch"
+		case have.SHA256 != want.SHA256:
+			res.Status = "modified"
+		}
+		if res.Status != "ok" {
+			drift++
+		}
+		delete(found, want.File)
+		results = append(results, res)
+	}
+	for _, have := range found {
+		results = append(results, outputManifestCheck{File: have.File, Address: have.Address, Status: "unexpected"})
+		drift++
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].File < results[j].File })
+	return results, drift
+}
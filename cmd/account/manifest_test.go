@@ -0,0 +1,109 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestManifestVerify(t *testing.T) {
+	dir, keydir, passfile, accs := tmpKeystore(t, 3)
+	defer os.RemoveAll(dir)
+
+	// Sign the manifest with one key and keep it out of the listed directory
+	signer := filepath.Join(dir, "signer.key")
+	if err := os.Rename(accs[2].URL.Path, signer); err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(dir, "manifest.json")
+	create := runAccount(t, "manifest", "--passwordfile", passfile, "--key", signer, "--out", manifest, keydir)
+	create.ExpectExit()
+
+	verify := func(args ...string) *testAccount {
+		return runAccount(t, append([]string{"manifest", "--json", "--verify", manifest}, args...)...)
+	}
+	cmd := verify("--signer", accs[2].Address.Hex(), keydir)
+	var results []outputManifestCheck
+	cmd.expectJSON(&results)
+	cmd.ExpectExit()
+
+	want := map[string]string{filepath.Base(accs[0].URL.Path): "ok", filepath.Base(accs[1].URL.Path): "ok"}
+	if have := manifestStatuses(results); !reflect.DeepEqual(have, want) {
+		t.Fatalf("verification mismatch: have %v, want %v", have, want)
+	}
+	// A manifest signed by someone else must be rejected
+	cmd = runAccount(t, "manifest", "--verify", manifest, "--signer", accs[0].Address.Hex(), keydir)
+	cmd.ExpectRegexp(`^Fatal: Manifest signed by untrusted 0x[0-9a-fA-F]{40}\n`)
+	cmd.ExpectExit()
+
+	// Drift of the key files must be reported
+	blob, err := ioutil.ReadFile(accs[0].URL.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(accs[0].URL.Path, append(blob, '\n'), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(accs[1].URL.Path); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(keydir, "extra"), blob, 0600); err != nil {
+		t.Fatal(err)
+	}
+	cmd = verify(keydir)
+	cmd.ExpectRegexp(`"Status": "unexpected"(?s:.*)Fatal: 3 key files deviate from the manifest\n`)
+	cmd.WaitExit()
+	if status := cmd.ExitStatus(); status != 1 {
+		t.Errorf("exit status mismatch: have %d, want 1", status)
+	}
+}
+
+// manifestStatuses maps the checked files to their status.
+func manifestStatuses(results []outputManifestCheck) map[string]string {
+	statuses := make(map[string]string)
+	for _, res := range results {
+		statuses[res.File] = res.Status
+	}
+	return statuses
+}
+
+func TestCheckManifest(t *testing.T) {
+	listed := []manifestEntry{
+		{File: "a", SHA256: "01", Address: "0x0000000000000000000000000000000000000001"},
+		{File: "b", SHA256: "02", Address: "0x0000000000000000000000000000000000000002"},
+		{File: "c", SHA256: "03", Address: "0x0000000000000000000000000000000000000003"},
+		{File: "d", SHA256: "04", Address: "0x0000000000000000000000000000000000000004"},
+	}
+	actual := []manifestEntry{
+		{File: "a", SHA256: "01", Address: "0x0000000000000000000000000000000000000001"},
+		{File: "b", SHA256: "ff", Address: "0x0000000000000000000000000000000000000002"},
+		{File: "c", SHA256: "03", Address: "0x00000000000000000000000000000000000000ff"},
+		{File: "e", SHA256: "05", Address: "0x0000000000000000000000000000000000000005"},
+	}
+	results, drift := checkManifest(listed, actual)
+	if drift != 4 {
+		t.Errorf("drift mismatch: have %d, want 4", drift)
+	}
+	want := map[string]string{"a": "ok", "b": "modified", "c": "address mismatch", "d": "missing", "e": "unexpected"}
+	if have := manifestStatuses(results); !reflect.DeepEqual(have, want) {
+		t.Errorf("status mismatch: have %v, want %v", have, want)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/liuguodong24-8/3fcoin/core/signer/core"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputSignMessage struct {
+	Address   string
+	Hash      string
+	Signature string
+}
+
+type outputVerifyMessage struct {
+	Success            bool
+	RecoveredAddress   string
+	RecoveredPublicKey string
+}
+
+var (
+	msgfileFlag = cli.StringFlag{
+		Name:  "msgfile",
+		Usage: "file containing the message to sign/verify",
+	}
+	typedDataFlag = cli.BoolFlag{
+		Name:  "typed",
+		Usage: "treat the message as EIP-712 typed data JSON instead of personal_sign text",
+	}
+	addressFlag = cli.StringFlag{
+		Name:  "address",
+		Usage: "address of the key to use when given a keystore directory",
+	}
+)
+
+var commandSignMessage = cli.Command{
+	Name:      "sign-message",
+	Usage:     "sign a message with a key",
+	ArgsUsage: "<keyfile|keydir> [<message>]",
+	Description: `
+Sign a message with a key file, or with the key of --address within a keystore
+directory.
+
+By default the message is signed the way personal_sign does (EIP-191). With
+--typed the message is parsed as EIP-712 typed data instead; addresses within
+it must be given in FFF form. To sign a message contained in a file, use the
+--msgfile flag.
+
+The signature is printed in the 65 byte [R || S || V] form with V being 27 or
+28, as expected by wallets and ecrecover.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		keyringFlag,
+		keyringTTLFlag,
+		jsonFlag,
+		msgfileFlag,
+		typedDataFlag,
+		addressFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) < 1 {
+			utils.Fatalf("Key file or keystore directory required")
+		}
+		hash := messageHash(ctx, getMessage(ctx, 1))
+		key := loadSigningKey(ctx, ctx.Args().First())
+
+		signature, err := crypto.Sign(hash, key.PrivateKey)
+		if err != nil {
+			utils.Fatalf("Failed to sign message: %v", err)
+		}
+		signature[crypto.RecoveryIDOffset] += 27
+
+		out := outputSignMessage{
+			Address:   key.Address.Hex(),
+			Hash:      hexutil.Encode(hash),
+			Signature: hexutil.Encode(signature),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			fmt.Println("Address:  ", out.Address)
+			fmt.Println("Hash:     ", out.Hash)
+			fmt.Println("Signature:", out.Signature)
+		}
+		return nil
+	},
+}
+
+var commandVerifyMessage = cli.Command{
+	Name:      "verify",
+	Usage:     "verify the signature of a signed message",
+	ArgsUsage: "<address> <signature> [<message>]",
+	Description: `
+Verify that the signature of a message was made by the key of the given FFF or
+hex address. The message is interpreted the same way as by sign-message, so
+--typed has to be given for EIP-712 typed data.
+
+The command exits with a non-zero status if the verification fails.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		msgfileFlag,
+		typedDataFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) < 2 {
+			utils.Fatalf("Address and signature required")
+		}
+		var address common.Address
+		if err := address.UnmarshalText([]byte(ctx.Args().First())); err != nil {
+			utils.Fatalf("Invalid address %s: %v", ctx.Args().First(), err)
+		}
+		signature, err := hexutil.Decode(ctx.Args().Get(1))
+		if err != nil {
+			utils.Fatalf("Invalid signature: %v", err)
+		}
+		hash := messageHash(ctx, getMessage(ctx, 2))
+
+		pubkey, err := recoverSigner(hash, signature)
+		if err != nil {
+			utils.Fatalf("Signature verification failed: %v", err)
+		}
+		recovered := crypto.PubkeyToAddress(*pubkey)
+
+		out := outputVerifyMessage{
+			Success:            address == recovered,
+			RecoveredAddress:   recovered.Hex(),
+			RecoveredPublicKey: hexutil.Encode(crypto.FromECDSAPub(pubkey)),
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(out)
+		} else {
+			if out.Success {
+				fmt.Println("Signature verification successful!")
+			} else {
+				fmt.Println("Signature verification failed!")
+			}
+			fmt.Println("Recovered public key:", out.RecoveredPublicKey)
+			fmt.Println("Recovered address:", out.RecoveredAddress)
+		}
+		if !out.Success {
+			os.Exit(1)
+		}
+		return nil
+	},
+}
+
+// getMessage returns the message given either in the --msgfile flag or as the
+// command line argument at position msgarg.
+func getMessage(ctx *cli.Context, msgarg int) []byte {
+	if file := ctx.String(msgfileFlag.Name); file != "" {
+		if len(ctx.Args()) > msgarg {
+			utils.Fatalf("Can't use --msgfile and message argument at the same time.")
+		}
+		msg, err := ioutil.ReadFile(file)
+		if err != nil {
+			utils.Fatalf("Can't read message file: %v", err)
+		}
+		return msg
+	} else if len(ctx.Args()) == msgarg+1 {
+		return []byte(ctx.Args().Get(msgarg))
+	}
+	utils.Fatalf("Invalid number of arguments: want %d, got %d", msgarg+1, len(ctx.Args()))
+	return nil
+}
+
+// messageHash computes the hash to sign for a message, either following
+// personal_sign or EIP-712 if --typed is set.
+func messageHash(ctx *cli.Context, message []byte) []byte {
+	if !ctx.Bool(typedDataFlag.Name) {
+		return accounts.TextHash(message)
+	}
+	var typedData core.TypedData
+	if err := json.Unmarshal(message, &typedData); err != nil {
+		utils.Fatalf("Invalid typed data: %v", err)
+	}
+	hash, _, err := core.TypedDataAndHash(typedData)
+	if err != nil {
+		utils.Fatalf("Failed to hash typed data: %v", err)
+	}
+	return hash
+}
+
+// loadSigningKey decrypts the key to sign with, given either as a key file or
+// as a keystore directory together with the --address flag.
+func loadSigningKey(ctx *cli.Context, path string) *keystore.Key {
+	fi, err := os.Stat(path)
+	if err != nil {
+		utils.Fatalf("Failed to open key: %v", err)
+	}
+	if fi.IsDir() {
+		if !ctx.IsSet(addressFlag.Name) {
+			utils.Fatalf("The --address flag is required when signing with a keystore directory")
+		}
+		var address common.Address
+		if err := address.UnmarshalText([]byte(ctx.String(addressFlag.Name))); err != nil {
+			utils.Fatalf("Invalid address %s: %v", ctx.String(addressFlag.Name), err)
+		}
+		ks := keystore.NewKeyStore(path, keystore.LightScryptN, keystore.LightScryptP)
+		account, err := ks.Find(accounts.Account{Address: address})
+		if err != nil {
+			utils.Fatalf("Failed to find key %s: %v", address.Hex(), err)
+		}
+		path = account.URL.Path
+	}
+	keyjson, err := ioutil.ReadFile(path)
+	if err != nil {
+		utils.Fatalf("Failed to read the keyfile at '%s': %v", path, err)
+	}
+	key, err := decryptKeyfile(ctx, keyjson)
+	if err != nil {
+		utils.Fatalf("Error decrypting key: %v", err)
+	}
+	return key
+}
+
+// recoverSigner recovers the public key that signed a hash, accepting both the
+// [R || S || V] signatures of wallets with V being 27 or 28 and raw ones with
+// V being 0 or 1.
+func recoverSigner(hash []byte, signature []byte) (*ecdsa.PublicKey, error) {
+	if len(signature) != crypto.SignatureLength {
+		return nil, fmt.Errorf("signature must be %d bytes long", crypto.SignatureLength)
+	}
+	sig := common.CopyBytes(signature)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+	return crypto.SigToPub(hash, sig)
+}
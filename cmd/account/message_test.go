@@ -0,0 +1,78 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestMessageSignVerify(t *testing.T) {
+	dir, _, passfile, accs := tmpKeystore(t, 1)
+	defer os.RemoveAll(dir)
+
+	keyfile, address := accs[0].URL.Path, accs[0].Address.Hex()
+	message := "test message"
+
+	// Sign a message.
+	sign := runAccount(t, "sign-message", "--passwordfile", passfile, keyfile, message)
+	_, matches := sign.ExpectRegexp(`Address:   (\S+)\nHash:      0x[0-9a-f]{64}\nSignature: (0x[0-9a-f]{130})\n`)
+	signature := matches[2]
+	sign.ExpectExit()
+
+	if matches[1] != address {
+		t.Errorf("signer mismatch: have %s, want %s", matches[1], address)
+	}
+	// Verify the message.
+	verify := runAccount(t, "verify", address, signature, message)
+	_, matches = verify.ExpectRegexp(`
+Signature verification successful!
+Recovered public key: 0x[0-9a-f]+
+Recovered address: (\S+)
+`)
+	verify.ExpectExit()
+
+	if matches[1] != address {
+		t.Error("recovered address doesn't match the key")
+	}
+	// Verify a tampered message.
+	verify = runAccount(t, "verify", address, signature, message+"!")
+	verify.ExpectRegexp(`^Signature verification failed!\n`)
+	verify.WaitExit()
+	if status := verify.ExitStatus(); status != 1 {
+		t.Errorf("exit status mismatch: have %d, want 1", status)
+	}
+}
+
+func TestMessageSignKeydir(t *testing.T) {
+	dir, keydir, passfile, accs := tmpKeystore(t, 2)
+	defer os.RemoveAll(dir)
+
+	// Signing with a directory requires picking the key
+	sign := runAccount(t, "sign-message", "--passwordfile", passfile, keydir, "test message")
+	sign.Expect("Fatal: The --address flag is required when signing with a keystore directory\n")
+	sign.ExpectExit()
+
+	sign = runAccount(t, "sign-message", "--passwordfile", passfile, "--address", accs[1].Address.Hex(), "--json", keydir, "test message")
+	var out outputSignMessage
+	sign.expectJSON(&out)
+	sign.ExpectExit()
+
+	if out.Address != accs[1].Address.Hex() {
+		t.Errorf("signer mismatch: have %s, want %s", out.Address, accs[1].Address.Hex())
+	}
+}
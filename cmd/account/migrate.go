@@ -0,0 +1,126 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputMigrate struct {
+	Source     string
+	Format     string
+	Address    string
+	AddressHex string
+}
+
+var commandMigrate = cli.Command{
+	Name:      "migrate",
+	Usage:     "import keys from other wallets into a keystore",
+	ArgsUsage: "<keydir> <source>...",
+	Description: `
+Import the keys of other wallets into the keystore directory, re-encrypting them
+with a new password and printing their FFF addresses.
+
+Each source may be a key file or a directory. Supported are geth (and other Web3
+secret storage) key files, MetaMask vault exports and MyEtherWallet JSON wallets.
+Directories are scanned for key files; for a geth data directory the keystore
+within is used.
+
+The password of the sources is read from --passwordfile, the new one from
+--newpasswordfile, or prompted for otherwise.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+		newPassphraseFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) < 2 {
+			utils.Fatalf("Keystore directory and at least one source required")
+		}
+		keydir := ctx.Args().First()
+
+		var sources []string
+		for _, arg := range ctx.Args().Tail() {
+			files, err := migrationSources(arg)
+			if err != nil {
+				utils.Fatalf("Failed to read source %s: %v", arg, err)
+			}
+			sources = append(sources, files...)
+		}
+		password := getPassphrase(ctx, false)
+		newPassword := getNewPassphrase(ctx)
+		ks := keystore.NewKeyStore(keydir, keystore.StandardScryptN, keystore.StandardScryptP)
+
+		var results []outputMigrate
+		for _, source := range sources {
+			data, err := ioutil.ReadFile(source)
+			if err != nil {
+				utils.Fatalf("Failed to read %s: %v", source, err)
+			}
+			format, err := keystore.DetectWalletFormat(data)
+			if err != nil {
+				utils.Fatalf("Failed to detect wallet format of %s: %v", source, err)
+			}
+			imported, err := ks.Migrate(data, password, newPassword)
+			if err != nil {
+				utils.Fatalf("Failed to migrate %s: %v", source, err)
+			}
+			for _, a := range imported {
+				results = append(results, outputMigrate{
+					Source:     source,
+					Format:     format,
+					Address:    a.Address.Hex(),
+					AddressHex: hexutil.Encode(a.Address.Bytes()),
+				})
+			}
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(results)
+			return nil
+		}
+		for _, res := range results {
+			fmt.Printf("%s (%s) from %s: %s\n", res.Address, res.AddressHex, res.Format, res.Source)
+		}
+		fmt.Printf("Imported %d keys from %d sources\n", len(results), len(sources))
+		return nil
+	},
+}
+
+// migrationSources expands a migration source into the wallet files to import.
+// Directories are scanned for key files, preferring the keystore directory of
+// a geth data directory.
+func migrationSources(path string) ([]string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+	if fi, err := os.Stat(filepath.Join(path, "keystore")); err == nil && fi.IsDir() {
+		path = filepath.Join(path, "keystore")
+	}
+	return keyfiles(path)
+}
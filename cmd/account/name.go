@@ -0,0 +1,348 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/abi"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/abi/bind"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/liuguodong24-8/3fcoin/core/ethclient"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// nameRegistryABI is the subset of the ENS compatible registry, registrar and
+// resolver interfaces used to manage names. The methods don't overlap, so one
+// ABI can be bound to all contracts.
+const nameRegistryABI = `[
+	{"type":"function","name":"owner","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"resolver","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"setResolver","stateMutability":"nonpayable","inputs":[{"name":"node","type":"bytes32"},{"name":"resolver","type":"address"}],"outputs":[]},
+	{"type":"function","name":"register","stateMutability":"nonpayable","inputs":[{"name":"label","type":"bytes32"},{"name":"owner","type":"address"}],"outputs":[]},
+	{"type":"function","name":"addr","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"setAddr","stateMutability":"nonpayable","inputs":[{"name":"node","type":"bytes32"},{"name":"addr","type":"address"}],"outputs":[]},
+	{"type":"function","name":"name","stateMutability":"view","inputs":[{"name":"node","type":"bytes32"}],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"setName","stateMutability":"nonpayable","inputs":[{"name":"name","type":"string"}],"outputs":[{"name":"","type":"bytes32"}]}
+]`
+
+// reverseRegistrarName is the name owned by the reverse registrar.
+const reverseRegistrarName = "addr.reverse"
+
+var (
+	registryFlag = cli.StringFlag{
+		Name:  "registry",
+		Usage: "address of the name registry contract",
+	}
+	resolverFlag = cli.StringFlag{
+		Name:  "resolver",
+		Usage: "address of the resolver to point the name to (default = resolver of the parent name)",
+	}
+	reverseFlag = cli.BoolFlag{
+		Name:  "reverse",
+		Usage: "also set the reverse record of the account to the registered name",
+	}
+	timeoutFlag = cli.DurationFlag{
+		Name:  "timeout",
+		Usage: "maximum time to wait for each transaction to be mined",
+		Value: 2 * time.Minute,
+	}
+)
+
+type outputName struct {
+	Name     string
+	Address  string
+	Hex      string
+	Resolver string `json:",omitempty"`
+}
+
+var commandName = cli.Command{
+	Name:  "name",
+	Usage: "register and resolve chain-native names of accounts",
+	Description: `
+Manage the names of accounts in an ENS compatible name registry, whose address
+is given with --registry. Names are registered through the first-in-first-served
+registrar owning the parent name.`,
+	Subcommands: []cli.Command{
+		{
+			Name:      "register",
+			Usage:     "register a name for an account",
+			ArgsUsage: "<keyfile|keydir> <name>",
+			Description: `
+Register the name for the account of the key, pointing it to the account's
+address via the resolver. With --reverse, the account's reverse record is set
+to the name too, so that the address resolves back to it.`,
+			Flags: []cli.Flag{
+				passphraseFlag,
+				keyringFlag,
+				keyringTTLFlag,
+				addressFlag,
+				rpcFlag,
+				registryFlag,
+				resolverFlag,
+				reverseFlag,
+				timeoutFlag,
+			},
+			Action: registerName,
+		},
+		{
+			Name:      "resolve",
+			Usage:     "resolve a name to an address",
+			ArgsUsage: "<name>",
+			Flags:     []cli.Flag{jsonFlag, rpcFlag, registryFlag},
+			Action:    resolveName,
+		},
+		{
+			Name:      "lookup",
+			Usage:     "look up the name of an address via its reverse record",
+			ArgsUsage: "<address>",
+			Flags:     []cli.Flag{jsonFlag, rpcFlag, registryFlag},
+			Action:    lookupName,
+		},
+	},
+}
+
+// nameClient bundles the node connection and the name contracts bindings.
+type nameClient struct {
+	client   *ethclient.Client
+	abi      abi.ABI
+	registry *bind.BoundContract
+}
+
+// newNameClient connects to the node and binds the registry given on the
+// command line.
+func newNameClient(ctx *cli.Context) *nameClient {
+	if !ctx.IsSet(registryFlag.Name) {
+		utils.Fatalf("The --registry flag is required")
+	}
+	var registry common.Address
+	if err := registry.UnmarshalText([]byte(ctx.String(registryFlag.Name))); err != nil {
+		utils.Fatalf("Invalid registry address %s: %v", ctx.String(registryFlag.Name), err)
+	}
+	client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to connect to %s: %v", ctx.String(rpcFlag.Name), err)
+	}
+	parsed, err := abi.JSON(strings.NewReader(nameRegistryABI))
+	if err != nil {
+		utils.Fatalf("Failed to parse registry ABI: %v", err)
+	}
+	nc := &nameClient{client: client, abi: parsed}
+	nc.registry = nc.bind(registry)
+	return nc
+}
+
+// bind binds the name contract interface to an address.
+func (nc *nameClient) bind(address common.Address) *bind.BoundContract {
+	return bind.NewBoundContract(address, nc.abi, nc.client, nc.client, nc.client)
+}
+
+// call executes a view method, returning its single address or string result.
+func (nc *nameClient) call(contract *bind.BoundContract, method string, params ...interface{}) interface{} {
+	var out []interface{}
+	if err := contract.Call(nil, &out, method, params...); err != nil {
+		utils.Fatalf("Failed to call %s: %v", method, err)
+	}
+	return out[0]
+}
+
+// address executes a view method returning an address.
+func (nc *nameClient) address(contract *bind.BoundContract, method string, params ...interface{}) common.Address {
+	return *abi.ConvertType(nc.call(contract, method, params...), new(common.Address)).(*common.Address)
+}
+
+// resolver returns the resolver of a name, failing if it has none.
+func (nc *nameClient) resolver(name string) common.Address {
+	resolver := nc.address(nc.registry, "resolver", nameHash(name))
+	if resolver == (common.Address{}) {
+		utils.Fatalf("No resolver set for %s", name)
+	}
+	return resolver
+}
+
+// transact sends a transaction and waits for it to be executed successfully.
+func (nc *nameClient) transact(ctx *cli.Context, opts *bind.TransactOpts, contract *bind.BoundContract, method string, params ...interface{}) {
+	tx, err := contract.Transact(opts, method, params...)
+	if err != nil {
+		utils.Fatalf("Failed to send %s transaction: %v", method, err)
+	}
+	fmt.Printf("Sent %s transaction %s\n", method, tx.Hash().Hex())
+
+	timeout, cancel := context.WithTimeout(context.Background(), ctx.Duration(timeoutFlag.Name))
+	defer cancel()
+
+	receipt, err := bind.WaitMined(timeout, nc.client, tx)
+	if err != nil {
+		utils.Fatalf("Failed to wait for %s transaction: %v", method, err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		utils.Fatalf("Transaction %s failed in block %d", method, receipt.BlockNumber)
+	}
+}
+
+func registerName(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		utils.Fatalf("Key file or keystore directory and name required")
+	}
+	name := normalizeName(ctx.Args().Get(1))
+	label, parent := splitName(name)
+	if parent == "" {
+		utils.Fatalf("Top level names can't be registered")
+	}
+	key := loadSigningKey(ctx, ctx.Args().First())
+	nc := newNameClient(ctx)
+
+	chainID, err := nc.client.ChainID(context.Background())
+	if err != nil {
+		utils.Fatalf("Failed to retrieve chain ID: %v", err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(key.PrivateKey, chainID)
+	if err != nil {
+		utils.Fatalf("Failed to create transactor: %v", err)
+	}
+	// Register the name via the registrar owning the parent name, unless owned already
+	node := nameHash(name)
+	switch owner := nc.address(nc.registry, "owner", node); owner {
+	case key.Address:
+		fmt.Printf("Name %s already owned by %s\n", name, key.Address.Hex())
+	case common.Address{}:
+		registrar := nc.address(nc.registry, "owner", nameHash(parent))
+		if registrar == (common.Address{}) {
+			utils.Fatalf("Parent name %s is not registered", parent)
+		}
+		nc.transact(ctx, opts, nc.bind(registrar), "register", crypto.Keccak256Hash([]byte(label)), key.Address)
+	default:
+		utils.Fatalf("Name %s already owned by %s", name, owner.Hex())
+	}
+	// Point the name to the account via the resolver
+	var resolver common.Address
+	if ctx.IsSet(resolverFlag.Name) {
+		if err := resolver.UnmarshalText([]byte(ctx.String(resolverFlag.Name))); err != nil {
+			utils.Fatalf("Invalid resolver address %s: %v", ctx.String(resolverFlag.Name), err)
+		}
+	} else {
+		resolver = nc.resolver(parent)
+	}
+	if nc.address(nc.registry, "resolver", node) != resolver {
+		nc.transact(ctx, opts, nc.registry, "setResolver", node, resolver)
+	}
+	if nc.address(nc.bind(resolver), "addr", node) != key.Address {
+		nc.transact(ctx, opts, nc.bind(resolver), "setAddr", node, key.Address)
+	}
+	// Set the reverse record if requested
+	if ctx.Bool(reverseFlag.Name) {
+		registrar := nc.address(nc.registry, "owner", nameHash(reverseRegistrarName))
+		if registrar == (common.Address{}) {
+			utils.Fatalf("No reverse registrar deployed")
+		}
+		nc.transact(ctx, opts, nc.bind(registrar), "setName", name)
+	}
+	fmt.Printf("Name %s registered for %s\n", name, key.Address.Hex())
+	return nil
+}
+
+func resolveName(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("Name required")
+	}
+	name := normalizeName(ctx.Args().First())
+	nc := newNameClient(ctx)
+
+	resolver := nc.resolver(name)
+	address := nc.address(nc.bind(resolver), "addr", nameHash(name))
+	if address == (common.Address{}) {
+		utils.Fatalf("Name %s does not resolve to an address", name)
+	}
+	printName(ctx, outputName{Name: name, Address: address.Hex(), Hex: hex.EncodeToString(address.Bytes()), Resolver: resolver.Hex()})
+	return nil
+}
+
+func lookupName(ctx *cli.Context) error {
+	if len(ctx.Args()) != 1 {
+		utils.Fatalf("Address required")
+	}
+	var address common.Address
+	if err := address.UnmarshalText([]byte(ctx.Args().First())); err != nil {
+		utils.Fatalf("Invalid address %s: %v", ctx.Args().First(), err)
+	}
+	nc := newNameClient(ctx)
+
+	reverse := reverseName(address)
+	name, _ := nc.call(nc.bind(nc.resolver(reverse)), "name", nameHash(reverse)).(string)
+	if name == "" {
+		utils.Fatalf("No name set for %s", address.Hex())
+	}
+	// Only trust the reverse record if the name resolves back to the address
+	if resolved := nc.address(nc.bind(nc.resolver(name)), "addr", nameHash(name)); resolved != address {
+		utils.Fatalf("Reverse record %s of %s resolves to %s", name, address.Hex(), resolved.Hex())
+	}
+	printName(ctx, outputName{Name: name, Address: address.Hex(), Hex: hex.EncodeToString(address.Bytes())})
+	return nil
+}
+
+func printName(ctx *cli.Context, out outputName) {
+	if ctx.Bool(jsonFlag.Name) {
+		mustPrintJSON(out)
+		return
+	}
+	fmt.Println("Name:    ", out.Name)
+	fmt.Println("Address: ", out.Address)
+	fmt.Println("Hex:      0x" + out.Hex)
+	if out.Resolver != "" {
+		fmt.Println("Resolver:", out.Resolver)
+	}
+}
+
+// normalizeName lower cases a name and strips surrounding dots. Full UTS #46
+// normalization is not applied, so names must already be in canonical form
+// apart from the case.
+func normalizeName(name string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// splitName splits a name into its first label and the parent name.
+func splitName(name string) (string, string) {
+	if i := strings.IndexByte(name, '.'); i >= 0 {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// nameHash computes the EIP-137 namehash of a name.
+func nameHash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		node = crypto.Keccak256Hash(node[:], crypto.Keccak256([]byte(labels[i])))
+	}
+	return node
+}
+
+// reverseName returns the name of the reverse record of an address.
+func reverseName(address common.Address) string {
+	return hex.EncodeToString(address.Bytes()) + "." + reverseRegistrarName
+}
@@ -0,0 +1,239 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/liuguodong24-8/3fcoin/core/p2p"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/enode"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/rlpx"
+	"github.com/liuguodong24-8/3fcoin/core/rlp"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// protoHandshake is the RLP structure of the devp2p hello message.
+type protoHandshake struct {
+	Version    uint64
+	Name       string
+	Caps       []p2p.Cap
+	ListenPort uint64
+	ID         []byte // secp256k1 public key
+
+	// Ignore additional fields (for forward compatibility).
+	Rest []rlp.RawValue `rlp:"tail"`
+}
+
+type outputProbe struct {
+	Address   string
+	Enode     string
+	Endpoint  string
+	Reachable bool
+	RTT       string   `json:",omitempty"`
+	Client    string   `json:",omitempty"`
+	Version   uint64   `json:",omitempty"`
+	Protocols []string `json:",omitempty"`
+	Error     string   `json:",omitempty"`
+}
+
+var commandProbe = cli.Command{
+	Name:      "probe",
+	Usage:     "probe the devp2p endpoints of all node keys in a keystore",
+	ArgsUsage: "<keydir>",
+	Description: `
+Derive the enode of every key in the keystore directory and attempt a devp2p
+handshake against its configured endpoint, printing the reachability of each.
+
+Endpoints are read from the --hosts file, containing one "<address> <host[:port]>"
+entry per line, where the address may be FFF or hex encoded. Keys without an
+entry are reported as unconfigured.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+		cli.StringFlag{
+			Name:  "hosts",
+			Usage: "file mapping key addresses to node endpoints",
+		},
+		cli.IntFlag{
+			Name:  "port",
+			Usage: "TCP port to use for endpoints without an explicit one",
+			Value: 30303,
+		},
+		cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "maximum time to wait for each handshake",
+			Value: 5 * time.Second,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		keydir := ctx.Args().First()
+		if keydir == "" {
+			utils.Fatalf("Keystore directory not specified")
+		}
+		hosts := make(map[common.Address]string)
+		if file := ctx.String("hosts"); file != "" {
+			var err error
+			if hosts, err = readHosts(file, ctx.Int("port")); err != nil {
+				utils.Fatalf("Failed to read hosts file: %v", err)
+			}
+		}
+		keys, err := loadKeys(keydir, getPassphrase(ctx, false))
+		if err != nil {
+			utils.Fatalf("Failed to load keys: %v", err)
+		}
+		var (
+			results = make([]outputProbe, len(keys))
+			timeout = ctx.Duration("timeout")
+			wg      sync.WaitGroup
+		)
+		for i, key := range keys {
+			wg.Add(1)
+			go func(i int, pubkey *ecdsa.PublicKey, address common.Address) {
+				defer wg.Done()
+				results[i] = probe(pubkey, address, hosts[address], timeout)
+			}(i, &key.PrivateKey.PublicKey, key.Address)
+		}
+		wg.Wait()
+
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(results)
+			return nil
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Address", "Endpoint", "Status", "RTT", "Client", "Protocols"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		for _, res := range results {
+			status := "reachable"
+			if !res.Reachable {
+				status = res.Error
+			}
+			table.Append([]string{res.Address, res.Endpoint, status, res.RTT, res.Client, strings.Join(res.Protocols, " ")})
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// readHosts parses a hosts file mapping key addresses to node endpoints.
+func readHosts(file string, port int) (map[common.Address]string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hosts := make(map[common.Address]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"<address> <host[:port]>\"", line)
+		}
+		var address common.Address
+		if err := address.UnmarshalText([]byte(fields[0])); err != nil {
+			return nil, fmt.Errorf("line %d: invalid address: %v", line, err)
+		}
+		endpoint := fields[1]
+		if _, _, err := net.SplitHostPort(endpoint); err != nil {
+			endpoint = net.JoinHostPort(endpoint, strconv.Itoa(port))
+		}
+		hosts[address] = endpoint
+	}
+	return hosts, scanner.Err()
+}
+
+// probe dials the endpoint of a node key and performs the devp2p handshake,
+// reporting the round trip time and the capabilities advertised by the node.
+func probe(pubkey *ecdsa.PublicKey, address common.Address, endpoint string, timeout time.Duration) outputProbe {
+	out := outputProbe{
+		Address:  address.Hex(),
+		Enode:    enode.NewV4(pubkey, nil, 0, 0).URLv4(),
+		Endpoint: endpoint,
+	}
+	if endpoint == "" {
+		out.Error = "unconfigured"
+		return out
+	}
+	tcp, err := net.ResolveTCPAddr("tcp", endpoint)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	out.Enode = enode.NewV4(pubkey, tcp.IP, tcp.Port, tcp.Port).URLv4()
+
+	start := time.Now()
+	fd, err := net.DialTimeout("tcp", tcp.String(), timeout)
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	defer fd.Close()
+	out.RTT = time.Since(start).Round(time.Microsecond).String()
+
+	fd.SetDeadline(start.Add(timeout))
+	conn := rlpx.NewConn(fd, pubkey)
+	ourKey, _ := crypto.GenerateKey()
+	if _, err := conn.Handshake(ourKey); err != nil {
+		out.Error = fmt.Sprintf("handshake failed: %v", err)
+		return out
+	}
+	code, data, _, err := conn.Read()
+	if err != nil {
+		out.Error = err.Error()
+		return out
+	}
+	switch code {
+	case 0:
+		var hello protoHandshake
+		if err := rlp.DecodeBytes(data, &hello); err != nil {
+			out.Error = fmt.Sprintf("invalid hello: %v", err)
+			return out
+		}
+		out.Reachable = true
+		out.Client = hello.Name
+		out.Version = hello.Version
+		for _, cap := range hello.Caps {
+			out.Protocols = append(out.Protocols, cap.String())
+		}
+	case 1:
+		var reason []p2p.DiscReason
+		if rlp.DecodeBytes(data, &reason); len(reason) == 0 {
+			out.Error = "invalid disconnect"
+		} else {
+			out.Error = fmt.Sprintf("disconnected: %v", reason[0])
+		}
+	default:
+		out.Error = fmt.Sprintf("unexpected message code %d", code)
+	}
+	return out
+}
@@ -0,0 +1,107 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/liuguodong24-8/3fcoin/core/p2p"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/rlpx"
+	"github.com/liuguodong24-8/3fcoin/core/rlp"
+)
+
+// servePeer accepts a single connection on listener and answers the devp2p
+// handshake as the node of key.
+func servePeer(listener net.Listener, key *ecdsa.PrivateKey) {
+	fd, err := listener.Accept()
+	if err != nil {
+		return
+	}
+	defer fd.Close()
+
+	conn := rlpx.NewConn(fd, nil)
+	if _, err := conn.Handshake(key); err != nil {
+		return
+	}
+	hello, _ := rlp.EncodeToBytes(&protoHandshake{
+		Version: 5,
+		Name:    "test/v1",
+		Caps:    []p2p.Cap{{Name: "eth", Version: 65}},
+		ID:      crypto.FromECDSAPub(&key.PublicKey)[1:],
+	})
+	if _, err := conn.Write(0, hello); err != nil {
+		return
+	}
+	// Wait for the prober to hang up
+	conn.Read()
+}
+
+// Tests that probing a keystore handshakes with the nodes of the keys listed
+// in the hosts file, and reports the other keys as unconfigured.
+func TestProbe(t *testing.T) {
+	dir, keydir, passfile, _ := tmpKeystore(t, 2)
+	defer os.RemoveAll(dir)
+
+	keys, err := loadKeys(keydir, "foobar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go servePeer(listener, keys[0].PrivateKey)
+
+	hosts := filepath.Join(dir, "hosts")
+	if err := ioutil.WriteFile(hosts, []byte(fmt.Sprintf("# local peer\n%s %s\n", keys[0].Address.Hex(), listener.Addr())), 0600); err != nil {
+		t.Fatal(err)
+	}
+	probe := runAccount(t, "probe", "--json", "--passwordfile", passfile, "--hosts", hosts, keydir)
+
+	var results []outputProbe
+	probe.expectJSON(&results)
+	probe.ExpectExit()
+
+	if len(results) != 2 {
+		t.Fatalf("result count mismatch: have %d, want 2", len(results))
+	}
+	res := results[0]
+	if !res.Reachable || res.Error != "" {
+		t.Fatalf("local peer unreachable: %s", res.Error)
+	}
+	if res.Address != keys[0].Address.Hex() || res.Endpoint != listener.Addr().String() {
+		t.Errorf("peer mismatch: have %s at %s", res.Address, res.Endpoint)
+	}
+	if !strings.HasSuffix(res.Enode, "@"+listener.Addr().String()) {
+		t.Errorf("enode mismatch: have %s, want endpoint %s", res.Enode, listener.Addr())
+	}
+	if res.Client != "test/v1" || res.Version != 5 || len(res.Protocols) != 1 || res.Protocols[0] != "eth/65" {
+		t.Errorf("hello mismatch: have %s v%d %v", res.Client, res.Version, res.Protocols)
+	}
+	if res := results[1]; res.Reachable || res.Error != "unconfigured" {
+		t.Errorf("key without endpoint: have reachable %v, error %q", res.Reachable, res.Error)
+	}
+}
@@ -0,0 +1,325 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/ethclient"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Flags shared by the commands displaying animated QR codes.
+var (
+	urTypeFlag = cli.StringFlag{
+		Name:  "type",
+		Usage: "UR type of the exported data",
+		Value: "bytes",
+	}
+	fragmentFlag = cli.IntFlag{
+		Name:  "fragment",
+		Usage: "maximum number of bytes carried by a single QR frame",
+		Value: 100,
+	}
+	intervalFlag = cli.DurationFlag{
+		Name:  "interval",
+		Usage: "time each QR frame is displayed for",
+		Value: 300 * time.Millisecond,
+	}
+	loopsFlag = cli.IntFlag{
+		Name:  "loops",
+		Usage: "number of times to cycle through the frames (0 = until interrupted)",
+	}
+	invertFlag = cli.BoolFlag{
+		Name:  "invert",
+		Usage: "draw dark modules as blocks, for terminals with a light background",
+	}
+	partsFlag = cli.BoolFlag{
+		Name:  "parts",
+		Usage: "print the UR parts as text instead of displaying QR codes",
+	}
+)
+
+var commandQRExport = cli.Command{
+	Name:      "qr-export",
+	Usage:     "display data as an animated QR code for an air-gapped machine",
+	ArgsUsage: "<file>",
+	Description: `
+Encode the content of a file (or - for standard input) as a multipart UR and
+display its parts as an animated QR code in the terminal, for a camera of the
+machine on the other side of the air gap to scan. Files holding a 0x prefixed
+hex string, such as raw transactions, are exported as the decoded bytes.
+
+Only the pure fragments of the UR are displayed, in a loop, so the receiving
+side needs to scan every frame at least once.`,
+	Flags: []cli.Flag{
+		urTypeFlag,
+		fragmentFlag,
+		intervalFlag,
+		loopsFlag,
+		invertFlag,
+		partsFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) != 1 {
+			utils.Fatalf("File to export required")
+		}
+		var (
+			content []byte
+			err     error
+		)
+		if path := ctx.Args().First(); path == "-" {
+			content, err = ioutil.ReadAll(os.Stdin)
+		} else {
+			content, err = ioutil.ReadFile(path)
+		}
+		if err != nil {
+			utils.Fatalf("Failed to read data to export: %v", err)
+		}
+		if data, err := hexutil.Decode(strings.TrimSpace(string(content))); err == nil {
+			content = data
+		}
+		displayUR(ctx, content)
+		return nil
+	},
+}
+
+var commandQRImport = cli.Command{
+	Name:      "qr-import",
+	Usage:     "import data scanned from an animated QR code",
+	ArgsUsage: "[<file>]",
+	Description: `
+Reassemble the data of a multipart UR from the scanned QR frames, read one
+part per line from a file or standard input, as printed by a barcode scanner
+such as zbarcam. Repeated and foreign lines are skipped until all parts of the
+UR were received and its checksum verified.
+
+If the data is a transaction, it is summarized and may be handed on:
+
+ - on the offline machine, --sign signs an unsigned transaction with the key of
+   --chainid and displays the signed transaction as an animated QR code, for
+   the online machine to scan.
+ - on the online machine, --send broadcasts a signed transaction to the node
+   at --rpc.
+
+Otherwise, or with --out, the data is written as a hex string.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		keyringFlag,
+		keyringTTLFlag,
+		addressFlag,
+		rpcFlag,
+		urTypeFlag,
+		fragmentFlag,
+		intervalFlag,
+		loopsFlag,
+		invertFlag,
+		partsFlag,
+		cli.StringFlag{
+			Name:  "out",
+			Usage: "file to write the imported data to as hex",
+		},
+		cli.StringFlag{
+			Name:  "sign",
+			Usage: "key file or keystore directory to sign the imported transaction with",
+		},
+		cli.Uint64Flag{
+			Name:  "chainid",
+			Usage: "chain ID to sign the transaction for",
+		},
+		cli.BoolFlag{
+			Name:  "send",
+			Usage: "broadcast the imported signed transaction",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		input := io.Reader(os.Stdin)
+		if len(ctx.Args()) > 0 {
+			file, err := os.Open(ctx.Args().First())
+			if err != nil {
+				utils.Fatalf("Failed to open scanned parts: %v", err)
+			}
+			defer file.Close()
+			input = file
+		}
+		data, err := scanUR(input, os.Stderr)
+		if err != nil {
+			utils.Fatalf("Failed to import UR: %v", err)
+		}
+		if out := ctx.String("out"); out != "" {
+			if err := ioutil.WriteFile(out, []byte(hexutil.Encode(data)+"\n"), 0600); err != nil {
+				utils.Fatalf("Failed to write imported data: %v", err)
+			}
+		}
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(data); err != nil {
+			if ctx.IsSet("sign") || ctx.Bool("send") {
+				utils.Fatalf("Imported data is not a transaction: %v", err)
+			}
+			if ctx.String("out") == "" {
+				fmt.Println(hexutil.Encode(data))
+			}
+			return nil
+		}
+		printTransaction(tx)
+
+		switch {
+		case ctx.IsSet("sign"):
+			if !unsignedTransaction(tx) {
+				utils.Fatalf("Transaction is already signed")
+			}
+			if !ctx.IsSet("chainid") {
+				utils.Fatalf("The --chainid flag is required to sign transactions")
+			}
+			key := loadSigningKey(ctx, ctx.String("sign"))
+			signer := types.LatestSignerForChainID(new(big.Int).SetUint64(ctx.Uint64("chainid")))
+
+			signed, err := types.SignTx(tx, signer, key.PrivateKey)
+			if err != nil {
+				utils.Fatalf("Failed to sign transaction: %v", err)
+			}
+			blob, err := signed.MarshalBinary()
+			if err != nil {
+				utils.Fatalf("Failed to encode signed transaction: %v", err)
+			}
+			fmt.Printf("Signed by %s: %s\n", key.Address.Hex(), signed.Hash().Hex())
+			displayUR(ctx, blob)
+
+		case ctx.Bool("send"):
+			if unsignedTransaction(tx) {
+				utils.Fatalf("Cannot send an unsigned transaction")
+			}
+			client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+			if err != nil {
+				utils.Fatalf("Failed to connect to %s: %v", ctx.String(rpcFlag.Name), err)
+			}
+			if err := client.SendTransaction(context.Background(), tx); err != nil {
+				utils.Fatalf("Failed to send transaction: %v", err)
+			}
+			fmt.Printf("Sent transaction %s\n", tx.Hash().Hex())
+		}
+		return nil
+	},
+}
+
+// displayUR encodes data as a UR and displays its parts as an animated QR code
+// or prints them as text, as requested by the command line flags.
+func displayUR(ctx *cli.Context, data []byte) {
+	parts, err := urEncode(ctx.String(urTypeFlag.Name), data, ctx.Int(fragmentFlag.Name))
+	if err != nil {
+		utils.Fatalf("Failed to encode UR: %v", err)
+	}
+	if ctx.Bool(partsFlag.Name) {
+		for _, part := range parts {
+			fmt.Println(part)
+		}
+		return
+	}
+	// Render all the frames upfront, QR codes use upper case alphanumerics
+	frames := make([]string, len(parts))
+	for i, part := range parts {
+		qr, err := encodeQR(strings.ToUpper(part), qrLevelL)
+		if err != nil {
+			utils.Fatalf("Failed to encode QR frame, try a smaller --%s: %v", fragmentFlag.Name, err)
+		}
+		frames[i] = qr.render(ctx.Bool(invertFlag.Name))
+	}
+	if len(frames) == 1 {
+		fmt.Print(frames[0])
+		return
+	}
+	for loop := 0; ctx.Int(loopsFlag.Name) == 0 || loop < ctx.Int(loopsFlag.Name); loop++ {
+		for i, frame := range frames {
+			fmt.Print("\033[H\033[2J", frame)
+			fmt.Printf("Part %d of %d\n", i+1, len(frames))
+			time.Sleep(ctx.Duration(intervalFlag.Name))
+		}
+	}
+}
+
+// scanUR reads scanned UR parts line by line until the UR is complete, and
+// returns its payload. Progress and skipped lines are reported to log.
+func scanUR(input io.Reader, log io.Writer) ([]byte, error) {
+	var (
+		decoder urDecoder
+		scanner = bufio.NewScanner(input)
+	)
+	for scanner.Scan() {
+		// Barcode scanners prefix the decoded text with the symbology
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimPrefix(line, "QR-Code:")
+		if line == "" {
+			continue
+		}
+		if err := decoder.receive(line); err != nil {
+			if errors.Is(err, errURChecksum) && decoder.fragments != nil && len(decoder.fragments) == decoder.seqLen {
+				return nil, err
+			}
+			fmt.Fprintf(log, "Skipping part: %v\n", err)
+			continue
+		}
+		if decoder.message != nil {
+			return decoder.payload()
+		}
+		have, want := decoder.progress()
+		fmt.Fprintf(log, "Received %d of %d parts\n", have, want)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, errors.New("input ended before all UR parts were received")
+}
+
+// unsignedTransaction reports whether a transaction carries no signature.
+func unsignedTransaction(tx *types.Transaction) bool {
+	v, r, s := tx.RawSignatureValues()
+	return v.Sign() == 0 && r.Sign() == 0 && s.Sign() == 0
+}
+
+// printTransaction summarizes a transaction for review before signing or
+// sending it.
+func printTransaction(tx *types.Transaction) {
+	to := "contract creation"
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	fmt.Printf("Type:      %d\n", tx.Type())
+	fmt.Printf("Nonce:     %d\n", tx.Nonce())
+	fmt.Printf("To:        %s\n", to)
+	fmt.Printf("Value:     %v wei\n", tx.Value())
+	fmt.Printf("Gas:       %d\n", tx.Gas())
+	fmt.Printf("Gas price: %v wei\n", tx.GasPrice())
+	fmt.Printf("Data:      %d bytes\n", len(tx.Data()))
+	if unsignedTransaction(tx) {
+		fmt.Println("Signature: none")
+	} else {
+		fmt.Printf("Hash:      %s\n", tx.Hash().Hex())
+		fmt.Printf("Chain ID:  %v\n", tx.ChainId())
+	}
+}
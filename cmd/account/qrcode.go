@@ -0,0 +1,506 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"strings"
+)
+
+// qrLevel is the error correction level of a QR code.
+type qrLevel int
+
+const (
+	qrLevelL qrLevel = iota // Recovers 7% of the codewords
+	qrLevelM                // Recovers 15% of the codewords
+)
+
+// qrFormatBits are the format information bits of the error correction levels.
+var qrFormatBits = [...]uint{qrLevelL: 1, qrLevelM: 0}
+
+// qrMaxVersion is the largest QR code version supported by the encoder, which
+// is enough for animated codes made of short parts.
+const qrMaxVersion = 10
+
+// Error correction codewords per block and number of blocks, per level and
+// version (index 0 unused), as defined by ISO/IEC 18004 table 9.
+var (
+	qrECCPerBlock = [...][qrMaxVersion + 1]int{
+		qrLevelL: {0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18},
+		qrLevelM: {0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26},
+	}
+	qrECCBlocks = [...][qrMaxVersion + 1]int{
+		qrLevelL: {0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4},
+		qrLevelM: {0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5},
+	}
+	qrAlignment = [qrMaxVersion + 1][]int{
+		nil, nil, {6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34}, {6, 22, 38}, {6, 24, 42}, {6, 26, 46}, {6, 28, 50},
+	}
+)
+
+// qrAlphanumeric is the character set of the alphanumeric encoding mode.
+const qrAlphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// errQRTooLong is returned if the text doesn't fit into the largest supported
+// QR code version.
+var errQRTooLong = errors.New("text too long for QR code")
+
+// qrCode is an encoded QR code symbol.
+type qrCode struct {
+	size     int
+	modules  [][]bool // Dark modules, indexed by row then column
+	function [][]bool // Modules belonging to function patterns
+}
+
+// encodeQR encodes a text of the alphanumeric character set (e.g. an upper
+// cased UR) into the smallest QR code fitting it at the error correction level.
+func encodeQR(text string, level qrLevel) (*qrCode, error) {
+	for _, c := range text {
+		if !strings.ContainsRune(qrAlphanumeric, c) {
+			return nil, errors.New("text not alphanumeric")
+		}
+	}
+	for version := 1; version <= qrMaxVersion; version++ {
+		capacity := qrDataCodewords(version, level) * 8
+		countBits := 9
+		if version >= 10 {
+			countBits = 11
+		}
+		if 4+countBits+11*(len(text)/2)+6*(len(text)%2) > capacity {
+			continue
+		}
+		// Assemble the bit stream of the segment, terminate and pad it
+		var bits qrBits
+		bits.append(0x2, 4)
+		bits.append(uint(len(text)), countBits)
+		for i := 0; i+1 < len(text); i += 2 {
+			bits.append(uint(strings.IndexByte(qrAlphanumeric, text[i])*45+strings.IndexByte(qrAlphanumeric, text[i+1])), 11)
+		}
+		if len(text)%2 == 1 {
+			bits.append(uint(strings.IndexByte(qrAlphanumeric, text[len(text)-1])), 6)
+		}
+		terminator := capacity - len(bits)
+		if terminator > 4 {
+			terminator = 4
+		}
+		bits.append(0, terminator)
+		bits.append(0, (8-len(bits)%8)%8)
+		for pad := uint(0xec); len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+			bits.append(pad, 8)
+		}
+		data := make([]byte, len(bits)/8)
+		for i, bit := range bits {
+			if bit {
+				data[i/8] |= 1 << (7 - i%8)
+			}
+		}
+		return newQRCode(version, level, qrInterleave(version, level, data)), nil
+	}
+	return nil, errQRTooLong
+}
+
+// qrBits is a bit stream being assembled.
+type qrBits []bool
+
+func (b *qrBits) append(val uint, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (val>>uint(i))&1 == 1)
+	}
+}
+
+// qrRawModules returns the number of data and error correction bits available
+// in a QR code version.
+func qrRawModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		result -= (25*align-10)*align - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+// qrDataCodewords returns the number of data codewords of a QR code version.
+func qrDataCodewords(version int, level qrLevel) int {
+	return qrRawModules(version)/8 - qrECCPerBlock[level][version]*qrECCBlocks[level][version]
+}
+
+// qrInterleave splits the data codewords into blocks, appends their error
+// correction codewords and interleaves them.
+func qrInterleave(version int, level qrLevel, data []byte) []byte {
+	var (
+		numBlocks   = qrECCBlocks[level][version]
+		eccLen      = qrECCPerBlock[level][version]
+		raw         = qrRawModules(version) / 8
+		numShort    = numBlocks - raw%numBlocks
+		shortLen    = raw / numBlocks
+		divisor     = qrReedSolomonDivisor(eccLen)
+		blocks      = make([][]byte, numBlocks)
+		offset      = 0
+		interleaved = make([]byte, 0, raw)
+	)
+	for i := range blocks {
+		size := shortLen - eccLen
+		if i >= numShort {
+			size++
+		}
+		block := append([]byte{}, data[offset:offset+size]...)
+		offset += size
+
+		ecc := qrReedSolomonRemainder(block, divisor)
+		if i < numShort {
+			block = append(block, 0) // Placeholder aligning short and long blocks
+		}
+		blocks[i] = append(block, ecc...)
+	}
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortLen-eccLen || j >= numShort {
+				interleaved = append(interleaved, block[i])
+			}
+		}
+	}
+	return interleaved
+}
+
+// qrMultiply multiplies two elements of GF(2^8) modulo x^8+x^4+x^3+x^2+1.
+func qrMultiply(x, y byte) byte {
+	var z uint
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11d)
+		z ^= uint((y>>uint(i))&1) * uint(x)
+	}
+	return byte(z)
+}
+
+// qrReedSolomonDivisor computes the generator polynomial of the given degree,
+// without the leading term.
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+	return result
+}
+
+// qrReedSolomonRemainder computes the error correction codewords of a block.
+func qrReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= qrMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+// newQRCode lays out the function patterns and codewords of a QR code, using
+// the mask resulting in the lowest penalty.
+func newQRCode(version int, level qrLevel, codewords []byte) *qrCode {
+	size := 17 + 4*version
+	qr := &qrCode{size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for i := 0; i < size; i++ {
+		qr.modules[i] = make([]bool, size)
+		qr.function[i] = make([]bool, size)
+	}
+	// Draw the timing, finder and alignment patterns
+	for i := 0; i < size; i++ {
+		qr.set(6, i, i%2 == 0)
+		qr.set(i, 6, i%2 == 0)
+	}
+	qr.drawFinder(3, 3)
+	qr.drawFinder(size-4, 3)
+	qr.drawFinder(3, size-4)
+
+	align := qrAlignment[version]
+	for i := range align {
+		for j := range align {
+			if (i == 0 && j == 0) || (i == 0 && j == len(align)-1) || (i == len(align)-1 && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					qr.set(align[i]+dx, align[j]+dy, chebyshev(dx, dy) != 1)
+				}
+			}
+		}
+	}
+	qr.drawFormat(level, 0) // Reserve the format modules, overwritten after masking
+	qr.drawVersion(version)
+
+	// Place the codewords in the zigzag scan order
+	bit := 0
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = size - 1 - vert
+				}
+				if !qr.function[y][x] && bit < len(codewords)*8 {
+					qr.modules[y][x] = (codewords[bit>>3]>>(7-uint(bit&7)))&1 == 1
+					bit++
+				}
+			}
+		}
+	}
+	// Pick the mask with the lowest penalty
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		qr.applyMask(mask)
+		qr.drawFormat(level, mask)
+		if penalty := qr.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		qr.applyMask(mask) // Masking is an XOR, undo it
+	}
+	qr.applyMask(best)
+	qr.drawFormat(level, best)
+	return qr
+}
+
+// set sets a function module at column x and row y.
+func (qr *qrCode) set(x, y int, dark bool) {
+	qr.modules[y][x] = dark
+	qr.function[y][x] = true
+}
+
+// drawFinder draws a finder pattern with its separator around the center.
+func (qr *qrCode) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			if xx, yy := x+dx, y+dy; 0 <= xx && xx < qr.size && 0 <= yy && yy < qr.size {
+				dist := chebyshev(dx, dy)
+				qr.set(xx, yy, dist != 2 && dist != 4)
+			}
+		}
+	}
+}
+
+// drawFormat draws both copies of the format information and the dark module.
+func (qr *qrCode) drawFormat(level qrLevel, mask int) {
+	data := qrFormatBits[level]<<3 | uint(mask)
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>uint(i))&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		qr.set(8, i, bit(i))
+	}
+	qr.set(8, 7, bit(6))
+	qr.set(8, 8, bit(7))
+	qr.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		qr.set(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		qr.set(qr.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		qr.set(8, qr.size-15+i, bit(i))
+	}
+	qr.set(8, qr.size-8, true)
+}
+
+// drawVersion draws both copies of the version information, needed from
+// version 7 on.
+func (qr *qrCode) drawVersion(version int) {
+	if version < 7 {
+		return
+	}
+	rem := uint(version)
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1f25)
+	}
+	bits := uint(version)<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := (bits>>uint(i))&1 == 1
+		a, b := qr.size-11+i%3, i/3
+		qr.set(a, b, dark)
+		qr.set(b, a, dark)
+	}
+}
+
+// applyMask inverts the data modules selected by a mask pattern.
+func (qr *qrCode) applyMask(mask int) {
+	for y := 0; y < qr.size; y++ {
+		for x := 0; x < qr.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !qr.function[y][x] {
+				qr.modules[y][x] = !qr.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol by the rules of ISO/IEC 18004 section 7.8.3, lower
+// being easier to scan.
+func (qr *qrCode) penalty() int {
+	var (
+		penalty int
+		dark    int
+		finder  = []bool{true, false, true, true, true, false, true}
+	)
+	line := func(get func(i int) bool) {
+		run := 1
+		for i := 1; i <= qr.size; i++ {
+			if i < qr.size && get(i) == get(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				penalty += run - 2
+			}
+			run = 1
+		}
+		// Finder-like patterns preceded or followed by four light modules
+		for i := 0; i+len(finder) <= qr.size; i++ {
+			match := true
+			for j, want := range finder {
+				if get(i+j) != want {
+					match = false
+					break
+				}
+			}
+			if !match {
+				continue
+			}
+			light := func(from, to int) bool {
+				for k := from; k < to; k++ {
+					if k >= 0 && k < qr.size && get(k) {
+						return false
+					}
+				}
+				return true
+			}
+			if light(i-4, i) || light(i+len(finder), i+len(finder)+4) {
+				penalty += 40
+			}
+		}
+	}
+	for y := 0; y < qr.size; y++ {
+		row := y
+		line(func(i int) bool { return qr.modules[row][i] })
+		line(func(i int) bool { return qr.modules[i][row] })
+		for x := 0; x < qr.size; x++ {
+			if qr.modules[y][x] {
+				dark++
+			}
+			if x+1 < qr.size && y+1 < qr.size {
+				c := qr.modules[y][x]
+				if c == qr.modules[y][x+1] && c == qr.modules[y+1][x] && c == qr.modules[y+1][x+1] {
+					penalty += 3
+				}
+			}
+		}
+	}
+	total := qr.size * qr.size
+	return penalty + abs(dark*20-total*10)/total*10
+}
+
+// render draws the symbol with a quiet zone using half block characters, each
+// character covering two rows of modules. If invert is set, dark modules are
+// drawn as blocks, which suits terminals with a light background.
+func (qr *qrCode) render(invert bool) string {
+	const quiet = 4
+
+	dark := func(x, y int) bool {
+		x, y = x-quiet, y-quiet
+		if x < 0 || y < 0 || x >= qr.size || y >= qr.size {
+			return false
+		}
+		return qr.modules[y][x]
+	}
+	var out strings.Builder
+	for y := 0; y < qr.size+2*quiet; y += 2 {
+		for x := 0; x < qr.size+2*quiet; x++ {
+			top, bottom := dark(x, y) == invert, dark(x, y+1) == invert
+			switch {
+			case top && bottom:
+				out.WriteString("█")
+			case top:
+				out.WriteString("▀")
+			case bottom:
+				out.WriteString("▄")
+			default:
+				out.WriteString(" ")
+			}
+		}
+		out.WriteString("\n")
+	}
+	return out.String()
+}
+
+// chebyshev returns the distance of a module from a pattern center.
+func chebyshev(dx, dy int) int {
+	if dx < 0 {
+		dx = -dx
+	}
+	if dy < 0 {
+		dy = -dy
+	}
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,367 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/abi/bind"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/units"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/ethclient"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputRotate struct {
+	Alias string
+	Old   string
+	New   string
+	Tx    string `json:",omitempty"`
+	Swept string `json:",omitempty"`
+	Time  time.Time
+}
+
+var commandRotate = cli.Command{
+	Name:      "rotate",
+	Usage:     "periodically rotate operational keys, sweeping their funds to fresh keys",
+	ArgsUsage: "<keydir>",
+	Description: `
+Rotate the keys of the hot-wallet accounts named by --alias on a schedule. On
+every rotation a fresh key is generated in the keystore, the whole balance of
+the old account is swept to it via the node at --rpc, the alias is pointed to
+the new address in the --addressbook file and the old key file is moved into
+the --archive directory, still encrypted.
+
+The --hook command is run and the --webhook URL is posted to after every
+rotation, so downstream services can pick up the new FFF address. The hook
+gets the ROTATE_ALIAS, ROTATE_OLD, ROTATE_NEW and ROTATE_TX environment
+variables, the webhook the rotation as JSON.
+
+Only rotate accounts whose address nothing but the address book refers to;
+validator and contract owner keys must not be rotated this way.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		rpcFlag,
+		timeoutFlag,
+		cli.StringFlag{
+			Name:  "addressbook",
+			Usage: "JSON file mapping the account aliases to their addresses",
+		},
+		cli.StringSliceFlag{
+			Name:  "alias",
+			Usage: "alias of an account to rotate (may be repeated)",
+		},
+		cli.DurationFlag{
+			Name:  "interval",
+			Usage: "time between two rotations of the accounts",
+			Value: 24 * time.Hour,
+		},
+		cli.StringFlag{
+			Name:  "archive",
+			Usage: "directory to move the rotated out key files into",
+		},
+		cli.StringFlag{
+			Name:  "gasprice",
+			Usage: "gas price of the sweep transactions (e.g. 5gwei; default = suggested by the node)",
+		},
+		cli.StringFlag{
+			Name:  "hook",
+			Usage: "shell command to run after every rotation",
+		},
+		cli.StringFlag{
+			Name:  "webhook",
+			Usage: "URL to post every rotation to",
+		},
+		cli.BoolFlag{
+			Name:  "once",
+			Usage: "rotate the accounts once and exit instead of running as a daemon",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		log.Root().SetHandler(log.LvlFilterHandler(log.LvlInfo, log.StreamHandler(os.Stderr, log.TerminalFormat(true))))
+
+		if len(ctx.Args()) != 1 {
+			utils.Fatalf("Keystore directory required")
+		}
+		aliases := ctx.StringSlice("alias")
+		if len(aliases) == 0 {
+			utils.Fatalf("No --alias given to rotate")
+		}
+		r := &rotator{
+			ks:         keystore.NewKeyStore(ctx.Args().First(), keystore.StandardScryptN, keystore.StandardScryptP),
+			passphrase: getPassphrase(ctx, false),
+			book:       ctx.String("addressbook"),
+			archive:    ctx.String("archive"),
+			timeout:    ctx.Duration(timeoutFlag.Name),
+			hook:       ctx.String("hook"),
+			webhook:    ctx.String("webhook"),
+		}
+		if r.book == "" || r.archive == "" {
+			utils.Fatalf("The --addressbook and --archive flags are required")
+		}
+		if _, err := loadAddressBook(r.book); err != nil {
+			utils.Fatalf("Failed to load address book: %v", err)
+		}
+		if err := os.MkdirAll(r.archive, 0700); err != nil {
+			utils.Fatalf("Failed to create key archive: %v", err)
+		}
+		if ctx.IsSet("gasprice") {
+			gasPrice, err := units.Parse(ctx.String("gasprice"), units.Wei, units.RoundExact)
+			if err != nil || gasPrice.Sign() < 0 {
+				utils.Fatalf("Invalid gas price %q", ctx.String("gasprice"))
+			}
+			r.gasPrice = gasPrice
+		}
+		var err error
+		if r.client, err = ethclient.Dial(ctx.String(rpcFlag.Name)); err != nil {
+			utils.Fatalf("Failed to connect to %s: %v", ctx.String(rpcFlag.Name), err)
+		}
+		if r.chainID, err = r.client.ChainID(context.Background()); err != nil {
+			utils.Fatalf("Failed to retrieve chain ID: %v", err)
+		}
+		// Rotate all accounts right away, then on every tick until interrupted
+		if failed := r.rotateAll(aliases); ctx.Bool("once") {
+			if failed > 0 {
+				utils.Fatalf("%d of %d accounts not rotated", failed, len(aliases))
+			}
+			return nil
+		}
+		ticker := time.NewTicker(ctx.Duration("interval"))
+		defer ticker.Stop()
+
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+
+		log.Info("Key rotation scheduled", "accounts", len(aliases), "interval", ctx.Duration("interval"))
+		for {
+			select {
+			case <-ticker.C:
+				r.rotateAll(aliases)
+			case sig := <-sigs:
+				log.Info("Stopping key rotation", "signal", sig)
+				return nil
+			}
+		}
+	},
+}
+
+// addressBook maps the aliases of accounts to their addresses.
+type addressBook map[string]common.Address
+
+// loadAddressBook reads an address book file.
+func loadAddressBook(path string) (addressBook, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	book := make(addressBook)
+	if err := json.Unmarshal(blob, &book); err != nil {
+		return nil, err
+	}
+	return book, nil
+}
+
+// save atomically replaces the address book file, so readers never see a
+// partially written one.
+func (book addressBook) save(path string) error {
+	blob, err := json.MarshalIndent(book, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// rotator replaces the keys of operational accounts with fresh ones.
+type rotator struct {
+	ks         *keystore.KeyStore
+	client     *ethclient.Client
+	chainID    *big.Int
+	passphrase string
+	book       string        // Address book file holding the aliases
+	archive    string        // Directory to move rotated out key files into
+	gasPrice   *big.Int      // Gas price of the sweeps, nil if suggested by the node
+	timeout    time.Duration // Maximum time to wait for a sweep to be mined
+	hook       string        // Command to run after a rotation
+	webhook    string        // URL to post rotations to
+}
+
+// rotateAll rotates the given accounts one after the other, returning the
+// number of failed rotations.
+func (r *rotator) rotateAll(aliases []string) int {
+	failed := 0
+	for _, alias := range aliases {
+		res, err := r.rotate(alias)
+		if err != nil {
+			log.Error("Failed to rotate key", "alias", alias, "err", err)
+			failed++
+			continue
+		}
+		log.Info("Rotated key", "alias", alias, "old", res.Old, "new", res.New, "swept", res.Swept, "tx", res.Tx)
+		r.notify(res)
+	}
+	return failed
+}
+
+// rotate generates a fresh key for the account with the given alias, sweeps the
+// funds over, updates the address book and archives the old key. If the sweep
+// fails, the fresh key is dropped and the old one stays in use.
+func (r *rotator) rotate(alias string) (*outputRotate, error) {
+	book, err := loadAddressBook(r.book)
+	if err != nil {
+		return nil, err
+	}
+	address, ok := book[alias]
+	if !ok {
+		return nil, fmt.Errorf("unknown alias %q", alias)
+	}
+	old, err := r.ks.Find(accounts.Account{Address: address})
+	if err != nil {
+		return nil, err
+	}
+	keyjson, err := ioutil.ReadFile(old.URL.Path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(keyjson, r.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	fresh, err := r.ks.NewAccount(r.passphrase)
+	if err != nil {
+		return nil, err
+	}
+	res := &outputRotate{Alias: alias, Old: address.String(), New: fresh.Address.String(), Time: time.Now().UTC()}
+
+	tx, err := r.sweep(key, fresh.Address)
+	if err != nil {
+		if err := r.ks.Delete(fresh, r.passphrase); err != nil {
+			log.Warn("Failed to drop unused key", "address", fresh.Address, "err", err)
+		}
+		return nil, err
+	}
+	if tx != nil {
+		res.Tx, res.Swept = tx.Hash().Hex(), tx.Value().String()
+	}
+	// Funds are safe on the fresh key, point the alias to it and retire the old
+	book[alias] = fresh.Address
+	if err := book.save(r.book); err != nil {
+		return nil, fmt.Errorf("failed to update address book: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(r.archive, filepath.Base(old.URL.Path)), keyjson, 0600); err != nil {
+		return nil, fmt.Errorf("failed to archive old key: %v", err)
+	}
+	if err := r.ks.Delete(old, r.passphrase); err != nil {
+		return nil, fmt.Errorf("failed to remove archived key: %v", err)
+	}
+	return res, nil
+}
+
+// sweep transfers the whole balance of an account minus the fee to the given
+// address and waits for it to be mined. Nothing is sent if the balance doesn't
+// even cover the fee.
+func (r *rotator) sweep(key *keystore.Key, to common.Address) (*types.Transaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+
+	gasPrice := r.gasPrice
+	if gasPrice == nil {
+		var err error
+		if gasPrice, err = r.client.SuggestGasPrice(ctx); err != nil {
+			return nil, err
+		}
+	}
+	balance, err := r.client.PendingBalanceAt(ctx, key.Address)
+	if err != nil {
+		return nil, err
+	}
+	amount := new(big.Int).Sub(balance, new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(params.TxGas)))
+	if amount.Sign() <= 0 {
+		return nil, nil
+	}
+	nonce, err := r.client.PendingNonceAt(ctx, key.Address)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := types.SignTx(types.NewTransaction(nonce, to, amount, params.TxGas, gasPrice, nil), types.LatestSignerForChainID(r.chainID), key.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.client.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	receipt, err := bind.WaitMined(ctx, r.client, tx)
+	if err != nil {
+		return nil, fmt.Errorf("sweep %s not mined: %v", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("sweep %s failed", tx.Hash().Hex())
+	}
+	return tx, nil
+}
+
+// notify runs the rotation hook and posts to the webhook. Failures are only
+// logged, the rotation itself already happened.
+func (r *rotator) notify(res *outputRotate) {
+	if r.hook != "" {
+		cmd := exec.Command("sh", "-c", r.hook)
+		cmd.Env = append(os.Environ(),
+			"ROTATE_ALIAS="+res.Alias,
+			"ROTATE_OLD="+res.Old,
+			"ROTATE_NEW="+res.New,
+			"ROTATE_TX="+res.Tx,
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			log.Warn("Rotation hook failed", "alias", res.Alias, "err", err, "output", string(out))
+		}
+	}
+	if r.webhook != "" {
+		blob, _ := json.Marshal(res)
+		client := &http.Client{Timeout: 10 * time.Second}
+
+		resp, err := client.Post(r.webhook, "application/json", bytes.NewReader(blob))
+		if err != nil {
+			log.Warn("Rotation webhook failed", "alias", res.Alias, "err", err)
+			return
+		}
+		resp.Body.Close()
+		if resp.StatusCode/100 != 2 {
+			log.Warn("Rotation webhook rejected", "alias", res.Alias, "status", resp.Status)
+		}
+	}
+}
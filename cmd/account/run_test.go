@@ -0,0 +1,92 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/pkg/reexec"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/lib/cmdtest"
+)
+
+type testAccount struct {
+	*cmdtest.TestCmd
+}
+
+// spawns account with the given command line args.
+func runAccount(t *testing.T, args ...string) *testAccount {
+	tt := new(testAccount)
+	tt.TestCmd = cmdtest.NewTestCmd(t, tt)
+	tt.Run("account-test", args...)
+	return tt
+}
+
+// expectJSON decodes the whole output of the command into v.
+func (tt *testAccount) expectJSON(v interface{}) {
+	_, matches := tt.ExpectRegexp(`(?s)^(.*)$`)
+	if err := json.Unmarshal([]byte(matches[1]), v); err != nil {
+		tt.Fatalf("Invalid JSON output: %v", err)
+	}
+}
+
+func TestMain(m *testing.M) {
+	// Run the app if we've been exec'd as "account-test" in runAccount.
+	reexec.Register("account-test", func() {
+		if err := app.Run(os.Args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	})
+	// check if we have been reexec'd
+	if reexec.Init() {
+		return
+	}
+	os.Exit(m.Run())
+}
+
+// tmpKeystore creates a temporary directory with a keystore holding the given
+// number of keys, and the file of their shared passphrase.
+func tmpKeystore(t *testing.T, keys int) (dir string, keydir string, passfile string, accs []accounts.Account) {
+	dir, err := ioutil.TempDir("", "account-test")
+	if err != nil {
+		t.Fatal("Can't create temporary directory:", err)
+	}
+	passfile = filepath.Join(dir, ".password")
+	if err := ioutil.WriteFile(passfile, []byte("foobar"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	keydir = filepath.Join(dir, "keystore")
+	if err := os.Mkdir(keydir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < keys; i++ {
+		acc, err := keystore.StoreKey(keydir, "foobar", keystore.LightScryptN, keystore.LightScryptP)
+		if err != nil {
+			t.Fatal("Can't create key:", err)
+		}
+		accs = append(accs, acc)
+	}
+	return dir, keydir, passfile, accs
+}
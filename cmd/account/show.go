@@ -0,0 +1,180 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"strconv"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/fffenc"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputShow struct {
+	Input     string
+	Type      string `json:",omitempty"`
+	Hex       string
+	EIP55     string
+	FFF       []outputShowEncoding
+	Contracts []outputShowContract
+}
+
+type outputShowEncoding struct {
+	Network string
+	Address string
+}
+
+type outputShowContract struct {
+	Nonce   uint64
+	Address string
+	FFF     string
+}
+
+var commandShow = cli.Command{
+	Name:      "show",
+	Usage:     "print an address in every representation of the configured networks",
+	ArgsUsage: "<address|keyfile>...",
+	Description: `
+Print each address, given in hex or FFF form of any known network, or read from
+a key file without decrypting it, as:
+
+  - lowercase hex and EIP-55 checksummed hex
+  - FFF encoding for every known network prefix (mainnet, testnet and the ones
+    added by --prefix)
+  - the addresses of the contracts it creates at the next --count nonces,
+    starting at --nonce
+
+FFF addresses of any known network are accepted, regardless of the network the
+tool is configured for, so reports from users of other networks can be
+cross-referenced.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+		cli.StringSliceFlag{
+			Name:  "prefix",
+			Usage: "additional FFF network prefix to render (may be repeated)",
+		},
+		cli.Uint64Flag{
+			Name:  "nonce",
+			Usage: "first nonce to derive contract addresses for",
+		},
+		cli.IntFlag{
+			Name:  "count",
+			Usage: "number of contract addresses to derive",
+			Value: 3,
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) == 0 {
+			utils.Fatalf("Address or key file required")
+		}
+		for _, prefix := range ctx.StringSlice("prefix") {
+			if err := fffenc.Register(fffenc.New(prefix, nil)); err != nil {
+				utils.Fatalf("Invalid prefix %q: %v", prefix, err)
+			}
+		}
+		results := make([]outputShow, 0, len(ctx.Args()))
+		for _, arg := range ctx.Args() {
+			address, typ, err := showAddress(arg)
+			if err != nil {
+				utils.Fatalf("Invalid address or key file %s: %v", arg, err)
+			}
+			res := outputShow{
+				Input: arg,
+				Hex:   "0x" + common.Bytes2Hex(address[:]),
+				EIP55: common.AddressFormatHex.Address(address),
+			}
+			if typ != common.AddressTypeUnknown {
+				res.Type = typ.String()
+			}
+			for _, prefix := range common.AddressPrefixes() {
+				res.FFF = append(res.FFF, outputShowEncoding{
+					Network: networkName(prefix),
+					Address: fffenc.New(prefix, nil).Encode(address),
+				})
+			}
+			for i := 0; i < ctx.Int("count"); i++ {
+				nonce := ctx.Uint64("nonce") + uint64(i)
+				contract := crypto.CreateAddress(address, nonce)
+				res.Contracts = append(res.Contracts, outputShowContract{
+					Nonce:   nonce,
+					Address: common.AddressFormatHex.Address(contract),
+					FFF:     contract.String(),
+				})
+			}
+			results = append(results, res)
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(results)
+			return nil
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Input", "Format", "Address"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoMergeCells(true)
+		for _, res := range results {
+			if res.Type != "" {
+				table.Append([]string{res.Input, "Type", res.Type})
+			}
+			table.Append([]string{res.Input, "Hex", res.Hex})
+			table.Append([]string{res.Input, "EIP-55", res.EIP55})
+			for _, enc := range res.FFF {
+				table.Append([]string{res.Input, enc.Network, enc.Address})
+			}
+			for _, contract := range res.Contracts {
+				table.Append([]string{res.Input, "Contract @ nonce " + strconv.FormatUint(contract.Nonce, 10), contract.Address + " / " + contract.FFF})
+			}
+		}
+		table.Render()
+		return nil
+	},
+}
+
+// showAddress parses an address in hex or in the FFF form of any known network,
+// or reads it from a key file.
+func showAddress(arg string) (common.Address, common.AddressType, error) {
+	if fi, err := os.Stat(arg); err == nil && !fi.IsDir() {
+		blob, err := ioutil.ReadFile(arg)
+		if err != nil {
+			return common.Address{}, common.AddressTypeUnknown, err
+		}
+		address, err := keyfileAddress(blob)
+		return address, common.AddressTypeUnknown, err
+	}
+	for _, prefix := range common.AddressPrefixes() {
+		if config := fffenc.New(prefix, nil); config.Owns(arg) {
+			return config.DecodeTyped(arg)
+		}
+	}
+	return fffenc.Active().DecodeTyped(arg)
+}
+
+// networkName returns the human readable name of the network of an FFF prefix.
+func networkName(prefix string) string {
+	switch prefix {
+	case common.FFFHeader:
+		return "FFF mainnet"
+	case common.TFFHeader:
+		return "FFF testnet"
+	default:
+		return "FFF " + prefix
+	}
+}
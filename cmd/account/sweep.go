@@ -0,0 +1,286 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+
+	ethereum "github.com/liuguodong24-8/3fcoin"
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/abi/bind"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/units"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/ethclient"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+	"github.com/olekukonko/tablewriter"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type outputSweep struct {
+	Address string
+	Amount  string `json:",omitempty"`
+	Fee     string `json:",omitempty"`
+	Tx      string `json:",omitempty"`
+	Block   uint64 `json:",omitempty"`
+	Status  string
+
+	amount, fee *big.Int
+}
+
+var commandSweep = cli.Command{
+	Name:      "sweep",
+	Usage:     "move the full balance of keystore accounts to a destination via a running node",
+	ArgsUsage: "<destination> <keyfile|keydir>...",
+	Description: `
+Transfer the whole balance of one or more accounts to the FFF or hex destination
+address, minus the transaction fees, broadcasting the transfers to the node at
+--rpc. Key directories contribute all their accounts, or only the --from ones.
+
+The gas price is queried from the node's price oracle unless given by
+--gasprice, and sweeping is refused if it exceeds --maxgasprice. The chain has
+no EIP-1559 base fee, so the fee is the gas price times the gas. Transfers to
+contracts get their gas estimated, plain transfers cost the intrinsic gas. The
+sweep is made from the pending state, so transactions still pending for an
+account are accounted for in its balance and nonce.
+
+The command waits for all receipts before reporting, and exits with a non-zero
+status if any account wasn't swept.`,
+	Flags: []cli.Flag{
+		passphraseFlag,
+		jsonFlag,
+		rpcFlag,
+		timeoutFlag,
+		decimalsFlag,
+		localeFlag,
+		cli.StringSliceFlag{
+			Name:  "from",
+			Usage: "address of an account to sweep from the key directories (may be repeated, default = all)",
+		},
+		cli.StringFlag{
+			Name:  "gasprice",
+			Usage: "gas price (e.g. 5gwei; wei if no unit given, default = suggested by the node)",
+		},
+		cli.StringFlag{
+			Name:  "maxgasprice",
+			Usage: "highest gas price to sweep at (e.g. 20gwei; wei if no unit given)",
+		},
+	},
+	Action: func(ctx *cli.Context) error {
+		if len(ctx.Args()) < 2 {
+			utils.Fatalf("Destination address and key files or directories required")
+		}
+		var dest common.Address
+		if err := dest.UnmarshalText([]byte(ctx.Args().First())); err != nil {
+			utils.Fatalf("Invalid destination %s: %v", ctx.Args().First(), err)
+		}
+		keys := sweepKeys(ctx, ctx.Args().Tail(), getPassphrase(ctx, false))
+		if len(keys) == 0 {
+			utils.Fatalf("No accounts to sweep")
+		}
+		client, err := ethclient.Dial(ctx.String(rpcFlag.Name))
+		if err != nil {
+			utils.Fatalf("Failed to connect to %s: %v", ctx.String(rpcFlag.Name), err)
+		}
+		background := context.Background()
+
+		chainID, err := client.ChainID(background)
+		if err != nil {
+			utils.Fatalf("Failed to retrieve chain ID: %v", err)
+		}
+		gasPrice := sweepGasPrice(ctx, client)
+
+		// The destination decides the gas of all transfers, contracts may need
+		// more than the intrinsic gas to accept funds
+		gas := params.TxGas
+		code, err := client.CodeAt(background, dest, nil)
+		if err != nil {
+			utils.Fatalf("Failed to retrieve destination code: %v", err)
+		}
+		if len(code) > 0 {
+			if gas, err = client.EstimateGas(background, ethereum.CallMsg{From: keys[0].Address, To: &dest, Value: big.NewInt(1)}); err != nil {
+				utils.Fatalf("Failed to estimate gas of transfers to contract %s: %v", dest.Hex(), err)
+			}
+		}
+		fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas))
+		signer := types.LatestSignerForChainID(chainID)
+
+		// Sign and send all sweeps, then wait for them to be mined
+		results := make([]outputSweep, len(keys))
+		txs := make([]*types.Transaction, len(keys))
+		failed := 0
+		for i, key := range keys {
+			results[i] = outputSweep{Address: key.Address.Hex()}
+
+			tx, err := sweepAccount(client, signer, key, dest, gas, gasPrice)
+			switch {
+			case err != nil:
+				results[i].Status = err.Error()
+				failed++
+			case tx == nil:
+				results[i].Status = "balance below fee"
+			default:
+				results[i].Amount, results[i].Fee = units.Format(tx.Value(), units.FFF), units.Format(fee, units.FFF)
+				results[i].amount, results[i].fee = tx.Value(), fee
+				results[i].Tx = tx.Hash().Hex()
+				txs[i] = tx
+			}
+		}
+		timeout, cancel := context.WithTimeout(background, ctx.Duration(timeoutFlag.Name))
+		defer cancel()
+
+		for i, tx := range txs {
+			if tx == nil {
+				continue
+			}
+			receipt, err := bind.WaitMined(timeout, client, tx)
+			switch {
+			case err != nil:
+				results[i].Status = "pending"
+				failed++
+			case receipt.Status != types.ReceiptStatusSuccessful:
+				results[i].Block, results[i].Status = receipt.BlockNumber.Uint64(), "failed"
+				failed++
+			default:
+				results[i].Block, results[i].Status = receipt.BlockNumber.Uint64(), "swept"
+			}
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(results)
+		} else {
+			style := amountStyle(ctx, units.FFF)
+			fmt.Printf("Sweeping to %s at %s per gas\n", dest.Hex(), amountStyle(ctx, units.GWei).Format(gasPrice))
+
+			table := tablewriter.NewWriter(os.Stdout)
+			table.SetHeader([]string{"Address", "Amount", "Fee", "Transaction", "Block", "Status"})
+			table.SetAlignment(tablewriter.ALIGN_LEFT)
+			for _, res := range results {
+				var amount, fee string
+				if res.amount != nil {
+					amount, fee = style.Format(res.amount), style.Format(res.fee)
+				}
+				table.Append([]string{res.Address, amount, fee, res.Tx, strconv.FormatUint(res.Block, 10), res.Status})
+			}
+			table.Render()
+		}
+		if failed > 0 {
+			utils.Fatalf("%d of %d accounts not swept", failed, len(keys))
+		}
+		return nil
+	},
+}
+
+// sweepKeys decrypts the keys to sweep from key files and directories, keeping
+// only the --from accounts of directories if given.
+func sweepKeys(ctx *cli.Context, paths []string, passphrase string) []*keystore.Key {
+	from := make(map[common.Address]bool)
+	for _, arg := range ctx.StringSlice("from") {
+		var address common.Address
+		if err := address.UnmarshalText([]byte(arg)); err != nil {
+			utils.Fatalf("Invalid address %s: %v", arg, err)
+		}
+		from[address] = true
+	}
+	var keys []*keystore.Key
+	for _, path := range paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			utils.Fatalf("Failed to open key: %v", err)
+		}
+		if !fi.IsDir() {
+			keyjson, err := ioutil.ReadFile(path)
+			if err != nil {
+				utils.Fatalf("Failed to read the keyfile at '%s': %v", path, err)
+			}
+			key, err := keystore.DecryptKey(keyjson, passphrase)
+			if err != nil {
+				utils.Fatalf("Error decrypting key %s: %v", path, err)
+			}
+			keys = append(keys, key)
+			continue
+		}
+		dirKeys, err := loadKeys(path, passphrase)
+		if err != nil {
+			utils.Fatalf("Failed to load keys: %v", err)
+		}
+		for _, key := range dirKeys {
+			if len(from) == 0 || from[key.Address] {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// sweepGasPrice returns the gas price to sweep at, either the --gasprice one or
+// the node's suggestion, refusing prices above --maxgasprice.
+func sweepGasPrice(ctx *cli.Context, client *ethclient.Client) *big.Int {
+	var (
+		gasPrice *big.Int
+		err      error
+	)
+	if ctx.IsSet("gasprice") {
+		if gasPrice, err = units.Parse(ctx.String("gasprice"), units.Wei, units.RoundExact); err != nil || gasPrice.Sign() < 0 {
+			utils.Fatalf("Invalid gas price %q", ctx.String("gasprice"))
+		}
+	} else if gasPrice, err = client.SuggestGasPrice(context.Background()); err != nil {
+		utils.Fatalf("Failed to retrieve gas price: %v", err)
+	}
+	if ctx.IsSet("maxgasprice") {
+		limit, err := units.Parse(ctx.String("maxgasprice"), units.Wei, units.RoundExact)
+		if err != nil || limit.Sign() < 0 {
+			utils.Fatalf("Invalid maximum gas price %q", ctx.String("maxgasprice"))
+		}
+		if gasPrice.Cmp(limit) > 0 {
+			utils.Fatalf("Gas price %s above maximum %s", units.FormatUnit(gasPrice, units.GWei), units.FormatUnit(limit, units.GWei))
+		}
+	}
+	return gasPrice
+}
+
+// sweepAccount signs and sends the transfer of the whole pending balance of an
+// account minus the fee. Nothing is sent if the balance doesn't cover the fee.
+func sweepAccount(client *ethclient.Client, signer types.Signer, key *keystore.Key, dest common.Address, gas uint64, gasPrice *big.Int) (*types.Transaction, error) {
+	ctx := context.Background()
+
+	balance, err := client.PendingBalanceAt(ctx, key.Address)
+	if err != nil {
+		return nil, fmt.Errorf("balance unavailable: %v", err)
+	}
+	amount := new(big.Int).Sub(balance, new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gas)))
+	if amount.Sign() <= 0 {
+		return nil, nil
+	}
+	nonce, err := client.PendingNonceAt(ctx, key.Address)
+	if err != nil {
+		return nil, fmt.Errorf("nonce unavailable: %v", err)
+	}
+	tx, err := types.SignTx(types.NewTransaction(nonce, dest, amount, gas, gasPrice, nil), signer, key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("signing failed: %v", err)
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("sending failed: %v", err)
+	}
+	return tx, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Tests that invalid sweep invocations are rejected before contacting a node.
+func TestSweepArguments(t *testing.T) {
+	dir, keydir, passfile, accs := tmpKeystore(t, 1)
+	defer os.RemoveAll(dir)
+
+	empty := filepath.Join(dir, "empty")
+	if err := os.Mkdir(empty, 0700); err != nil {
+		t.Fatal(err)
+	}
+	dest := accs[0].Address.Hex()
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{
+			args: []string{"sweep"},
+			want: `^Fatal: Destination address and key files or directories required\n`,
+		},
+		{
+			args: []string{"sweep", dest},
+			want: `^Fatal: Destination address and key files or directories required\n`,
+		},
+		{
+			args: []string{"sweep", "--passwordfile", passfile, "FFFinvalid0OIl", keydir},
+			want: `^Fatal: Invalid destination FFFinvalid0OIl: .+\n`,
+		},
+		{
+			args: []string{"sweep", "--passwordfile", passfile, dest, filepath.Join(dir, "missing")},
+			want: `^Fatal: Failed to open key: .+\n`,
+		},
+		{
+			args: []string{"sweep", "--passwordfile", passfile, "--from", "bogus", dest, keydir},
+			want: `^Fatal: Invalid address bogus: .+\n`,
+		},
+		{
+			args: []string{"sweep", "--passwordfile", passfile, dest, empty},
+			want: `^Fatal: No accounts to sweep\n`,
+		},
+		{
+			// Valid arguments get as far as the node
+			args: []string{"sweep", "--passwordfile", passfile, "--rpc", "http://127.0.0.1:1", "--from", dest, dest, empty, keydir},
+			want: `^Fatal: Failed to retrieve chain ID: .+\n`,
+		},
+	}
+	for _, tt := range tests {
+		sweep := runAccount(t, tt.args...)
+		sweep.ExpectRegexp(tt.want)
+		sweep.ExpectExit()
+		if status := sweep.ExitStatus(); status != 1 {
+			t.Errorf("%v: exit status mismatch: have %d, want 1", tt.args, status)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var commandUpgrade = cli.Command{
+	Name:      "upgrade",
+	Usage:     "upgrade the key files of a keystore to the v4 schema",
+	ArgsUsage: "<keydir>",
+	Description: `
+Upgrade the v3 key files of a keystore directory to the v4 schema in place,
+recording their creation times and inlining their escrow blobs. The encrypted
+key material is carried over unchanged, so no password is needed.
+
+New keys are written as v3, which other wallets can read; upgraded key files
+can only be read by this keystore.`,
+	Flags: []cli.Flag{
+		jsonFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		keydir := ctx.Args().First()
+		if keydir == "" {
+			utils.Fatalf("Keystore directory not specified")
+		}
+		upgraded, err := keystore.UpgradeKeyDir(keydir)
+		if err != nil {
+			utils.Fatalf("Failed to upgrade keystore: %v", err)
+		}
+		if ctx.Bool(jsonFlag.Name) {
+			mustPrintJSON(upgraded)
+			return nil
+		}
+		for _, path := range upgraded {
+			fmt.Println(path)
+		}
+		fmt.Printf("Upgraded %d key files\n", len(upgraded))
+		return nil
+	},
+}
@@ -0,0 +1,316 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+)
+
+// This file implements the subset of the Uniform Resources (UR) specification
+// (BCR-2020-005) needed to move transactions over animated QR codes: the
+// minimal bytewords encoding, and multipart URs split into pure fragments. The
+// fountain coded parts mixing several fragments are not produced, and skipped
+// when received, so a multipart UR is complete once every fragment was seen.
+
+// bytewords is the concatenation of the 256 four letter words of BCR-2020-012,
+// a word encoding a byte value. The minimal encoding uses only the first and
+// the last letters of the words, which are unique.
+const bytewords = "ableacidalsoapexaquaarchatomauntawayaxisbackbaldbarnbeltbetabiasbluebodybragbrewbulbbuzzcalmcashcatschefcityclawcodecolacookcostcruxcurlcuspcyandarkdatadaysdelidicedietdoordowndrawdropdrumdulldutyeacheasyechoedgeepicevenexamexiteyesfactfairfernfigsfilmfishfizzflapflewfluxfoxyfreefrogfuelfundgalagamegeargemsgiftgirlglowgoodgraygrimgurugushgyrohalfhanghardhawkheathelphighhillholyhopehornhutsicedideaidleinchinkyintoirisironitemjadejazzjoinjoltjowljudojugsjumpjunkjurykeepkenokeptkeyskickkilnkingkitekiwiknoblamblavalazyleaflegsliarlimplionlistlogoloudloveluaulucklungmainmanymathmazememomenumeowmildmintmissmonknailnavyneednewsnextnoonnotenumbobeyoboeomitonyxopenovalowlspaidpartpeckplaypluspoempoolposepuffpumapurrquadquizraceramprealredorichroadrockroofrubyruinrunsrustsafesagascarsetssilkskewslotsoapsolosongstubsurfswantacotasktaxitenttiedtimetinytoiltombtoystriptunatwinuglyundouniturgeuservastveryvetovialvibeviewvisavoidvowswallwandwarmwaspwavewaxywebswhatwhenwhizwolfworkyankyawnyellyogayurtzapszerozestzinczonezoom"
+
+// urMinFragment is the smallest fragment length multipart URs are split into.
+const urMinFragment = 10
+
+var (
+	errURChecksum = errors.New("UR checksum mismatch")
+	errURInvalid  = errors.New("invalid UR")
+	errURMixed    = errors.New("fountain coded UR part not supported")
+)
+
+// bytewordsEncode encodes data with its CRC32 checksum as minimal bytewords.
+func bytewordsEncode(data []byte) string {
+	data = binary.BigEndian.AppendUint32(append([]byte{}, data...), crc32.ChecksumIEEE(data))
+
+	out := make([]byte, 0, 2*len(data))
+	for _, b := range data {
+		out = append(out, bytewords[4*int(b)], bytewords[4*int(b)+3])
+	}
+	return string(out)
+}
+
+// bytewordsDecode decodes minimal bytewords and verifies the trailing checksum.
+func bytewordsDecode(text string) ([]byte, error) {
+	text = strings.ToLower(text)
+	if len(text)%2 != 0 || len(text) < 10 {
+		return nil, fmt.Errorf("%w: bad bytewords length %d", errURInvalid, len(text))
+	}
+	data := make([]byte, 0, len(text)/2)
+	for i := 0; i < len(text); i += 2 {
+		b, ok := bytewordIndex[text[i:i+2]]
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown byteword %q", errURInvalid, text[i:i+2])
+		}
+		data = append(data, b)
+	}
+	body, sum := data[:len(data)-4], binary.BigEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return nil, errURChecksum
+	}
+	return body, nil
+}
+
+// bytewordIndex maps the minimal form of the bytewords to their byte values.
+var bytewordIndex = func() map[string]byte {
+	index := make(map[string]byte, 256)
+	for i := 0; i < 256; i++ {
+		index[bytewords[4*i:4*i+1]+bytewords[4*i+3:4*i+4]] = byte(i)
+	}
+	return index
+}()
+
+// urEncode encodes a payload as the parts of a UR of the given type, split in
+// fragments of at most maxFragment bytes. Payloads fitting a single fragment
+// result in a single part UR.
+func urEncode(urType string, payload []byte, maxFragment int) ([]string, error) {
+	if !validURType(urType) {
+		return nil, fmt.Errorf("invalid UR type %q", urType)
+	}
+	if maxFragment < urMinFragment {
+		maxFragment = urMinFragment
+	}
+	message := cborAppendHead(nil, cborBytes, uint64(len(payload)))
+	message = append(message, payload...)
+	if len(message) <= maxFragment {
+		return []string{"ur:" + urType + "/" + bytewordsEncode(message)}, nil
+	}
+	var (
+		count    = (len(message) + maxFragment - 1) / maxFragment
+		length   = (len(message) + count - 1) / count
+		checksum = crc32.ChecksumIEEE(message)
+		parts    = make([]string, count)
+	)
+	padded := make([]byte, count*length)
+	copy(padded, message)
+
+	for i := range parts {
+		body := cborAppendHead(nil, cborArray, 5)
+		body = cborAppendHead(body, cborUint, uint64(i+1))
+		body = cborAppendHead(body, cborUint, uint64(count))
+		body = cborAppendHead(body, cborUint, uint64(len(message)))
+		body = cborAppendHead(body, cborUint, uint64(checksum))
+		body = cborAppendHead(body, cborBytes, uint64(length))
+		body = append(body, padded[i*length:(i+1)*length]...)
+
+		parts[i] = fmt.Sprintf("ur:%s/%d-%d/%s", urType, i+1, count, bytewordsEncode(body))
+	}
+	return parts, nil
+}
+
+// validURType reports whether a UR type consists of lower case letters, digits
+// and hyphens only.
+func validURType(urType string) bool {
+	if urType == "" {
+		return false
+	}
+	for _, c := range urType {
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// urDecoder reassembles the payload of a UR from its parts, received in any
+// order and possibly repeatedly, as scanned from an animated QR code.
+type urDecoder struct {
+	urType    string
+	seqLen    int
+	length    int    // Length of the CBOR encoded message
+	checksum  uint32 // CRC32 of the CBOR encoded message
+	fragments map[int][]byte
+	message   []byte
+}
+
+// receive adds a UR part to the decoder. Parts of a different UR than the ones
+// received before are rejected.
+func (d *urDecoder) receive(part string) error {
+	part = strings.ToLower(strings.TrimSpace(part))
+	if !strings.HasPrefix(part, "ur:") {
+		return fmt.Errorf("%w: missing ur: scheme", errURInvalid)
+	}
+	components := strings.Split(part[3:], "/")
+	if d.urType == "" {
+		d.urType = components[0]
+	} else if d.urType != components[0] {
+		return fmt.Errorf("UR type mismatch: have %s, got %s", d.urType, components[0])
+	}
+	switch len(components) {
+	case 2:
+		message, err := bytewordsDecode(components[1])
+		if err != nil {
+			return err
+		}
+		d.message = message
+		return nil
+	case 3:
+		return d.receiveFragment(components[1], components[2])
+	default:
+		return fmt.Errorf("%w: bad path", errURInvalid)
+	}
+}
+
+// receiveFragment adds a part of a multipart UR to the decoder.
+func (d *urDecoder) receiveFragment(seq string, text string) error {
+	body, err := bytewordsDecode(text)
+	if err != nil {
+		return err
+	}
+	var header [4]uint64
+	rest, err := cborExpectHead(body, cborArray, 5)
+	if err != nil {
+		return err
+	}
+	for i := range header {
+		if header[i], rest, err = cborReadHead(rest, cborUint); err != nil {
+			return err
+		}
+	}
+	size, rest, err := cborReadHead(rest, cborBytes)
+	if err != nil {
+		return err
+	}
+	if uint64(len(rest)) != size {
+		return fmt.Errorf("%w: fragment length mismatch", errURInvalid)
+	}
+	seqNum, seqLen, length, checksum := header[0], header[1], header[2], uint32(header[3])
+	if seq != strconv.FormatUint(seqNum, 10)+"-"+strconv.FormatUint(seqLen, 10) {
+		return fmt.Errorf("%w: sequence %s doesn't match part", errURInvalid, seq)
+	}
+	if seqNum == 0 || seqLen == 0 || length == 0 || seqLen*size < length || seqLen > 1<<16 {
+		return fmt.Errorf("%w: bad sequence %d-%d", errURInvalid, seqNum, seqLen)
+	}
+	if seqNum > seqLen {
+		return errURMixed
+	}
+	if d.fragments == nil {
+		d.seqLen, d.length, d.checksum = int(seqLen), int(length), checksum
+		d.fragments = make(map[int][]byte)
+	} else if d.seqLen != int(seqLen) || d.length != int(length) || d.checksum != checksum {
+		return fmt.Errorf("%w: part of a different message", errURInvalid)
+	}
+	d.fragments[int(seqNum)] = rest
+
+	if len(d.fragments) == d.seqLen {
+		var message []byte
+		for i := 1; i <= d.seqLen; i++ {
+			message = append(message, d.fragments[i]...)
+		}
+		message = message[:d.length]
+		if crc32.ChecksumIEEE(message) != d.checksum {
+			return errURChecksum
+		}
+		d.message = message
+	}
+	return nil
+}
+
+// progress returns the number of fragments received and expected.
+func (d *urDecoder) progress() (int, int) {
+	if d.message != nil {
+		return 1, 1
+	}
+	return len(d.fragments), d.seqLen
+}
+
+// payload returns the byte string carried by a completely received UR.
+func (d *urDecoder) payload() ([]byte, error) {
+	if d.message == nil {
+		return nil, errors.New("UR incomplete")
+	}
+	size, rest, err := cborReadHead(d.message, cborBytes)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(rest)) != size {
+		return nil, fmt.Errorf("%w: payload length mismatch", errURInvalid)
+	}
+	return rest, nil
+}
+
+// CBOR major types used by the UR encodings.
+const (
+	cborUint  = 0
+	cborBytes = 2
+	cborArray = 4
+)
+
+// cborAppendHead appends the head of a CBOR data item, its major type and
+// argument, in the shortest form.
+func cborAppendHead(buf []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(buf, major<<5|byte(arg))
+	case arg <= 0xff:
+		return append(buf, major<<5|24, byte(arg))
+	case arg <= 0xffff:
+		return binary.BigEndian.AppendUint16(append(buf, major<<5|25), uint16(arg))
+	case arg <= 0xffffffff:
+		return binary.BigEndian.AppendUint32(append(buf, major<<5|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major<<5|27), arg)
+	}
+}
+
+// cborReadHead decodes the head of a CBOR data item of the given major type
+// and returns its argument with the remaining input.
+func cborReadHead(buf []byte, major byte) (uint64, []byte, error) {
+	if len(buf) == 0 || buf[0]>>5 != major {
+		return 0, nil, fmt.Errorf("%w: expected CBOR major type %d", errURInvalid, major)
+	}
+	info := buf[0] & 0x1f
+	if info < 24 {
+		return uint64(info), buf[1:], nil
+	}
+	if info > 27 {
+		return 0, nil, fmt.Errorf("%w: unsupported CBOR item", errURInvalid)
+	}
+	size := 1 << (info - 24)
+	if len(buf) < 1+size {
+		return 0, nil, fmt.Errorf("%w: truncated CBOR item", errURInvalid)
+	}
+	var arg uint64
+	for _, b := range buf[1 : 1+size] {
+		arg = arg<<8 | uint64(b)
+	}
+	return arg, buf[1+size:], nil
+}
+
+// cborExpectHead decodes the head of a CBOR data item, requiring its argument.
+func cborExpectHead(buf []byte, major byte, arg uint64) ([]byte, error) {
+	have, rest, err := cborReadHead(buf, major)
+	if err != nil {
+		return nil, err
+	}
+	if have != arg {
+		return nil, fmt.Errorf("%w: unexpected CBOR item", errURInvalid)
+	}
+	return rest, nil
+}
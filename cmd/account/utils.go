@@ -0,0 +1,160 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/cmd/utils"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/fffenc"
+	"github.com/liuguodong24-8/3fcoin/core/common/units"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// getPassphrase obtains a passphrase given by the user.  It first checks the
+// --passwordfile command line flag and ultimately prompts the user for a
+// passphrase.
+func getPassphrase(ctx *cli.Context, confirmation bool) string {
+	// Look for the --passwordfile flag.
+	passphraseFile := ctx.String(passphraseFlag.Name)
+	if passphraseFile != "" {
+		content, err := ioutil.ReadFile(passphraseFile)
+		if err != nil {
+			utils.Fatalf("Failed to read password file '%s': %v",
+				passphraseFile, err)
+		}
+		return strings.TrimRight(string(content), "\r\n")
+	}
+
+	// Otherwise prompt the user for the passphrase.
+	return utils.GetPassPhrase("", confirmation)
+}
+
+// getNewPassphrase obtains the passphrase to encrypt new key files with, read
+// from the --newpasswordfile flag or prompted for with confirmation.
+func getNewPassphrase(ctx *cli.Context) string {
+	if passphraseFile := ctx.String(newPassphraseFlag.Name); passphraseFile != "" {
+		content, err := ioutil.ReadFile(passphraseFile)
+		if err != nil {
+			utils.Fatalf("Failed to read new password file '%s': %v", passphraseFile, err)
+		}
+		return strings.TrimRight(string(content), "\r\n")
+	}
+	fmt.Println("Please provide a password for the new keyfiles")
+	return utils.GetPassPhrase("", true)
+}
+
+// keyfiles lists the key files within a keystore directory, skipping editor
+// backups, hidden and special files the same way the keystore does.
+func keyfiles(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, fi := range entries {
+		if strings.HasSuffix(fi.Name(), "~") || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		if fi.IsDir() || fi.Mode()&os.ModeType != 0 {
+			continue
+		}
+		files = append(files, filepath.Join(dir, fi.Name()))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// keyfileAddress returns the address a key file is for without decrypting it.
+// The keystore records it in the FFF form of the network the key was created
+// on, older and foreign key files in plain hex.
+func keyfileAddress(keyjson []byte) (common.Address, error) {
+	var keyfile struct {
+		Address string `json:"address"`
+	}
+	if err := json.Unmarshal(keyjson, &keyfile); err != nil {
+		return common.Address{}, err
+	}
+	if raw, err := hex.DecodeString(strings.TrimPrefix(keyfile.Address, common.ETHHeader)); err == nil && len(raw) == common.AddressLength {
+		return common.BytesToAddress(raw), nil
+	}
+	for _, prefix := range common.AddressPrefixes() {
+		if config := fffenc.New(prefix, nil); config.Owns(keyfile.Address) {
+			return config.Decode(keyfile.Address)
+		}
+	}
+	return common.Address{}, fmt.Errorf("invalid address %q", keyfile.Address)
+}
+
+// loadKeys decrypts every key file within a keystore directory with the given
+// passphrase.
+func loadKeys(dir string, passphrase string) ([]*keystore.Key, error) {
+	files, err := keyfiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]*keystore.Key, 0, len(files))
+	for _, file := range files {
+		keyjson, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		key, err := keystore.DecryptKey(keyjson, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// amountStyle returns the style to display amounts of the given unit in, as
+// configured by the --decimals and --locale flags. Amounts in JSON output are
+// always exact and don't use it.
+func amountStyle(ctx *cli.Context, unit units.Unit) units.Style {
+	locale, err := units.LookupLocale(ctx.String(localeFlag.Name))
+	if err != nil {
+		utils.Fatalf("Invalid --%s: %v", localeFlag.Name, err)
+	}
+	return units.Style{
+		Unit:     unit,
+		Decimals: ctx.Int(decimalsFlag.Name),
+		Mode:     units.RoundHalfEven,
+		Pad:      true,
+		Suffix:   true,
+		Locale:   locale,
+	}
+}
+
+// mustPrintJSON prints the JSON encoding of the given object and
+// exits the program with an error message when the marshaling fails.
+func mustPrintJSON(jsonObject interface{}) {
+	str, err := json.MarshalIndent(jsonObject, "", "  ")
+	if err != nil {
+		utils.Fatalf("Failed to marshal JSON object: %v", err)
+	}
+	fmt.Println(string(str))
+}
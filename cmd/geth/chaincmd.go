@@ -17,11 +17,19 @@
 package main
 
 import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"math/big"
 	"net"
 	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 	"strconv"
 	"strings"
@@ -38,6 +46,7 @@ import (
 	"github.com/liuguodong24-8/3fcoin/core/metrics"
 	"github.com/liuguodong24-8/3fcoin/core/node"
 	"github.com/liuguodong24-8/3fcoin/core/p2p/enode"
+	"github.com/liuguodong24-8/3fcoin/core/rlp"
 	"gopkg.in/urfave/cli.v1"
 )
 
@@ -155,6 +164,26 @@ be gzipped.`,
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
 	The import-preimages command imports hash preimages from an RLP encoded stream.`,
+	}
+	importSeedCommand = cli.Command{
+		Action:    utils.MigrateFlags(importSeed),
+		Name:      "import-seed",
+		Usage:     "Import a state-sync seed into a freshly initialized database",
+		ArgsUsage: "<manifestPath>",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.SyncModeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The import-seed command imports the state and checkpoint block of a state-sync seed
+as exported by puppeth, so the node continues syncing from the checkpoint instead
+of from the genesis. The seed files are expected in the folder named after the
+manifest, and are verified against it before being imported.
+
+The database must be initialized with the genesis of the seed. Chains that already
+progressed past their genesis are left untouched.`,
 	}
 	exportPreimagesCommand = cli.Command{
 		Action:    utils.MigrateFlags(exportPreimages),
@@ -468,6 +497,80 @@ func importPreimages(ctx *cli.Context) error {
 	return nil
 }
 
+// importSeed imports a state-sync seed into a freshly initialized database,
+// making its checkpoint block the head of the chain.
+func importSeed(ctx *cli.Context) error {
+	if len(ctx.Args()) < 1 {
+		utils.Fatalf("This command requires an argument.")
+	}
+	manifestPath := ctx.Args().First()
+	blob, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		utils.Fatalf("Failed to read seed manifest: %v", err)
+	}
+	manifest := new(core.SeedManifest)
+	if err := json.Unmarshal(blob, manifest); err != nil {
+		utils.Fatalf("Invalid seed manifest: %v", err)
+	}
+	// Load all the seed files, verifying them against the manifest
+	folder := strings.TrimSuffix(manifestPath, filepath.Ext(manifestPath))
+	files := make(map[string][]byte)
+	for _, file := range manifest.Files {
+		data, err := ioutil.ReadFile(filepath.Join(folder, file.Name))
+		if err != nil {
+			utils.Fatalf("Failed to read seed file: %v", err)
+		}
+		hash := sha256.Sum256(data)
+		if len(data) != file.Size || hex.EncodeToString(hash[:]) != file.SHA256 {
+			utils.Fatalf("Seed file %s doesn't match the manifest", file.Name)
+		}
+		files[file.Name] = data
+	}
+	checkpoint := manifest.Checkpoint
+
+	block := new(types.Block)
+	if err := rlp.DecodeBytes(files[core.SeedBlockFile], block); err != nil {
+		utils.Fatalf("Invalid checkpoint block: %v", err)
+	}
+	if block.NumberU64() != checkpoint.Number || block.Hash() != checkpoint.Hash || block.Root() != checkpoint.Root {
+		utils.Fatalf("Checkpoint block #%d [%x] doesn't match the manifest", block.NumberU64(), block.Hash())
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(files[core.SeedStateFile(checkpoint.Number)]))
+	if err != nil {
+		utils.Fatalf("Invalid seed state: %v", err)
+	}
+	var dump state.Dump
+	if err := json.NewDecoder(zr).Decode(&dump); err != nil {
+		utils.Fatalf("Invalid seed state: %v", err)
+	}
+	alloc, err := core.DumpAlloc(&dump)
+	if err != nil {
+		utils.Fatalf("Invalid seed state: %v", err)
+	}
+	// Seed the database, as long as it runs the same chain
+	stack, _ := makeConfigNode(ctx)
+	defer stack.Close()
+
+	db := utils.MakeChainDatabase(ctx, stack, false, false)
+	defer db.Close()
+
+	if genesis := rawdb.ReadCanonicalHash(db, 0); genesis != manifest.Genesis {
+		utils.Fatalf("Seed genesis mismatch: have %x, want %x", genesis, manifest.Genesis)
+	}
+	start := time.Now()
+
+	err = core.CommitCheckpoint(db, block, (*big.Int)(checkpoint.TotalDifficulty), alloc)
+	switch {
+	case errors.Is(err, core.ErrSeedChainAdvanced):
+		log.Warn("Skipping state-sync seed import", "number", checkpoint.Number, "err", err)
+	case err != nil:
+		utils.Fatalf("Import error: %v\n", err)
+	default:
+		log.Info("Imported state-sync seed", "number", checkpoint.Number, "hash", checkpoint.Hash, "accounts", len(alloc), "elapsed", common.PrettyDuration(time.Since(start)))
+	}
+	return nil
+}
+
 // exportPreimages dumps the preimage data to specified json file in streaming way.
 func exportPreimages(ctx *cli.Context) error {
 	if len(ctx.Args()) < 1 {
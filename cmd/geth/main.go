@@ -154,6 +154,8 @@ var (
 		utils.YoloV3Flag,
 		utils.VMEnableDebugFlag,
 		utils.NetworkIdFlag,
+		utils.AddressCrossNetworkFlag,
+		utils.AddressCacheFlag,
 		utils.EthStatsURLFlag,
 		utils.FakePoWFlag,
 		utils.NoCompactionFlag,
@@ -227,6 +229,7 @@ func init() {
 		exportCommand,
 		importPreimagesCommand,
 		exportPreimagesCommand,
+		importSeedCommand,
 		removedbCommand,
 		dumpCommand,
 		dumpGenesisCommand,
@@ -44,6 +44,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.RangeLimitFlag,
 			utils.SmartCardDaemonPathFlag,
 			utils.NetworkIdFlag,
+			utils.AddressCrossNetworkFlag,
+			utils.AddressCacheFlag,
 			utils.MainnetFlag,
 			utils.GoerliFlag,
 			utils.RinkebyFlag,
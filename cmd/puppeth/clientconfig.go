@@ -0,0 +1,155 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"text/template"
+)
+
+// gethConfigTemplate is the TOML configuration file loadable by geth --config,
+// using the same sections as geth dumpconfig.
+var gethConfigTemplate = `[Eth]
+NetworkId = {{.NetworkID}}
+SyncMode = "full"
+{{if .MinGasPrice}}
+[Eth.Miner]
+GasPrice = {{.MinGasPrice}}
+
+[Eth.TxPool]
+PriceLimit = {{.MinGasPrice}}
+{{end}}
+[Node]
+DataDir = {{printf "%q" .Datadir}}
+HTTPHost = "localhost"
+HTTPPort = {{.RPCPort}}
+HTTPModules = ["eth", "net", "web3"]
+
+[Node.P2P]
+MaxPeers = 50
+ListenAddr = ":{{.Port}}"
+BootstrapNodes = [{{range $i, $node := .Bootnodes}}{{if $i}}, {{end}}{{printf "%q" $node}}{{end}}]
+`
+
+// besuConfigTemplate is the TOML configuration file loadable by besu
+// --config-file. Besu reads the native genesis spec.
+var besuConfigTemplate = `network-id={{.NetworkID}}
+genesis-file={{printf "%q" .Genesis}}
+data-path={{printf "%q" .Datadir}}
+sync-mode="FULL"
+
+p2p-port={{.Port}}
+max-peers=50
+bootnodes=[{{range $i, $node := .Bootnodes}}{{if $i}}, {{end}}{{printf "%q" $node}}{{end}}]
+
+rpc-http-enabled=true
+rpc-http-host="127.0.0.1"
+rpc-http-port={{.RPCPort}}
+rpc-http-api=["ETH", "NET", "WEB3"]
+{{if .MinGasPrice}}
+min-gas-price={{.MinGasPrice}}
+{{end}}`
+
+// clientConfig contains the node parameters to render into the configuration
+// files of the different clients.
+type clientConfig struct {
+	Network   string   // Name of the network, used for the chain spec file names
+	NetworkID uint64   // Network identifier to join
+	Bootnodes []string // Enode URLs of the bootnodes
+	Datadir   string   // Data directory of the client
+	Port      int      // Port to listen on for peer connections
+	RPCPort   int      // Port to serve HTTP RPC on
+
+	MinGasPrice *big.Int // Gas price floor of the chain's gas schedule, nil if none
+}
+
+// nethermindConfig is the JSON configuration file loadable by nethermind
+// --config. Nethermind reads the Parity chain spec.
+type nethermindConfig struct {
+	Init struct {
+		ChainSpecPath string `json:"ChainSpecPath"`
+		BaseDbPath    string `json:"BaseDbPath"`
+		IsMining      bool   `json:"IsMining"`
+	} `json:"Init"`
+	Network struct {
+		P2PPort             int `json:"P2PPort"`
+		DiscoveryPort       int `json:"DiscoveryPort"`
+		ActivePeersMaxCount int `json:"ActivePeersMaxCount"`
+	} `json:"Network"`
+	Discovery struct {
+		Bootnodes string `json:"Bootnodes"`
+	} `json:"Discovery"`
+	JsonRpc struct {
+		Enabled bool   `json:"Enabled"`
+		Host    string `json:"Host"`
+		Port    int    `json:"Port"`
+	} `json:"JsonRpc"`
+	Mining *struct {
+		MinGasPrice *big.Int `json:"MinGasPrice"`
+	} `json:"Mining,omitempty"`
+}
+
+// newClientConfigs renders the node configuration files of the supported
+// clients, keyed by file name. The files reference the chain specs exported
+// alongside them.
+func newClientConfigs(conf *clientConfig) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	data := map[string]interface{}{
+		"NetworkID": conf.NetworkID,
+		"Bootnodes": conf.Bootnodes,
+		"Datadir":   conf.Datadir,
+		"Port":      conf.Port,
+		"RPCPort":   conf.RPCPort,
+		"Genesis":   fmt.Sprintf("%s.json", conf.Network),
+
+		"MinGasPrice": conf.MinGasPrice,
+	}
+	for client, tmpl := range map[string]string{"geth": gethConfigTemplate, "besu": besuConfigTemplate} {
+		out := new(bytes.Buffer)
+		if err := template.Must(template.New(client).Parse(tmpl)).Execute(out, data); err != nil {
+			return nil, err
+		}
+		files[fmt.Sprintf("%s-%s.toml", conf.Network, client)] = out.Bytes()
+	}
+	nethermind := new(nethermindConfig)
+	nethermind.Init.ChainSpecPath = fmt.Sprintf("%s-parity.json", conf.Network)
+	nethermind.Init.BaseDbPath = conf.Datadir
+	nethermind.Network.P2PPort = conf.Port
+	nethermind.Network.DiscoveryPort = conf.Port
+	nethermind.Network.ActivePeersMaxCount = 50
+	nethermind.Discovery.Bootnodes = strings.Join(conf.Bootnodes, ",")
+	nethermind.JsonRpc.Enabled = true
+	nethermind.JsonRpc.Host = "127.0.0.1"
+	nethermind.JsonRpc.Port = conf.RPCPort
+	if conf.MinGasPrice != nil {
+		nethermind.Mining = &struct {
+			MinGasPrice *big.Int `json:"MinGasPrice"`
+		}{conf.MinGasPrice}
+	}
+
+	out, err := json.MarshalIndent(nethermind, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files[fmt.Sprintf("%s-nethermind.cfg", conf.Network)] = out
+	return files, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// Tests that the client configurations carry the network parameters.
+func TestClientConfigs(t *testing.T) {
+	conf := &clientConfig{
+		Network:   "stureby",
+		NetworkID: 314158,
+		Bootnodes: []string{"enode://aa@1.2.3.4:30303", "enode://bb@5.6.7.8:30303"},
+		Datadir:   "/var/lib/stureby",
+		Port:      30310,
+		RPCPort:   8550,
+	}
+	files, err := newClientConfigs(conf)
+	if err != nil {
+		t.Fatalf("failed to create configs: %v", err)
+	}
+	geth := string(files["stureby-geth.toml"])
+	for _, want := range []string{
+		"NetworkId = 314158",
+		`DataDir = "/var/lib/stureby"`,
+		"HTTPPort = 8550",
+		`ListenAddr = ":30310"`,
+		`BootstrapNodes = ["enode://aa@1.2.3.4:30303", "enode://bb@5.6.7.8:30303"]`,
+	} {
+		if !strings.Contains(geth, want) {
+			t.Errorf("geth config missing %q:\n%s", want, geth)
+		}
+	}
+	besu := string(files["stureby-besu.toml"])
+	for _, want := range []string{
+		"network-id=314158",
+		`genesis-file="stureby.json"`,
+		"p2p-port=30310",
+		"rpc-http-port=8550",
+		`bootnodes=["enode://aa@1.2.3.4:30303", "enode://bb@5.6.7.8:30303"]`,
+	} {
+		if !strings.Contains(besu, want) {
+			t.Errorf("besu config missing %q:\n%s", want, besu)
+		}
+	}
+	var nethermind nethermindConfig
+	if err := json.Unmarshal(files["stureby-nethermind.cfg"], &nethermind); err != nil {
+		t.Fatalf("invalid nethermind config: %v", err)
+	}
+	if nethermind.Init.ChainSpecPath != "stureby-parity.json" || nethermind.Network.P2PPort != 30310 || nethermind.JsonRpc.Port != 8550 {
+		t.Errorf("nethermind config mismatch: %+v", nethermind)
+	}
+	if nethermind.Discovery.Bootnodes != "enode://aa@1.2.3.4:30303,enode://bb@5.6.7.8:30303" {
+		t.Errorf("nethermind bootnodes mismatch: %s", nethermind.Discovery.Bootnodes)
+	}
+}
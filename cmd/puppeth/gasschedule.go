@@ -0,0 +1,139 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core/vm"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+// gasPrecompile is a precompiled contract the gas schedule can reprice.
+type gasPrecompile struct {
+	Name    string                   // Name of the precompile in the gas schedule
+	Address byte                     // Address the precompile is deployed at
+	Default params.PrecompilePricing // Ethereum price of the precompile
+	Const   bool                     // Whether the price is per operation, without a word cost
+}
+
+// gasPrecompiles are the precompiles with a linear price, the only ones which
+// all the exported chain spec formats can reprice.
+var gasPrecompiles = []gasPrecompile{
+	{"ecrecover", 1, params.PrecompilePricing{Base: params.EcrecoverGas}, true},
+	{"sha256", 2, params.PrecompilePricing{Base: params.Sha256BaseGas, Word: params.Sha256PerWordGas}, false},
+	{"ripemd160", 3, params.PrecompilePricing{Base: params.Ripemd160BaseGas, Word: params.Ripemd160PerWordGas}, false},
+	{"identity", 4, params.PrecompilePricing{Base: params.IdentityBaseGas, Word: params.IdentityPerWordGas}, false},
+	{"bn256Add", 6, params.PrecompilePricing{Base: params.Bn256AddGasIstanbul}, true},
+	{"bn256ScalarMul", 7, params.PrecompilePricing{Base: params.Bn256ScalarMulGasIstanbul}, true},
+}
+
+// effectiveGasSchedule returns the gas schedule with all defaults filled in.
+func effectiveGasSchedule(schedule *params.GasScheduleConfig) *params.GasScheduleConfig {
+	effective := &params.GasScheduleConfig{
+		TxGas:                 params.TxGas,
+		TxGasContractCreation: params.TxGasContractCreation,
+		TxDataZeroGas:         params.TxDataZeroGas,
+		TxDataNonZeroGas:      params.TxDataNonZeroGasEIP2028,
+		Precompiles:           make(map[string]*params.PrecompilePricing),
+	}
+	for _, precompile := range gasPrecompiles {
+		pricing := precompile.Default
+		effective.Precompiles[precompile.Name] = &pricing
+	}
+	if schedule == nil {
+		return effective
+	}
+	for _, field := range []struct {
+		dst *uint64
+		src uint64
+	}{
+		{&effective.TxGas, schedule.TxGas},
+		{&effective.TxGasContractCreation, schedule.TxGasContractCreation},
+		{&effective.TxDataZeroGas, schedule.TxDataZeroGas},
+		{&effective.TxDataNonZeroGas, schedule.TxDataNonZeroGas},
+	} {
+		if field.src != 0 {
+			*field.dst = field.src
+		}
+	}
+	effective.MinGasPrice = schedule.MinGasPrice
+	for name, pricing := range schedule.Precompiles {
+		effective.Precompiles[name] = pricing
+	}
+	return effective
+}
+
+// checkGasSchedule validates the overrides of a gas schedule.
+func checkGasSchedule(schedule *params.GasScheduleConfig) error {
+	if schedule == nil {
+		return nil
+	}
+	effective := effectiveGasSchedule(schedule)
+	if effective.TxGasContractCreation < effective.TxGas {
+		return fmt.Errorf("contract creation gas %d below transaction gas %d", effective.TxGasContractCreation, effective.TxGas)
+	}
+	if effective.TxDataNonZeroGas < effective.TxDataZeroGas {
+		return fmt.Errorf("non-zero byte gas %d below zero byte gas %d", effective.TxDataNonZeroGas, effective.TxDataZeroGas)
+	}
+	if schedule.MinGasPrice != nil && schedule.MinGasPrice.Sign() < 0 {
+		return errors.New("negative minimum gas price")
+	}
+	for name, pricing := range schedule.Precompiles {
+		precompile := findGasPrecompile(name)
+		if precompile == nil {
+			return fmt.Errorf("unknown precompile %q", name)
+		}
+		// Geth must charge the same price as the clients of the exported specs
+		if addr, ok := vm.RepriceablePrecompiles[name]; !ok || addr != common.BytesToAddress([]byte{precompile.Address}) {
+			return fmt.Errorf("precompile %s not repriceable by geth", name)
+		}
+		// Free precompiles would allow spamming the network with heavy computations
+		if pricing == nil || pricing.Base == 0 {
+			return fmt.Errorf("precompile %s without base price", name)
+		}
+		if precompile.Const && pricing.Word != 0 {
+			return fmt.Errorf("precompile %s can't be priced per word", name)
+		}
+	}
+	return nil
+}
+
+// findGasPrecompile looks up a repriceable precompile by name.
+func findGasPrecompile(name string) *gasPrecompile {
+	for i := range gasPrecompiles {
+		if gasPrecompiles[i].Name == name {
+			return &gasPrecompiles[i]
+		}
+	}
+	return nil
+}
+
+// hasIntrinsicOverrides reports whether a gas schedule changes the intrinsic gas
+// of transactions, which the chain spec formats of other clients can't express.
+func hasIntrinsicOverrides(schedule *params.GasScheduleConfig) bool {
+	return schedule != nil && (schedule.TxGas != 0 || schedule.TxGasContractCreation != 0 ||
+		schedule.TxDataZeroGas != 0 || schedule.TxDataNonZeroGas != 0)
+}
+
+// isDefaultGasSchedule reports whether a gas schedule has no overrides at all.
+func isDefaultGasSchedule(schedule *params.GasScheduleConfig) bool {
+	return schedule == nil || reflect.DeepEqual(*schedule, params.GasScheduleConfig{})
+}
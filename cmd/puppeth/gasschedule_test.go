@@ -0,0 +1,121 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+	"github.com/liuguodong24-8/3fcoin/core/core/vm"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+// Tests that invalid gas schedules are rejected.
+func TestGasScheduleValidation(t *testing.T) {
+	tests := []struct {
+		schedule *params.GasScheduleConfig
+		valid    bool
+	}{
+		{nil, true},
+		{&params.GasScheduleConfig{TxGas: 5000, TxDataNonZeroGas: 8, MinGasPrice: big.NewInt(1)}, true},
+		{&params.GasScheduleConfig{TxGas: 60000}, false},
+		{&params.GasScheduleConfig{TxDataZeroGas: 20}, false},
+		{&params.GasScheduleConfig{MinGasPrice: big.NewInt(-1)}, false},
+		{&params.GasScheduleConfig{Precompiles: map[string]*params.PrecompilePricing{"sha256": {Base: 30, Word: 6}}}, true},
+		{&params.GasScheduleConfig{Precompiles: map[string]*params.PrecompilePricing{"sha256": {Word: 6}}}, false},
+		{&params.GasScheduleConfig{Precompiles: map[string]*params.PrecompilePricing{"ecrecover": {Base: 1000, Word: 1}}}, false},
+		{&params.GasScheduleConfig{Precompiles: map[string]*params.PrecompilePricing{"modexp": {Base: 1}}}, false},
+	}
+	for i, tt := range tests {
+		if err := checkGasSchedule(tt.schedule); (err == nil) != tt.valid {
+			t.Errorf("test %d: validity mismatch: have %v, want valid %v", i, err, tt.valid)
+		}
+	}
+}
+
+// Tests that geth reprices every precompile the gas schedule may name, so the
+// exported specs can't charge differently than geth.
+func TestGasPrecompilesRepriceable(t *testing.T) {
+	for _, precompile := range gasPrecompiles {
+		if addr, ok := vm.RepriceablePrecompiles[precompile.Name]; !ok || addr != common.BytesToAddress([]byte{precompile.Address}) {
+			t.Errorf("precompile %s not repriced by geth", precompile.Name)
+		}
+	}
+}
+
+// Tests that gas schedule overrides are propagated into the exported chain specs
+// and client configs.
+func TestGasScheduleExport(t *testing.T) {
+	blob, err := ioutil.ReadFile("testdata/stureby_geth.json")
+	if err != nil {
+		t.Fatalf("could not read file: %v", err)
+	}
+	var genesis core.Genesis
+	if err := json.Unmarshal(blob, &genesis); err != nil {
+		t.Fatalf("failed parsing genesis: %v", err)
+	}
+	genesis.Config.GasSchedule = &params.GasScheduleConfig{
+		MinGasPrice: big.NewInt(1000000000),
+		Precompiles: map[string]*params.PrecompilePricing{"sha256": {Base: 30, Word: 6}},
+	}
+	sha256 := common.BytesToAddress([]byte{2})
+
+	aleth, err := newAlethGenesisSpec("stureby", &genesis)
+	if err != nil {
+		t.Fatalf("failed creating aleth chainspec: %v", err)
+	}
+	if have := aleth.Accounts[sha256].Precompiled.Linear; have.Base != 30 || have.Word != 6 {
+		t.Errorf("aleth sha256 pricing mismatch: have %+v", have)
+	}
+	parity, err := newParityChainSpec("stureby", &genesis, nil)
+	if err != nil {
+		t.Fatalf("failed creating parity chainspec: %v", err)
+	}
+	if have := parity.Accounts[sha256].Builtin.Pricing.(*parityChainSpecPricing).Linear; have.Base != 30 || have.Word != 6 {
+		t.Errorf("parity sha256 pricing mismatch: have %+v", have)
+	}
+	// Intrinsic gas can only be carried by the native spec
+	genesis.Config.GasSchedule.TxGas = 5000
+	if _, err := newAlethGenesisSpec("stureby", &genesis); err == nil {
+		t.Errorf("aleth accepted intrinsic gas override")
+	}
+	if _, err := newParityChainSpec("stureby", &genesis, nil); err == nil {
+		t.Errorf("parity accepted intrinsic gas override")
+	}
+	native, _ := json.Marshal(&genesis)
+	if !strings.Contains(string(native), `"gasSchedule":{"txGas":5000`) {
+		t.Errorf("native spec lacks gas schedule: %s", native)
+	}
+	files, err := newClientConfigs(&clientConfig{Network: "stureby", MinGasPrice: genesis.Config.GasSchedule.MinGasPrice})
+	if err != nil {
+		t.Fatalf("failed to create configs: %v", err)
+	}
+	for file, want := range map[string]string{
+		"stureby-geth.toml":      "PriceLimit = 1000000000",
+		"stureby-besu.toml":      "min-gas-price=1000000000",
+		"stureby-nethermind.cfg": `"MinGasPrice": 1000000000`,
+	} {
+		if !strings.Contains(string(files[file]), want) {
+			t.Errorf("%s missing %q:\n%s", file, want, files[file])
+		}
+	}
+}
@@ -18,6 +18,7 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"strings"
@@ -196,6 +197,9 @@ func newAlethGenesisSpec(network string, genesis *core.Genesis) (*alethGenesisSp
 			StartingBlock: (*hexutil.Big)(genesis.Config.IstanbulBlock),
 		})
 	}
+	if err := spec.setGasSchedule(genesis.Config.GasSchedule); err != nil {
+		return nil, err
+	}
 	return spec, nil
 }
 
@@ -210,6 +214,35 @@ func (spec *alethGenesisSpec) setPrecompile(address byte, data *alethGenesisSpec
 	spec.Accounts[addr].Precompiled = data
 }
 
+// setGasSchedule reprices the precompiles according to the chain specific gas
+// schedule. Aleth has no notion of intrinsic gas overrides, so those are refused.
+func (spec *alethGenesisSpec) setGasSchedule(schedule *params.GasScheduleConfig) error {
+	if schedule == nil {
+		return nil
+	}
+	if err := checkGasSchedule(schedule); err != nil {
+		return err
+	}
+	if hasIntrinsicOverrides(schedule) {
+		return errors.New("intrinsic gas overrides not supported by aleth")
+	}
+	for name, pricing := range schedule.Precompiles {
+		precompile := findGasPrecompile(name)
+		if precompile == nil {
+			return fmt.Errorf("unknown precompile %q", name)
+		}
+		account := spec.Accounts[common.BytesToAddress([]byte{precompile.Address})]
+		if account == nil || account.Precompiled == nil {
+			return fmt.Errorf("precompile %s not active", name)
+		}
+		if account.Precompiled.Linear == nil {
+			return fmt.Errorf("aleth hardcoded the price of precompile %s", name)
+		}
+		account.Precompiled.Linear = &alethGenesisSpecLinearPricing{Base: pricing.Base, Word: pricing.Word}
+	}
+	return nil
+}
+
 func (spec *alethGenesisSpec) setAccount(address common.Address, account core.GenesisAccount) {
 	if spec.Accounts == nil {
 		spec.Accounts = make(map[common.Address]*alethGenesisSpecAccount)
@@ -544,6 +577,9 @@ func newParityChainSpec(network string, genesis *core.Genesis, bootnodes []strin
 			},
 		})
 	}
+	if err := spec.setGasSchedule(genesis.Config.GasSchedule); err != nil {
+		return nil, err
+	}
 	return spec, nil
 }
 
@@ -558,6 +594,35 @@ func (spec *parityChainSpec) setPrecompile(address byte, data *parityChainSpecBu
 	spec.Accounts[a].Builtin = data
 }
 
+// setGasSchedule reprices the precompiles according to the chain specific gas
+// schedule. Parity has no notion of intrinsic gas overrides, so those are refused.
+func (spec *parityChainSpec) setGasSchedule(schedule *params.GasScheduleConfig) error {
+	if schedule == nil {
+		return nil
+	}
+	if err := checkGasSchedule(schedule); err != nil {
+		return err
+	}
+	if hasIntrinsicOverrides(schedule) {
+		return errors.New("intrinsic gas overrides not supported by parity")
+	}
+	for name, pricing := range schedule.Precompiles {
+		precompile := findGasPrecompile(name)
+		if precompile == nil {
+			return fmt.Errorf("unknown precompile %q", name)
+		}
+		account := spec.Accounts[common.BytesToAddress([]byte{precompile.Address})]
+		if account == nil || account.Builtin == nil {
+			return fmt.Errorf("precompile %s not active", name)
+		}
+		// A single linear price replaces any fork specific versions
+		account.Builtin.Pricing = &parityChainSpecPricing{
+			Linear: &parityChainSpecLinearPricing{Base: pricing.Base, Word: pricing.Word},
+		}
+	}
+	return nil
+}
+
 func (spec *parityChainSpec) setByzantium(num *big.Int) {
 	spec.Engine.Ethash.Params.BlockReward[hexutil.EncodeBig(num)] = hexutil.EncodeBig(ethash.ByzantiumBlockReward)
 	spec.Engine.Ethash.Params.DifficultyBombDelays[hexutil.EncodeBig(num)] = hexutil.EncodeUint64(3000000)
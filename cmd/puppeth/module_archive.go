@@ -0,0 +1,252 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/core"
+)
+
+// errNoArchivedSpec is returned if no archived spec was active at a block.
+var errNoArchivedSpec = errors.New("no archived spec active at block")
+
+// specArchive is a content addressed store of every genesis spec a network was
+// configured with, along with a changelog of who changed which fork blocks and
+// when. It permits reproducing the exact spec active at any past block.
+type specArchive struct {
+	dir string // Folder containing the specs and the changelog
+}
+
+// specChange is a single fork block modified between two archived specs.
+type specChange struct {
+	Fork string   `json:"fork"`          // JSON name of the fork block within the chain config
+	Old  *big.Int `json:"old,omitempty"` // Block the fork was previously scheduled at
+	New  *big.Int `json:"new,omitempty"` // Block the fork is now scheduled at
+}
+
+// specEntry is a changelog entry recording an update or export of the spec.
+type specEntry struct {
+	Time       time.Time    `json:"time"`              // Time the spec was archived
+	User       string       `json:"user"`              // Operator running puppeth
+	Action     string       `json:"action"`            // Wizard action touching the spec
+	Hash       string       `json:"hash,omitempty"`    // SHA256 of the archived spec, empty on removal
+	Activation uint64       `json:"activation"`        // First block the spec differs from the previous one at
+	Changes    []specChange `json:"changes,omitempty"` // Fork blocks changed compared to the previous spec
+}
+
+// effective reports whether the entry changed the spec the nodes run with, as
+// opposed to only scheduling or exporting it.
+func (entry *specEntry) effective() bool {
+	switch entry.Action {
+	case "create", "import", "modify", "distribute":
+		return true
+	}
+	return false
+}
+
+// newSpecArchive creates an archive rooted at the given folder.
+func newSpecArchive(dir string) *specArchive {
+	return &specArchive{dir: dir}
+}
+
+// record archives the given spec and appends an entry to the changelog. The
+// fork changes and activation block are derived from the last effective spec.
+func (a *specArchive) record(action, user string, genesis *core.Genesis) (*specEntry, error) {
+	entries, err := a.changelog()
+	if err != nil {
+		return nil, err
+	}
+	entry := &specEntry{Time: time.Now().UTC(), User: user, Action: action}
+
+	if genesis != nil {
+		blob, err := json.MarshalIndent(genesis, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(blob)
+		entry.Hash = hex.EncodeToString(hash[:])
+
+		path := filepath.Join(a.dir, "specs", entry.Hash+".json")
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return nil, err
+			}
+			if err := ioutil.WriteFile(path, blob, 0644); err != nil {
+				return nil, err
+			}
+		}
+		// New networks start from scratch, updates are diffed against the last spec
+		if action != "create" && action != "import" {
+			prev, err := a.current(entries)
+			if err != nil {
+				return nil, err
+			}
+			if prev != nil {
+				entry.Activation = prev.activation
+				if entry.Changes = diffForkBlocks(prev.genesis, genesis); len(entry.Changes) > 0 {
+					entry.Activation = firstChange(entry.Changes)
+				}
+			}
+		}
+	}
+	entries = append(entries, entry)
+
+	out, _ := json.MarshalIndent(entries, "", "  ")
+	if err := os.MkdirAll(a.dir, 0755); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(filepath.Join(a.dir, "changelog.json"), out, 0644); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// changelog loads all the entries recorded in the archive, oldest first.
+func (a *specArchive) changelog() ([]*specEntry, error) {
+	blob, err := ioutil.ReadFile(filepath.Join(a.dir, "changelog.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []*specEntry
+	if err := json.Unmarshal(blob, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// spec loads an archived spec by hash, verifying that it was not tampered with.
+func (a *specArchive) spec(hash string) (*core.Genesis, error) {
+	blob, err := ioutil.ReadFile(filepath.Join(a.dir, "specs", hash+".json"))
+	if err != nil {
+		return nil, err
+	}
+	if have := sha256.Sum256(blob); hex.EncodeToString(have[:]) != hash {
+		return nil, fmt.Errorf("archived spec %s corrupted", hash)
+	}
+	genesis := new(core.Genesis)
+	if err := json.Unmarshal(blob, genesis); err != nil {
+		return nil, err
+	}
+	return genesis, nil
+}
+
+// archivedSpec is an effective spec along with the block it activated at.
+type archivedSpec struct {
+	entry      *specEntry
+	genesis    *core.Genesis
+	activation uint64
+}
+
+// current returns the latest effective spec of the changelog, or nil if the
+// genesis was never created or got removed since.
+func (a *specArchive) current(entries []*specEntry) (*archivedSpec, error) {
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Action == "remove" {
+			return nil, nil
+		}
+		if !entries[i].effective() {
+			continue
+		}
+		genesis, err := a.spec(entries[i].Hash)
+		if err != nil {
+			return nil, err
+		}
+		return &archivedSpec{entry: entries[i], genesis: genesis, activation: entries[i].Activation}, nil
+	}
+	return nil, nil
+}
+
+// specAt returns the changelog entry and spec that was in effect at the given
+// block of the current network, i.e. the last effective entry since the genesis
+// was created whose fork changes activated at or before the block.
+func (a *specArchive) specAt(number uint64) (*specEntry, *core.Genesis, error) {
+	entries, err := a.changelog()
+	if err != nil {
+		return nil, nil, err
+	}
+	var active *specEntry
+	for _, entry := range entries {
+		switch {
+		case entry.Action == "remove":
+			active = nil
+		case entry.Action == "create" || entry.Action == "import":
+			active = entry
+		case entry.effective() && entry.Activation <= number:
+			active = entry
+		}
+	}
+	if active == nil {
+		return nil, nil, errNoArchivedSpec
+	}
+	genesis, err := a.spec(active.Hash)
+	if err != nil {
+		return nil, nil, err
+	}
+	return active, genesis, nil
+}
+
+// diffForkBlocks lists the fork blocks that differ between two specs.
+func diffForkBlocks(prev, next *core.Genesis) []specChange {
+	if prev.Config == nil || next.Config == nil {
+		return nil
+	}
+	blocks := make(map[string]*big.Int)
+	for _, fork := range forkBlocks(prev.Config) {
+		blocks[fork.Name] = fork.Block
+	}
+	var changes []specChange
+	for _, fork := range forkBlocks(next.Config) {
+		before := blocks[fork.Name]
+		if before == nil && fork.Block == nil {
+			continue
+		}
+		if before == nil || fork.Block == nil || before.Cmp(fork.Block) != 0 {
+			changes = append(changes, specChange{Fork: fork.Name, Old: before, New: fork.Block})
+		}
+	}
+	return changes
+}
+
+// firstChange returns the lowest block any of the changed forks was or is now
+// scheduled at, which is where the two specs start to diverge.
+func firstChange(changes []specChange) uint64 {
+	var first *big.Int
+	for _, change := range changes {
+		for _, block := range []*big.Int{change.Old, change.New} {
+			if block != nil && (first == nil || block.Cmp(first) < 0) {
+				first = block
+			}
+		}
+	}
+	if first == nil {
+		return 0
+	}
+	return first.Uint64()
+}
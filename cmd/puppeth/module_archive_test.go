@@ -0,0 +1,124 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/core"
+)
+
+// Tests that archived specs can be reproduced at any past block and that the
+// changelog tracks the modified fork blocks.
+func TestSpecArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "puppeth-archive-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	archive := newSpecArchive(dir)
+	if _, _, err := archive.specAt(0); err != errNoArchivedSpec {
+		t.Fatalf("empty archive lookup error mismatch: have %v, want %v", err, errNoArchivedSpec)
+	}
+	genesis := &core.Genesis{Config: testForkConfig(), GasLimit: 8000000}
+	created, err := archive.record("create", "alice", genesis)
+	if err != nil {
+		t.Fatalf("failed to archive created spec: %v", err)
+	}
+	// Modify a fork block, schedule and distribute another
+	modified := *genesis
+	config := *genesis.Config
+	modified.Config = &config
+	modified.Config.BrunoBlock = big.NewInt(100)
+
+	entry, err := archive.record("modify", "bob", &modified)
+	if err != nil {
+		t.Fatalf("failed to archive modified spec: %v", err)
+	}
+	if entry.Activation != 100 || len(entry.Changes) != 1 || entry.Changes[0].Fork != "brunoBlock" || entry.Changes[0].Old != nil || entry.Changes[0].New.Uint64() != 100 {
+		t.Fatalf("modification entry mismatch: %+v", entry)
+	}
+	distributed := modified
+	config = *modified.Config
+	distributed.Config = &config
+	distributed.Config.BerlinBlock = big.NewInt(200)
+
+	if _, err := archive.record("schedule", "bob", &distributed); err != nil {
+		t.Fatalf("failed to archive scheduled spec: %v", err)
+	}
+	if _, err := archive.record("distribute", "bob", &distributed); err != nil {
+		t.Fatalf("failed to archive distributed spec: %v", err)
+	}
+	if _, err := archive.record("export", "carol", &distributed); err != nil {
+		t.Fatalf("failed to archive exported spec: %v", err)
+	}
+	entries, err := archive.changelog()
+	if err != nil {
+		t.Fatalf("failed to load changelog: %v", err)
+	}
+	if len(entries) != 5 || entries[4].User != "carol" || entries[4].Hash != entries[3].Hash || entries[4].Activation != 200 || len(entries[4].Changes) != 0 {
+		t.Fatalf("changelog mismatch: %d entries, last %+v", len(entries), entries[len(entries)-1])
+	}
+	// Ensure the exact spec of every block range can be reproduced
+	for _, tt := range []struct {
+		number uint64
+		hash   string
+		bruno  *big.Int
+		berlin *big.Int
+	}{
+		{0, created.Hash, nil, nil},
+		{99, created.Hash, nil, nil},
+		{100, entry.Hash, big.NewInt(100), nil},
+		{199, entry.Hash, big.NewInt(100), nil},
+		{200, entries[3].Hash, big.NewInt(100), big.NewInt(200)},
+		{1000000, entries[3].Hash, big.NewInt(100), big.NewInt(200)},
+	} {
+		active, spec, err := archive.specAt(tt.number)
+		if err != nil {
+			t.Fatalf("block %d: failed to reproduce spec: %v", tt.number, err)
+		}
+		if active.Hash != tt.hash {
+			t.Errorf("block %d: spec mismatch: have %s, want %s", tt.number, active.Hash, tt.hash)
+		}
+		if (spec.Config.BrunoBlock == nil) != (tt.bruno == nil) || (tt.bruno != nil && spec.Config.BrunoBlock.Cmp(tt.bruno) != 0) {
+			t.Errorf("block %d: bruno block mismatch: have %v, want %v", tt.number, spec.Config.BrunoBlock, tt.bruno)
+		}
+		if (spec.Config.BerlinBlock == nil) != (tt.berlin == nil) || (tt.berlin != nil && spec.Config.BerlinBlock.Cmp(tt.berlin) != 0) {
+			t.Errorf("block %d: berlin block mismatch: have %v, want %v", tt.number, spec.Config.BerlinBlock, tt.berlin)
+		}
+	}
+	// Tampered specs must be rejected
+	path := filepath.Join(dir, "specs", created.Hash+".json")
+	if err := ioutil.WriteFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := archive.specAt(0); err == nil {
+		t.Errorf("tampered spec accepted")
+	}
+	// Removing the genesis ends the history of the network
+	if _, err := archive.record("remove", "alice", nil); err != nil {
+		t.Fatalf("failed to archive removal: %v", err)
+	}
+	if _, _, err := archive.specAt(1000000); err != errNoArchivedSpec {
+		t.Errorf("removed genesis lookup error mismatch: have %v, want %v", err, errNoArchivedSpec)
+	}
+}
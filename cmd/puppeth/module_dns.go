@@ -0,0 +1,168 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cloudflare/cloudflare-go"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/dnsdisc"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/enode"
+)
+
+const (
+	dnsRootTTL = 30 * 60              // Root records change on every publish, keep them short lived
+	dnsNodeTTL = 4 * 7 * 24 * 60 * 60 // Tree nodes are content addressed, they never change
+)
+
+// dnsTree is the EIP-1459 node list of a managed network, as last published.
+type dnsTree struct {
+	Domain string   `json:"domain"`           // Domain name the tree is published at
+	Seq    uint     `json:"seq"`              // Sequence number of the last published tree
+	Links  []string `json:"links,omitempty"`  // Other trees linked from this one (enrtree:// URLs)
+	ZoneID string   `json:"zoneid,omitempty"` // Cloudflare zone the tree was pushed to, if any
+	URL    string   `json:"url,omitempty"`    // Signed enrtree:// URL to hand to nodes
+}
+
+// queryNodeRecord retrieves the signed node record of a boot or seal node.
+func queryNodeRecord(client *sshClient, network string, kind string) (*enode.Node, error) {
+	out, err := client.Run(fmt.Sprintf("docker exec %s_%s_1 geth --exec admin.nodeInfo.enr --cache=16 attach", network, kind))
+	if err != nil {
+		return nil, ErrServiceUnreachable
+	}
+	return enode.Parse(enode.ValidSchemes, string(bytes.Trim(bytes.TrimSpace(out), "\"")))
+}
+
+// makeDNSTree builds and signs the discovery tree of a set of nodes, returning
+// the tree and its enrtree:// URL.
+func makeDNSTree(domain string, seq uint, nodes []*enode.Node, links []string, key *ecdsa.PrivateKey) (*dnsdisc.Tree, string, error) {
+	if len(nodes) == 0 && len(links) == 0 {
+		return nil, "", errors.New("no nodes or links to publish")
+	}
+	for _, link := range links {
+		if _, _, err := dnsdisc.ParseURL(link); err != nil {
+			return nil, "", fmt.Errorf("invalid link %q: %v", link, err)
+		}
+	}
+	tree, err := dnsdisc.MakeTree(seq, nodes, links)
+	if err != nil {
+		return nil, "", err
+	}
+	url, err := tree.Sign(key, domain)
+	if err != nil {
+		return nil, "", err
+	}
+	return tree, url, nil
+}
+
+// dnsZoneFile renders the TXT records of a tree as zone file entries. Values
+// longer than a DNS character-string are split into multiple strings.
+func dnsZoneFile(domain string, records map[string]string) []byte {
+	names := make([]string, 0, len(records))
+	for name := range records {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		// Keep the root record on top, it's the one being looked at
+		if (names[i] == domain) != (names[j] == domain) {
+			return names[i] == domain
+		}
+		return names[i] < names[j]
+	})
+	var zone bytes.Buffer
+	fmt.Fprintf(&zone, "; EIP-1459 node tree of %s\n", domain)
+	for _, name := range names {
+		ttl := dnsRootTTL
+		if name != domain {
+			ttl = dnsNodeTTL
+		}
+		value := records[name]
+
+		var chunks []string
+		for len(value) > 255 {
+			chunks, value = append(chunks, value[:255]), value[255:]
+		}
+		chunks = append(chunks, value)
+		fmt.Fprintf(&zone, "%s.\t%d\tIN\tTXT\t\"%s\"\n", name, ttl, strings.Join(chunks, "\" \""))
+	}
+	return zone.Bytes()
+}
+
+// deployCloudflare pushes the TXT records of a tree into a Cloudflare zone,
+// updating changed records and deleting stale ones below the domain. If the
+// zone ID is empty, it's looked up by the domain.
+func deployCloudflare(token string, zoneID string, domain string, records map[string]string) (string, error) {
+	api, err := cloudflare.NewWithAPIToken(token)
+	if err != nil {
+		return "", err
+	}
+	ctx := context.Background()
+	if zoneID == "" {
+		labels := strings.Split(domain, ".")
+		for i := 0; i < len(labels)-1 && zoneID == ""; i++ {
+			zoneID, _ = api.ZoneIDByName(strings.Join(labels[i:], "."))
+		}
+		if zoneID == "" {
+			return "", fmt.Errorf("no Cloudflare zone found for %s", domain)
+		}
+	}
+	entries, err := api.DNSRecords(ctx, zoneID, cloudflare.DNSRecord{Type: "TXT"})
+	if err != nil {
+		return "", err
+	}
+	existing := make(map[string]cloudflare.DNSRecord)
+	for _, entry := range entries {
+		if name := strings.ToLower(entry.Name); name == domain || strings.HasSuffix(name, "."+domain) {
+			existing[name] = entry
+		}
+	}
+	for name, value := range records {
+		name = strings.ToLower(name)
+		old, ok := existing[name]
+		switch {
+		case !ok:
+			ttl := dnsRootTTL
+			if name != domain {
+				ttl = dnsNodeTTL
+			}
+			log.Debug("Creating DNS record", "name", name)
+			_, err = api.CreateDNSRecord(ctx, zoneID, cloudflare.DNSRecord{Type: "TXT", Name: name, Content: value, TTL: ttl})
+		case old.Content != value:
+			log.Debug("Updating DNS record", "name", name)
+			old.Content = value
+			err = api.UpdateDNSRecord(ctx, zoneID, old.ID, old)
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to publish %s: %v", name, err)
+		}
+		delete(existing, name)
+	}
+	for name, entry := range existing {
+		log.Debug("Deleting stale DNS record", "name", name)
+		if err := api.DeleteDNSRecord(ctx, zoneID, entry.ID); err != nil {
+			return "", fmt.Errorf("failed to delete %s: %v", name, err)
+		}
+	}
+	return zoneID, nil
+}
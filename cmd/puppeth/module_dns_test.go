@@ -0,0 +1,97 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/dnsdisc"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/enode"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/enr"
+)
+
+// Tests that the node tree of a network is signed for the requested domain and
+// rendered into zone file records.
+func TestDNSTree(t *testing.T) {
+	signer, _ := crypto.GenerateKey()
+
+	var nodes []*enode.Node
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+
+		var r enr.Record
+		r.Set(enr.IP(net.IPv4(10, 0, 0, byte(i+1))))
+		r.Set(enr.TCP(30303))
+		r.Set(enr.UDP(30303))
+		if err := enode.SignV4(&r, key); err != nil {
+			t.Fatalf("failed to sign record: %v", err)
+		}
+		node, err := enode.New(enode.ValidSchemes, &r)
+		if err != nil {
+			t.Fatalf("failed to create node: %v", err)
+		}
+		nodes = append(nodes, node)
+	}
+	if _, _, err := makeDNSTree("nodes.example.org", 1, nil, nil, signer); err == nil {
+		t.Errorf("empty tree signed")
+	}
+	if _, _, err := makeDNSTree("nodes.example.org", 1, nodes, []string{"enrtree://invalid"}, signer); err == nil {
+		t.Errorf("tree with invalid link signed")
+	}
+	tree, url, err := makeDNSTree("nodes.example.org", 7, nodes, nil, signer)
+	if err != nil {
+		t.Fatalf("failed to make tree: %v", err)
+	}
+	domain, pubkey, err := dnsdisc.ParseURL(url)
+	if err != nil {
+		t.Fatalf("invalid tree URL %s: %v", url, err)
+	}
+	if domain != "nodes.example.org" || !pubkey.Equal(&signer.PublicKey) {
+		t.Errorf("tree URL mismatch: have %s %x", domain, crypto.FromECDSAPub(pubkey))
+	}
+	if tree.Seq() != 7 || len(tree.Nodes()) != len(nodes) {
+		t.Errorf("tree content mismatch: seq %d, %d nodes", tree.Seq(), len(tree.Nodes()))
+	}
+	records := tree.ToTXT("nodes.example.org")
+	zone := string(dnsZoneFile("nodes.example.org", records))
+
+	lines := strings.Split(strings.TrimSpace(zone), "\n")
+	if len(lines) != len(records)+1 {
+		t.Fatalf("zone record count mismatch: have %d, want %d", len(lines)-1, len(records))
+	}
+	if want := "nodes.example.org.\t1800\tIN\tTXT\t\"" + records["nodes.example.org"] + "\""; lines[1] != want {
+		t.Errorf("root record mismatch: have %q, want %q", lines[1], want)
+	}
+	for _, line := range lines[2:] {
+		if !strings.Contains(line, ".nodes.example.org.\t2419200\tIN\tTXT\t\"") {
+			t.Errorf("invalid tree record: %q", line)
+		}
+	}
+}
+
+// Tests that record values exceeding a DNS character-string are split up.
+func TestDNSZoneFileSplit(t *testing.T) {
+	value := strings.Repeat("a", 300)
+	zone := string(dnsZoneFile("example.org", map[string]string{"x.example.org": value}))
+
+	if want := "\"" + value[:255] + "\" \"" + value[255:] + "\"\n"; !strings.HasSuffix(zone, want) {
+		t.Errorf("long record not split: %q", zone)
+	}
+}
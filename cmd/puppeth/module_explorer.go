@@ -24,7 +24,9 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	texttemplate "text/template"
 
+	"github.com/liuguodong24-8/3fcoin/core/common"
 	"github.com/liuguodong24-8/3fcoin/core/log"
 )
 
@@ -33,7 +35,9 @@ var explorerDockerfile = `
 FROM puppeth/blockscout:latest
 
 ADD genesis.json /genesis.json
+ADD fff-address.js /opt/app/apps/block_scout_web/priv/static/js/fff-address.js
 RUN \
+  sed -i 's|</body>|<script src="/js/fff-address.js"></script></body>|' /opt/app/apps/block_scout_web/lib/block_scout_web/templates/layout/app.html.eex && \
   echo 'geth --cache 512 init /genesis.json' > explorer.sh && \
   echo $'geth --networkid {{.NetworkID}} --syncmode "full" --gcmode "archive" --port {{.EthPort}} --bootnodes {{.Bootnodes}} --ethstats \'{{.Ethstats}}\' --cache=512 --http --http.api "net,web3,eth,shh,debug" --http.corsdomain "*" --http.vhosts "*" --ws --ws.origins "*" --exitwhensynced' >> explorer.sh && \
   echo $'exec geth --networkid {{.NetworkID}} --syncmode "full" --gcmode "archive" --port {{.EthPort}} --bootnodes {{.Bootnodes}} --ethstats \'{{.Ethstats}}\' --cache=512 --http --http.api "net,web3,eth,shh,debug" --http.corsdomain "*" --http.vhosts "*" --ws --ws.origins "*" &' >> explorer.sh && \
@@ -45,6 +49,81 @@ RUN \
 ENTRYPOINT ["/bin/sh", "explorer.sh"]
 `
 
+// explorerAddressPlugin is the script injected into the explorer pages to render
+// the hex addresses in FFF form, and to accept FFF addresses in the search box.
+var explorerAddressPlugin = `
+(function() {
+  var prefix = "{{.Prefix}}";
+  var alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz";
+  var hexAddress = /0x[0-9a-fA-F]{40}(?![0-9a-fA-F])/g;
+
+  // encode converts a hex address into FFF form, base58 over its lowercase hex
+  function encode(hex) {
+    hex = hex.slice(2).toLowerCase();
+    var n = BigInt(0);
+    for (var i = 0; i < hex.length; i++) {
+      n = n * BigInt(256) + BigInt(hex.charCodeAt(i));
+    }
+    var body = "";
+    while (n > BigInt(0)) {
+      body = alphabet[Number(n % BigInt(58))] + body;
+      n = n / BigInt(58);
+    }
+    return prefix + body;
+  }
+  // decode converts an FFF address of this network into hex form, or returns
+  // null if the input isn't one
+  function decode(fff) {
+    if (fff.slice(0, prefix.length).toUpperCase() !== prefix.toUpperCase()) {
+      return null;
+    }
+    var n = BigInt(0);
+    var body = fff.slice(prefix.length);
+    for (var i = 0; i < body.length; i++) {
+      var digit = alphabet.indexOf(body[i]);
+      if (digit < 0) {
+        return null;
+      }
+      n = n * BigInt(58) + BigInt(digit);
+    }
+    var hex = "";
+    while (n > BigInt(0)) {
+      hex = String.fromCharCode(Number(n % BigInt(256))) + hex;
+      n = n / BigInt(256);
+    }
+    return /^[0-9a-f]{40}$/.test(hex) ? "0x" + hex : null;
+  }
+  // render rewrites the hex addresses within the text nodes below root
+  function render(root) {
+    var walker = document.createTreeWalker(root, NodeFilter.SHOW_TEXT);
+    for (var node = walker.nextNode(); node; node = walker.nextNode()) {
+      if (hexAddress.test(node.nodeValue)) {
+        node.nodeValue = node.nodeValue.replace(hexAddress, encode);
+      }
+      hexAddress.lastIndex = 0;
+    }
+  }
+  document.addEventListener("DOMContentLoaded", function() {
+    render(document.body);
+    new MutationObserver(function(mutations) {
+      mutations.forEach(function(mutation) {
+        mutation.addedNodes.forEach(function(node) {
+          render(node);
+        });
+      });
+    }).observe(document.body, {childList: true, subtree: true});
+
+    document.addEventListener("submit", function(event) {
+      var input = event.target.querySelector("input[name=q]");
+      var hex = input && decode(input.value.trim());
+      if (hex) {
+        input.value = hex;
+      }
+    }, true);
+  });
+})();
+`
+
 // explorerComposefile is the docker-compose.yml file required to deploy and
 // maintain a block explorer.
 var explorerComposefile = `
@@ -61,6 +140,10 @@ services:
         environment:
             - ETH_PORT={{.EthPort}}
             - ETH_NAME={{.EthName}}
+            - CHAIN_ID={{.ChainID}}
+            - NETWORK={{.Network}}
+            - COIN={{.Coin}}
+            - FFF_PREFIX={{.Prefix}}
             - BLOCK_TRANSFORMER={{.Transformer}}{{if .VHost}}
             - VIRTUAL_HOST={{.VHost}}
             - VIRTUAL_PORT=4000{{end}}
@@ -92,6 +175,12 @@ func deployExplorer(client *sshClient, network string, bootnodes []string, confi
 	})
 	files[filepath.Join(workdir, "Dockerfile")] = dockerfile.Bytes()
 
+	plugin := new(bytes.Buffer)
+	texttemplate.Must(texttemplate.New("").Parse(explorerAddressPlugin)).Execute(plugin, map[string]interface{}{
+		"Prefix": config.prefix,
+	})
+	files[filepath.Join(workdir, "fff-address.js")] = plugin.Bytes()
+
 	transformer := "base"
 	if isClique {
 		transformer = "clique"
@@ -107,6 +196,9 @@ func deployExplorer(client *sshClient, network string, bootnodes []string, confi
 		"EthName":     config.node.ethstats[:strings.Index(config.node.ethstats, ":")],
 		"WebPort":     config.port,
 		"Transformer": transformer,
+		"ChainID":     config.node.network,
+		"Coin":        common.FFFHeader,
+		"Prefix":      config.prefix,
 	})
 	files[filepath.Join(workdir, "docker-compose.yaml")] = composefile.Bytes()
 	files[filepath.Join(workdir, "genesis.json")] = config.node.genesis
@@ -127,10 +219,11 @@ func deployExplorer(client *sshClient, network string, bootnodes []string, confi
 // explorerInfos is returned from a block explorer status check to allow reporting
 // various configuration parameters.
 type explorerInfos struct {
-	node  *nodeInfos
-	dbdir string
-	host  string
-	port  int
+	node   *nodeInfos
+	dbdir  string
+	host   string
+	port   int
+	prefix string // Network prefix the addresses are rendered with
 }
 
 // Report converts the typed struct into a plain string->string map, containing
@@ -141,6 +234,8 @@ func (info *explorerInfos) Report() map[string]string {
 		"Website listener port ":  strconv.Itoa(info.port),
 		"Ethereum listener port ": strconv.Itoa(info.node.port),
 		"Ethstats username":       info.node.ethstats,
+		"Chain ID":                strconv.FormatInt(info.node.network, 10),
+		"FFF address prefix":      info.prefix,
 	}
 	return report
 }
@@ -186,9 +281,11 @@ func checkExplorer(client *sshClient, network string) (*explorerInfos, error) {
 			port:     infos.portmap[infos.envvars["ETH_PORT"]+"/tcp"],
 			ethstats: infos.envvars["ETH_NAME"],
 		},
-		dbdir: infos.volumes["/var/lib/postgresql/data"],
-		host:  host,
-		port:  port,
+		dbdir:  infos.volumes["/var/lib/postgresql/data"],
+		host:   host,
+		port:   port,
+		prefix: infos.envvars["FFF_PREFIX"],
 	}
+	stats.node.network, _ = strconv.ParseInt(infos.envvars["CHAIN_ID"], 10, 64)
 	return stats, nil
 }
@@ -0,0 +1,215 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core/forkid"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+// forkPlan is a scheduled hard fork of a managed network: the future fork
+// blocks the operator picked and the chain config resulting from them.
+type forkPlan struct {
+	Forks      map[string]uint64   `json:"forks"`      // Newly scheduled fork blocks, keyed by config field
+	Activation uint64              `json:"activation"` // First block any of the scheduled forks activates at
+	Config     *params.ChainConfig `json:"config"`     // Chain config with the forks applied
+}
+
+// forkBlock is a single fork rule of a chain config.
+type forkBlock struct {
+	Name  string   // JSON name of the fork block within the chain config
+	Block *big.Int // Block the fork activates at, nil if not scheduled
+}
+
+// forkBlocks gathers the fork rules of a chain config the same way fork IDs
+// are calculated, via reflection on all the block number fields.
+func forkBlocks(config *params.ChainConfig) []forkBlock {
+	kind := reflect.TypeOf(params.ChainConfig{})
+	conf := reflect.ValueOf(config).Elem()
+
+	var forks []forkBlock
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if !strings.HasSuffix(field.Name, "Block") || field.Type != reflect.TypeOf(new(big.Int)) {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		forks = append(forks, forkBlock{Name: name, Block: conf.Field(i).Interface().(*big.Int)})
+	}
+	return forks
+}
+
+// setForkBlock updates the fork rule with the given JSON name in a chain config.
+func setForkBlock(config *params.ChainConfig, name string, block *big.Int) error {
+	kind := reflect.TypeOf(params.ChainConfig{})
+	conf := reflect.ValueOf(config).Elem()
+
+	for i := 0; i < kind.NumField(); i++ {
+		field := kind.Field(i)
+		if field.Type == reflect.TypeOf(new(big.Int)) && strings.Split(field.Tag.Get("json"), ",")[0] == name {
+			conf.Field(i).Set(reflect.ValueOf(block))
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown fork %q", name)
+}
+
+// newForkPlan schedules the given forks on top of the current chain config of a
+// network whose chain is at head, ensuring the result can still be applied to
+// the running nodes.
+func newForkPlan(current *params.ChainConfig, forks map[string]uint64, head uint64) (*forkPlan, error) {
+	if len(forks) == 0 {
+		return nil, errors.New("no forks scheduled")
+	}
+	config := *current
+
+	plan := &forkPlan{Forks: forks, Config: &config}
+	for name, block := range forks {
+		if block <= head {
+			return nil, fmt.Errorf("fork %s at block %d not after chain head %d", name, block, head)
+		}
+		if err := setForkBlock(plan.Config, name, new(big.Int).SetUint64(block)); err != nil {
+			return nil, err
+		}
+		if plan.Activation == 0 || block < plan.Activation {
+			plan.Activation = block
+		}
+	}
+	if err := plan.Config.CheckConfigForkOrder(); err != nil {
+		return nil, err
+	}
+	if err := current.CheckCompatible(plan.Config, head); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// names returns the scheduled forks ordered by activation block.
+func (plan *forkPlan) names() []string {
+	names := make([]string, 0, len(plan.Forks))
+	for name := range plan.Forks {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if plan.Forks[names[i]] != plan.Forks[names[j]] {
+			return plan.Forks[names[i]] < plan.Forks[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return names
+}
+
+// nodeForkInfo is the chain configuration a running node reports via RPC.
+type nodeForkInfo struct {
+	Genesis common.Hash         `json:"genesis"`
+	Config  *params.ChainConfig `json:"config"`
+	Head    uint64              `json:"-"`
+}
+
+// queryNodeFork retrieves the chain config and head of a running node container.
+func queryNodeFork(client *sshClient, container string) (*nodeForkInfo, error) {
+	// The console prints the stringified info as a quoted string literal
+	out, err := client.Run(fmt.Sprintf("docker exec %s geth --exec 'JSON.stringify(admin.nodeInfo.protocols.eth)' --cache=16 attach", container))
+	if err != nil {
+		return nil, ErrServiceUnreachable
+	}
+	var blob string
+	if err := json.Unmarshal(bytes.TrimSpace(out), &blob); err != nil {
+		return nil, fmt.Errorf("invalid node info response: %s", out)
+	}
+	info := new(nodeForkInfo)
+	if err := json.Unmarshal([]byte(blob), info); err != nil {
+		return nil, fmt.Errorf("invalid node info: %v", err)
+	}
+	if info.Config == nil {
+		return nil, errors.New("node reported no chain config")
+	}
+	if info.Head, err = queryNodeHead(client, container); err != nil {
+		return nil, err
+	}
+	return info, nil
+}
+
+// forkNodeReport is the readiness of a single node for a scheduled fork.
+type forkNodeReport struct {
+	Node     string    `json:"node"`
+	Head     uint64    `json:"head"`
+	Reported forkid.ID `json:"reported"` // Fork ID the node currently advertises
+	Expected forkid.ID `json:"expected"` // Fork ID the node should advertise
+	Ready    bool      `json:"ready"`
+	Problem  string    `json:"problem,omitempty"`
+}
+
+// forkReport is the go/no-go decision for a scheduled fork across all nodes.
+type forkReport struct {
+	Network    string           `json:"network"`
+	Activation uint64           `json:"activation"`
+	ForkID     forkid.ID        `json:"forkId"` // Fork ID after activation
+	Nodes      []forkNodeReport `json:"nodes"`
+	Go         bool             `json:"go"`
+}
+
+// checkNodeFork verifies that a node is running with the planned chain config,
+// i.e. that it already announces the upcoming fork in its fork ID and has not
+// yet passed the activation block.
+func checkNodeFork(plan *forkPlan, genesis common.Hash, node string, info *nodeForkInfo) forkNodeReport {
+	report := forkNodeReport{
+		Node:     node,
+		Head:     info.Head,
+		Reported: forkid.NewID(info.Config, info.Genesis, info.Head),
+		Expected: forkid.NewID(plan.Config, genesis, info.Head),
+	}
+	switch {
+	case info.Genesis != genesis:
+		report.Problem = fmt.Sprintf("genesis mismatch: %x", info.Genesis)
+	case report.Reported != report.Expected:
+		report.Problem = "fork ID mismatch, chain config not updated"
+	case info.Head >= plan.Activation:
+		report.Problem = "activation block already passed"
+	default:
+		report.Ready = true
+	}
+	return report
+}
+
+// newForkReport assembles the go/no-go report of a scheduled fork. The fork is
+// only a go if at least one node was checked and all of them are ready.
+func newForkReport(network string, plan *forkPlan, genesis common.Hash, nodes []forkNodeReport) *forkReport {
+	report := &forkReport{
+		Network:    network,
+		Activation: plan.Activation,
+		ForkID:     forkid.NewID(plan.Config, genesis, plan.Activation),
+		Nodes:      nodes,
+		Go:         len(nodes) > 0,
+	}
+	for _, node := range nodes {
+		if !node.Ready {
+			report.Go = false
+		}
+	}
+	return report
+}
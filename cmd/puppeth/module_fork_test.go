@@ -0,0 +1,112 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+// testForkConfig returns a chain config with all forks up to MirrorSync active
+// from genesis and the later ones unscheduled.
+func testForkConfig() *params.ChainConfig {
+	return &params.ChainConfig{
+		ChainID:             big.NewInt(1337),
+		HomesteadBlock:      big.NewInt(0),
+		EIP150Block:         big.NewInt(0),
+		EIP155Block:         big.NewInt(0),
+		EIP158Block:         big.NewInt(0),
+		ByzantiumBlock:      big.NewInt(0),
+		ConstantinopleBlock: big.NewInt(0),
+		PetersburgBlock:     big.NewInt(0),
+		IstanbulBlock:       big.NewInt(0),
+		MirrorSyncBlock:     big.NewInt(0),
+	}
+}
+
+// Tests that fork plans are applied on top of the current config and that
+// invalid schedules are rejected.
+func TestForkPlan(t *testing.T) {
+	current := testForkConfig()
+
+	plan, err := newForkPlan(current, map[string]uint64{"brunoBlock": 100, "berlinBlock": 200}, 50)
+	if err != nil {
+		t.Fatalf("failed to schedule forks: %v", err)
+	}
+	if plan.Activation != 100 {
+		t.Errorf("activation mismatch: have %d, want 100", plan.Activation)
+	}
+	if plan.Config.BrunoBlock.Uint64() != 100 || plan.Config.BerlinBlock.Uint64() != 200 {
+		t.Errorf("fork blocks not applied: bruno %v, berlin %v", plan.Config.BrunoBlock, plan.Config.BerlinBlock)
+	}
+	if current.BrunoBlock != nil || current.BerlinBlock != nil {
+		t.Errorf("current config modified")
+	}
+	if names := plan.names(); len(names) != 2 || names[0] != "brunoBlock" || names[1] != "berlinBlock" {
+		t.Errorf("fork order mismatch: %v", names)
+	}
+	// Invalid schedules
+	if _, err := newForkPlan(current, map[string]uint64{"berlinBlock": 200}, 50); err == nil {
+		t.Errorf("out of order fork accepted")
+	}
+	if _, err := newForkPlan(current, map[string]uint64{"brunoBlock": 50}, 50); err == nil {
+		t.Errorf("fork at chain head accepted")
+	}
+	if _, err := newForkPlan(current, map[string]uint64{"fooBlock": 100}, 50); err == nil {
+		t.Errorf("unknown fork accepted")
+	}
+}
+
+// Tests that nodes are only deemed ready if they announce the scheduled fork
+// ahead of its activation.
+func TestForkReport(t *testing.T) {
+	genesis := common.HexToHash("0x01")
+
+	plan, err := newForkPlan(testForkConfig(), map[string]uint64{"brunoBlock": 100}, 50)
+	if err != nil {
+		t.Fatalf("failed to schedule fork: %v", err)
+	}
+	tests := []struct {
+		info  *nodeForkInfo
+		ready bool
+	}{
+		{&nodeForkInfo{Genesis: genesis, Config: plan.Config, Head: 50}, true},
+		{&nodeForkInfo{Genesis: genesis, Config: testForkConfig(), Head: 50}, false},
+		{&nodeForkInfo{Genesis: genesis, Config: plan.Config, Head: 100}, false},
+		{&nodeForkInfo{Genesis: common.HexToHash("0x02"), Config: plan.Config, Head: 50}, false},
+	}
+	var nodes []forkNodeReport
+	for i, tt := range tests {
+		node := checkNodeFork(plan, genesis, "node", tt.info)
+		if node.Ready != tt.ready {
+			t.Errorf("test %d: readiness mismatch: have %v (%s), want %v", i, node.Ready, node.Problem, tt.ready)
+		}
+		nodes = append(nodes, node)
+	}
+	if report := newForkReport("test", plan, genesis, nodes[:1]); !report.Go {
+		t.Errorf("ready network reported as no-go")
+	}
+	if report := newForkReport("test", plan, genesis, nodes); report.Go {
+		t.Errorf("partially ready network reported as go")
+	}
+	if report := newForkReport("test", plan, genesis, nil); report.Go {
+		t.Errorf("empty network reported as go")
+	}
+}
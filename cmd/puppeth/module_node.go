@@ -19,6 +19,7 @@ package main
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand"
 	"path/filepath"
@@ -35,20 +36,27 @@ var nodeDockerfile = `
 FROM ethereum/client-go:latest
 
 ADD genesis.json /genesis.json
-{{if .Unlock}}
+{{if .Seed}}
+	ADD seed.json /seed/{{.Seed}}.json{{range .SeedFiles}}
+	ADD seed-{{.}} /seed/{{$.Seed}}/{{.}}{{end}}
+{{end}}{{if .Unlock}}
 	ADD signer.json /signer.json
 	ADD signer.pass /signer.pass
+{{end}}{{if .RPC}}
+	ADD jwt.hex /jwt.hex
 {{end}}
 RUN \
-  echo 'geth --cache 512 init /genesis.json' > geth.sh && \{{if .Unlock}}
+  echo 'geth --cache 512 init /genesis.json' > geth.sh && \{{if .Seed}}
+	echo 'geth --cache 512 import-seed /seed/{{.Seed}}.json' >> geth.sh && \{{end}}{{if .Unlock}}
 	echo 'mkdir -p /root/.ethereum/keystore/ && cp /signer.json /root/.ethereum/keystore/' >> geth.sh && \{{end}}
-	echo $'exec geth --networkid {{.NetworkID}} --cache 512 --port {{.Port}} --nat extip:{{.IP}} --maxpeers {{.Peers}} {{.LightFlag}} --ethstats \'{{.Ethstats}}\' {{if .Bootnodes}}--bootnodes {{.Bootnodes}}{{end}} {{if .Etherbase}}--miner.etherbase {{.Etherbase}} --mine --miner.threads 1{{end}} {{if .Unlock}}--unlock 0 --password /signer.pass --mine{{end}} --miner.gastarget {{.GasTarget}} --miner.gaslimit {{.GasLimit}} --miner.gasprice {{.GasPrice}}' >> geth.sh
+	echo $'exec geth --networkid {{.NetworkID}} --cache 512 --port {{.Port}} --nat extip:{{.IP}} --maxpeers {{.Peers}} {{.LightFlag}} --ethstats \'{{.Ethstats}}\' {{if .Bootnodes}}--bootnodes {{.Bootnodes}}{{end}} {{if .Etherbase}}--miner.etherbase {{.Etherbase}} --mine --miner.threads 1{{end}} {{if .Unlock}}--unlock 0 --password /signer.pass --mine{{end}} --miner.gastarget {{.GasTarget}} --miner.gaslimit {{.GasLimit}} --miner.gasprice {{.GasPrice}} {{if .RPC}}--authrpc.addr 0.0.0.0 --authrpc.port {{.AuthPort}} --authrpc.vhosts \'*\' --authrpc.jwtsecret /jwt.hex{{end}}' >> geth.sh
 
 ENTRYPOINT ["/bin/sh", "geth.sh"]
 `
 
 // nodeComposefile is the docker-compose.yml file required to deploy and maintain
-// an Ethereum node (bootnode or miner for now).
+// an Ethereum node (bootnode or miner for now), along with the TLS proxy of its
+// RPC endpoint if exposed.
 var nodeComposefile = `
 version: '2'
 services:
@@ -70,13 +78,35 @@ services:
       - MINER_NAME={{.Etherbase}}
       - GAS_TARGET={{.GasTarget}}
       - GAS_LIMIT={{.GasLimit}}
-      - GAS_PRICE={{.GasPrice}}
+      - GAS_PRICE={{.GasPrice}}{{if .RPCPort}}
+      - RPC_PORT={{.RPCPort}}
+      - RPC_DOMAIN={{.RPCDomain}}
+      - RPC_EMAIL={{.RPCEmail}}{{end}}
     logging:
       driver: "json-file"
       options:
         max-size: "1m"
         max-file: "10"
-    restart: always
+    restart: always{{if .RPCPort}}
+  {{.Type}}_rpc:
+    build:
+      context: .
+      dockerfile: Dockerfile.rpc
+    image: {{.Network}}/{{.Type}}_rpc
+    container_name: {{.Network}}_{{.Type}}_rpc_1
+    ports:
+      - "{{.RPCPort}}:{{.RPCPort}}"{{if .RPCDomain}}
+      - "80:80"
+    volumes:
+      - {{.Datadir}}/caddy:/data{{end}}
+    depends_on:
+      - {{.Type}}
+    logging:
+      driver: "json-file"
+      options:
+        max-size: "1m"
+        max-file: "10"
+    restart: always{{end}}
 `
 
 // deployNode deploys a new Ethereum node container to a remote machine via SSH,
@@ -96,6 +126,13 @@ func deployNode(client *sshClient, network string, bootnodes []string, config *n
 	if config.peersLight > 0 {
 		lightFlag = fmt.Sprintf("--light.maxpeers=%d --light.serve=50", config.peersLight)
 	}
+	var (
+		seedName  string
+		seedFiles []string
+	)
+	if config.seed != nil {
+		seedName, seedFiles = config.seed.name, config.seed.fileNames()
+	}
 	dockerfile := new(bytes.Buffer)
 	template.Must(template.New("").Parse(nodeDockerfile)).Execute(dockerfile, map[string]interface{}{
 		"NetworkID": config.network,
@@ -110,6 +147,10 @@ func deployNode(client *sshClient, network string, bootnodes []string, config *n
 		"GasLimit":  uint64(1000000 * config.gasLimit),
 		"GasPrice":  uint64(1000000000 * config.gasPrice),
 		"Unlock":    config.keyJSON != "",
+		"RPC":       config.rpcPort > 0,
+		"AuthPort":  rpcAuthPort,
+		"Seed":      seedName,
+		"SeedFiles": seedFiles,
 	})
 	files[filepath.Join(workdir, "Dockerfile")] = dockerfile.Bytes()
 
@@ -128,14 +169,54 @@ func deployNode(client *sshClient, network string, bootnodes []string, config *n
 		"GasTarget":  config.gasTarget,
 		"GasLimit":   config.gasLimit,
 		"GasPrice":   config.gasPrice,
+		"RPCPort":    config.rpcPort,
+		"RPCDomain":  config.rpcDomain,
+		"RPCEmail":   config.rpcEmail,
 	})
 	files[filepath.Join(workdir, "docker-compose.yaml")] = composefile.Bytes()
 
 	files[filepath.Join(workdir, "genesis.json")] = config.genesis
+	if config.seed != nil {
+		files[filepath.Join(workdir, "seed.json")] = config.seed.manifest
+		for name, data := range config.seed.files {
+			files[filepath.Join(workdir, "seed-"+name)] = data
+		}
+	}
 	if config.keyJSON != "" {
 		files[filepath.Join(workdir, "signer.json")] = []byte(config.keyJSON)
 		files[filepath.Join(workdir, "signer.pass")] = []byte(config.keyPass)
 	}
+	if config.rpcPort > 0 {
+		// Never expose the RPC endpoint without TLS and JWT authentication
+		if config.jwtSecret == "" {
+			return nil, errors.New("missing RPC JWT secret")
+		}
+		if config.rpcDomain == "" && (len(config.tlsCert) == 0 || len(config.tlsKey) == 0) {
+			return nil, errors.New("missing RPC TLS certificate")
+		}
+		files[filepath.Join(workdir, "jwt.hex")] = []byte(config.jwtSecret)
+
+		proxyfile := new(bytes.Buffer)
+		template.Must(template.New("").Parse(rpcProxyDockerfile)).Execute(proxyfile, map[string]interface{}{
+			"Domain": config.rpcDomain,
+		})
+		files[filepath.Join(workdir, "Dockerfile.rpc")] = proxyfile.Bytes()
+
+		caddyfile := new(bytes.Buffer)
+		template.Must(template.New("").Parse(rpcProxyCaddyfile)).Execute(caddyfile, map[string]interface{}{
+			"Domain":   config.rpcDomain,
+			"Email":    config.rpcEmail,
+			"Port":     config.rpcPort,
+			"Upstream": kind,
+			"AuthPort": rpcAuthPort,
+		})
+		files[filepath.Join(workdir, "Caddyfile")] = caddyfile.Bytes()
+
+		if config.rpcDomain == "" {
+			files[filepath.Join(workdir, "node.crt")] = config.tlsCert
+			files[filepath.Join(workdir, "node.key")] = config.tlsKey
+		}
+	}
 	// Upload the deployment files to the remote server (and clean up afterwards)
 	if out, err := client.Upload(files); err != nil {
 		return out, err
@@ -167,6 +248,13 @@ type nodeInfos struct {
 	gasTarget  float64
 	gasLimit   float64
 	gasPrice   float64
+	rpcPort    int       // Port of the TLS proxy in front of the RPC endpoint, 0 if not exposed
+	rpcDomain  string    // Domain to obtain the TLS certificate for via ACME, empty to use the network CA
+	rpcEmail   string    // Contact email for the ACME account
+	jwtSecret  string    // Hex encoded JWT secret shared with the RPC clients
+	tlsCert    []byte    // PEM encoded TLS certificate issued by the network CA
+	tlsKey     []byte    // PEM encoded private key of the TLS certificate
+	seed       *nodeSeed // State-sync seed to import on first boot, nil to sync from the genesis
 }
 
 // Report converts the typed struct into a plain string->string map, containing
@@ -179,6 +267,14 @@ func (info *nodeInfos) Report() map[string]string {
 		"Peer count (light nodes)": strconv.Itoa(info.peersLight),
 		"Ethstats username":        info.ethstats,
 	}
+	if info.rpcPort > 0 {
+		report["RPC port (TLS + JWT)"] = strconv.Itoa(info.rpcPort)
+		if info.rpcDomain != "" {
+			report["RPC certificate"] = fmt.Sprintf("ACME (%s)", info.rpcDomain)
+		} else {
+			report["RPC certificate"] = "Network CA"
+		}
+	}
 	if info.gasTarget > 0 {
 		// Miner or signer node
 		report["Gas price (minimum accepted)"] = fmt.Sprintf("%0.3f GWei", info.gasPrice)
@@ -226,6 +322,7 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 	gasTarget, _ := strconv.ParseFloat(infos.envvars["GAS_TARGET"], 64)
 	gasLimit, _ := strconv.ParseFloat(infos.envvars["GAS_LIMIT"], 64)
 	gasPrice, _ := strconv.ParseFloat(infos.envvars["GAS_PRICE"], 64)
+	rpcPort, _ := strconv.Atoi(infos.envvars["RPC_PORT"])
 
 	// Container available, retrieve its node ID and its genesis json
 	var out []byte
@@ -246,6 +343,20 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 	if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 cat /signer.pass", network, kind)); err == nil {
 		keyPass = string(bytes.TrimSpace(out))
 	}
+	jwtSecret := ""
+	if out, err = client.Run(fmt.Sprintf("docker exec %s_%s_1 cat /jwt.hex", network, kind)); err == nil {
+		jwtSecret = string(bytes.TrimSpace(out))
+	}
+	// Certificates issued by the network CA live in the RPC proxy, keep them for redeploys
+	var tlsCert, tlsKey []byte
+	if rpcPort > 0 && infos.envvars["RPC_DOMAIN"] == "" {
+		if out, err = client.Download(fmt.Sprintf("docker exec %s_%s_rpc_1 cat /tls/node.crt", network, kind)); err == nil {
+			tlsCert = out
+		}
+		if out, err = client.Download(fmt.Sprintf("docker exec %s_%s_rpc_1 cat /tls/node.key", network, kind)); err == nil {
+			tlsKey = out
+		}
+	}
 	// Run a sanity check to see if the devp2p is reachable
 	port := infos.portmap[infos.envvars["PORT"]]
 	if err = checkPort(client.server, port); err != nil {
@@ -266,6 +377,12 @@ func checkNode(client *sshClient, network string, boot bool) (*nodeInfos, error)
 		gasTarget:  gasTarget,
 		gasLimit:   gasLimit,
 		gasPrice:   gasPrice,
+		rpcPort:    rpcPort,
+		rpcDomain:  infos.envvars["RPC_DOMAIN"],
+		rpcEmail:   infos.envvars["RPC_EMAIL"],
+		jwtSecret:  jwtSecret,
+		tlsCert:    tlsCert,
+		tlsKey:     tlsKey,
 	}
 	stats.enode = string(enode)
 
@@ -0,0 +1,487 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+)
+
+// replayStallTimeout is the time a replaying client may not make any progress
+// while the other one is ahead of it, before it's deemed to have rejected a block.
+const replayStallTimeout = 2 * time.Minute
+
+// replayTraceConfig disables the bulky parts of the struct logs, the results of
+// the transactions are enough to find where clients diverge.
+var replayTraceConfig = map[string]interface{}{
+	"disableStorage": true,
+	"disableMemory":  true,
+	"disableStack":   true,
+}
+
+// replayProject returns the docker-compose project name, also the name of the
+// network the replaying containers run in.
+func replayProject(network string) string {
+	return network + "_replay"
+}
+
+// newReplayComposefile renders the compose file booting the two replaying
+// clients of the network, importing the live chain from the bootnodes.
+func newReplayComposefile(network string, networkID uint64, bootnodes []string, clients [2]*specClient) []byte {
+	return newSpecComposefile(replayProject(network), clients[:], networkID, bootnodes, true)
+}
+
+// replayLog is the consensus content of a log emitted by a transaction.
+type replayLog struct {
+	Address string        `json:"address"`
+	Topics  []common.Hash `json:"topics"`
+	Data    hexutil.Bytes `json:"data"`
+}
+
+// replayReceipt is the outcome of a transaction as reported by a client.
+type replayReceipt struct {
+	TxHash            common.Hash    `json:"transactionHash"`
+	Status            hexutil.Uint64 `json:"status"`
+	GasUsed           hexutil.Uint64 `json:"gasUsed"`
+	CumulativeGasUsed hexutil.Uint64 `json:"cumulativeGasUsed"`
+	ContractAddress   string         `json:"contractAddress"`
+	Logs              []*replayLog   `json:"logs"`
+}
+
+// replayBlock is a block as imported by a client, along with the receipts of
+// its transactions.
+type replayBlock struct {
+	Number       hexutil.Uint64   `json:"number"`
+	Hash         common.Hash      `json:"hash"`
+	Root         common.Hash      `json:"stateRoot"`
+	ReceiptHash  common.Hash      `json:"receiptsRoot"`
+	GasUsed      hexutil.Uint64   `json:"gasUsed"`
+	Transactions []common.Hash    `json:"transactions"`
+	Receipts     []*replayReceipt `json:"-"`
+}
+
+// replayTrace is the execution result of a transaction traced by a client.
+type replayTrace struct {
+	Gas         uint64 `json:"gas"`
+	Failed      bool   `json:"failed"`
+	ReturnValue string `json:"returnValue"`
+	Error       string `json:"-"`
+}
+
+// replayDivergence is the first point at which the replaying clients disagree.
+type replayDivergence struct {
+	Number  uint64      `json:"number"`
+	Tx      int         `json:"tx"`               // Index of the divergent transaction, -1 if unknown
+	TxHash  common.Hash `json:"txHash,omitempty"` // Hash of the divergent transaction, if known
+	Problem string      `json:"problem"`
+}
+
+// replayReport is the outcome of replaying a block range against two clients.
+type replayReport struct {
+	Network    string            `json:"network"`
+	Clients    [2]string         `json:"clients"`
+	First      uint64            `json:"first"`
+	Last       uint64            `json:"last"`
+	Heads      [2]uint64         `json:"heads"`    // Head blocks the clients reached
+	Compared   uint64            `json:"compared"` // Number of blocks the clients agree on
+	Divergence *replayDivergence `json:"divergence,omitempty"`
+	Passed     bool              `json:"passed"`
+}
+
+// diffReplayBlocks compares a block imported by both clients, returning the
+// divergence if they disagree. A nil block means the client didn't import it.
+func diffReplayBlocks(number uint64, clients [2]string, blocks [2]*replayBlock) *replayDivergence {
+	for i, block := range blocks {
+		if block == nil {
+			return &replayDivergence{Number: number, Tx: -1, Problem: fmt.Sprintf("block missing on %s", clients[i])}
+		}
+	}
+	a, b := blocks[0], blocks[1]
+
+	div := &replayDivergence{Number: number, Tx: -1}
+	switch {
+	case a.Root != b.Root:
+		div.Problem = fmt.Sprintf("state root mismatch: %s %x, %s %x", clients[0], a.Root[:8], clients[1], b.Root[:8])
+	case a.ReceiptHash != b.ReceiptHash:
+		div.Problem = fmt.Sprintf("receipts root mismatch: %s %x, %s %x", clients[0], a.ReceiptHash[:8], clients[1], b.ReceiptHash[:8])
+	case a.Hash != b.Hash:
+		div.Problem = fmt.Sprintf("block hash mismatch: %s %x, %s %x", clients[0], a.Hash[:8], clients[1], b.Hash[:8])
+	}
+	if index, problem := diffReplayReceipts(a.Receipts, b.Receipts); index >= 0 {
+		if div.Problem != "" {
+			div.Problem += ", "
+		}
+		div.Tx, div.Problem = index, fmt.Sprintf("%stransaction %d %s", div.Problem, index, problem)
+		if index < len(a.Transactions) {
+			div.TxHash = a.Transactions[index]
+		}
+	}
+	if div.Problem == "" {
+		return nil
+	}
+	return div
+}
+
+// diffReplayReceipts returns the index of the first transaction the receipts
+// disagree on and how, or -1 if they match.
+func diffReplayReceipts(a, b []*replayReceipt) (int, string) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch ra, rb := a[i], b[i]; {
+		case ra.TxHash != rb.TxHash:
+			return i, fmt.Sprintf("transaction mismatch: %x != %x", ra.TxHash[:8], rb.TxHash[:8])
+		case ra.Status != rb.Status:
+			return i, fmt.Sprintf("status mismatch: %d != %d", ra.Status, rb.Status)
+		case ra.GasUsed != rb.GasUsed:
+			return i, fmt.Sprintf("gas used mismatch: %d != %d", ra.GasUsed, rb.GasUsed)
+		case ra.CumulativeGasUsed != rb.CumulativeGasUsed:
+			return i, fmt.Sprintf("cumulative gas used mismatch: %d != %d", ra.CumulativeGasUsed, rb.CumulativeGasUsed)
+		case !strings.EqualFold(ra.ContractAddress, rb.ContractAddress):
+			return i, fmt.Sprintf("contract address mismatch: %s != %s", ra.ContractAddress, rb.ContractAddress)
+		case len(ra.Logs) != len(rb.Logs):
+			return i, fmt.Sprintf("log count mismatch: %d != %d", len(ra.Logs), len(rb.Logs))
+		default:
+			for j := range ra.Logs {
+				if problem := diffReplayLogs(ra.Logs[j], rb.Logs[j]); problem != "" {
+					return i, fmt.Sprintf("log %d %s", j, problem)
+				}
+			}
+		}
+	}
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return len(a), fmt.Sprintf("receipt count mismatch: %d != %d", len(a), len(b))
+		}
+		return len(b), fmt.Sprintf("receipt count mismatch: %d != %d", len(a), len(b))
+	}
+	return -1, ""
+}
+
+// diffReplayLogs returns how two logs disagree, or an empty string if they match.
+func diffReplayLogs(a, b *replayLog) string {
+	switch {
+	case !strings.EqualFold(a.Address, b.Address):
+		return fmt.Sprintf("address mismatch: %s != %s", a.Address, b.Address)
+	case len(a.Topics) != len(b.Topics):
+		return fmt.Sprintf("topic count mismatch: %d != %d", len(a.Topics), len(b.Topics))
+	case string(a.Data) != string(b.Data):
+		return fmt.Sprintf("data mismatch: %x != %x", a.Data, b.Data)
+	}
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return fmt.Sprintf("topic %d mismatch: %x != %x", i, a.Topics[i][:8], b.Topics[i][:8])
+		}
+	}
+	return ""
+}
+
+// diffReplayTraces returns the index of the first transaction the traces of a
+// block disagree on and how, or -1 if they match.
+func diffReplayTraces(a, b []*replayTrace) (int, string) {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		switch ta, tb := a[i], b[i]; {
+		case ta.Error != "" || tb.Error != "":
+			if ta.Error != tb.Error {
+				return i, fmt.Sprintf("trace error mismatch: %q != %q", ta.Error, tb.Error)
+			}
+		case ta.Failed != tb.Failed:
+			return i, fmt.Sprintf("failure mismatch: %v != %v", ta.Failed, tb.Failed)
+		case ta.Gas != tb.Gas:
+			return i, fmt.Sprintf("gas mismatch: %d != %d", ta.Gas, tb.Gas)
+		case normalizeReturnValue(ta.ReturnValue) != normalizeReturnValue(tb.ReturnValue):
+			return i, fmt.Sprintf("return value mismatch: %s != %s", ta.ReturnValue, tb.ReturnValue)
+		}
+	}
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return len(a), fmt.Sprintf("trace count mismatch: %d != %d", len(a), len(b))
+		}
+		return len(b), fmt.Sprintf("trace count mismatch: %d != %d", len(a), len(b))
+	}
+	return -1, ""
+}
+
+// normalizeReturnValue drops the formatting differences of the return values
+// reported by the various clients.
+func normalizeReturnValue(value string) string {
+	return strings.TrimPrefix(strings.ToLower(value), "0x")
+}
+
+// parseReplayTraces parses the block traces of a client. Geth wraps the result
+// of each transaction into a result and error pair, others return them as is.
+func parseReplayTraces(blob json.RawMessage) ([]*replayTrace, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(blob, &items); err != nil {
+		return nil, err
+	}
+	traces := make([]*replayTrace, len(items))
+	for i, item := range items {
+		var wrapped struct {
+			Result json.RawMessage `json:"result"`
+			Error  string          `json:"error"`
+		}
+		if err := json.Unmarshal(item, &wrapped); err != nil {
+			return nil, err
+		}
+		traces[i] = new(replayTrace)
+		switch {
+		case wrapped.Error != "":
+			traces[i].Error = wrapped.Error
+		case wrapped.Result != nil:
+			item = wrapped.Result
+			fallthrough
+		default:
+			if err := json.Unmarshal(item, traces[i]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return traces, nil
+}
+
+// replaySpecs boots two clients on their exported specs on the remote server,
+// lets them import the block range from the live network and compares the
+// state roots and receipts of every block. The first divergent transaction is
+// pinpointed by tracing the divergent block on both clients. The containers are
+// torn down afterwards.
+func replaySpecs(client *sshClient, network string, networkID uint64, specs map[string][]byte, bootnodes []string, clients [2]*specClient, first, last uint64, timeout time.Duration) (*replayReport, error) {
+	workdir := fmt.Sprintf("%d", rand.Int63())
+	files := map[string][]byte{
+		filepath.Join(workdir, "docker-compose.yaml"): newReplayComposefile(network, networkID, bootnodes, clients),
+	}
+	for name, spec := range specs {
+		files[filepath.Join(workdir, name)] = spec
+	}
+	if out, err := client.Upload(files); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, out)
+	}
+	defer client.Run("rm -rf " + workdir)
+
+	project := replayProject(network)
+	if err := client.Stream(fmt.Sprintf("cd %s && docker-compose -p %s up -d --force-recreate", workdir, project)); err != nil {
+		return nil, err
+	}
+	defer client.Run(fmt.Sprintf("cd %s && docker-compose -p %s down -v", workdir, project))
+
+	report := &replayReport{Network: network, Clients: [2]string{clients[0].Name, clients[1].Name}, First: first, Last: last}
+
+	// Wait for both clients to import the range, or one of them to get stuck
+	var (
+		online   [2]bool
+		progress [2]time.Time
+	)
+	for deadline := time.Now().Add(timeout); time.Now().Before(deadline); time.Sleep(5 * time.Second) {
+		for i, spec := range clients {
+			head, err := queryContainerRPC(client, project, spec, "eth_blockNumber")
+			if err != nil {
+				log.Debug("Replaying client not ready", "client", spec.Name, "err", err)
+				continue
+			}
+			var num hexutil.Uint64
+			if err := json.Unmarshal(head, &num); err != nil {
+				continue
+			}
+			if !online[i] || uint64(num) > report.Heads[i] {
+				online[i], progress[i] = true, time.Now()
+			}
+			report.Heads[i] = uint64(num)
+		}
+		if report.Heads[0] >= last && report.Heads[1] >= last {
+			break
+		}
+		if replayStalled(report.Heads, online, progress, 0) || replayStalled(report.Heads, online, progress, 1) {
+			break
+		}
+		log.Info("Replaying block range", "first", first, "last", last, clients[0].Name, report.Heads[0], clients[1].Name, report.Heads[1])
+	}
+	for i, spec := range clients {
+		if !online[i] {
+			return nil, fmt.Errorf("%s failed to start", spec.Name)
+		}
+	}
+	// Compare the imported blocks until the first divergence
+	for number := first; number <= last; number++ {
+		if number > report.Heads[0] && number > report.Heads[1] {
+			break
+		}
+		var blocks [2]*replayBlock
+		for i, spec := range clients {
+			if number > report.Heads[i] {
+				continue
+			}
+			block, err := queryReplayBlock(client, project, spec, number)
+			if err != nil {
+				return nil, fmt.Errorf("failed to retrieve block %d from %s: %v", number, spec.Name, err)
+			}
+			blocks[i] = block
+		}
+		if report.Divergence = diffReplayBlocks(number, report.Clients, blocks); report.Divergence != nil {
+			if report.Divergence.Tx < 0 {
+				pinpointReplay(client, project, clients, blocks, report.Divergence)
+			}
+			break
+		}
+		report.Compared++
+	}
+	report.Passed = report.Divergence == nil && report.Compared == last-first+1
+	return report, nil
+}
+
+// replayStalled returns whether a replaying client didn't make progress for a while,
+// while the other one is already ahead of it.
+func replayStalled(heads [2]uint64, online [2]bool, progress [2]time.Time, i int) bool {
+	return online[i] && heads[1-i] > heads[i] && time.Since(progress[i]) > replayStallTimeout
+}
+
+// pinpointReplay traces the divergent block on both clients to find the first
+// divergent transaction. A client that rejected the block traces the block as
+// imported by the other one.
+func pinpointReplay(client *sshClient, project string, clients [2]*specClient, blocks [2]*replayBlock, div *replayDivergence) {
+	var (
+		traces [2][]*replayTrace
+		txs    []common.Hash
+	)
+	for i, spec := range clients {
+		var (
+			blob json.RawMessage
+			err  error
+		)
+		if blocks[i] != nil {
+			txs = blocks[i].Transactions
+			blob, err = queryContainerRPC(client, project, spec, "debug_traceBlockByNumber", hexutil.EncodeUint64(div.Number), replayTraceConfig)
+		} else {
+			var raw string
+			if raw, err = queryReplayRawBlock(client, project, clients[1-i], div.Number); err == nil {
+				blob, err = queryContainerRPC(client, project, spec, "debug_traceBlock", raw, replayTraceConfig)
+			}
+		}
+		if err == nil {
+			traces[i], err = parseReplayTraces(blob)
+		}
+		if err != nil {
+			log.Warn("Failed to trace divergent block", "client", spec.Name, "number", div.Number, "err", err)
+			return
+		}
+	}
+	if index, problem := diffReplayTraces(traces[0], traces[1]); index >= 0 {
+		div.Tx, div.Problem = index, fmt.Sprintf("%s, transaction %d %s", div.Problem, index, problem)
+		if index < len(txs) {
+			div.TxHash = txs[index]
+		}
+	}
+}
+
+// queryReplayBlock retrieves a block and the receipts of its transactions from
+// a replaying client, or nil if the client doesn't have the block.
+func queryReplayBlock(client *sshClient, project string, spec *specClient, number uint64) (*replayBlock, error) {
+	blob, err := queryContainerRPC(client, project, spec, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false)
+	if err != nil {
+		return nil, err
+	}
+	var block *replayBlock
+	if err := json.Unmarshal(blob, &block); err != nil || block == nil {
+		return nil, err
+	}
+	if len(block.Transactions) == 0 {
+		return block, nil
+	}
+	params := make([][]interface{}, len(block.Transactions))
+	for i, hash := range block.Transactions {
+		params[i] = []interface{}{hash}
+	}
+	results, err := queryContainerBatch(client, project, spec, "eth_getTransactionReceipt", params)
+	if err != nil {
+		return nil, err
+	}
+	block.Receipts = make([]*replayReceipt, len(results))
+	for i, result := range results {
+		if err := json.Unmarshal(result, &block.Receipts[i]); err != nil {
+			return nil, err
+		}
+		if block.Receipts[i] == nil {
+			return nil, fmt.Errorf("missing receipt of transaction %x", block.Transactions[i])
+		}
+	}
+	return block, nil
+}
+
+// queryReplayRawBlock retrieves the RLP encoding of a block from a replaying
+// client, falling back to the legacy Geth endpoint if the standard one is not
+// available.
+func queryReplayRawBlock(client *sshClient, project string, spec *specClient, number uint64) (string, error) {
+	blob, err := queryContainerRPC(client, project, spec, "debug_getRawBlock", hexutil.EncodeUint64(number))
+	if err != nil {
+		if blob, err = queryContainerRPC(client, project, spec, "debug_getBlockRlp", number); err != nil {
+			return "", err
+		}
+	}
+	var raw string
+	if err := json.Unmarshal(blob, &raw); err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(raw, "0x") {
+		raw = "0x" + raw
+	}
+	return raw, nil
+}
+
+// queryContainerBatch executes a batch of calls to the same RPC method against
+// a client, issued from a single throwaway container.
+func queryContainerBatch(client *sshClient, project string, spec *specClient, method string, params [][]interface{}) ([]json.RawMessage, error) {
+	requests := make([]map[string]interface{}, len(params))
+	for i, param := range params {
+		requests[i] = map[string]interface{}{"jsonrpc": "2.0", "id": i, "method": method, "params": param}
+	}
+	request, _ := json.Marshal(requests)
+
+	out, err := client.Download(fmt.Sprintf("docker run --rm --network %s curlimages/curl:latest -s -X POST -H 'Content-Type: application/json' --data '%s' http://%s:%s", project, request, spec.Name, strconv.Itoa(spec.RPCPort)))
+	if err != nil {
+		return nil, err
+	}
+	var responses []struct {
+		ID     int
+		Result json.RawMessage
+		Error  *struct{ Message string }
+	}
+	if err := json.Unmarshal(out, &responses); err != nil {
+		return nil, fmt.Errorf("invalid RPC response: %v", err)
+	}
+	results := make([]json.RawMessage, len(params))
+	for _, response := range responses {
+		if response.ID < 0 || response.ID >= len(results) {
+			return nil, fmt.Errorf("invalid RPC response id %d", response.ID)
+		}
+		if response.Error != nil {
+			return nil, fmt.Errorf("%s failed: %s", method, response.Error.Message)
+		}
+		results[response.ID] = response.Result
+	}
+	for i, result := range results {
+		if result == nil {
+			return nil, fmt.Errorf("missing RPC response %d", i)
+		}
+	}
+	return results, nil
+}
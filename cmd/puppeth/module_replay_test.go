@@ -0,0 +1,128 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// Tests that only the two replaying clients are booted, serving the tracing APIs
+// on top of archive state.
+func TestReplayComposefile(t *testing.T) {
+	compose := string(newReplayComposefile("stureby", 314158, []string{"enode://aa@1.2.3.4:30303"}, [2]*specClient{specClients[0], specClients[1]}))
+	for _, want := range []string{
+		"container_name: stureby_replay_geth_1",
+		"container_name: stureby_replay_besu_1",
+		"--http.api eth,net,web3,debug --gcmode archive",
+		"--rpc-http-api=ETH,NET,WEB3,DEBUG",
+		"--bootnodes enode://aa@1.2.3.4:30303",
+		"name: stureby_replay",
+	} {
+		if !strings.Contains(compose, want) {
+			t.Errorf("compose file missing %q:\n%s", want, compose)
+		}
+	}
+	if strings.Contains(compose, "nethermind") {
+		t.Errorf("unselected client booted:\n%s", compose)
+	}
+	if compose := string(newVerifyComposefile("stureby", 314158, nil)); strings.Contains(compose, "debug") {
+		t.Errorf("verified clients serve tracing APIs:\n%s", compose)
+	}
+}
+
+// Tests that the first divergence between two imported blocks is found.
+func TestDiffReplayBlocks(t *testing.T) {
+	clients := [2]string{"geth", "besu"}
+	block := func(root byte, receipts ...*replayReceipt) *replayBlock {
+		b := &replayBlock{Hash: common.Hash{root}, Root: common.Hash{root}, ReceiptHash: common.Hash{0xee}, Receipts: receipts}
+		for _, receipt := range receipts {
+			b.Transactions = append(b.Transactions, receipt.TxHash)
+		}
+		return b
+	}
+	ok := &replayReceipt{TxHash: common.Hash{0x01}, Status: 1, GasUsed: 21000, CumulativeGasUsed: 21000}
+	transfer := &replayReceipt{TxHash: common.Hash{0x02}, Status: 1, GasUsed: 30000, CumulativeGasUsed: 51000, Logs: []*replayLog{{Address: "0xAA", Topics: []common.Hash{{0x01}}}}}
+
+	tests := []struct {
+		blocks  [2]*replayBlock
+		tx      int
+		problem string
+	}{
+		{[2]*replayBlock{block(1, ok, transfer), block(1, ok, transfer)}, 0, ""},
+		{[2]*replayBlock{block(1, ok), nil}, -1, "block missing on besu"},
+		{[2]*replayBlock{block(1, ok), block(2, ok)}, -1, "state root mismatch"},
+		{[2]*replayBlock{block(1, ok, transfer), block(2, ok, &replayReceipt{TxHash: common.Hash{0x02}, Status: 0, GasUsed: 30000, CumulativeGasUsed: 51000})}, 1, "state root mismatch"},
+		{[2]*replayBlock{block(1, ok, transfer), block(1, ok, &replayReceipt{TxHash: common.Hash{0x02}, Status: 1, GasUsed: 30000, CumulativeGasUsed: 51000, Logs: []*replayLog{{Address: "0xaa", Topics: []common.Hash{{0x02}}}}})}, 1, "transaction 1 log 0 topic 0 mismatch"},
+		{[2]*replayBlock{block(1, ok, transfer), block(1, ok)}, 1, "transaction 1 receipt count mismatch"},
+	}
+	for i, tt := range tests {
+		div := diffReplayBlocks(16, clients, tt.blocks)
+		if tt.problem == "" {
+			if div != nil {
+				t.Errorf("test %d: unexpected divergence: %+v", i, div)
+			}
+			continue
+		}
+		if div == nil {
+			t.Errorf("test %d: divergence not detected", i)
+			continue
+		}
+		if div.Number != 16 || div.Tx != tt.tx || !strings.HasPrefix(div.Problem, tt.problem) {
+			t.Errorf("test %d: divergence mismatch: have %+v, want tx %d, problem %q", i, div, tt.tx, tt.problem)
+		}
+		if div.Tx >= 0 && div.TxHash != tt.blocks[0].Transactions[div.Tx] {
+			t.Errorf("test %d: divergent transaction mismatch: have %x", i, div.TxHash)
+		}
+	}
+}
+
+// Tests that block traces are parsed from both the wrapped and flat formats and
+// the first divergent transaction is found.
+func TestDiffReplayTraces(t *testing.T) {
+	geth, err := parseReplayTraces([]byte(`[
+		{"result": {"gas": 21000, "failed": false, "returnValue": "", "structLogs": []}},
+		{"result": {"gas": 45000, "failed": false, "returnValue": "00ff", "structLogs": [{"op": "STOP"}]}},
+		{"error": "execution timeout"}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to parse wrapped traces: %v", err)
+	}
+	besu, err := parseReplayTraces([]byte(`[
+		{"gas": 21000, "failed": false, "returnValue": "0x", "structLogs": []},
+		{"gas": 45000, "failed": false, "returnValue": "0x00FF", "structLogs": []},
+		{"gas": 50000, "failed": true, "returnValue": ""}
+	]`))
+	if err != nil {
+		t.Fatalf("failed to parse flat traces: %v", err)
+	}
+	if index, problem := diffReplayTraces(geth[:2], besu[:2]); index != -1 {
+		t.Errorf("matching traces diverged at %d: %s", index, problem)
+	}
+	if index, problem := diffReplayTraces(geth, besu); index != 2 || !strings.HasPrefix(problem, "trace error mismatch") {
+		t.Errorf("divergence mismatch: have %d %q, want 2", index, problem)
+	}
+	besu[1].Gas = 46000
+	if index, problem := diffReplayTraces(geth, besu); index != 1 || !strings.HasPrefix(problem, "gas mismatch") {
+		t.Errorf("divergence mismatch: have %d %q, want 1", index, problem)
+	}
+	if index, _ := diffReplayTraces(geth[:1], besu); index != 1 {
+		t.Errorf("missing trace divergence mismatch: have %d, want 1", index)
+	}
+}
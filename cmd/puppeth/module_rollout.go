@@ -0,0 +1,222 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/log"
+)
+
+// rolloutStep is a single node taking part in a staged rollout.
+type rolloutStep interface {
+	name() string
+
+	// apply deploys the changed configuration to the node.
+	apply() error
+
+	// revert restores the configuration the node ran before the change.
+	revert() error
+
+	// check verifies that the node is healthy. Soaked is set once the soak
+	// period is over, when the node is expected to have made progress.
+	check(soaked bool) error
+}
+
+// rolloutPlan defines how a change is staged across the nodes of a network.
+type rolloutPlan struct {
+	Canaries int           // Number of nodes, first in order, to change before the others
+	Soak     time.Duration // Time the canaries must stay healthy for
+	Interval time.Duration // Time between two health checks
+}
+
+// rolloutReport is the outcome of a staged rollout.
+type rolloutReport struct {
+	Canaries   []string `json:"canaries"`             // Nodes the change was tried on first
+	Updated    []string `json:"updated,omitempty"`    // Nodes running the change
+	RolledBack []string `json:"rolledBack,omitempty"` // Nodes restored to their previous configuration
+	Stranded   []string `json:"stranded,omitempty"`   // Nodes failing to be restored, needing manual care
+	Failure    string   `json:"failure,omitempty"`    // Reason of aborting the rollout
+	Success    bool     `json:"success"`              // Whether the change is live on all nodes
+}
+
+// runRollout applies a change to the canary nodes first, verifies their health
+// for the soak period, and then rolls it out to the remaining nodes one by one,
+// checking each after its deploy. On any failure all changed nodes are rolled
+// back to their previous configuration, in reverse order.
+func runRollout(plan *rolloutPlan, steps []rolloutStep, sleep func(time.Duration)) *rolloutReport {
+	canaries := plan.Canaries
+	if canaries < 1 {
+		canaries = 1
+	}
+	if canaries > len(steps) {
+		canaries = len(steps)
+	}
+	interval := plan.Interval
+	if interval <= 0 || interval > plan.Soak {
+		interval = plan.Soak
+	}
+	var (
+		report  = new(rolloutReport)
+		applied []rolloutStep
+	)
+	abort := func(step rolloutStep, err error) *rolloutReport {
+		report.Failure = fmt.Sprintf("%s: %v", step.name(), err)
+		log.Error("Staged rollout failed, rolling back", "node", step.name(), "err", err)
+
+		for i := len(applied) - 1; i >= 0; i-- {
+			log.Info("Rolling back node", "node", applied[i].name())
+			if err := applied[i].revert(); err != nil {
+				log.Error("Failed to roll back node", "node", applied[i].name(), "err", err)
+				report.Stranded = append(report.Stranded, applied[i].name())
+				continue
+			}
+			report.RolledBack = append(report.RolledBack, applied[i].name())
+		}
+		return report
+	}
+	deploy := func(step rolloutStep) error {
+		// Failed deploys may leave the node half changed, so revert those too
+		applied = append(applied, step)
+		return step.apply()
+	}
+	// Change the canaries and let them soak
+	for _, step := range steps[:canaries] {
+		log.Info("Deploying change to canary", "node", step.name())
+		report.Canaries = append(report.Canaries, step.name())
+
+		if err := deploy(step); err != nil {
+			return abort(step, err)
+		}
+	}
+	for waited := time.Duration(0); ; {
+		if wait := plan.Soak - waited; wait > 0 {
+			if wait > interval {
+				wait = interval
+			}
+			sleep(wait)
+			waited += wait
+		}
+		soaked := waited >= plan.Soak
+		for _, step := range steps[:canaries] {
+			if err := step.check(soaked); err != nil {
+				return abort(step, err)
+			}
+		}
+		if soaked {
+			break
+		}
+		log.Info("Canaries healthy, soaking", "elapsed", waited, "remaining", plan.Soak-waited)
+	}
+	// Canaries survived, change the remaining nodes
+	for _, step := range steps[canaries:] {
+		log.Info("Deploying change to node", "node", step.name())
+		if err := deploy(step); err != nil {
+			return abort(step, err)
+		}
+		sleep(interval)
+		if err := step.check(false); err != nil {
+			return abort(step, err)
+		}
+	}
+	for _, step := range applied {
+		report.Updated = append(report.Updated, step.name())
+	}
+	report.Success = true
+	return report
+}
+
+// nodeRolloutStep rolls a configuration change out to a boot or seal node.
+type nodeRolloutStep struct {
+	node      *forkNode
+	network   string
+	bootnodes []string
+
+	previous *nodeInfos // Configuration the node ran before the change
+	next     *nodeInfos // Configuration to roll out
+
+	minPeers int                        // Peers the node must be connected to
+	progress bool                       // Whether the head must advance during the soak period
+	verify   func(node *forkNode) error // Check specific to the change, nil if none
+
+	head uint64 // Head of the node before the change
+}
+
+// name implements rolloutStep.
+func (s *nodeRolloutStep) name() string { return s.node.name() }
+
+// apply implements rolloutStep, redeploying the node with the changed config.
+func (s *nodeRolloutStep) apply() error {
+	if head, err := queryNodeHead(s.node.client, s.node.container); err == nil {
+		s.head = head
+	}
+	return s.deploy(s.next)
+}
+
+// revert implements rolloutStep, redeploying the node with its previous config.
+func (s *nodeRolloutStep) revert() error {
+	return s.deploy(s.previous)
+}
+
+// deploy redeploys the node with the given configuration.
+func (s *nodeRolloutStep) deploy(infos *nodeInfos) error {
+	out, err := deployNode(s.node.client, s.network, s.bootnodes, infos, false)
+	if err != nil && len(out) > 0 {
+		return fmt.Errorf("%v: %s", err, out)
+	}
+	return err
+}
+
+// check implements rolloutStep, verifying via RPC that the node follows the
+// chain and stays connected to the network.
+func (s *nodeRolloutStep) check(soaked bool) error {
+	head, err := queryNodeHead(s.node.client, s.node.container)
+	if err != nil {
+		return err
+	}
+	if head < s.head {
+		return fmt.Errorf("head regressed from %d to %d", s.head, head)
+	}
+	if soaked && s.progress && head == s.head {
+		return fmt.Errorf("head stalled at %d", head)
+	}
+	if s.minPeers > 0 {
+		peers, err := queryNodePeers(s.node.client, s.node.container)
+		if err != nil {
+			return err
+		}
+		if peers < s.minPeers {
+			return fmt.Errorf("connected to %d peers, want at least %d", peers, s.minPeers)
+		}
+	}
+	if s.verify != nil {
+		return s.verify(s.node)
+	}
+	return nil
+}
+
+// queryNodePeers retrieves the number of peers of a running node container.
+func queryNodePeers(client *sshClient, container string) (int, error) {
+	out, err := client.Run(fmt.Sprintf("docker exec %s geth --exec admin.peers.length --cache=16 attach", container))
+	if err != nil {
+		return 0, ErrServiceUnreachable
+	}
+	return strconv.Atoi(string(bytes.TrimSpace(out)))
+}
@@ -0,0 +1,152 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRolloutStep is a rollout step recording the operations done on it.
+type testRolloutStep struct {
+	node string
+	log  *[]string
+
+	failApply  bool
+	failCheck  int // Number of the check to fail at, 0 to never fail
+	failRevert bool
+
+	checks int
+	soaked bool
+}
+
+func (s *testRolloutStep) name() string { return s.node }
+
+func (s *testRolloutStep) apply() error {
+	*s.log = append(*s.log, "apply "+s.node)
+	if s.failApply {
+		return errors.New("apply failed")
+	}
+	return nil
+}
+
+func (s *testRolloutStep) revert() error {
+	*s.log = append(*s.log, "revert "+s.node)
+	if s.failRevert {
+		return errors.New("revert failed")
+	}
+	return nil
+}
+
+func (s *testRolloutStep) check(soaked bool) error {
+	s.checks++
+	s.soaked = s.soaked || soaked
+	if s.checks == s.failCheck {
+		return errors.New("unhealthy")
+	}
+	return nil
+}
+
+// Tests that rollouts change the canaries first, soak them, and either finish
+// the rollout or roll all changed nodes back.
+func TestRollout(t *testing.T) {
+	plan := &rolloutPlan{Canaries: 2, Soak: 5 * time.Minute, Interval: 2 * time.Minute}
+
+	tests := []struct {
+		name     string
+		setup    func(steps []*testRolloutStep)
+		log      []string
+		report   rolloutReport
+		canaries int // Checks expected on each canary
+	}{
+		{
+			name:     "success",
+			setup:    func(steps []*testRolloutStep) {},
+			log:      []string{"apply a", "apply b", "apply c", "apply d"},
+			report:   rolloutReport{Canaries: []string{"a", "b"}, Updated: []string{"a", "b", "c", "d"}, Success: true},
+			canaries: 3,
+		},
+		{
+			name:     "canary unhealthy while soaking",
+			setup:    func(steps []*testRolloutStep) { steps[1].failCheck = 2 },
+			log:      []string{"apply a", "apply b", "revert b", "revert a"},
+			report:   rolloutReport{Canaries: []string{"a", "b"}, RolledBack: []string{"b", "a"}, Failure: "b: unhealthy"},
+			canaries: 2,
+		},
+		{
+			name:   "canary deploy failing",
+			setup:  func(steps []*testRolloutStep) { steps[1].failApply = true },
+			log:    []string{"apply a", "apply b", "revert b", "revert a"},
+			report: rolloutReport{Canaries: []string{"a", "b"}, RolledBack: []string{"b", "a"}, Failure: "b: apply failed"},
+		},
+		{
+			name:     "remaining node unhealthy",
+			setup:    func(steps []*testRolloutStep) { steps[3].failCheck = 1 },
+			log:      []string{"apply a", "apply b", "apply c", "apply d", "revert d", "revert c", "revert b", "revert a"},
+			report:   rolloutReport{Canaries: []string{"a", "b"}, RolledBack: []string{"d", "c", "b", "a"}, Failure: "d: unhealthy"},
+			canaries: 3,
+		},
+		{
+			name:     "rollback failing",
+			setup:    func(steps []*testRolloutStep) { steps[2].failCheck = 1; steps[0].failRevert = true },
+			log:      []string{"apply a", "apply b", "apply c", "revert c", "revert b", "revert a"},
+			report:   rolloutReport{Canaries: []string{"a", "b"}, RolledBack: []string{"c", "b"}, Stranded: []string{"a"}, Failure: "c: unhealthy"},
+			canaries: 3,
+		},
+	}
+	for _, tt := range tests {
+		var (
+			log   []string
+			steps []*testRolloutStep
+			slept time.Duration
+		)
+		for _, node := range []string{"a", "b", "c", "d"} {
+			steps = append(steps, &testRolloutStep{node: node, log: &log})
+		}
+		tt.setup(steps)
+
+		generic := make([]rolloutStep, len(steps))
+		for i, step := range steps {
+			generic[i] = step
+		}
+		report := runRollout(plan, generic, func(d time.Duration) { slept += d })
+
+		if !reflect.DeepEqual(log, tt.log) {
+			t.Errorf("%s: operations mismatch:\nhave %v\nwant %v", tt.name, log, tt.log)
+		}
+		if !reflect.DeepEqual(*report, tt.report) {
+			t.Errorf("%s: report mismatch:\nhave %+v\nwant %+v", tt.name, *report, tt.report)
+		}
+		if tt.canaries > 0 {
+			for _, step := range steps[:2] {
+				if step.checks != tt.canaries {
+					t.Errorf("%s: canary %s checked %d times, want %d", tt.name, step.node, step.checks, tt.canaries)
+				}
+			}
+		}
+		if tt.report.Success {
+			if !steps[0].soaked || steps[2].soaked {
+				t.Errorf("%s: soak completion signalled to the wrong nodes", tt.name)
+			}
+			if want := plan.Soak + 2*plan.Interval; slept != want {
+				t.Errorf("%s: waited %v, want %v", tt.name, slept, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"time"
+)
+
+// rpcAuthPort is the port geth serves its JWT authenticated RPC endpoint on
+// inside the node container. It's never published, only the TLS proxy in front
+// of it is.
+const rpcAuthPort = 8551
+
+const (
+	rpcCAValidity   = 10 * 365 * 24 * time.Hour // Validity of a network RPC CA
+	rpcCertValidity = 2 * 365 * 24 * time.Hour  // Validity of a node RPC certificate
+)
+
+// rpcProxyDockerfile is the Dockerfile required to build the TLS terminating
+// proxy in front of the RPC endpoint of a node.
+var rpcProxyDockerfile = `
+FROM caddy:2
+
+ADD Caddyfile /etc/caddy/Caddyfile{{if not .Domain}}
+ADD node.crt /tls/node.crt
+ADD node.key /tls/node.key{{end}}
+`
+
+// rpcProxyCaddyfile is the configuration of the TLS terminating proxy. TLS is
+// served either with a certificate issued by the network CA, or one obtained
+// via ACME if the node has a domain. Requests are forwarded to the JWT
+// authenticated endpoint of the node, so unauthenticated ones never pass.
+var rpcProxyCaddyfile = `{
+	{{if .Domain}}{{if .Email}}email {{.Email}}{{end}}{{else}}auto_https off{{end}}
+}
+
+{{.Domain}}:{{.Port}} {
+	{{if not .Domain}}tls /tls/node.crt /tls/node.key
+	{{end}}reverse_proxy {{.Upstream}}:{{.AuthPort}}
+}
+`
+
+// rpcAuthority is the certificate authority issuing the TLS certificates of the
+// node RPC endpoints of a network. It's persisted in the puppeth configs, so
+// clients trusting it keep working across node redeploys.
+type rpcAuthority struct {
+	Cert string `json:"cert"` // PEM encoded self-signed CA certificate
+	Key  string `json:"key"`  // PEM encoded CA private key
+}
+
+// newRPCAuthority creates a self-signed certificate authority for the RPC
+// endpoints of a network.
+func newRPCAuthority(network string) (*rpcAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serial, err := newCertSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s RPC CA", network), Organization: []string{network}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(rpcCAValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := encodeECKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcAuthority{
+		Cert: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		Key:  string(keyPEM),
+	}, nil
+}
+
+// parse decodes the certificate and private key of the authority.
+func (ca *rpcAuthority) parse() (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certBlock, _ := pem.Decode([]byte(ca.Cert))
+	if certBlock == nil {
+		return nil, nil, errors.New("invalid CA certificate")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyBlock, _ := pem.Decode([]byte(ca.Key))
+	if keyBlock == nil {
+		return nil, nil, errors.New("invalid CA key")
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cert, key, nil
+}
+
+// issue creates a TLS server certificate for the RPC endpoint of a node reachable
+// at host, which may either be an IP address or a DNS name. The certificate and
+// its private key are returned PEM encoded.
+func (ca *rpcAuthority) issue(host string) ([]byte, []byte, error) {
+	caCert, caKey, err := ca.parse()
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	serial, err := newCertSerial()
+	if err != nil {
+		return nil, nil, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: host, Organization: caCert.Subject.Organization},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(rpcCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := encodeECKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), keyPEM, nil
+}
+
+// newCertSerial generates a random 128 bit certificate serial number.
+func newCertSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// encodeECKey PEM encodes an ECDSA private key.
+func encodeECKey(key *ecdsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), nil
+}
+
+// newJWTSecret generates a random 256 bit JWT secret shared between a node and
+// its RPC clients, hex encoded as geth expects it.
+func newJWTSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// rpcEndpoint describes how clients connect to the RPC endpoint of a node.
+type rpcEndpoint struct {
+	URL     string // HTTPS URL of the endpoint
+	CAFile  string // CA certificate to verify the endpoint with, empty if issued via ACME
+	JWTFile string // File containing the hex encoded JWT secret
+}
+
+// String implements fmt.Stringer, rendering the client connection string.
+func (e *rpcEndpoint) String() string {
+	if e.CAFile == "" {
+		return fmt.Sprintf("%s (jwtsecret=%s)", e.URL, e.JWTFile)
+	}
+	return fmt.Sprintf("%s (jwtsecret=%s, cacert=%s)", e.URL, e.JWTFile, e.CAFile)
+}
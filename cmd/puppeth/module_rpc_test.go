@@ -0,0 +1,123 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+// Tests that the certificates issued by the network CA verify against it for
+// both IP addresses and domain names.
+func TestRPCAuthorityIssue(t *testing.T) {
+	ca, err := newRPCAuthority("stureby")
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(ca.Cert)) {
+		t.Fatalf("failed to load CA certificate")
+	}
+	for _, host := range []string{"1.2.3.4", "rpc.stureby.org"} {
+		certPEM, keyPEM, err := ca.issue(host)
+		if err != nil {
+			t.Fatalf("%s: failed to issue certificate: %v", host, err)
+		}
+		pair, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			t.Fatalf("%s: invalid key pair: %v", host, err)
+		}
+		cert, err := x509.ParseCertificate(pair.Certificate[0])
+		if err != nil {
+			t.Fatalf("%s: failed to parse certificate: %v", host, err)
+		}
+		opts := x509.VerifyOptions{DNSName: host, Roots: pool, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}}
+		if _, err := cert.Verify(opts); err != nil {
+			t.Errorf("%s: certificate doesn't verify: %v", host, err)
+		}
+	}
+	// Certificates of other authorities must not verify
+	other, err := newRPCAuthority("stureby")
+	if err != nil {
+		t.Fatalf("failed to create CA: %v", err)
+	}
+	certPEM, _, err := other.issue("1.2.3.4")
+	if err != nil {
+		t.Fatalf("failed to issue certificate: %v", err)
+	}
+	block, _ := pem.Decode(certPEM)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{DNSName: "1.2.3.4", Roots: pool}); err == nil {
+		t.Errorf("foreign certificate verified against the network CA")
+	}
+}
+
+// Tests that JWT secrets are 256 bit hex strings, unique per call.
+func TestJWTSecret(t *testing.T) {
+	a, err := newJWTSecret()
+	if err != nil {
+		t.Fatalf("failed to generate secret: %v", err)
+	}
+	b, _ := newJWTSecret()
+	if blob, err := hex.DecodeString(a); err != nil || len(blob) != 32 {
+		t.Errorf("invalid secret %q: %v", a, err)
+	}
+	if a == b {
+		t.Errorf("secrets repeated")
+	}
+}
+
+// Tests that the proxy terminates TLS with the configured certificates and
+// forwards to the authenticated endpoint only.
+func TestRPCProxyCaddyfile(t *testing.T) {
+	tests := []struct {
+		domain, email string
+		want, reject  []string
+	}{
+		{"", "", []string{"auto_https off", ":8545 {", "tls /tls/node.crt /tls/node.key", "reverse_proxy sealnode:8551"}, []string{"email"}},
+		{"rpc.stureby.org", "ops@stureby.org", []string{"email ops@stureby.org", "rpc.stureby.org:8545 {", "reverse_proxy sealnode:8551"}, []string{"auto_https", "tls /tls"}},
+	}
+	for _, tt := range tests {
+		out := new(bytes.Buffer)
+		template.Must(template.New("").Parse(rpcProxyCaddyfile)).Execute(out, map[string]interface{}{
+			"Domain":   tt.domain,
+			"Email":    tt.email,
+			"Port":     8545,
+			"Upstream": "sealnode",
+			"AuthPort": rpcAuthPort,
+		})
+		for _, want := range tt.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("domain %q: missing %q in:\n%s", tt.domain, want, out)
+			}
+		}
+		for _, reject := range tt.reject {
+			if strings.Contains(out.String(), reject) {
+				t.Errorf("domain %q: unexpected %q in:\n%s", tt.domain, reject, out)
+			}
+		}
+	}
+}
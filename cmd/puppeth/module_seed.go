@@ -0,0 +1,393 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+	"github.com/liuguodong24-8/3fcoin/core/core/rawdb"
+	"github.com/liuguodong24-8/3fcoin/core/core/state"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/rlp"
+)
+
+// defaultSeedEpoch is the seed length used if the consensus engine doesn't
+// define an epoch of its own.
+const defaultSeedEpoch = 30000
+
+// defaultParliaSeedEpoch is the epoch length parlia falls back to if the genesis
+// doesn't configure one.
+const defaultParliaSeedEpoch = 100
+
+// seedPieceLength is the piece size of the torrents distributing the seeds.
+const seedPieceLength = 256 * 1024
+
+// errSeedStateMissing is returned if the node doesn't hold the state to seed
+// anymore, which is the case for pruning nodes once it's old enough.
+var errSeedStateMissing = errors.New("state unavailable, seed nodes need --gcmode=archive once past the first epoch")
+
+// seedArtifact is a state-sync seed retrieved from a running node.
+type seedArtifact struct {
+	genesis    []byte              // Genesis spec the chain was initialized with
+	block      []byte              // RLP encoded checkpoint block
+	state      []byte              // Gzipped JSON dump of the state at the checkpoint
+	checkpoint core.SeedCheckpoint // Trusted block the state was taken at
+}
+
+// nodeSeed is a saved state-sync seed, loaded for uploading along a node which
+// imports it on first boot.
+type nodeSeed struct {
+	name     string            // Name of the seed, its files living in a folder of the same name
+	manifest []byte            // JSON manifest describing the seed
+	files    map[string][]byte // Seed files, verified against the manifest
+}
+
+// seedEpoch returns the block seeds are taken at, the end of the first epoch of
+// the consensus engine.
+func seedEpoch(genesis *core.Genesis) uint64 {
+	switch {
+	case genesis.Config.Clique != nil && genesis.Config.Clique.Epoch > 0:
+		return genesis.Config.Clique.Epoch
+	case genesis.Config.Parlia != nil && genesis.Config.Parlia.Epoch > 0:
+		return genesis.Config.Parlia.Epoch
+	case genesis.Config.Parlia != nil:
+		return defaultParliaSeedEpoch
+	}
+	return defaultSeedEpoch
+}
+
+// queryNodeHead retrieves the current head block number of a running node.
+func queryNodeHead(client *sshClient, container string) (uint64, error) {
+	out, err := client.Run(fmt.Sprintf("docker exec %s geth --exec eth.blockNumber --cache=16 attach", container))
+	if err != nil {
+		return 0, ErrServiceUnreachable
+	}
+	return strconv.ParseUint(string(bytes.TrimSpace(out)), 10, 64)
+}
+
+// queryNodeJSON evaluates a console expression on a running node, returning the
+// JSON encoding of its result. The console prints the stringified result as a
+// quoted string literal, which is unwrapped.
+func queryNodeJSON(client *sshClient, container string, expr string) ([]byte, error) {
+	out, err := client.Download(fmt.Sprintf("docker exec %s geth --exec 'JSON.stringify(%s)' --cache=16 attach", container, expr))
+	if err != nil {
+		return nil, ErrServiceUnreachable
+	}
+	var blob string
+	if err := json.Unmarshal(bytes.TrimSpace(out), &blob); err != nil {
+		return nil, fmt.Errorf("invalid %s response: %s", expr, bytes.TrimSpace(out))
+	}
+	return []byte(blob), nil
+}
+
+// exportSeed exports the state at the given block from a running node container
+// along with the block itself, which becomes the trusted checkpoint.
+func exportSeed(client *sshClient, container string, genesis *core.Genesis, number uint64) (*seedArtifact, error) {
+	block, err := queryNodeJSON(client, container, fmt.Sprintf("eth.getBlock(%d)", number))
+	if err != nil {
+		return nil, err
+	}
+	raw, err := queryNodeJSON(client, container, fmt.Sprintf("debug.getBlockRlp(%d)", number))
+	if err != nil {
+		return nil, err
+	}
+	var encoded string
+	if err := json.Unmarshal(raw, &encoded); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint block RLP: %s", raw)
+	}
+	dump, err := queryNodeJSON(client, container, fmt.Sprintf("debug.dumpBlock(%d)", number))
+	if err != nil {
+		return nil, err
+	}
+	return newSeedArtifact(genesis, block, common.FromHex(encoded), dump)
+}
+
+// newSeedArtifact assembles a seed from the JSON encoding of the checkpoint block,
+// its RLP encoding and the state dump taken at it. The seed is imported into a
+// throwaway database, so only seeds nodes can actually start from are accepted.
+func newSeedArtifact(genesis *core.Genesis, block []byte, encoded []byte, dump []byte) (*seedArtifact, error) {
+	var checkpoint core.SeedCheckpoint
+	if err := json.Unmarshal(block, &checkpoint); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint block: %v", err)
+	}
+	decoded := new(types.Block)
+	if err := rlp.DecodeBytes(encoded, decoded); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint block RLP: %v", err)
+	}
+	if decoded.NumberU64() != checkpoint.Number || decoded.Hash() != checkpoint.Hash || decoded.Root() != checkpoint.Root {
+		return nil, fmt.Errorf("checkpoint block mismatch: have #%d [%x], want #%d [%x]", decoded.NumberU64(), decoded.Hash(), checkpoint.Number, checkpoint.Hash)
+	}
+	var snapshot state.Dump
+	if err := json.Unmarshal(dump, &snapshot); err != nil {
+		return nil, fmt.Errorf("%w: %s", errSeedStateMissing, bytes.TrimSpace(dump))
+	}
+	if root := common.BytesToHash(common.FromHex(snapshot.Root)); root != checkpoint.Root {
+		return nil, fmt.Errorf("state root mismatch: have %x, want %x", root, checkpoint.Root)
+	}
+	alloc, err := core.DumpAlloc(&snapshot)
+	if err != nil {
+		return nil, err
+	}
+	db := rawdb.NewMemoryDatabase()
+	if _, err := genesis.Commit(db); err != nil {
+		return nil, err
+	}
+	if err := core.CommitCheckpoint(db, decoded, (*big.Int)(checkpoint.TotalDifficulty), alloc); err != nil {
+		return nil, fmt.Errorf("seed not importable: %v", err)
+	}
+	blob, err := json.Marshal(&snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	if _, err := zw.Write(blob); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	spec, _ := json.MarshalIndent(genesis, "", "  ")
+
+	return &seedArtifact{
+		genesis:    spec,
+		block:      encoded,
+		state:      compressed.Bytes(),
+		checkpoint: checkpoint,
+	}, nil
+}
+
+// save writes the seed artifacts into a folder of their own within folder, next
+// to their HTTP manifest and a torrent distributing them. If baseURL is set, the
+// manifest references the files under it and the torrent uses it as a web seed.
+func (seed *seedArtifact) save(folder string, network string, genesis *core.Genesis, baseURL string, trackers []string) (string, error) {
+	name := fmt.Sprintf("%s-seed-%d", network, seed.checkpoint.Number)
+	if err := os.MkdirAll(filepath.Join(folder, name), 0755); err != nil {
+		return "", err
+	}
+	checkpoint, _ := json.MarshalIndent(seed.checkpoint, "", "  ")
+
+	files := []torrentFile{
+		{name: "genesis.json", data: seed.genesis},
+		{name: core.SeedStateFile(seed.checkpoint.Number), data: seed.state},
+		{name: core.SeedBlockFile, data: seed.block},
+		{name: "checkpoint.json", data: checkpoint},
+	}
+	manifest := &core.SeedManifest{
+		Network:    network,
+		ChainID:    genesis.Config.ChainID,
+		Genesis:    genesis.ToBlock(nil).Hash(),
+		Checkpoint: seed.checkpoint,
+	}
+	for _, file := range files {
+		if err := ioutil.WriteFile(filepath.Join(folder, name, file.name), file.data, 0644); err != nil {
+			return "", err
+		}
+		hash := sha256.Sum256(file.data)
+
+		entry := core.SeedFile{
+			Name:   file.name,
+			Size:   len(file.data),
+			SHA256: hex.EncodeToString(hash[:]),
+		}
+		if baseURL != "" {
+			entry.URL = strings.TrimRight(baseURL, "/") + "/" + name + "/" + file.name
+		}
+		manifest.Files = append(manifest.Files, entry)
+	}
+	var webseed string
+	if baseURL != "" {
+		webseed = strings.TrimRight(baseURL, "/") + "/"
+	}
+	torrent, infohash := makeTorrent(name, files, seedPieceLength, trackers, webseed)
+	if err := ioutil.WriteFile(filepath.Join(folder, name+".torrent"), torrent, 0644); err != nil {
+		return "", err
+	}
+	manifest.InfoHash = hex.EncodeToString(infohash[:])
+
+	path := filepath.Join(folder, name+".json")
+	out, _ := json.MarshalIndent(manifest, "", "  ")
+	return path, ioutil.WriteFile(path, out, 0644)
+}
+
+// loadSeed loads a saved state-sync seed from the path of its manifest, checking
+// that it belongs to the given genesis and that the files match the manifest.
+func loadSeed(path string, genesis *core.Genesis) (*nodeSeed, error) {
+	blob, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	manifest := new(core.SeedManifest)
+	if err := json.Unmarshal(blob, manifest); err != nil {
+		return nil, fmt.Errorf("invalid seed manifest: %v", err)
+	}
+	if hash := genesis.ToBlock(nil).Hash(); manifest.Genesis != hash {
+		return nil, fmt.Errorf("seed genesis mismatch: have %x, want %x", manifest.Genesis, hash)
+	}
+	seed := &nodeSeed{
+		name:     strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)),
+		manifest: blob,
+		files:    make(map[string][]byte),
+	}
+	folder := strings.TrimSuffix(path, filepath.Ext(path))
+	for _, file := range manifest.Files {
+		data, err := ioutil.ReadFile(filepath.Join(folder, file.Name))
+		if err != nil {
+			return nil, err
+		}
+		hash := sha256.Sum256(data)
+		if len(data) != file.Size || hex.EncodeToString(hash[:]) != file.SHA256 {
+			return nil, fmt.Errorf("seed file %s doesn't match the manifest", file.Name)
+		}
+		seed.files[file.Name] = data
+	}
+	return seed, nil
+}
+
+// fileNames returns the alphabetically sorted names of the seed files.
+func (seed *nodeSeed) fileNames() []string {
+	names := make([]string, 0, len(seed.files))
+	for name := range seed.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// torrentFile is a file distributed by a torrent.
+type torrentFile struct {
+	name string
+	data []byte
+}
+
+// makeTorrent creates the metainfo of a multi-file torrent (BEP 3) named name,
+// returning it together with its info hash. Trackers are announced if given
+// (BEP 12), and the web seed, if set, serves the files over HTTP (BEP 19).
+func makeTorrent(name string, files []torrentFile, pieceLength int, trackers []string, webseed string) ([]byte, [sha1.Size]byte) {
+	var (
+		entries []interface{}
+		pieces  []byte
+		piece   = make([]byte, 0, pieceLength)
+	)
+	for _, file := range files {
+		entries = append(entries, map[string]interface{}{
+			"length": len(file.data),
+			"path":   []interface{}{file.name},
+		})
+		// Pieces span file boundaries, hashing the concatenation of all files
+		for data := file.data; len(data) > 0; {
+			n := pieceLength - len(piece)
+			if n > len(data) {
+				n = len(data)
+			}
+			piece, data = append(piece, data[:n]...), data[n:]
+			if len(piece) == pieceLength {
+				hash := sha1.Sum(piece)
+				pieces, piece = append(pieces, hash[:]...), piece[:0]
+			}
+		}
+	}
+	if len(piece) > 0 {
+		hash := sha1.Sum(piece)
+		pieces = append(pieces, hash[:]...)
+	}
+	info := bencode(map[string]interface{}{
+		"files":        entries,
+		"name":         name,
+		"piece length": pieceLength,
+		"pieces":       string(pieces),
+	})
+	metainfo := map[string]interface{}{
+		"created by": "puppeth",
+		"info":       rawBencode(info),
+	}
+	if len(trackers) > 0 {
+		tiers := make([]interface{}, len(trackers))
+		for i, tracker := range trackers {
+			tiers[i] = []interface{}{tracker}
+		}
+		metainfo["announce"], metainfo["announce-list"] = trackers[0], tiers
+	}
+	if webseed != "" {
+		metainfo["url-list"] = []interface{}{webseed}
+	}
+	return bencode(metainfo), sha1.Sum(info)
+}
+
+// rawBencode is an already bencoded value.
+type rawBencode []byte
+
+// bencode encodes a value built of strings, integers, lists and dictionaries
+// into the bencoding of torrent metainfo files.
+func bencode(v interface{}) []byte {
+	var buf bytes.Buffer
+	writeBencode(&buf, v)
+	return buf.Bytes()
+}
+
+func writeBencode(buf *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case rawBencode:
+		buf.Write(v)
+	case string:
+		buf.WriteString(strconv.Itoa(len(v)))
+		buf.WriteByte(':')
+		buf.WriteString(v)
+	case int:
+		buf.WriteByte('i')
+		buf.WriteString(strconv.Itoa(v))
+		buf.WriteByte('e')
+	case []interface{}:
+		buf.WriteByte('l')
+		for _, item := range v {
+			writeBencode(buf, item)
+		}
+		buf.WriteByte('e')
+	case map[string]interface{}:
+		// Dictionary keys are sorted as raw strings
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		buf.WriteByte('d')
+		for _, key := range keys {
+			writeBencode(buf, key)
+			writeBencode(buf, v[key])
+		}
+		buf.WriteByte('e')
+	default:
+		panic(fmt.Sprintf("unsupported bencode type %T", v))
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+	"github.com/liuguodong24-8/3fcoin/core/rlp"
+)
+
+// Tests that values are bencoded with their dictionary keys sorted.
+func TestBencode(t *testing.T) {
+	have := string(bencode(map[string]interface{}{
+		"b": []interface{}{"spam", 42},
+		"a": rawBencode("i-1e"),
+	}))
+	if want := "d1:ai-1e1:bl4:spami42eee"; have != want {
+		t.Errorf("encoding mismatch: have %s, want %s", have, want)
+	}
+}
+
+// Tests that torrent pieces span file boundaries and that the metainfo carries
+// the trackers and web seed.
+func TestMakeTorrent(t *testing.T) {
+	files := []torrentFile{
+		{name: "a", data: []byte("12345")},
+		{name: "b", data: []byte("6789")},
+	}
+	torrent, infohash := makeTorrent("seed", files, 4, []string{"udp://tracker.example.org:80"}, "https://example.org/")
+
+	pieces := [][]byte{[]byte("1234"), []byte("5678"), []byte("9")}
+	var hashes []byte
+	for _, piece := range pieces {
+		hash := sha1.Sum(piece)
+		hashes = append(hashes, hash[:]...)
+	}
+	info := bencode(map[string]interface{}{
+		"files": []interface{}{
+			map[string]interface{}{"length": 5, "path": []interface{}{"a"}},
+			map[string]interface{}{"length": 4, "path": []interface{}{"b"}},
+		},
+		"name":         "seed",
+		"piece length": 4,
+		"pieces":       string(hashes),
+	})
+	if !bytes.Contains(torrent, info) {
+		t.Fatalf("info dictionary mismatch: have %q", torrent)
+	}
+	if infohash != sha1.Sum(info) {
+		t.Errorf("info hash mismatch: have %x, want %x", infohash, sha1.Sum(info))
+	}
+	for _, want := range []string{"8:announce28:udp://tracker.example.org:80", "8:url-listl20:https://example.org/e"} {
+		if !strings.Contains(string(torrent), want) {
+			t.Errorf("torrent missing %q", want)
+		}
+	}
+}
+
+// seedTestBlock returns the JSON and RLP encodings of a checkpoint block on top
+// of the empty state.
+func seedTestBlock(t *testing.T) ([]byte, []byte) {
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:     big.NewInt(30000),
+		ParentHash: common.HexToHash("0x01"),
+		Root:       types.EmptyRootHash,
+		Difficulty: big.NewInt(1),
+	})
+	encoded, err := rlp.EncodeToBytes(block)
+	if err != nil {
+		t.Fatalf("failed to encode block: %v", err)
+	}
+	spec := fmt.Sprintf(`{"number":30000,"hash":"%s","stateRoot":"%s","totalDifficulty":"30001"}`, block.Hash().Hex(), types.EmptyRootHash.Hex())
+	return []byte(spec), encoded
+}
+
+// Tests that seeds are only assembled from state dumps and blocks matching the
+// checkpoint, and only if they can actually be imported.
+func TestSeedArtifact(t *testing.T) {
+	genesis := &core.Genesis{Config: params.AllEthashProtocolChanges}
+	block, encoded := seedTestBlock(t)
+	root := fmt.Sprintf(`{"root":"%x","accounts":{}}`, types.EmptyRootHash)
+
+	if _, err := newSeedArtifact(genesis, block, encoded, []byte("missing trie node")); !errors.Is(err, errSeedStateMissing) {
+		t.Errorf("missing state error mismatch: have %v, want %v", err, errSeedStateMissing)
+	}
+	if _, err := newSeedArtifact(genesis, block, encoded, []byte(`{"root":"0x0000000000000000000000000000000000000000000000000000000000000003","accounts":{}}`)); err == nil {
+		t.Errorf("mismatching state root accepted")
+	}
+	other, _ := rlp.EncodeToBytes(types.NewBlockWithHeader(&types.Header{Number: big.NewInt(30000)}))
+	if _, err := newSeedArtifact(genesis, block, other, []byte(root)); err == nil {
+		t.Errorf("mismatching checkpoint block accepted")
+	}
+	if _, err := newSeedArtifact(genesis, []byte(strings.Replace(string(block), `"30001"`, `"0"`, 1)), encoded, []byte(root)); err == nil {
+		t.Errorf("checkpoint without total difficulty accepted")
+	}
+	seed, err := newSeedArtifact(genesis, block, encoded, []byte(root))
+	if err != nil {
+		t.Fatalf("failed to assemble seed: %v", err)
+	}
+	if seed.checkpoint.Number != 30000 {
+		t.Errorf("checkpoint number mismatch: have %d, want %d", seed.checkpoint.Number, 30000)
+	}
+	if !bytes.Equal(seed.block, encoded) {
+		t.Errorf("checkpoint block mismatch: have %x, want %x", seed.block, encoded)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(seed.state))
+	if err != nil {
+		t.Fatalf("state not gzipped: %v", err)
+	}
+	blob, _ := ioutil.ReadAll(zr)
+	if !bytes.Contains(blob, []byte(fmt.Sprintf("%x", types.EmptyRootHash))) {
+		t.Errorf("state dump missing root: %s", blob)
+	}
+}
+
+// Tests that saved seeds load back for node deploys, and that seeds of other
+// networks or with tampered files are rejected.
+func TestLoadSeed(t *testing.T) {
+	genesis := &core.Genesis{Config: params.AllEthashProtocolChanges}
+	block, encoded := seedTestBlock(t)
+
+	seed, err := newSeedArtifact(genesis, block, encoded, []byte(fmt.Sprintf(`{"root":"%x","accounts":{}}`, types.EmptyRootHash)))
+	if err != nil {
+		t.Fatalf("failed to assemble seed: %v", err)
+	}
+	folder, err := ioutil.TempDir("", "seed-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(folder)
+
+	path, err := seed.save(folder, "testnet", genesis, "", nil)
+	if err != nil {
+		t.Fatalf("failed to save seed: %v", err)
+	}
+	loaded, err := loadSeed(path, genesis)
+	if err != nil {
+		t.Fatalf("failed to load seed: %v", err)
+	}
+	if loaded.name != "testnet-seed-30000" {
+		t.Errorf("seed name mismatch: have %s, want %s", loaded.name, "testnet-seed-30000")
+	}
+	want := []string{core.SeedBlockFile, "checkpoint.json", "genesis.json", core.SeedStateFile(30000)}
+	if names := loaded.fileNames(); strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("seed files mismatch: have %v, want %v", names, want)
+	}
+	if _, err := loadSeed(path, &core.Genesis{Config: params.AllEthashProtocolChanges, ExtraData: []byte{1}}); err == nil {
+		t.Errorf("seed of another genesis accepted")
+	}
+	if err := ioutil.WriteFile(filepath.Join(folder, "testnet-seed-30000", core.SeedBlockFile), []byte{0xc0}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSeed(path, genesis); err == nil {
+		t.Errorf("tampered seed accepted")
+	}
+}
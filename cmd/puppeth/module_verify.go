@@ -0,0 +1,306 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+)
+
+// specClient is a client implementation the exported chain specs are verified
+// against, booted from its upstream docker image.
+type specClient struct {
+	Name    string // Name of the client, also used as the service name
+	Image   string // Docker image to run the client from
+	Shell   bool   // Whether the command is run by a shell instead of the image entrypoint
+	Spec    string // Exported chain spec the client loads (native or parity)
+	Command string // Command line template of the client
+	RPCPort int    // Port the HTTP RPC is served on within the container
+}
+
+// specClients are the clients verified by booting the exported specs. Aleth is
+// missing as it does not serve HTTP RPC without an external proxy.
+var specClients = []*specClient{
+	{
+		Name:    "geth",
+		Image:   "ethereum/client-go:latest",
+		Spec:    "genesis.json",
+		Shell:   true,
+		Command: `-c "geth --datadir /data init /spec/genesis.json && exec geth --datadir /data --networkid {{.NetworkID}} --syncmode full --http --http.addr 0.0.0.0 --http.vhosts '*' {{if .Debug}}--http.api eth,net,web3,debug --gcmode archive {{end}}{{if .Bootnodes}}--bootnodes {{.Bootnodes}}{{else}}--nodiscover --maxpeers 0{{end}}"`,
+		RPCPort: 8545,
+	},
+	{
+		Name:    "besu",
+		Image:   "hyperledger/besu:latest",
+		Spec:    "genesis.json",
+		Command: `--genesis-file=/spec/genesis.json --data-path=/tmp/besu --network-id={{.NetworkID}} --sync-mode=FULL --rpc-http-enabled --rpc-http-host=0.0.0.0 --host-allowlist='*' {{if .Debug}}--rpc-http-api=ETH,NET,WEB3,DEBUG --data-storage-format=FOREST {{end}}{{if .Bootnodes}}--bootnodes={{.Bootnodes}}{{else}}--discovery-enabled=false{{end}}`,
+		RPCPort: 8545,
+	},
+	{
+		Name:    "openethereum",
+		Image:   "openethereum/openethereum:latest",
+		Spec:    "parity.json",
+		Command: `--chain /spec/parity.json --base-path /tmp/openethereum --jsonrpc-interface all --jsonrpc-hosts all {{if .Debug}}--jsonrpc-apis all --pruning archive {{end}}{{if .Bootnodes}}--bootnodes {{.Bootnodes}}{{else}}--no-discovery{{end}}`,
+		RPCPort: 8545,
+	},
+	{
+		Name:    "nethermind",
+		Image:   "nethermind/nethermind:latest",
+		Spec:    "parity.json",
+		Command: `--Init.ChainSpecPath /spec/parity.json --Init.BaseDbPath /tmp/nethermind --Sync.FastSync false --JsonRpc.Enabled true --JsonRpc.Host 0.0.0.0 {{if .Debug}}--JsonRpc.EnabledModules Eth,Net,Web3,Debug --Pruning.Mode None {{end}}{{if .Bootnodes}}--Discovery.Bootnodes {{.Bootnodes}}{{else}}--Init.DiscoveryEnabled false{{end}}`,
+		RPCPort: 8545,
+	},
+}
+
+// verifyComposefile is the docker-compose.yml file required to boot every
+// verified client on its exported chain spec.
+var verifyComposefile = `
+version: '3.5'
+services:{{range .Clients}}
+  {{.Name}}:
+    image: {{.Image}}
+    container_name: {{$.Project}}_{{.Name}}_1{{if .Shell}}
+    entrypoint: /bin/sh{{end}}
+    command: {{.Command}}
+    volumes:
+      - ./{{.Spec}}:/spec/{{.Spec}}:ro{{end}}
+networks:
+  default:
+    name: {{.Project}}
+`
+
+// verifyProject returns the docker-compose project name, also the name of the
+// network the verification containers run in.
+func verifyProject(network string) string {
+	return network + "_specverify"
+}
+
+// newVerifyComposefile renders the compose file booting all clients of the
+// network, with the optional bootnodes to import the live chain from.
+func newVerifyComposefile(network string, networkID uint64, bootnodes []string) []byte {
+	return newSpecComposefile(verifyProject(network), specClients, networkID, bootnodes, false)
+}
+
+// newSpecComposefile renders the compose file booting the given clients in the
+// project. Debug clients serve the tracing APIs and retain historical state.
+func newSpecComposefile(project string, specs []*specClient, networkID uint64, bootnodes []string, debug bool) []byte {
+	clients := make([]*specClient, len(specs))
+	for i, client := range specs {
+		command := new(bytes.Buffer)
+		template.Must(template.New("").Parse(client.Command)).Execute(command, map[string]interface{}{
+			"NetworkID": networkID,
+			"Bootnodes": strings.Join(bootnodes, ","),
+			"Debug":     debug,
+		})
+		clients[i] = &specClient{Name: client.Name, Image: client.Image, Shell: client.Shell, Spec: client.Spec, Command: command.String(), RPCPort: client.RPCPort}
+	}
+	composefile := new(bytes.Buffer)
+	template.Must(template.New("").Parse(verifyComposefile)).Execute(composefile, map[string]interface{}{
+		"Project": project,
+		"Clients": clients,
+	})
+	return composefile.Bytes()
+}
+
+// specClientReport is the outcome of booting a single client on its spec.
+type specClientReport struct {
+	Client    string      `json:"client"`
+	Genesis   common.Hash `json:"genesis"`           // Genesis hash reported by the client
+	Head      uint64      `json:"head"`              // Head block number reached
+	Block     common.Hash `json:"block,omitempty"`   // Hash of the verified block, if imported
+	Compliant bool        `json:"compliant"`         // Whether the client agrees with the expected chain
+	Problem   string      `json:"problem,omitempty"` // Reason for the incompatibility, if any
+}
+
+// specReport is the outcome of verifying the exported specs.
+type specReport struct {
+	Network string             `json:"network"`
+	Genesis common.Hash        `json:"genesis"` // Genesis hash of the native spec
+	Number  uint64             `json:"number"`  // Block number verified besides the genesis
+	Hash    common.Hash        `json:"hash"`    // Hash of the verified block agreed on by most clients
+	Clients []specClientReport `json:"clients"`
+	Passed  bool               `json:"passed"`
+}
+
+// newSpecReport compares the client results against the expected genesis hash
+// and each other. The verified block is expected to match the one the majority
+// of clients imported.
+func newSpecReport(network string, genesis common.Hash, number uint64, clients []specClientReport) *specReport {
+	report := &specReport{Network: network, Genesis: genesis, Number: number, Clients: clients, Passed: true}
+
+	if number > 0 {
+		votes := make(map[common.Hash]int)
+		for _, client := range clients {
+			if client.Problem == "" && client.Block != (common.Hash{}) {
+				votes[client.Block]++
+			}
+		}
+		for hash, count := range votes {
+			if count > votes[report.Hash] || (count == votes[report.Hash] && bytes.Compare(hash[:], report.Hash[:]) < 0) {
+				report.Hash = hash
+			}
+		}
+	}
+	for i := range report.Clients {
+		client := &report.Clients[i]
+		switch {
+		case client.Problem != "":
+		case client.Genesis != genesis:
+			client.Problem = fmt.Sprintf("genesis mismatch: have %x, want %x", client.Genesis[:8], genesis[:8])
+		case number > 0 && client.Block == (common.Hash{}):
+			client.Problem = fmt.Sprintf("stalled at block %d", client.Head)
+		case number > 0 && client.Block != report.Hash:
+			client.Problem = fmt.Sprintf("block %d mismatch: have %x, want %x", number, client.Block[:8], report.Hash[:8])
+		default:
+			client.Compliant = true
+		}
+		if !client.Compliant {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+// verifySpecs boots every client on its exported spec on the remote server and
+// checks that they agree on the genesis and, if bootnodes are given, on block
+// number after importing it from the live network. The containers are torn
+// down afterwards.
+func verifySpecs(client *sshClient, network string, networkID uint64, specs map[string][]byte, bootnodes []string, number uint64, timeout time.Duration) ([]specClientReport, error) {
+	workdir := fmt.Sprintf("%d", rand.Int63())
+	files := map[string][]byte{
+		filepath.Join(workdir, "docker-compose.yaml"): newVerifyComposefile(network, networkID, bootnodes),
+	}
+	for name, spec := range specs {
+		files[filepath.Join(workdir, name)] = spec
+	}
+	if out, err := client.Upload(files); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, out)
+	}
+	defer client.Run("rm -rf " + workdir)
+
+	project := verifyProject(network)
+	if err := client.Stream(fmt.Sprintf("cd %s && docker-compose -p %s up -d --force-recreate", workdir, project)); err != nil {
+		return nil, err
+	}
+	defer client.Run(fmt.Sprintf("cd %s && docker-compose -p %s down -v", workdir, project))
+
+	// Wait for all clients to come online and import the requested block
+	reports := make([]specClientReport, len(specClients))
+	pending := make(map[int]bool)
+	for i, spec := range specClients {
+		reports[i].Client = spec.Name
+		pending[i] = true
+	}
+	for deadline := time.Now().Add(timeout); len(pending) > 0 && time.Now().Before(deadline); time.Sleep(5 * time.Second) {
+		for i := range pending {
+			done, err := querySpecClient(client, project, specClients[i], number, &reports[i])
+			if err != nil {
+				log.Debug("Spec client not ready", "client", specClients[i].Name, "err", err)
+				continue
+			}
+			if done {
+				delete(pending, i)
+			}
+		}
+	}
+	for i := range pending {
+		if reports[i].Genesis == (common.Hash{}) {
+			reports[i].Problem = "failed to start"
+			if out, err := client.Run(fmt.Sprintf("docker logs --tail 5 %s_%s_1", project, specClients[i].Name)); err == nil {
+				reports[i].Problem += ": " + strings.TrimSpace(string(out))
+			}
+		}
+	}
+	return reports, nil
+}
+
+// querySpecClient polls the state of a client, returning whether it has reached
+// the block to verify.
+func querySpecClient(client *sshClient, project string, spec *specClient, number uint64, report *specClientReport) (bool, error) {
+	if report.Genesis == (common.Hash{}) {
+		block, err := queryContainerRPC(client, project, spec, "eth_getBlockByNumber", "0x0", false)
+		if err != nil {
+			return false, err
+		}
+		var header struct{ Hash common.Hash }
+		if err := json.Unmarshal(block, &header); err != nil {
+			return false, err
+		}
+		report.Genesis = header.Hash
+	}
+	if number == 0 {
+		return true, nil
+	}
+	head, err := queryContainerRPC(client, project, spec, "eth_blockNumber")
+	if err != nil {
+		return false, err
+	}
+	var num hexutil.Uint64
+	if err := json.Unmarshal(head, &num); err != nil {
+		return false, err
+	}
+	report.Head = uint64(num)
+	if report.Head < number {
+		return false, nil
+	}
+	block, err := queryContainerRPC(client, project, spec, "eth_getBlockByNumber", hexutil.EncodeUint64(number), false)
+	if err != nil {
+		return false, err
+	}
+	var header struct{ Hash common.Hash }
+	if err := json.Unmarshal(block, &header); err != nil {
+		return false, err
+	}
+	report.Block = header.Hash
+	return true, nil
+}
+
+// queryContainerRPC executes an RPC call against a verified client, issued from
+// a throwaway container attached to the verification network.
+func queryContainerRPC(client *sshClient, project string, spec *specClient, method string, params ...interface{}) (json.RawMessage, error) {
+	if params == nil {
+		params = []interface{}{}
+	}
+	request, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+
+	out, err := client.Download(fmt.Sprintf("docker run --rm --network %s curlimages/curl:latest -s -X POST -H 'Content-Type: application/json' --data '%s' http://%s:%s", project, request, spec.Name, strconv.Itoa(spec.RPCPort)))
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Result json.RawMessage
+		Error  *struct{ Message string }
+	}
+	if err := json.Unmarshal(out, &response); err != nil {
+		return nil, fmt.Errorf("invalid RPC response: %v", err)
+	}
+	if response.Error != nil {
+		return nil, fmt.Errorf("%s failed: %s", method, response.Error.Message)
+	}
+	return response.Result, nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// Tests that the clients are booted on their matching specs and network.
+func TestVerifyComposefile(t *testing.T) {
+	compose := string(newVerifyComposefile("stureby", 314158, []string{"enode://aa@1.2.3.4:30303"}))
+	for _, want := range []string{
+		"container_name: stureby_specverify_geth_1",
+		"entrypoint: /bin/sh",
+		"--networkid 314158",
+		"--bootnodes enode://aa@1.2.3.4:30303",
+		"./genesis.json:/spec/genesis.json:ro",
+		"--chain /spec/parity.json",
+		"./parity.json:/spec/parity.json:ro",
+		"--Discovery.Bootnodes enode://aa@1.2.3.4:30303",
+		"name: stureby_specverify",
+	} {
+		if !strings.Contains(compose, want) {
+			t.Errorf("compose file missing %q:\n%s", want, compose)
+		}
+	}
+	if compose := string(newVerifyComposefile("stureby", 314158, nil)); !strings.Contains(compose, "--nodiscover") {
+		t.Errorf("isolated geth not configured:\n%s", compose)
+	}
+}
+
+// Tests that incompatible clients are detected.
+func TestSpecReport(t *testing.T) {
+	var (
+		genesis = common.HexToHash("0x01")
+		block   = common.HexToHash("0x02")
+		fork    = common.HexToHash("0x03")
+	)
+	report := newSpecReport("stureby", genesis, 16, []specClientReport{
+		{Client: "geth", Genesis: genesis, Head: 20, Block: block},
+		{Client: "besu", Genesis: genesis, Head: 18, Block: block},
+		{Client: "openethereum", Genesis: genesis, Head: 16, Block: fork},
+		{Client: "nethermind", Genesis: common.HexToHash("0x04")},
+		{Client: "aleth", Genesis: genesis, Head: 3},
+	})
+	if report.Passed || report.Hash != block {
+		t.Fatalf("report mismatch: passed %v, hash %x", report.Passed, report.Hash)
+	}
+	for i, want := range []string{"", "", "block 16 mismatch", "genesis mismatch", "stalled at block 3"} {
+		if client := report.Clients[i]; client.Compliant != (want == "") || !strings.HasPrefix(client.Problem, want) {
+			t.Errorf("%s: status mismatch: compliant %v, problem %q", client.Client, client.Compliant, client.Problem)
+		}
+	}
+	// Genesis only verification must ignore the block
+	report = newSpecReport("stureby", genesis, 0, []specClientReport{{Client: "geth", Genesis: genesis}})
+	if !report.Passed {
+		t.Errorf("genesis only verification failed: %+v", report.Clients)
+	}
+}
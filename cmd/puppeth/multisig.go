@@ -0,0 +1,117 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+)
+
+// multisigCode is the runtime bytecode of the threshold multisig governance
+// contract placed in the genesis alloc. Calls without data are accepted as
+// deposits. Owners confirm a transaction by calling the contract with
+//
+//	to (32 bytes) || value (32 bytes) || nonce (32 bytes) || data
+//
+// and once threshold owners confirmed the same payload for the current nonce,
+// the nonce is bumped and the call is executed. The storage layout is:
+//
+//	slot 0:                    confirmation threshold
+//	slot 1:                    nonce of the next transaction to execute
+//	slot owner:                1 for each owner address
+//	slot keccak(payload):      number of confirmations of a payload
+//	slot keccak(hash, caller): 1 if caller confirmed the payload hash
+//
+// Assembly:
+//
+//	    CALLDATASIZE PUSH1 exec JUMPI
+//	    STOP                                          ; deposit
+//	exec:
+//	    CALLER SLOAD ISZERO PUSH1 fail JUMPI          ; caller not an owner
+//	    CALLDATASIZE PUSH1 96 GT PUSH1 fail JUMPI     ; payload too short
+//	    PUSH1 1 SLOAD PUSH1 64 CALLDATALOAD EQ ISZERO PUSH1 fail JUMPI
+//	    CALLDATASIZE PUSH1 0 PUSH1 0 CALLDATACOPY
+//	    CALLDATASIZE PUSH1 0 KECCAK256                ; hash = keccak(payload)
+//	    DUP1 PUSH1 0 MSTORE CALLER PUSH1 32 MSTORE
+//	    PUSH1 64 PUSH1 0 KECCAK256                    ; flag = keccak(hash, caller)
+//	    DUP1 SLOAD PUSH1 fail JUMPI                   ; already confirmed
+//	    PUSH1 1 SWAP1 SSTORE
+//	    DUP1 SLOAD PUSH1 1 ADD DUP1 DUP3 SSTORE       ; confirmations++
+//	    PUSH1 0 SLOAD GT PUSH1 done JUMPI             ; threshold not reached
+//	    PUSH1 1 SLOAD PUSH1 1 ADD PUSH1 1 SSTORE      ; nonce++
+//	    PUSH1 96 CALLDATASIZE SUB DUP1 PUSH1 96 PUSH1 0 CALLDATACOPY
+//	    PUSH1 0 PUSH1 0 DUP3 PUSH1 0 PUSH1 32 CALLDATALOAD PUSH1 0 CALLDATALOAD GAS CALL
+//	    ISZERO PUSH1 fail JUMPI
+//	    STOP
+//	done:
+//	    STOP
+//	fail:
+//	    PUSH1 0 DUP1 REVERT
+var multisigCode = hexutil.MustDecode("0x36600557005b335415607657366060116076576001546040351415607657366000600037366000208060005233602052604060002080546076576001905580546001018082556000541160745760015460010160015560603603806060600037600060008260006020356000355af115607657005b005b600080fd")
+
+// Storage slots of the multisig contract.
+var (
+	multisigThresholdSlot = common.BigToHash(big.NewInt(0))
+	multisigNonceSlot     = common.BigToHash(big.NewInt(1))
+)
+
+// defaultMultisigAddress is the address the governance multisig is deployed at
+// unless the user picks a different one.
+var defaultMultisigAddress = common.BytesToAddress([]byte{0x0f, 0xff, 0x00, 0x01})
+
+// governance is the chain-level admin role assignment made when bootstrapping a
+// multisig into the genesis.
+type governance struct {
+	Multisig     common.Address   `json:"multisig"`     // Address of the governance multisig
+	Owners       []common.Address `json:"owners"`       // Owners allowed to confirm transactions
+	Threshold    uint64           `json:"threshold"`    // Confirmations needed to execute
+	FeeRecipient common.Address   `json:"feeRecipient"` // Account receiving block rewards and fees
+}
+
+// multisigAccount compiles the owners and threshold into a genesis account of
+// the multisig governance contract.
+func multisigAccount(owners []common.Address, threshold uint64) (core.GenesisAccount, error) {
+	if len(owners) == 0 {
+		return core.GenesisAccount{}, errors.New("no multisig owners")
+	}
+	if threshold == 0 || threshold > uint64(len(owners)) {
+		return core.GenesisAccount{}, fmt.Errorf("invalid threshold %d for %d owners", threshold, len(owners))
+	}
+	storage := map[common.Hash]common.Hash{
+		multisigThresholdSlot: common.BigToHash(new(big.Int).SetUint64(threshold)),
+	}
+	for _, owner := range owners {
+		slot := owner.Hash()
+		if slot == multisigThresholdSlot || slot == multisigNonceSlot {
+			return core.GenesisAccount{}, fmt.Errorf("invalid multisig owner %v", owner)
+		}
+		if _, ok := storage[slot]; ok {
+			return core.GenesisAccount{}, fmt.Errorf("duplicate multisig owner %v", owner)
+		}
+		storage[slot] = common.BigToHash(big.NewInt(1))
+	}
+	return core.GenesisAccount{
+		Code:    common.CopyBytes(multisigCode),
+		Balance: new(big.Int),
+		Storage: storage,
+	}, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// Tests that the multisig owners and threshold are compiled into the expected
+// contract storage.
+func TestMultisigAccount(t *testing.T) {
+	owners := []common.Address{
+		common.BytesToAddress(common.FromHex("0x0d023dfc9c025e263d974985f3367d99f91e071b")),
+		common.BytesToAddress(common.FromHex("0x9a1c3dfc9c025e263d974985f3367d99f91e0abc")),
+		common.BytesToAddress(common.FromHex("0x45dea0fb0bba44f4fcf290bba71fd57d7117cbb8")),
+	}
+	account, err := multisigAccount(owners, 2)
+	if err != nil {
+		t.Fatalf("failed to compile multisig: %v", err)
+	}
+	if !bytes.Equal(account.Code, multisigCode) {
+		t.Errorf("code mismatch")
+	}
+	if have := account.Storage[multisigThresholdSlot].Big(); have.Cmp(big.NewInt(2)) != 0 {
+		t.Errorf("threshold mismatch: have %v, want 2", have)
+	}
+	if _, ok := account.Storage[multisigNonceSlot]; ok {
+		t.Errorf("nonce unexpectedly initialised")
+	}
+	for _, owner := range owners {
+		if have := account.Storage[owner.Hash()].Big(); have.Cmp(big.NewInt(1)) != 0 {
+			t.Errorf("owner %v not registered", owner)
+		}
+	}
+	if len(account.Storage) != 1+len(owners) {
+		t.Errorf("storage size mismatch: have %d, want %d", len(account.Storage), 1+len(owners))
+	}
+}
+
+// Tests that invalid multisig configurations are rejected.
+func TestMultisigAccountInvalid(t *testing.T) {
+	owner := common.BytesToAddress(common.FromHex("0x0d023dfc9c025e263d974985f3367d99f91e071b"))
+
+	tests := []struct {
+		owners    []common.Address
+		threshold uint64
+	}{
+		{nil, 1},
+		{[]common.Address{owner}, 0},
+		{[]common.Address{owner}, 2},
+		{[]common.Address{owner, owner}, 1},
+		{[]common.Address{common.BytesToAddress([]byte{1})}, 1},
+	}
+	for i, tt := range tests {
+		if _, err := multisigAccount(tt.owners, tt.threshold); err == nil {
+			t.Errorf("test %d: invalid configuration accepted", i)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/consensus"
+	"github.com/liuguodong24-8/3fcoin/core/consensus/clique"
+	"github.com/liuguodong24-8/3fcoin/core/consensus/ethash"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+	"github.com/liuguodong24-8/3fcoin/core/core/rawdb"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/core/vm"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+const (
+	cliqueExtraVanity = 32                     // Bytes of signer vanity at the start of clique extra-data
+	cliqueExtraSeal   = crypto.SignatureLength // Bytes of signer seal at the end of clique extra-data
+
+	simSampleAccounts = 64 // Maximum number of allocated accounts a dry-run sends transactions from
+)
+
+// simReport is the outcome of a dry-run of a genesis block.
+type simReport struct {
+	Blocks   int      // Number of blocks sealed and imported
+	Txs      int      // Number of transactions included in the blocks
+	GasUsed  uint64   // Gas used by the imported blocks
+	Accounts int      // Number of allocated accounts transactions were executed from
+	Failures []string // Problems found, empty if the genesis is sound
+}
+
+func (r *simReport) fail(format string, args ...interface{}) {
+	r.Failures = append(r.Failures, fmt.Sprintf(format, args...))
+}
+
+// checkGenesis statically checks a genesis block for configurations nodes would
+// refuse or choke on.
+func checkGenesis(genesis *core.Genesis, report *simReport) {
+	config := genesis.Config
+	if config == nil {
+		report.fail("missing chain configuration")
+		return
+	}
+	if config.ChainID == nil {
+		report.fail("missing chain ID")
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		report.fail("invalid fork ordering: %v", err)
+	}
+	if genesis.GasLimit < params.MinGasLimit {
+		report.fail("gas limit %d below the minimum of %d", genesis.GasLimit, params.MinGasLimit)
+	}
+	switch {
+	case config.Clique != nil:
+		extra := len(genesis.ExtraData)
+		switch {
+		case extra < cliqueExtraVanity+cliqueExtraSeal:
+			report.fail("extra-data of %d bytes lacks the clique vanity and seal", extra)
+		case (extra-cliqueExtraVanity-cliqueExtraSeal)%common.AddressLength != 0:
+			report.fail("extra-data signer list of %d bytes isn't a list of addresses", extra-cliqueExtraVanity-cliqueExtraSeal)
+		case extra == cliqueExtraVanity+cliqueExtraSeal:
+			report.fail("extra-data lists no clique signers")
+		}
+	case config.Ethash != nil:
+		if genesis.Difficulty == nil || genesis.Difficulty.Sign() <= 0 {
+			report.fail("non-positive proof-of-work difficulty")
+		}
+	default:
+		report.fail("no consensus engine configured")
+	}
+}
+
+// simulateGenesis dry-runs a genesis block: it's committed into an in-memory
+// database, the given number of blocks are sealed on top of it and imported,
+// after which a transfer is executed from a sample of the allocated accounts.
+//
+// As the keys of the configured clique signers and allocated accounts aren't
+// known, blocks are sealed by an ephemeral signer replacing the configured ones
+// and the transfers of allocated accounts are executed as unsigned messages.
+func simulateGenesis(genesis *core.Genesis, blocks int, samples int) (report *simReport) {
+	report = new(simReport)
+	if checkGenesis(genesis, report); genesis.Config == nil || genesis.Config.ChainID == nil {
+		return report
+	}
+	// Block generation panics on invalid blocks, report them as failures
+	defer func() {
+		if r := recover(); r != nil {
+			report.fail("block generation failed: %v", r)
+		}
+	}()
+	key, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(key.PublicKey)
+
+	sim := simGenesis(genesis, signer, blocks)
+	db := rawdb.NewMemoryDatabase()
+	parent, err := sim.Commit(db)
+	if err != nil {
+		report.fail("genesis commit failed: %v", err)
+		return report
+	}
+	var engine consensus.Engine
+	if sim.Config.Clique != nil {
+		engine = clique.New(sim.Config.Clique, db)
+	} else {
+		engine = ethash.NewFaker()
+	}
+	chain, err := core.NewBlockChain(db, nil, sim.Config, engine, vm.Config{}, nil, nil)
+	if err != nil {
+		report.fail("blockchain creation failed: %v", err)
+		return report
+	}
+	defer chain.Stop()
+
+	// Seal the blocks, each with a transaction touching one of the accounts
+	var (
+		txSigner = types.LatestSigner(sim.Config)
+		gasPrice = simGasPrice(sim.Config)
+		accounts = sampleAccounts(genesis.Alloc, samples)
+	)
+	generated, _ := core.GenerateChain(sim.Config, parent, engine, db, blocks, func(i int, b *core.BlockGen) {
+		if sim.Config.Clique != nil {
+			b.OffsetTime(int64(cliqueBlockTime(sim.Config.Clique)) - 10)
+			b.SetDifficulty(big.NewInt(2)) // A sole signer is always in-turn
+		}
+		to := signer
+		if len(accounts) > 0 {
+			to = accounts[i%len(accounts)]
+		}
+		tx, err := types.SignTx(types.NewTransaction(b.TxNonce(signer), to, new(big.Int), params.TxGas, gasPrice, nil), txSigner, key)
+		if err != nil {
+			panic(err)
+		}
+		b.AddTx(tx)
+	})
+	if sim.Config.Clique != nil {
+		epoch := sim.Config.Clique.Epoch
+		if epoch == 0 {
+			epoch = 30000 // Default of the clique engine
+		}
+		for i, block := range generated {
+			header := block.Header()
+			if i > 0 {
+				header.ParentHash = generated[i-1].Hash()
+			}
+			header.Extra = make([]byte, cliqueExtraVanity+cliqueExtraSeal)
+			if header.Number.Uint64()%epoch == 0 {
+				header.Extra = append(append(header.Extra[:cliqueExtraVanity], signer[:]...), make([]byte, cliqueExtraSeal)...)
+			}
+			sig, err := crypto.Sign(clique.SealHash(header).Bytes(), key)
+			if err != nil {
+				report.fail("block %d sealing failed: %v", header.Number, err)
+				return report
+			}
+			copy(header.Extra[len(header.Extra)-cliqueExtraSeal:], sig)
+			generated[i] = block.WithSeal(header)
+		}
+	}
+	if n, err := chain.InsertChain(generated); err != nil {
+		report.fail("block %d rejected: %v", n+1, err)
+		return report
+	}
+	for _, block := range generated {
+		report.Blocks++
+		report.Txs += len(block.Transactions())
+		report.GasUsed += block.GasUsed()
+	}
+	// Execute a transfer from each sampled account on top of the chain
+	head := chain.CurrentBlock().Header()
+	statedb, err := chain.State()
+	if err != nil {
+		report.fail("state retrieval failed: %v", err)
+		return report
+	}
+	for _, addr := range accounts {
+		msg := types.NewMessage(addr, &signer, 0, big.NewInt(1), params.TxGas, gasPrice, nil, nil, false)
+		evm := vm.NewEVM(core.NewEVMBlockContext(head, chain, nil), core.NewEVMTxContext(msg), statedb, sim.Config, vm.Config{})
+
+		result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+		switch {
+		case err != nil:
+			report.fail("transfer from %s failed: %v", common.AddressFormatHex.Address(addr), err)
+		case result.Failed():
+			report.fail("transfer from %s reverted: %v", common.AddressFormatHex.Address(addr), result.Err)
+		}
+		report.Accounts++
+	}
+	return report
+}
+
+// simGenesis derives the genesis block a dry-run is executed on: the signer is
+// funded and made the sole clique signer, and the timestamp is moved back so the
+// sealed blocks aren't in the future.
+func simGenesis(genesis *core.Genesis, signer common.Address, blocks int) *core.Genesis {
+	sim := *genesis
+	sim.Alloc = make(core.GenesisAlloc, len(genesis.Alloc)+1)
+	for addr, account := range genesis.Alloc {
+		sim.Alloc[addr] = account
+	}
+	sim.Alloc[signer] = core.GenesisAccount{Balance: new(big.Int).Lsh(big.NewInt(1), 128)}
+
+	step := uint64(10)
+	if sim.Config.Clique != nil {
+		step = cliqueBlockTime(sim.Config.Clique)
+
+		sim.ExtraData = make([]byte, cliqueExtraVanity+common.AddressLength+cliqueExtraSeal)
+		copy(sim.ExtraData[cliqueExtraVanity:], signer[:])
+	}
+	now := uint64(time.Now().Unix())
+	if end := sim.Timestamp + uint64(blocks)*step; end > now {
+		if sim.Timestamp > end-now {
+			sim.Timestamp -= end - now
+		} else {
+			sim.Timestamp = 0
+		}
+	}
+	return &sim
+}
+
+// cliqueBlockTime returns the time between the blocks sealed by a dry-run, the
+// configured period but at least a second.
+func cliqueBlockTime(config *params.CliqueConfig) uint64 {
+	if config.Period == 0 {
+		return 1
+	}
+	return config.Period
+}
+
+// simGasPrice returns the gas price of the transactions of a dry-run, the lowest
+// accepted by the nodes if configured.
+func simGasPrice(config *params.ChainConfig) *big.Int {
+	if config.GasSchedule != nil && config.GasSchedule.MinGasPrice != nil {
+		return new(big.Int).Set(config.GasSchedule.MinGasPrice)
+	}
+	return big.NewInt(params.GWei)
+}
+
+// sampleAccounts returns up to limit of the allocated externally owned accounts,
+// in address order. The precompile addresses pre-funded with a single wei are
+// skipped, as are contracts.
+func sampleAccounts(alloc core.GenesisAlloc, limit int) []common.Address {
+	var accounts []common.Address
+	for addr, account := range alloc {
+		if len(account.Code) > 0 || new(big.Int).SetBytes(addr[:]).Cmp(big.NewInt(0xff)) <= 0 {
+			continue
+		}
+		accounts = append(accounts, addr)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return string(accounts[i][:]) < string(accounts[j][:])
+	})
+	if len(accounts) > limit {
+		accounts = accounts[:limit]
+	}
+	return accounts
+}
@@ -0,0 +1,104 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+// simTestGenesis creates a genesis of the given consensus engine with a funded
+// and an unfunded account.
+func simTestGenesis(engine string) *core.Genesis {
+	genesis := &core.Genesis{
+		Timestamp:  1600000000,
+		GasLimit:   4700000,
+		Difficulty: big.NewInt(131072),
+		Alloc: core.GenesisAlloc{
+			common.BytesToAddress([]byte{0x01}):       {Balance: big.NewInt(1)}, // Precompile, skipped
+			common.BytesToAddress([]byte{0x10, 0x00}): {Balance: big.NewInt(params.Ether)},
+		},
+		Config: &params.ChainConfig{
+			ChainID:             big.NewInt(1337),
+			HomesteadBlock:      big.NewInt(0),
+			EIP150Block:         big.NewInt(0),
+			EIP155Block:         big.NewInt(0),
+			EIP158Block:         big.NewInt(0),
+			ByzantiumBlock:      big.NewInt(0),
+			ConstantinopleBlock: big.NewInt(0),
+			PetersburgBlock:     big.NewInt(0),
+			IstanbulBlock:       big.NewInt(0),
+		},
+	}
+	switch engine {
+	case "ethash":
+		genesis.Config.Ethash = new(params.EthashConfig)
+		genesis.ExtraData = make([]byte, 32)
+	case "clique":
+		genesis.Difficulty = big.NewInt(1)
+		genesis.Config.Clique = &params.CliqueConfig{Period: 5, Epoch: 30000}
+		genesis.ExtraData = make([]byte, cliqueExtraVanity+common.AddressLength+cliqueExtraSeal)
+		genesis.ExtraData[cliqueExtraVanity] = 0xaa
+	}
+	return genesis
+}
+
+// Tests that sound genesis blocks pass a dry-run with both consensus engines.
+func TestSimulateGenesis(t *testing.T) {
+	for _, engine := range []string{"ethash", "clique"} {
+		report := simulateGenesis(simTestGenesis(engine), 8, simSampleAccounts)
+		if len(report.Failures) > 0 {
+			t.Errorf("%s: dry-run failed: %v", engine, report.Failures)
+			continue
+		}
+		if report.Blocks != 8 || report.Txs != 8 || report.GasUsed != 8*params.TxGas || report.Accounts != 1 {
+			t.Errorf("%s: report mismatch: %+v", engine, report)
+		}
+	}
+}
+
+// Tests that broken genesis blocks are caught by a dry-run.
+func TestSimulateGenesisFailures(t *testing.T) {
+	tests := []struct {
+		engine string
+		mutate func(*core.Genesis)
+		want   string
+	}{
+		{"ethash", func(g *core.Genesis) { g.GasLimit = 0 }, "gas limit"},
+		{"clique", func(g *core.Genesis) { g.ExtraData = make([]byte, 32) }, "vanity and seal"},
+		{"clique", func(g *core.Genesis) { g.ExtraData = make([]byte, cliqueExtraVanity+cliqueExtraSeal+3) }, "signer list"},
+		{"clique", func(g *core.Genesis) { g.ExtraData = make([]byte, cliqueExtraVanity+cliqueExtraSeal) }, "no clique signers"},
+		{"ethash", func(g *core.Genesis) {
+			g.Alloc[common.BytesToAddress([]byte{0x20, 0x00})] = core.GenesisAccount{Balance: big.NewInt(1000)}
+		}, "transfer from"},
+		{"ethash", func(g *core.Genesis) { g.Config.ChainID = nil }, "chain ID"},
+	}
+	for i, tt := range tests {
+		genesis := simTestGenesis(tt.engine)
+		tt.mutate(genesis)
+
+		report := simulateGenesis(genesis, 4, simSampleAccounts)
+		if !strings.Contains(strings.Join(report.Failures, "\n"), tt.want) {
+			t.Errorf("test %d: failures %v lack %q", i, report.Failures, tt.want)
+		}
+	}
+}
@@ -243,6 +243,21 @@ func (client *sshClient) Stream(cmd string) error {
 	return session.Run(cmd)
 }
 
+// Download executes a command on the remote server and returns its standard
+// output only, keeping binary content free of any diagnostic noise.
+func (client *sshClient) Download(cmd string) ([]byte, error) {
+	// Establish a single command session
+	session, err := client.client.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	// Execute the command and return the output stream
+	client.logger.Trace("Downloading command output from server", "cmd", cmd)
+	return session.Output(cmd)
+}
+
 // Upload copies the set of files to a remote server via SCP, creating any non-
 // existing folders in the mean time.
 func (client *sshClient) Upload(files map[string][]byte) ([]byte, error) {
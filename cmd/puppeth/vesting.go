@@ -0,0 +1,188 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/core"
+)
+
+// vestingCode is the runtime bytecode of the linear vesting contract placed in
+// the genesis alloc. Any call without value releases the vested but not yet paid
+// out part of the contract balance to the beneficiary, calls carrying value are
+// accepted as deposits. The storage layout is:
+//
+//	slot 0: beneficiary address
+//	slot 1: vesting start (unix seconds)
+//	slot 2: cliff (unix seconds), nothing is released before
+//	slot 3: vesting duration in seconds, measured from the start
+//	slot 4: amount already released
+//
+// Assembly:
+//
+//	    CALLVALUE PUSH1 done JUMPI
+//	    PUSH1 2 SLOAD TIMESTAMP LT PUSH1 fail JUMPI   ; now < cliff
+//	    PUSH1 4 SLOAD ADDRESS BALANCE ADD             ; total = balance + released
+//	    PUSH1 1 SLOAD TIMESTAMP SUB                   ; elapsed = now - start
+//	    PUSH1 3 SLOAD DUP1 DUP3 LT PUSH1 partial JUMPI
+//	    POP POP PUSH1 payout JUMP                     ; vested = total
+//	partial:
+//	    SWAP2 MUL DIV                                 ; vested = total * elapsed / duration
+//	payout:
+//	    PUSH1 4 SLOAD SWAP1 SUB                       ; amount = vested - released
+//	    DUP1 ISZERO PUSH1 done JUMPI
+//	    DUP1 PUSH1 4 SLOAD ADD PUSH1 4 SSTORE         ; released += amount
+//	    PUSH1 0 PUSH1 0 PUSH1 0 PUSH1 0 DUP5 PUSH1 0 SLOAD GAS CALL
+//	    ISZERO PUSH1 fail JUMPI
+//	done:
+//	    STOP
+//	fail:
+//	    PUSH1 0 DUP1 REVERT
+var vestingCode = hexutil.MustDecode("0x34604e576002544210605057600454303101600154420360035480821060255750506029565b9102045b60045490038015604e5780600454016004556000600060006000846000545af1156050575b005b600080fd")
+
+// Storage slots of the vesting contract.
+var (
+	vestingBeneficiarySlot = common.BigToHash(big.NewInt(0))
+	vestingStartSlot       = common.BigToHash(big.NewInt(1))
+	vestingCliffSlot       = common.BigToHash(big.NewInt(2))
+	vestingDurationSlot    = common.BigToHash(big.NewInt(3))
+)
+
+// maxVestingBalance caps the balance of a vesting contract so that the vested
+// amount computation (balance * elapsed seconds) can never overflow 256 bits.
+var maxVestingBalance = new(big.Int).Lsh(big.NewInt(1), 128)
+
+// vestingTerms describes the release schedule of a genesis vesting contract.
+type vestingTerms struct {
+	Beneficiary common.Address
+	Cliff       time.Duration // Delay after the genesis before anything is released
+	Duration    time.Duration // Total time after the genesis until fully vested
+}
+
+// account compiles the vesting terms into a genesis account holding balance,
+// with the release schedule anchored at the genesis timestamp start.
+func (v *vestingTerms) account(balance *big.Int, start uint64) core.GenesisAccount {
+	var (
+		cliff    = start + uint64(v.Cliff/time.Second)
+		duration = uint64(v.Duration / time.Second)
+	)
+	return core.GenesisAccount{
+		Code:    common.CopyBytes(vestingCode),
+		Balance: balance,
+		Storage: map[common.Hash]common.Hash{
+			vestingBeneficiarySlot: v.Beneficiary.Hash(),
+			vestingStartSlot:       common.BigToHash(new(big.Int).SetUint64(start)),
+			vestingCliffSlot:       common.BigToHash(new(big.Int).SetUint64(cliff)),
+			vestingDurationSlot:    common.BigToHash(new(big.Int).SetUint64(duration)),
+		},
+	}
+}
+
+// parseAllocCSV reads genesis allocations from a CSV stream. Each record is of
+// the form
+//
+//	address,balance[,beneficiary,cliff,duration]
+//
+// where addresses are FFF or hex encoded, balances are in wei and the cliff and
+// duration are Go durations (e.g. 8760h). Records with vesting terms are compiled
+// into vesting contracts anchored at the genesis timestamp start.
+func parseAllocCSV(r io.Reader, start uint64) (core.GenesisAlloc, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	alloc := make(core.GenesisAlloc)
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) != 2 && len(record) != 5 {
+			return nil, fmt.Errorf("line %d: expected 2 or 5 fields, have %d", line, len(record))
+		}
+		address, err := parseAllocAddress(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid address: %v", line, err)
+		}
+		if _, exists := alloc[address]; exists {
+			return nil, fmt.Errorf("line %d: duplicate allocation for %v", line, address)
+		}
+		balance, ok := new(big.Int).SetString(strings.TrimSpace(record[1]), 10)
+		if !ok || balance.Sign() < 0 {
+			return nil, fmt.Errorf("line %d: invalid balance %q", line, record[1])
+		}
+		if len(record) == 2 {
+			alloc[address] = core.GenesisAccount{Balance: balance}
+			continue
+		}
+		terms, err := parseVestingTerms(record[2:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if balance.Cmp(maxVestingBalance) >= 0 {
+			return nil, fmt.Errorf("line %d: vesting balance %v too large", line, balance)
+		}
+		alloc[address] = terms.account(balance, start)
+	}
+	return alloc, nil
+}
+
+// parseVestingTerms parses the beneficiary, cliff and duration fields of an
+// allocation record.
+func parseVestingTerms(fields []string) (*vestingTerms, error) {
+	beneficiary, err := parseAllocAddress(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid beneficiary: %v", err)
+	}
+	cliff, err := time.ParseDuration(strings.TrimSpace(fields[1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid cliff: %v", err)
+	}
+	duration, err := time.ParseDuration(strings.TrimSpace(fields[2]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid duration: %v", err)
+	}
+	switch {
+	case cliff < 0:
+		return nil, errors.New("negative cliff")
+	case duration < time.Second:
+		return nil, errors.New("vesting duration must be at least a second")
+	case cliff > duration:
+		return nil, errors.New("cliff exceeds vesting duration")
+	}
+	return &vestingTerms{Beneficiary: beneficiary, Cliff: cliff, Duration: duration}, nil
+}
+
+// parseAllocAddress converts an FFF or hex encoded address into its binary form.
+func parseAllocAddress(s string) (common.Address, error) {
+	var address common.Address
+	err := address.UnmarshalText([]byte(strings.TrimSpace(s)))
+	return address, err
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// Tests that vesting terms in an allocation CSV are compiled into vesting
+// contracts with the correct storage layout.
+func TestAllocCSVVesting(t *testing.T) {
+	input := `# address,balance,beneficiary,cliff,duration
+0x0000000000000000000000000000000000000001,1000
+0x0000000000000000000000000000000000000002,5000,0x00000000000000000000000000000000000000ff,1h,10h
+`
+	alloc, err := parseAllocCSV(strings.NewReader(input), 1000)
+	if err != nil {
+		t.Fatalf("failed to parse allocations: %v", err)
+	}
+	if len(alloc) != 2 {
+		t.Fatalf("allocation count mismatch: have %d, want 2", len(alloc))
+	}
+	plain := alloc[common.BytesToAddress([]byte{1})]
+	if plain.Balance.Cmp(big.NewInt(1000)) != 0 || len(plain.Code) != 0 {
+		t.Errorf("plain allocation mismatch: balance %v, code %x", plain.Balance, plain.Code)
+	}
+	vesting := alloc[common.BytesToAddress([]byte{2})]
+	if !bytes.Equal(vesting.Code, vestingCode) {
+		t.Errorf("vesting code mismatch: have %x", vesting.Code)
+	}
+	want := map[common.Hash]common.Hash{
+		vestingBeneficiarySlot: common.BytesToHash([]byte{0xff}),
+		vestingStartSlot:       common.BigToHash(big.NewInt(1000)),
+		vestingCliffSlot:       common.BigToHash(big.NewInt(1000 + 3600)),
+		vestingDurationSlot:    common.BigToHash(big.NewInt(36000)),
+	}
+	for slot, value := range want {
+		if have := vesting.Storage[slot]; have != value {
+			t.Errorf("slot %x mismatch: have %x, want %x", slot, have, value)
+		}
+	}
+}
+
+// Tests that malformed allocation records are rejected.
+func TestAllocCSVInvalid(t *testing.T) {
+	tests := []string{
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000001,-1",
+		"0x0000000000000000000000000000000000000001,ten",
+		"0x0000000000000000000000000000000000000001,1\n0x0000000000000000000000000000000000000001,2",
+		"0x0000000000000000000000000000000000000001,1,0x00000000000000000000000000000000000000ff,10h,1h",
+		"0x0000000000000000000000000000000000000001,1,0x00000000000000000000000000000000000000ff,0s,0s",
+		"0x0000000000000000000000000000000000000001,1,0x00000000000000000000000000000000000000ff,1y,2y",
+	}
+	for i, input := range tests {
+		if _, err := parseAllocCSV(strings.NewReader(input), 0); err == nil {
+			t.Errorf("test %d: expected error for %q", i, input)
+		}
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/liuguodong24-8/3fcoin/core/common"
 	"github.com/liuguodong24-8/3fcoin/core/core"
@@ -44,8 +45,13 @@ type config struct {
 	bootnodes []string // Bootnodes to always connect to by all nodes
 	ethstats  string   // Ethstats settings to cache for node deploys
 
-	Genesis *core.Genesis     `json:"genesis,omitempty"` // Genesis block to cache for node deploys
-	Servers map[string][]byte `json:"servers,omitempty"`
+	Genesis    *core.Genesis     `json:"genesis,omitempty"`    // Genesis block to cache for node deploys
+	Governance *governance       `json:"governance,omitempty"` // Admin roles assigned to the genesis multisig
+	Fork       *forkPlan         `json:"fork,omitempty"`       // Hard fork scheduled on the network
+	DNS        *dnsTree          `json:"dns,omitempty"`        // DNS discovery tree of the network nodes
+	RPCAuth    *rpcAuthority     `json:"rpcauth,omitempty"`    // Certificate authority of the node RPC endpoints
+	Seed       string            `json:"seed,omitempty"`       // Manifest of the state-sync seed new nodes import
+	Servers    map[string][]byte `json:"servers,omitempty"`
 }
 
 // servers retrieves an alphabetically sorted list of servers.
@@ -64,9 +70,11 @@ func (c config) flush() {
 	os.MkdirAll(filepath.Dir(c.path), 0755)
 
 	out, _ := json.MarshalIndent(c, "", "  ")
-	if err := ioutil.WriteFile(c.path, out, 0644); err != nil {
+	// The configs hold the RPC CA key, keep them private
+	if err := ioutil.WriteFile(c.path, out, 0600); err != nil {
 		log.Warn("Failed to save puppeth configs", "file", c.path, "err", err)
 	}
+	os.Chmod(c.path, 0600)
 }
 
 type wizard struct {
@@ -203,6 +211,32 @@ func (w *wizard) readDefaultInt(def int) int {
 	}
 }
 
+// readDefaultDuration reads a single line from stdin, trimming if from spaces,
+// enforcing it to parse into a non-negative duration. If an empty line is
+// entered, the default value is returned.
+func (w *wizard) readDefaultDuration(def time.Duration) time.Duration {
+	for {
+		fmt.Printf("> ")
+		text, err := w.in.ReadString('\n')
+		if err != nil {
+			log.Crit("Failed to read user input", "err", err)
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			return def
+		}
+		val, err := time.ParseDuration(text)
+		if err != nil {
+			log.Error("Invalid input, expected duration", "err", err)
+			continue
+		}
+		if val < 0 {
+			log.Error("Invalid input, expected non-negative duration")
+			continue
+		}
+		return val
+	}
+}
+
 // readDefaultBigInt reads a single line from stdin, trimming if from spaces,
 // enforcing it to parse into a big integer. If an empty line is entered, the
 // default value is returned.
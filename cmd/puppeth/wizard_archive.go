@@ -0,0 +1,112 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strconv"
+
+	"github.com/liuguodong24-8/3fcoin/core/core"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/olekukonko/tablewriter"
+)
+
+// archive returns the spec archive of the managed network, stored next to the
+// puppeth config file.
+func (w *wizard) archive() *specArchive {
+	return newSpecArchive(w.conf.path + "-archive")
+}
+
+// archiveSpec records an update or export of the genesis spec in the archive.
+// Failures are only reported, the wizard action itself already succeeded.
+func (w *wizard) archiveSpec(action string, genesis *core.Genesis) {
+	operator := "unknown"
+	if current, err := user.Current(); err == nil {
+		operator = current.Username
+	}
+	entry, err := w.archive().record(action, operator, genesis)
+	if err != nil {
+		log.Warn("Failed to archive genesis spec", "action", action, "err", err)
+		return
+	}
+	log.Debug("Archived genesis spec", "action", action, "hash", entry.Hash, "changes", len(entry.Changes))
+}
+
+// manageArchive displays the spec changelog or reproduces past specs.
+func (w *wizard) manageArchive() {
+	fmt.Println()
+	fmt.Println(" 1. Show spec changelog")
+	fmt.Println(" 2. Export spec active at a past block")
+
+	switch w.read() {
+	case "1":
+		entries, err := w.archive().changelog()
+		if err != nil {
+			log.Error("Failed to load spec changelog", "err", err)
+			return
+		}
+		table := tablewriter.NewWriter(os.Stdout)
+		table.SetHeader([]string{"Time", "User", "Action", "Spec", "Activation", "Changes"})
+		table.SetAlignment(tablewriter.ALIGN_LEFT)
+		table.SetAutoWrapText(false)
+		for _, entry := range entries {
+			var changes string
+			for i, change := range entry.Changes {
+				if i > 0 {
+					changes += "\n"
+				}
+				changes += fmt.Sprintf("%s: %v -> %v", change.Fork, change.Old, change.New)
+			}
+			hash := entry.Hash
+			if len(hash) > 16 {
+				hash = hash[:16]
+			}
+			table.Append([]string{entry.Time.Format("2006-01-02 15:04:05"), entry.User, entry.Action, hash, strconv.FormatUint(entry.Activation, 10), changes})
+		}
+		table.Render()
+
+	case "2":
+		fmt.Println()
+		fmt.Println("Which block should the spec be reproduced for?")
+		number := w.readInt()
+
+		entry, genesis, err := w.archive().specAt(uint64(number))
+		if err != nil {
+			log.Error("Failed to reproduce spec", "number", number, "err", err)
+			return
+		}
+		log.Info("Found active spec", "number", number, "hash", entry.Hash, "action", entry.Action, "user", entry.User, "time", entry.Time)
+
+		fmt.Println()
+		fmt.Printf("Which file to save the spec into? (default = %s-%d.json)\n", w.network, number)
+		path := w.readDefaultString(fmt.Sprintf("%s-%d.json", w.network, number))
+
+		out, _ := json.MarshalIndent(genesis, "", "  ")
+		if err := ioutil.WriteFile(path, out, 0644); err != nil {
+			log.Error("Failed to save spec", "path", path, "err", err)
+			return
+		}
+		log.Info("Saved archived spec", "path", path)
+
+	default:
+		log.Error("That's not something I can do")
+	}
+}
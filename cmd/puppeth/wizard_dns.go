@@ -0,0 +1,172 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/liuguodong24-8/3fcoin/core/p2p/enode"
+)
+
+// publishDNS builds an EIP-1459 discovery tree out of the boot and seal nodes
+// of the network, signs it and publishes it as zone file records or directly
+// into a Cloudflare zone, so nodes can bootstrap without hard coded bootnodes.
+func (w *wizard) publishDNS() {
+	// Gather the signed records of all the running nodes
+	servers := make([]string, 0, len(w.services))
+	for server := range w.services {
+		servers = append(servers, server)
+	}
+	sort.Strings(servers)
+
+	var nodes []*enode.Node
+	for _, server := range servers {
+		for _, service := range w.services[server] {
+			if service != "bootnode" && service != "sealnode" {
+				continue
+			}
+			node, err := queryNodeRecord(w.servers[server], w.network, service)
+			if err != nil {
+				log.Warn("Failed to retrieve node record", "server", server, "service", service, "err", err)
+				continue
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	log.Info("Collected records of network nodes", "count", len(nodes))
+
+	fmt.Println()
+	fmt.Println("Any other node records to include? (enr:..., empty line to finish)")
+	for {
+		text := w.readDefaultString("")
+		if text == "" {
+			break
+		}
+		node, err := enode.Parse(enode.ValidSchemes, text)
+		if err != nil {
+			log.Error("Invalid node record", "err", err)
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	// Figure out where to publish the tree, reusing the previous settings
+	tree := new(dnsTree)
+	if w.conf.DNS != nil {
+		*tree = *w.conf.DNS
+	}
+	fmt.Println()
+	if tree.Domain == "" {
+		fmt.Println("Which domain should the tree be published at? (e.g. nodes.example.org)")
+		tree.Domain = w.readString()
+	} else {
+		fmt.Printf("Which domain should the tree be published at? (default = %s)\n", tree.Domain)
+		tree.Domain = w.readDefaultString(tree.Domain)
+	}
+	tree.Domain = strings.ToLower(strings.TrimSuffix(tree.Domain, "."))
+
+	if len(tree.Links) > 0 {
+		fmt.Println()
+		fmt.Printf("Keep linking the trees %s (y/n)? (default = yes)\n", strings.Join(tree.Links, ", "))
+		if !w.readDefaultYesNo(true) {
+			tree.Links = nil
+		}
+	}
+	fmt.Println()
+	fmt.Println("Any other trees to link? (enrtree://..., empty line to finish)")
+	for {
+		text := w.readDefaultString("")
+		if text == "" {
+			break
+		}
+		tree.Links = append(tree.Links, text)
+	}
+	fmt.Println()
+	fmt.Printf("What sequence number should the tree have? (default = %d)\n", tree.Seq+1)
+	tree.Seq = uint(w.readDefaultInt(int(tree.Seq + 1)))
+
+	// Sign the tree with the key of the operator
+	fmt.Println()
+	fmt.Println("Please paste the tree signer's key JSON:")
+	keyJSON := w.readJSON()
+
+	fmt.Println()
+	fmt.Println("What's the unlock password for the account? (won't be echoed)")
+	key, err := keystore.DecryptKey([]byte(keyJSON), w.readPassword())
+	if err != nil {
+		log.Error("Failed to decrypt key with given password", "err", err)
+		return
+	}
+	signed, url, err := makeDNSTree(tree.Domain, tree.Seq, nodes, tree.Links, key.PrivateKey)
+	if err != nil {
+		log.Error("Failed to create DNS tree", "err", err)
+		return
+	}
+	records := signed.ToTXT(tree.Domain)
+
+	// Publish the tree records wherever the user wants them
+	fmt.Println()
+	fmt.Println("How should the tree be published? (default = zone file)")
+	fmt.Println(" 1. Zone file to import into the DNS provider")
+	fmt.Println(" 2. Push to Cloudflare")
+
+	switch w.read() {
+	case "", "1":
+		fmt.Println()
+		fmt.Printf("Which file to save the zone records into? (default = %s.zone)\n", w.network)
+		path := w.readDefaultString(fmt.Sprintf("%s.zone", w.network))
+
+		if err := ioutil.WriteFile(path, dnsZoneFile(tree.Domain, records), 0644); err != nil {
+			log.Error("Failed to save zone file", "file", path, "err", err)
+			return
+		}
+		log.Info("Saved zone file", "file", path, "records", len(records))
+
+	case "2":
+		fmt.Println()
+		fmt.Println("What's the Cloudflare API token? (won't be echoed)")
+		token := w.readPassword()
+
+		fmt.Println()
+		if tree.ZoneID == "" {
+			fmt.Println("What's the Cloudflare zone ID? (default = look up by domain)")
+		} else {
+			fmt.Printf("What's the Cloudflare zone ID? (default = %s)\n", tree.ZoneID)
+		}
+		zoneID := w.readDefaultString(tree.ZoneID)
+
+		if tree.ZoneID, err = deployCloudflare(token, zoneID, tree.Domain, records); err != nil {
+			log.Error("Failed to push tree to Cloudflare", "err", err)
+			return
+		}
+		log.Info("Pushed tree to Cloudflare", "zone", tree.ZoneID, "records", len(records))
+
+	default:
+		log.Error("That's not something I can do")
+		return
+	}
+	tree.URL = url
+	w.conf.DNS = tree
+	w.conf.flush()
+
+	fmt.Println()
+	fmt.Printf("Nodes can bootstrap from the tree via --discovery.dns %s\n", url)
+}
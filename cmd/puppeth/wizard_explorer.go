@@ -55,6 +55,7 @@ func (w *wizard) deployExplorer() {
 
 	infos.node.genesis, _ = json.MarshalIndent(w.conf.Genesis, "", "  ")
 	infos.node.network = w.conf.Genesis.Config.ChainID.Int64()
+	infos.prefix = w.conf.Genesis.Config.FFFAddressPrefix()
 
 	// Figure out which port to listen on
 	fmt.Println()
@@ -118,3 +119,16 @@ func (w *wizard) deployExplorer() {
 
 	w.networkStats()
 }
+
+// explorerDeployed reports whether a block explorer is known to run on any of
+// the servers.
+func (w *wizard) explorerDeployed() bool {
+	for _, services := range w.services {
+		for _, service := range services {
+			if service == "explorer" {
+				return true
+			}
+		}
+	}
+	return false
+}
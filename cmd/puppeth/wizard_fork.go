@@ -0,0 +1,272 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/core/forkid"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/olekukonko/tablewriter"
+)
+
+// forkNode is a node container running on one of the managed servers.
+type forkNode struct {
+	server    string
+	boot      bool
+	client    *sshClient
+	container string
+}
+
+// name returns a user friendly identifier of the node.
+func (node *forkNode) name() string {
+	if node.boot {
+		return node.server + " (bootnode)"
+	}
+	return node.server + " (sealnode)"
+}
+
+// forkNodes collects all the node containers running on the managed servers.
+func (w *wizard) forkNodes() []*forkNode {
+	var nodes []*forkNode
+	for _, server := range w.conf.servers() {
+		client := w.servers[server]
+		if client == nil {
+			continue
+		}
+		for _, kind := range []string{"sealnode", "bootnode"} {
+			if _, err := checkNode(client, w.network, kind == "bootnode"); err == nil {
+				nodes = append(nodes, &forkNode{
+					server:    server,
+					boot:      kind == "bootnode",
+					client:    client,
+					container: fmt.Sprintf("%s_%s_1", w.network, kind),
+				})
+			}
+		}
+	}
+	return nodes
+}
+
+// manageFork coordinates a scheduled hard fork of the managed network: picking
+// the fork blocks, rolling the new chain config out to all nodes and checking
+// that they are ready before the activation block.
+func (w *wizard) manageFork() {
+	fmt.Println()
+	if w.conf.Fork == nil {
+		fmt.Println(" 1. Schedule new hard fork")
+	} else {
+		for _, name := range w.conf.Fork.names() {
+			fmt.Printf("Scheduled %s at block %d\n", name, w.conf.Fork.Forks[name])
+		}
+		fmt.Println()
+		fmt.Println(" 1. Reschedule hard fork")
+		fmt.Println(" 2. Distribute chain config to nodes")
+		fmt.Println(" 3. Check nodes and create go/no-go report")
+		fmt.Println(" 4. Cancel scheduled hard fork")
+	}
+	choice := w.read()
+	switch {
+	case choice == "1":
+		w.scheduleFork()
+	case choice == "2" && w.conf.Fork != nil:
+		w.distributeFork()
+	case choice == "3" && w.conf.Fork != nil:
+		w.reportFork()
+	case choice == "4" && w.conf.Fork != nil:
+		w.conf.Fork = nil
+		w.conf.flush()
+		log.Info("Scheduled hard fork cancelled")
+	default:
+		log.Error("That's not something I can do")
+	}
+}
+
+// scheduleFork asks the user for the future fork blocks and stores the plan.
+func (w *wizard) scheduleFork() {
+	// Make sure no fork gets scheduled below the head of any node
+	var head uint64
+	for _, node := range w.forkNodes() {
+		number, err := queryNodeHead(node.client, node.container)
+		if err != nil {
+			log.Warn("Failed to retrieve node head", "node", node.name(), "err", err)
+			continue
+		}
+		if number > head {
+			head = number
+		}
+	}
+	log.Info("Current network head", "number", head)
+
+	forks := make(map[string]uint64)
+	for _, fork := range forkBlocks(w.conf.Genesis.Config) {
+		// Already activated forks can't be changed any more
+		if fork.Block != nil && fork.Block.Uint64() <= head {
+			continue
+		}
+		def := fork.Block
+		if w.conf.Fork != nil {
+			if block, ok := w.conf.Fork.Forks[fork.Name]; ok {
+				def = new(big.Int).SetUint64(block)
+			}
+		}
+		fmt.Println()
+		fmt.Printf("Which block should %s come into effect? (default = %v)\n", fork.Name, def)
+		block := w.readDefaultBigInt(def)
+		if block == nil || (fork.Block != nil && fork.Block.Cmp(block) == 0) {
+			continue
+		}
+		forks[fork.Name] = block.Uint64()
+	}
+	plan, err := newForkPlan(w.conf.Genesis.Config, forks, head)
+	if err != nil {
+		log.Error("Invalid hard fork schedule", "err", err)
+		return
+	}
+	w.conf.Fork = plan
+	w.conf.flush()
+
+	genesis := *w.conf.Genesis
+	genesis.Config = plan.Config
+	w.archiveSpec("schedule", &genesis)
+
+	out, _ := json.MarshalIndent(plan.Config, "", "  ")
+	fmt.Printf("Scheduled chain configuration:\n\n%s\n", out)
+	log.Info("Hard fork scheduled", "activation", plan.Activation, "remaining", plan.Activation-head)
+}
+
+// distributeFork redeploys all nodes with the scheduled chain config.
+func (w *wizard) distributeFork() {
+	genesis := *w.conf.Genesis
+	genesis.Config = w.conf.Fork.Config
+	spec, _ := json.MarshalIndent(&genesis, "", "  ")
+
+	// Prefer trying the new config on a few canaries before touching all nodes
+	fmt.Println()
+	fmt.Println("Roll the chain config out in stages, canary nodes first (y/n)? (default = yes)")
+	if w.readDefaultYesNo(true) {
+		hash := w.conf.Genesis.ToBlock(nil).Hash()
+		verify := func(node *forkNode) error {
+			info, err := queryNodeFork(node.client, node.container)
+			if err != nil {
+				return err
+			}
+			if report := checkNodeFork(w.conf.Fork, hash, node.name(), info); !report.Ready {
+				return errors.New(report.Problem)
+			}
+			return nil
+		}
+		if !w.rolloutNodes(w.forkNodes(), func(infos *nodeInfos) { infos.genesis = spec }, verify) {
+			return
+		}
+		w.finalizeFork()
+		return
+	}
+	failed := 0
+	for _, node := range w.forkNodes() {
+		infos, err := checkNode(node.client, w.network, node.boot)
+		if err != nil {
+			log.Error("Failed to retrieve node configuration", "node", node.name(), "err", err)
+			failed++
+			continue
+		}
+		infos.genesis = spec
+
+		log.Info("Updating node chain config", "node", node.name())
+		if out, err := deployNode(node.client, w.network, w.conf.bootnodes, infos, false); err != nil {
+			log.Error("Failed to redeploy node", "node", node.name(), "err", err)
+			if len(out) > 0 {
+				fmt.Printf("%s\n", out)
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		log.Error("Chain config not distributed to all nodes", "failed", failed)
+		return
+	}
+	w.finalizeFork()
+}
+
+// finalizeFork makes the scheduled chain config, now run by all nodes, the one
+// used for future deploys.
+func (w *wizard) finalizeFork() {
+	w.conf.Genesis.Config = w.conf.Fork.Config
+	w.conf.flush()
+	w.archiveSpec("distribute", w.conf.Genesis)
+
+	log.Info("Waiting for nodes to finish booting")
+	time.Sleep(3 * time.Second)
+	w.reportFork()
+}
+
+// reportFork checks the fork ID of every node against the scheduled one and
+// renders a go/no-go report.
+func (w *wizard) reportFork() {
+	genesis := w.conf.Genesis.ToBlock(nil).Hash()
+
+	var nodes []forkNodeReport
+	for _, node := range w.forkNodes() {
+		info, err := queryNodeFork(node.client, node.container)
+		if err != nil {
+			nodes = append(nodes, forkNodeReport{Node: node.name(), Problem: err.Error()})
+			continue
+		}
+		nodes = append(nodes, checkNodeFork(w.conf.Fork, genesis, node.name(), info))
+	}
+	report := newForkReport(w.network, w.conf.Fork, genesis, nodes)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Node", "Head", "Reported fork ID", "Expected fork ID", "Status"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, node := range report.Nodes {
+		status := "ready"
+		if !node.Ready {
+			status = node.Problem
+		}
+		table.Append([]string{node.Node, strconv.FormatUint(node.Head, 10), formatForkID(node.Reported), formatForkID(node.Expected), status})
+	}
+	table.Render()
+
+	if report.Go {
+		log.Info("Hard fork is a GO", "activation", report.Activation, "forkid", formatForkID(report.ForkID))
+	} else {
+		log.Error("Hard fork is a NO-GO", "activation", report.Activation, "nodes", len(report.Nodes))
+	}
+	fmt.Println()
+	fmt.Println("Which file to save the report into? (default = none)")
+	if path := w.readDefaultString(""); path != "" {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		if err := ioutil.WriteFile(path, out, 0644); err != nil {
+			log.Error("Failed to save fork report", "path", path, "err", err)
+			return
+		}
+		log.Info("Saved fork report", "path", path)
+	}
+}
+
+// formatForkID renders a fork ID as its checksum and next fork block.
+func formatForkID(id forkid.ID) string {
+	return fmt.Sprintf("%x/%d", id.Hash, id.Next)
+}
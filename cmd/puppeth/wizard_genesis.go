@@ -122,11 +122,31 @@ func (w *wizard) makeGenesis() {
 		break
 	}
 	fmt.Println()
+	fmt.Println("Where's the allocation CSV file, if any? (address,balance[,beneficiary,cliff,duration])")
+	if path := w.readDefaultString(""); path != "" {
+		w.importAllocCSV(genesis, path)
+	}
+	fmt.Println()
+	fmt.Println("Should a multisig governance contract be deployed? (default = no)")
+	if w.readDefaultYesNo(false) {
+		w.makeGovernance(genesis)
+	}
+	fmt.Println()
+	fmt.Println("Should the gas schedule be customized? (default = no)")
+	if w.readDefaultYesNo(false) {
+		w.makeGasSchedule(genesis)
+	}
+	fmt.Println()
 	fmt.Println("Should the precompile-addresses (0x1 .. 0xff) be pre-funded with 1 wei? (advisable yes)")
 	if w.readDefaultYesNo(true) {
-		// Add a batch of precompile balances to avoid them getting deleted
+		// Add a batch of precompile balances to avoid them getting deleted, leaving
+		// any account imported or deployed into the range above untouched
 		for i := int64(0); i < 256; i++ {
-			genesis.Alloc[common.BigToAddress(big.NewInt(i))] = core.GenesisAccount{Balance: big.NewInt(1)}
+			address := common.BigToAddress(big.NewInt(i))
+			if _, ok := genesis.Alloc[address]; ok {
+				continue
+			}
+			genesis.Alloc[address] = core.GenesisAccount{Balance: big.NewInt(1)}
 		}
 	}
 	// Query the user for some custom extras
@@ -139,6 +159,182 @@ func (w *wizard) makeGenesis() {
 
 	w.conf.Genesis = genesis
 	w.conf.flush()
+
+	w.archiveSpec("create", genesis)
+
+	fmt.Println()
+	fmt.Println("Dry-run the genesis by sealing a few blocks locally? (default = yes)")
+	if w.readDefaultYesNo(true) {
+		w.dryRunGenesis()
+	}
+}
+
+// dryRunGenesis seals and imports a number of blocks on top of the configured
+// genesis in memory, reporting any problems found.
+func (w *wizard) dryRunGenesis() {
+	fmt.Println()
+	fmt.Println("How many blocks should be sealed? (default = 16)")
+	blocks := w.readDefaultInt(16)
+
+	report := simulateGenesis(w.conf.Genesis, blocks, simSampleAccounts)
+	for _, failure := range report.Failures {
+		log.Error("Genesis dry-run failure", "err", failure)
+	}
+	if len(report.Failures) > 0 {
+		log.Error("Genesis dry-run failed, fix the configuration before deploying", "failures", len(report.Failures))
+		return
+	}
+	log.Info("Genesis dry-run passed", "blocks", report.Blocks, "txs", report.Txs, "gas", report.GasUsed, "accounts", report.Accounts)
+}
+
+// importAllocCSV loads the genesis allocations from a CSV file, compiling any
+// vesting terms into vesting contracts.
+func (w *wizard) importAllocCSV(genesis *core.Genesis, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Error("Failed to open allocation file", "err", err)
+		return
+	}
+	defer file.Close()
+
+	alloc, err := parseAllocCSV(file, genesis.Timestamp)
+	if err != nil {
+		log.Error("Invalid allocation file", "err", err)
+		return
+	}
+	vesting := 0
+	for address, account := range alloc {
+		if len(account.Code) > 0 {
+			vesting++
+		}
+		genesis.Alloc[address] = account
+	}
+	log.Info("Imported genesis allocations", "accounts", len(alloc), "vesting", vesting)
+}
+
+// makeGasSchedule queries the user for the chain specific gas parameters and
+// stores the ones deviating from the Ethereum defaults in the chain config.
+func (w *wizard) makeGasSchedule(genesis *core.Genesis) {
+	current := effectiveGasSchedule(genesis.Config.GasSchedule)
+	schedule := new(params.GasScheduleConfig)
+
+	for _, field := range []struct {
+		question string
+		current  uint64
+		def      uint64
+		dst      *uint64
+	}{
+		{"How much intrinsic gas should a transaction cost?", current.TxGas, params.TxGas, &schedule.TxGas},
+		{"How much intrinsic gas should a contract creation cost?", current.TxGasContractCreation, params.TxGasContractCreation, &schedule.TxGasContractCreation},
+		{"How much gas should a zero byte of transaction data cost?", current.TxDataZeroGas, params.TxDataZeroGas, &schedule.TxDataZeroGas},
+		{"How much gas should a non-zero byte of transaction data cost?", current.TxDataNonZeroGas, params.TxDataNonZeroGasEIP2028, &schedule.TxDataNonZeroGas},
+	} {
+		fmt.Println()
+		fmt.Printf("%s (default = %d)\n", field.question, field.current)
+		if value := uint64(w.readDefaultInt(int(field.current))); value != field.def {
+			*field.dst = value
+		}
+	}
+	fmt.Println()
+	fmt.Printf("What should the minimum gas price be in wei? (default = %v)\n", current.MinGasPrice)
+	schedule.MinGasPrice = w.readDefaultBigInt(current.MinGasPrice)
+
+	fmt.Println()
+	fmt.Println("Should precompiled contracts be repriced? (default = no)")
+	if w.readDefaultYesNo(false) {
+		schedule.Precompiles = make(map[string]*params.PrecompilePricing)
+		for _, precompile := range gasPrecompiles {
+			pricing := *current.Precompiles[precompile.Name]
+
+			fmt.Println()
+			fmt.Printf("What should the base price of %s be? (default = %d)\n", precompile.Name, pricing.Base)
+			pricing.Base = uint64(w.readDefaultInt(int(pricing.Base)))
+			if !precompile.Const {
+				fmt.Println()
+				fmt.Printf("What should the price per word of %s be? (default = %d)\n", precompile.Name, pricing.Word)
+				pricing.Word = uint64(w.readDefaultInt(int(pricing.Word)))
+			}
+			if pricing != precompile.Default {
+				schedule.Precompiles[precompile.Name] = &pricing
+			}
+		}
+		if len(schedule.Precompiles) == 0 {
+			schedule.Precompiles = nil
+		}
+	} else if genesis.Config.GasSchedule != nil {
+		schedule.Precompiles = genesis.Config.GasSchedule.Precompiles
+	}
+	if err := checkGasSchedule(schedule); err != nil {
+		log.Error("Invalid gas schedule", "err", err)
+		return
+	}
+	if isDefaultGasSchedule(schedule) {
+		genesis.Config.GasSchedule = nil
+		log.Info("Using the default gas schedule")
+		return
+	}
+	genesis.Config.GasSchedule = schedule
+	log.Info("Configured custom gas schedule", "txgas", effectiveGasSchedule(schedule).TxGas, "mingasprice", schedule.MinGasPrice, "precompiles", len(schedule.Precompiles))
+}
+
+// makeGovernance deploys a threshold multisig into the genesis alloc and offers
+// it as the recipient of block rewards and fees. The validator set stays under
+// the control of the consensus engine (signer votes or the system contracts).
+func (w *wizard) makeGovernance(genesis *core.Genesis) {
+	fmt.Println()
+	fmt.Println("Which accounts should own the multisig? (mandatory at least one)")
+
+	var owners []common.Address
+	for {
+		if address := w.readAddress(); address != nil {
+			owners = append(owners, *address)
+			continue
+		}
+		if len(owners) > 0 {
+			break
+		}
+	}
+	fmt.Println()
+	fmt.Printf("How many owners must confirm a transaction? (default = %d)\n", len(owners)/2+1)
+	threshold := uint64(w.readDefaultInt(len(owners)/2 + 1))
+
+	fmt.Println()
+	fmt.Printf("Which address should the multisig be deployed at? (default = %s)\n", defaultMultisigAddress.Hex())
+	address := w.readDefaultAddress(defaultMultisigAddress)
+
+	account, err := multisigAccount(owners, threshold)
+	if err != nil {
+		log.Error("Invalid multisig configuration", "err", err)
+		return
+	}
+	if _, ok := genesis.Alloc[address]; ok {
+		log.Error("Multisig address already allocated", "address", address.Hex())
+		return
+	}
+	genesis.Alloc[address] = account
+
+	gov := &governance{
+		Multisig:     address,
+		Owners:       owners,
+		Threshold:    threshold,
+		FeeRecipient: address,
+	}
+	fmt.Println()
+	fmt.Println("Should the multisig receive block rewards and fees? (default = yes)")
+	if !w.readDefaultYesNo(true) {
+		fmt.Println()
+		fmt.Println("Which account should receive block rewards and fees?")
+		for {
+			if recipient := w.readAddress(); recipient != nil {
+				gov.FeeRecipient = *recipient
+				break
+			}
+		}
+	}
+	genesis.Coinbase = gov.FeeRecipient
+	w.conf.Governance = gov
+
+	log.Info("Deployed governance multisig", "address", address.Hex(), "owners", len(owners), "threshold", threshold)
 }
 
 // importGenesis imports a Geth genesis spec into puppeth.
@@ -185,7 +381,11 @@ func (w *wizard) importGenesis() {
 	log.Info("Imported genesis block")
 
 	w.conf.Genesis = &genesis
+	w.conf.Governance = nil
+	w.conf.Fork = nil
 	w.conf.flush()
+
+	w.archiveSpec("import", &genesis)
 }
 
 // manageGenesis permits the modification of chain configuration parameters in
@@ -196,6 +396,10 @@ func (w *wizard) manageGenesis() {
 	fmt.Println(" 1. Modify existing configurations")
 	fmt.Println(" 2. Export genesis configurations")
 	fmt.Println(" 3. Remove genesis configuration")
+	fmt.Println(" 4. Schedule hard fork on the network")
+	fmt.Println(" 5. Browse spec archive and changelog")
+	fmt.Println(" 6. Dry-run the genesis locally")
+	fmt.Println(" 7. Replay live blocks across client specs")
 
 	choice := w.read()
 	switch choice {
@@ -243,16 +447,24 @@ func (w *wizard) manageGenesis() {
 		fmt.Printf("Which block should YOLOv3 come into effect? (default = %v)\n", w.conf.Genesis.Config.YoloV3Block)
 		w.conf.Genesis.Config.YoloV3Block = w.readDefaultBigInt(w.conf.Genesis.Config.YoloV3Block)
 
+		fmt.Println()
+		fmt.Println("Should the gas schedule be customized? (default = no)")
+		if w.readDefaultYesNo(false) {
+			w.makeGasSchedule(w.conf.Genesis)
+		}
+
 		out, _ := json.MarshalIndent(w.conf.Genesis.Config, "", "  ")
 		fmt.Printf("Chain configuration updated:\n\n%s\n", out)
 
 		w.conf.flush()
+		w.archiveSpec("modify", w.conf.Genesis)
 
 	case "2":
 		// Save whatever genesis configuration we currently have
 		fmt.Println()
 		fmt.Printf("Which folder to save the genesis specs into? (default = current)\n")
 		fmt.Printf("  Will create %s.json, %s-aleth.json, %s-harmony.json, %s-parity.json\n", w.network, w.network, w.network, w.network)
+		fmt.Printf("  and the client configs %s-geth.toml, %s-besu.toml, %s-nethermind.cfg\n", w.network, w.network, w.network)
 
 		folder := w.readDefaultString(".")
 		if err := os.MkdirAll(folder, 0755); err != nil {
@@ -268,6 +480,7 @@ func (w *wizard) manageGenesis() {
 			return
 		}
 		log.Info("Saved native genesis chain spec", "path", gethJson)
+		w.archiveSpec("export", w.conf.Genesis)
 
 		// Export the genesis spec used by Aleth (formerly C++ Ethereum)
 		if spec, err := newAlethGenesisSpec(w.network, w.conf.Genesis); err != nil {
@@ -276,7 +489,7 @@ func (w *wizard) manageGenesis() {
 			saveGenesis(folder, w.network, "aleth", spec)
 		}
 		// Export the genesis spec used by Parity
-		if spec, err := newParityChainSpec(w.network, w.conf.Genesis, []string{}); err != nil {
+		if spec, err := newParityChainSpec(w.network, w.conf.Genesis, w.conf.bootnodes); err != nil {
 			log.Error("Failed to create Parity chain spec", "err", err)
 		} else {
 			saveGenesis(folder, w.network, "parity", spec)
@@ -284,6 +497,50 @@ func (w *wizard) manageGenesis() {
 		// Export the genesis spec used by Harmony (formerly EthereumJ)
 		saveGenesis(folder, w.network, "harmony", w.conf.Genesis)
 
+		// Export the node configurations matching the specs for each client
+		conf := &clientConfig{
+			Network:   w.network,
+			NetworkID: w.conf.Genesis.Config.ChainID.Uint64(),
+			Bootnodes: w.conf.bootnodes,
+		}
+		if schedule := w.conf.Genesis.Config.GasSchedule; schedule != nil {
+			conf.MinGasPrice = schedule.MinGasPrice
+		}
+		fmt.Println()
+		fmt.Printf("Where should the clients store their data? (default = /var/lib/%s)\n", w.network)
+		conf.Datadir = w.readDefaultString("/var/lib/" + w.network)
+
+		fmt.Println()
+		fmt.Printf("Which TCP/UDP port should the clients listen on for peers? (default = 30303)\n")
+		conf.Port = w.readDefaultInt(30303)
+
+		fmt.Println()
+		fmt.Printf("Which TCP port should the clients serve HTTP RPC on? (default = 8545)\n")
+		conf.RPCPort = w.readDefaultInt(8545)
+
+		if len(conf.Bootnodes) == 0 {
+			log.Warn("No bootnodes deployed, client configs will have none")
+		}
+		files, err := newClientConfigs(conf)
+		if err != nil {
+			log.Error("Failed to create client configs", "err", err)
+			return
+		}
+		for name, content := range files {
+			path := filepath.Join(folder, name)
+			if err := ioutil.WriteFile(path, content, 0644); err != nil {
+				log.Error("Failed to save client config", "path", path, "err", err)
+				return
+			}
+			log.Info("Saved client configuration", "path", path)
+		}
+		// Optionally boot the exported specs to catch conversion bugs before launch
+		fmt.Println()
+		fmt.Println("Verify the exported specs by booting them in client containers (y/n)? (default = no)")
+		if w.readDefaultYesNo(false) {
+			w.verifyGenesis()
+		}
+
 	case "3":
 		// Make sure we don't have any services running
 		if len(w.conf.servers()) > 0 {
@@ -293,7 +550,24 @@ func (w *wizard) manageGenesis() {
 		log.Info("Genesis block destroyed")
 
 		w.conf.Genesis = nil
+		w.conf.Governance = nil
+		w.conf.Fork = nil
 		w.conf.flush()
+
+		w.archiveSpec("remove", nil)
+
+	case "4":
+		w.manageFork()
+
+	case "5":
+		w.manageArchive()
+
+	case "6":
+		w.dryRunGenesis()
+
+	case "7":
+		w.replayGenesis()
+
 	default:
 		log.Error("That's not something I can do")
 		return
@@ -123,6 +123,7 @@ func (w *wizard) run() {
 		} else {
 			fmt.Println(" 4. Manage network components")
 		}
+		fmt.Println(" 5. Publish DNS discovery tree")
 
 		choice := w.read()
 		switch {
@@ -162,6 +163,8 @@ func (w *wizard) run() {
 			} else {
 				w.manageComponents()
 			}
+		case choice == "5":
+			w.publishDNS()
 		default:
 			log.Error("That's not something I can do")
 		}
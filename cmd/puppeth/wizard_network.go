@@ -128,12 +128,17 @@ func (w *wizard) manageComponents() {
 		}
 	}
 	fmt.Printf(" %d. Deploy new network component\n", len(serviceHosts)+1)
+	fmt.Printf(" %d. Roll out sealer settings in stages\n", len(serviceHosts)+2)
 
 	choice := w.readInt()
-	if choice < 0 || choice > len(serviceHosts)+1 {
+	if choice < 0 || choice > len(serviceHosts)+2 {
 		log.Error("Invalid component choice, aborting")
 		return
 	}
+	if choice == len(serviceHosts)+2 {
+		w.rolloutSealers()
+		return
+	}
 	// If the user selected an existing service, destroy it
 	if choice <= len(serviceHosts) {
 		// Figure out the service to destroy and execute it
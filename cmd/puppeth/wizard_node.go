@@ -19,6 +19,10 @@ package main
 import (
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
@@ -106,6 +110,9 @@ func (w *wizard) deployNode(boot bool) {
 		if w.conf.Genesis.Config.Ethash != nil {
 			// Ethash based miners only need an etherbase to mine against
 			fmt.Println()
+			if infos.etherbase == "" && w.conf.Governance != nil {
+				infos.etherbase = w.conf.Governance.FeeRecipient.Hex()
+			}
 			if infos.etherbase == "" {
 				fmt.Printf("What address should the miner use?\n")
 				for {
@@ -160,6 +167,35 @@ func (w *wizard) deployNode(boot bool) {
 		fmt.Printf("What gas price should the signer require (GWei)? (default = %0.3f)\n", infos.gasPrice)
 		infos.gasPrice = w.readDefaultFloat(infos.gasPrice)
 	}
+	// Figure out whether to expose an RPC endpoint, secured if so
+	fmt.Println()
+	if infos.rpcPort > 0 {
+		fmt.Printf("Expose a TLS and JWT secured RPC endpoint (y/n)? (default = yes)\n")
+	} else {
+		fmt.Printf("Expose a TLS and JWT secured RPC endpoint (y/n)? (default = no)\n")
+	}
+	if w.readDefaultYesNo(infos.rpcPort > 0) {
+		if err := w.configureNodeRPC(server, client, infos); err != nil {
+			log.Error("Failed to provision RPC credentials", "err", err)
+			return
+		}
+	} else {
+		infos.rpcPort, infos.rpcDomain, infos.rpcEmail, infos.jwtSecret = 0, "", "", ""
+	}
+	// New nodes may start from a state-sync seed instead of syncing from the genesis
+	if !existed {
+		fmt.Println()
+		if w.conf.Seed != "" {
+			fmt.Printf("Bootstrap the node from the state-sync seed (y/n)? (default = yes)\n")
+		} else {
+			fmt.Printf("Bootstrap the node from a state-sync seed (y/n)? (default = no)\n")
+		}
+		if w.readDefaultYesNo(w.conf.Seed != "") {
+			if infos.seed = w.selectSeed(); infos.seed == nil {
+				return
+			}
+		}
+	}
 	// Try to deploy the full node on the host
 	nocache := false
 	if existed {
@@ -174,9 +210,150 @@ func (w *wizard) deployNode(boot bool) {
 		}
 		return
 	}
+	// Hand out the credentials the RPC clients need to connect
+	if infos.rpcPort > 0 {
+		if endpoint, err := w.saveNodeRPC(client, infos); err != nil {
+			log.Error("Failed to save RPC credentials", "err", err)
+		} else {
+			fmt.Println()
+			fmt.Printf("RPC clients connect to %s\n", endpoint)
+			fmt.Println("Requests must carry an HS256 JWT signed with the secret, see the engine API authentication spec.")
+		}
+	}
 	// All ok, run a network scan to pick any changes up
 	log.Info("Waiting for node to finish booting")
 	time.Sleep(3 * time.Second)
 
 	w.networkStats()
+
+	// Offer an explorer along the first nodes, so the network is browsable
+	if boot && !w.explorerDeployed() {
+		fmt.Println()
+		fmt.Printf("No block explorer is running yet, deploy one too (y/n)? (default = yes)\n")
+		if w.readDefaultYesNo(true) {
+			w.deployExplorer()
+		}
+	}
+}
+
+// selectSeed loads the state-sync seed to bootstrap a node with, offering to
+// export a fresh one from a running node instead of the last one saved.
+func (w *wizard) selectSeed() *nodeSeed {
+	path := w.conf.Seed
+	if path != "" {
+		fmt.Println()
+		fmt.Printf("Reuse previous (%s) seed (y/n)? (default = yes)\n", path)
+		if !w.readDefaultYesNo(true) {
+			path = ""
+		}
+	}
+	if path == "" {
+		if path = w.makeSeed(); path == "" {
+			return nil
+		}
+	}
+	seed, err := loadSeed(path, w.conf.Genesis)
+	if err != nil {
+		log.Error("Failed to load state-sync seed", "manifest", path, "err", err)
+		return nil
+	}
+	return seed
+}
+
+// configureNodeRPC asks the user for the RPC endpoint settings of a node, and
+// provisions its JWT secret and TLS certificate. The endpoint is only ever
+// served over TLS with JWT authentication.
+func (w *wizard) configureNodeRPC(server string, client *sshClient, infos *nodeInfos) error {
+	if infos.rpcPort == 0 {
+		infos.rpcPort = 8545
+	}
+	fmt.Println()
+	fmt.Printf("Which TCP port to serve RPC on? (default = %d)\n", infos.rpcPort)
+	infos.rpcPort = w.readDefaultInt(infos.rpcPort)
+
+	fmt.Println()
+	if infos.rpcDomain != "" {
+		fmt.Printf("Where should the TLS certificate come from? (default = ACME for %s)\n", infos.rpcDomain)
+	} else {
+		fmt.Println("Where should the TLS certificate come from? (default = network CA)")
+	}
+	fmt.Println(" 1. Issued by the network CA managed by puppeth")
+	fmt.Println(" 2. Obtained via ACME, e.g. Let's Encrypt (requires a domain and port 80)")
+
+	choice := w.read()
+	if choice == "" && infos.rpcDomain != "" {
+		choice = "2"
+	}
+	switch choice {
+	case "2":
+		fmt.Println()
+		if infos.rpcDomain == "" {
+			fmt.Printf("Which domain resolves to %s?\n", client.address)
+			infos.rpcDomain = w.readString()
+		} else {
+			fmt.Printf("Which domain resolves to %s? (default = %s)\n", client.address, infos.rpcDomain)
+			infos.rpcDomain = w.readDefaultString(infos.rpcDomain)
+		}
+		fmt.Println()
+		fmt.Printf("Which email to register the ACME account with? (default = %s)\n", infos.rpcEmail)
+		infos.rpcEmail = w.readDefaultString(infos.rpcEmail)
+
+		for _, service := range w.services[server] {
+			if service == "nginx" {
+				log.Warn("Nginx reverse-proxy running on the server, ACME challenges need port 80 free")
+			}
+		}
+		infos.tlsCert, infos.tlsKey = nil, nil
+
+	default:
+		infos.rpcDomain, infos.rpcEmail = "", ""
+
+		if w.conf.RPCAuth == nil {
+			ca, err := newRPCAuthority(w.network)
+			if err != nil {
+				return err
+			}
+			w.conf.RPCAuth = ca
+			w.conf.flush()
+
+			log.Info("Created network RPC certificate authority")
+		}
+		cert, key, err := w.conf.RPCAuth.issue(client.address)
+		if err != nil {
+			return err
+		}
+		infos.tlsCert, infos.tlsKey = cert, key
+	}
+	// Keep the JWT secret of redeployed nodes, so their clients keep working
+	if infos.jwtSecret == "" {
+		secret, err := newJWTSecret()
+		if err != nil {
+			return err
+		}
+		infos.jwtSecret = secret
+	}
+	return nil
+}
+
+// saveNodeRPC stores the JWT secret and CA certificate the RPC clients of a node
+// need next to the puppeth configs, returning the endpoint to connect to.
+func (w *wizard) saveNodeRPC(client *sshClient, infos *nodeInfos) (*rpcEndpoint, error) {
+	dir := filepath.Dir(w.conf.path)
+
+	endpoint := &rpcEndpoint{
+		JWTFile: filepath.Join(dir, fmt.Sprintf("%s-%s-jwt.hex", w.network, client.server)),
+	}
+	if err := ioutil.WriteFile(endpoint.JWTFile, []byte(infos.jwtSecret), 0600); err != nil {
+		return nil, err
+	}
+	if infos.rpcDomain != "" {
+		endpoint.URL = fmt.Sprintf("https://%s", net.JoinHostPort(infos.rpcDomain, strconv.Itoa(infos.rpcPort)))
+		return endpoint, nil
+	}
+	endpoint.CAFile = filepath.Join(dir, fmt.Sprintf("%s-rpc-ca.crt", w.network))
+	if err := ioutil.WriteFile(endpoint.CAFile, []byte(w.conf.RPCAuth.Cert), 0644); err != nil {
+		return nil, err
+	}
+	endpoint.URL = fmt.Sprintf("https://%s", net.JoinHostPort(client.address, strconv.Itoa(infos.rpcPort)))
+	return endpoint, nil
 }
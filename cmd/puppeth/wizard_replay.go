@@ -0,0 +1,117 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/olekukonko/tablewriter"
+)
+
+// replayGenesis replays a block range of the live network against two clients
+// booted from their exported chain specs and reports the first transaction
+// they diverge on, if any.
+func (w *wizard) replayGenesis() {
+	if len(w.conf.bootnodes) == 0 {
+		log.Error("Replaying requires a live network, deploy a bootnode first")
+		return
+	}
+	// Pick the two clients to replay the blocks against
+	fmt.Println()
+	fmt.Println("Which clients should replay the blocks?")
+	for i, client := range specClients {
+		fmt.Printf(" %d. %s (%s)\n", i+1, client.Name, client.Spec)
+	}
+	var clients [2]*specClient
+	for i, def := range []int{1, 2} {
+		fmt.Println()
+		fmt.Printf("Which client should be the %s one? (default = %s)\n", []string{"first", "second"}[i], specClients[def-1].Name)
+		choice := w.readDefaultInt(def)
+		if choice < 1 || choice > len(specClients) {
+			log.Error("Invalid client choice, aborting")
+			return
+		}
+		clients[i] = specClients[choice-1]
+	}
+	if clients[0] == clients[1] {
+		log.Error("Replaying requires two different clients")
+		return
+	}
+	fmt.Println()
+	fmt.Println("Which block should the replay start at? (default = 1)")
+	first := uint64(w.readDefaultInt(1))
+
+	fmt.Println()
+	fmt.Printf("Which block should the replay end at? (default = %d)\n", first+63)
+	last := uint64(w.readDefaultInt(int(first + 63)))
+	if last < first {
+		log.Error("Invalid block range", "first", first, "last", last)
+		return
+	}
+	fmt.Println()
+	fmt.Println("How many minutes to wait for the clients? (default = 10)")
+	timeout := time.Duration(w.readDefaultInt(10)) * time.Minute
+
+	server := w.selectServer()
+	if server == "" {
+		return
+	}
+	specs, err := w.clientSpecs()
+	if err != nil {
+		log.Error("Failed to create Parity chain spec", "err", err)
+		return
+	}
+	report, err := replaySpecs(w.servers[server], w.network, w.conf.Genesis.Config.ChainID.Uint64(), specs, w.conf.bootnodes, clients, first, last, timeout)
+	if err != nil {
+		log.Error("Failed to replay blocks", "err", err)
+		return
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Client", "Spec", "Head"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for i, client := range clients {
+		table.Append([]string{client.Name, client.Spec, strconv.FormatUint(report.Heads[i], 10)})
+	}
+	table.Render()
+
+	switch div := report.Divergence; {
+	case report.Passed:
+		log.Info("Clients agree on the replayed blocks", "first", first, "last", last)
+	case div == nil:
+		log.Error("Clients didn't reach the end of the range", "compared", report.Compared, "blocks", last-first+1)
+	case div.Tx < 0:
+		log.Error("Clients diverged", "number", div.Number, "problem", div.Problem)
+	default:
+		log.Error("Clients diverged", "number", div.Number, "tx", div.Tx, "hash", div.TxHash, "problem", div.Problem)
+	}
+	fmt.Println()
+	fmt.Println("Which file to save the report into? (default = none)")
+	if path := w.readDefaultString(""); path != "" {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		if err := ioutil.WriteFile(path, out, 0644); err != nil {
+			log.Error("Failed to save replay report", "path", path, "err", err)
+			return
+		}
+		log.Info("Saved replay report", "path", path)
+	}
+}
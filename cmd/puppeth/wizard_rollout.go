@@ -0,0 +1,204 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/olekukonko/tablewriter"
+)
+
+// rolloutNodes rolls a configuration change out to the given nodes in stages,
+// the canaries picked by the user first. It returns whether the change is live
+// on all the nodes.
+func (w *wizard) rolloutNodes(nodes []*forkNode, change func(infos *nodeInfos), verify func(node *forkNode) error) bool {
+	if len(nodes) == 0 {
+		log.Error("No nodes to roll the change out to")
+		return false
+	}
+	// Snapshot the current configurations to be able to roll back to them
+	steps := make([]*nodeRolloutStep, len(nodes))
+	for i, node := range nodes {
+		previous, err := checkNode(node.client, w.network, node.boot)
+		if err != nil {
+			log.Error("Failed to retrieve node configuration", "node", node.name(), "err", err)
+			return false
+		}
+		next := *previous
+		change(&next)
+
+		steps[i] = &nodeRolloutStep{
+			node:      node,
+			network:   w.network,
+			bootnodes: w.conf.bootnodes,
+			previous:  previous,
+			next:      &next,
+			verify:    verify,
+		}
+		if len(nodes) > 1 {
+			steps[i].minPeers = 1
+		}
+	}
+	// Figure out which nodes to try the change on first
+	fmt.Println()
+	fmt.Println("Which nodes should receive the change first as canaries? (comma separated, default = 1)")
+	for i, node := range nodes {
+		fmt.Printf(" %d. %s\n", i+1, node.name())
+	}
+	canaries := w.readCanaries(len(nodes))
+
+	ordered := make([]rolloutStep, 0, len(steps))
+	for _, index := range canaries {
+		ordered = append(ordered, steps[index])
+	}
+	for i, step := range steps {
+		if !containsInt(canaries, i) {
+			ordered = append(ordered, step)
+		}
+	}
+	// Figure out how long to observe the canaries for
+	plan := &rolloutPlan{Canaries: len(canaries)}
+
+	fmt.Println()
+	fmt.Println("How long should the canaries stay healthy before rolling on? (default = 5m)")
+	plan.Soak = w.readDefaultDuration(5 * time.Minute)
+
+	fmt.Println()
+	fmt.Println("How often should the nodes be health checked? (default = 30s)")
+	plan.Interval = w.readDefaultDuration(30 * time.Second)
+
+	fmt.Println()
+	fmt.Println("Should the chain advance on the canaries while soaking (y/n)? (default = yes)")
+	progress := w.readDefaultYesNo(true)
+	for _, step := range steps {
+		step.progress = progress
+	}
+	report := runRollout(plan, ordered, time.Sleep)
+
+	// Render the outcome for every node
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Node", "Stage", "Status"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for i, step := range ordered {
+		stage := "rollout"
+		if i < plan.Canaries {
+			stage = "canary"
+		}
+		status := "unchanged"
+		switch {
+		case containsString(report.Updated, step.name()):
+			status = "updated"
+		case containsString(report.RolledBack, step.name()):
+			status = "rolled back"
+		case containsString(report.Stranded, step.name()):
+			status = "ROLLBACK FAILED"
+		}
+		table.Append([]string{step.name(), stage, status})
+	}
+	table.Render()
+
+	if !report.Success {
+		log.Error("Staged rollout aborted", "failure", report.Failure, "rolledback", len(report.RolledBack), "stranded", len(report.Stranded))
+		return false
+	}
+	log.Info("Staged rollout complete", "nodes", len(report.Updated))
+	return true
+}
+
+// readCanaries reads the indexes of the canary nodes out of count nodes.
+func (w *wizard) readCanaries(count int) []int {
+	for {
+		text := w.readDefaultString("1")
+
+		var (
+			canaries []int
+			invalid  bool
+		)
+		for _, field := range strings.Split(text, ",") {
+			index, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || index < 1 || index > count || containsInt(canaries, index-1) {
+				log.Error("Invalid canary node", "choice", field)
+				invalid = true
+				break
+			}
+			canaries = append(canaries, index-1)
+		}
+		if !invalid {
+			return canaries
+		}
+	}
+}
+
+// rolloutSealers asks the user for new gas settings and rolls them out to the
+// seal nodes in stages.
+func (w *wizard) rolloutSealers() {
+	var sealers []*forkNode
+	for _, node := range w.forkNodes() {
+		if !node.boot {
+			sealers = append(sealers, node)
+		}
+	}
+	if len(sealers) == 0 {
+		log.Error("No sealers running on the managed servers")
+		return
+	}
+	current, err := checkNode(sealers[0].client, w.network, false)
+	if err != nil {
+		log.Error("Failed to retrieve sealer configuration", "node", sealers[0].name(), "err", err)
+		return
+	}
+	fmt.Println()
+	fmt.Printf("What gas limit should empty blocks target (MGas)? (default = %0.3f)\n", current.gasTarget)
+	gasTarget := w.readDefaultFloat(current.gasTarget)
+
+	fmt.Println()
+	fmt.Printf("What gas limit should full blocks target (MGas)? (default = %0.3f)\n", current.gasLimit)
+	gasLimit := w.readDefaultFloat(current.gasLimit)
+
+	fmt.Println()
+	fmt.Printf("What gas price should the signer require (GWei)? (default = %0.3f)\n", current.gasPrice)
+	gasPrice := w.readDefaultFloat(current.gasPrice)
+
+	w.rolloutNodes(sealers, func(infos *nodeInfos) {
+		infos.gasTarget, infos.gasLimit, infos.gasPrice = gasTarget, gasLimit, gasPrice
+	}, nil)
+}
+
+// containsInt reports whether a slice holds the given integer.
+func containsInt(list []int, item int) bool {
+	for _, have := range list {
+		if have == item {
+			return true
+		}
+	}
+	return false
+}
+
+// containsString reports whether a slice holds the given string.
+func containsString(list []string, item string) bool {
+	for _, have := range list {
+		if have == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,119 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/log"
+)
+
+// makeSeed exports a state-sync seed from a running node, so new nodes joining
+// the network can import it instead of syncing from the genesis. The path of the
+// saved seed manifest is returned, or an empty string if the export failed.
+func (w *wizard) makeSeed() string {
+	// Do some sanity check before the user wastes time on input
+	if w.conf.Genesis == nil {
+		log.Error("No genesis block configured")
+		return ""
+	}
+	// Select the server to interact with and find a node running on it
+	server := w.selectServer()
+	if server == "" {
+		return ""
+	}
+	client := w.servers[server]
+
+	var container string
+	for _, kind := range []string{"sealnode", "bootnode"} {
+		if _, err := checkNode(client, w.network, kind == "bootnode"); err == nil {
+			container = fmt.Sprintf("%s_%s_1", w.network, kind)
+			break
+		}
+	}
+	if container == "" {
+		log.Error("No running node found on server", "server", server)
+		return ""
+	}
+	head, err := queryNodeHead(client, container)
+	if err != nil {
+		log.Error("Failed to retrieve chain head", "err", err)
+		return ""
+	}
+	// Seed the first epoch by default, as long as the node already has it. Clique
+	// and parlia nodes only trust epoch checkpoints, which carry the signer set.
+	var (
+		epoch  = seedEpoch(w.conf.Genesis)
+		poa    = w.conf.Genesis.Config.Clique != nil || w.conf.Genesis.Config.Parlia != nil
+		number = epoch
+	)
+	if number > head {
+		number = head
+		if poa {
+			number = 0
+		}
+	}
+	if number == 0 {
+		log.Error("Node has no blocks to seed yet", "container", container)
+		return ""
+	}
+	fmt.Println()
+	fmt.Printf("Which block should the seed state be taken at? (default = %d, head = %d)\n", number, head)
+	number = uint64(w.readDefaultInt(int(number)))
+	if number == 0 || number > head || (poa && number%epoch != 0) {
+		log.Error("Invalid seed block", "block", number, "head", head, "epoch", epoch)
+		return ""
+	}
+	fmt.Println()
+	fmt.Printf("Which folder to save the seed artifacts into? (default = current)\n")
+	folder := w.readDefaultString(".")
+	if err := os.MkdirAll(folder, 0755); err != nil {
+		log.Error("Failed to create seed folder", "folder", folder, "err", err)
+		return ""
+	}
+	fmt.Println()
+	fmt.Printf("Which base URL will the artifacts be served from? (default = none)\n")
+	baseURL := w.readDefaultString("")
+
+	fmt.Println()
+	fmt.Printf("Which torrent trackers should the seed be announced on? (comma separated, default = none)\n")
+	var trackers []string
+	for _, tracker := range strings.Split(w.readDefaultString(""), ",") {
+		if tracker = strings.TrimSpace(tracker); tracker != "" {
+			trackers = append(trackers, tracker)
+		}
+	}
+	log.Info("Exporting state-sync seed", "container", container, "number", number)
+	seed, err := exportSeed(client, container, w.conf.Genesis, number)
+	if err != nil {
+		log.Error("Failed to export seed", "err", err)
+		return ""
+	}
+	manifest, err := seed.save(folder, w.network, w.conf.Genesis, baseURL, trackers)
+	if err != nil {
+		log.Error("Failed to save seed", "err", err)
+		return ""
+	}
+	log.Info("Saved state-sync seed", "manifest", manifest, "checkpoint", seed.checkpoint.Hash, "number", seed.checkpoint.Number)
+
+	w.conf.Seed = manifest
+	w.conf.flush()
+
+	return manifest
+}
@@ -0,0 +1,106 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of go-ethereum.
+//
+// go-ethereum is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-ethereum is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-ethereum. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/log"
+	"github.com/olekukonko/tablewriter"
+)
+
+// verifyGenesis boots the exported chain specs in client containers on one of
+// the servers and reports any incompatibilities between them.
+func (w *wizard) verifyGenesis() {
+	server := w.selectServer()
+	if server == "" {
+		return
+	}
+	client := w.servers[server]
+
+	// Assemble the specs the clients load
+	specs, err := w.clientSpecs()
+	if err != nil {
+		log.Error("Failed to create Parity chain spec", "err", err)
+		return
+	}
+
+	// Importing blocks is only possible if there's a live network to sync with
+	var number uint64
+	if len(w.conf.bootnodes) > 0 {
+		fmt.Println()
+		fmt.Println("Which block should the clients import from the live network? (default = 16)")
+		number = uint64(w.readDefaultInt(16))
+	} else {
+		log.Warn("No bootnodes deployed, verifying the genesis only")
+	}
+	fmt.Println()
+	fmt.Println("How many minutes to wait for the clients? (default = 5)")
+	timeout := time.Duration(w.readDefaultInt(5)) * time.Minute
+
+	clients, err := verifySpecs(client, w.network, w.conf.Genesis.Config.ChainID.Uint64(), specs, w.conf.bootnodes, number, timeout)
+	if err != nil {
+		log.Error("Failed to verify chain specs", "err", err)
+		return
+	}
+	report := newSpecReport(w.network, w.conf.Genesis.ToBlock(nil).Hash(), number, clients)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Client", "Genesis", "Head", "Status"})
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	for _, client := range report.Clients {
+		status := "compatible"
+		if !client.Compliant {
+			status = client.Problem
+		}
+		table.Append([]string{client.Client, client.Genesis.Hex(), strconv.FormatUint(client.Head, 10), status})
+	}
+	table.Render()
+
+	if report.Passed {
+		log.Info("Exported chain specs are compatible", "genesis", report.Genesis, "clients", len(report.Clients))
+	} else {
+		log.Error("Exported chain specs are incompatible", "genesis", report.Genesis)
+	}
+	fmt.Println()
+	fmt.Println("Which file to save the report into? (default = none)")
+	if path := w.readDefaultString(""); path != "" {
+		out, _ := json.MarshalIndent(report, "", "  ")
+		if err := ioutil.WriteFile(path, out, 0644); err != nil {
+			log.Error("Failed to save verification report", "path", path, "err", err)
+			return
+		}
+		log.Info("Saved verification report", "path", path)
+	}
+}
+
+// clientSpecs assembles the exported chain specs the verified clients load,
+// keyed by the file name they are mounted as.
+func (w *wizard) clientSpecs() (map[string][]byte, error) {
+	native, _ := json.MarshalIndent(w.conf.Genesis, "", "  ")
+	parity, err := newParityChainSpec(w.network, w.conf.Genesis, w.conf.bootnodes)
+	if err != nil {
+		return nil, err
+	}
+	parityJSON, _ := json.MarshalIndent(parity, "", "  ")
+	return map[string][]byte{"genesis.json": native, "parity.json": parityJSON}, nil
+}
@@ -1,22 +1,415 @@
-﻿package main
+﻿// fffaddr is a conformance tool for the FFF address codec, allowing pipelines
+// and integrators to verify their address handling against the reference
+// implementation without writing Go.
+package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/fffenc"
+	"github.com/liuguodong24-8/3fcoin/core/common/ffftest"
+	"github.com/liuguodong24-8/3fcoin/core/lib/flags"
+	"gopkg.in/urfave/cli.v1"
 )
 
-func main() {
-	newS := "\"FFF3QTZ3uQoVCiATg2ELuMjLb3SqoYtq6fnxV6jGMPFbLwJctj1q2qGj3F\""
+// Exit codes of the commands.
+const (
+	exitInvalid     = 1 // Some input is not a valid address
+	exitUsage       = 2 // Invalid command line
+	exitConformance = 3 // The codecs disagree or broke a round-trip
+)
+
+// maxFailures is the number of conformance failures reported in JSON output.
+const maxFailures = 100
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+var gitDate = ""
+
+var app *cli.App
+
+func init() {
+	app = flags.NewApp(gitCommit, gitDate, "an FFF address conformance tool")
+	app.Name = "fffaddr"
+	app.Flags = []cli.Flag{
+		prefixFlag,
+		crossNetworkFlag,
+		jsonFlag,
+	}
+	app.Before = func(ctx *cli.Context) error {
+		config := fffenc.New(ctx.GlobalString(prefixFlag.Name), nil)
+		if err := fffenc.Register(config); err != nil {
+			return cli.NewExitError(err, exitUsage)
+		}
+		common.SetAddressPrefix(config.Prefix)
+		common.SetCrossNetworkAddresses(ctx.GlobalBool(crossNetworkFlag.Name))
+		network, crossNetwork = config, ctx.GlobalBool(crossNetworkFlag.Name)
+		return nil
+	}
+	app.Commands = []cli.Command{
+		commandEncode,
+		commandDecode,
+		commandValidate,
+		commandRoundtripFuzz,
+	}
+	cli.CommandHelpTemplate = flags.OriginCommandHelpTemplate
+}
 
-	if common.IsHexAddress(newS[1 : len(newS)-1]) {
-		fmt.Println(1)
+var (
+	prefixFlag = cli.StringFlag{
+		Name:  "prefix",
+		Usage: "network prefix of the FFF addresses",
+		Value: common.FFFHeader,
+	}
+	crossNetworkFlag = cli.BoolFlag{
+		Name:  "cross-network",
+		Usage: "accept FFF addresses of other known networks when validating",
+	}
+	jsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "output a JSON object per address instead of plain text",
 	}
+	seedFlag = cli.Int64Flag{
+		Name:  "seed",
+		Usage: "seed of the random addresses (default = current time)",
+	}
+)
+
+var (
+	network      *fffenc.Config // Codec of the configured network
+	crossNetwork bool           // Whether addresses of other networks are valid
+)
 
-	fmt.Println(common.FFFAddressEncode("0x0d023dfc9c025e263d974985f3367d99f91e071b"))
-	fmt.Println(common.FFFAddressDecode("FFF3QTZ3uQoVCiATg2ELuMjLb3SqoYtq6fnxV6jGMPFbLwJctj1q2qGj3F"))
-	fmt.Println()
-	// input = []byte(`"` + common.FFFAddressDecode(newS[1:len(newS)-1]) + `"`)
+// result is the outcome of processing a single address.
+type result struct {
+	Input   string `json:"input"`
+	Valid   bool   `json:"valid"`
+	Format  string `json:"format,omitempty"`  // Format of the input, fff or hex
+	Type    string `json:"type,omitempty"`    // Account type of typed FFF addresses
+	Address string `json:"address,omitempty"` // FFF encoding on the configured network
+	Hex     string `json:"hex,omitempty"`     // EIP-55 checksummed hex
+	Error   string `json:"error,omitempty"`
+}
+
+var commandEncode = cli.Command{
+	Name:      "encode",
+	Usage:     "encode hex addresses into FFF form",
+	ArgsUsage: "[<address>...]",
+	Description: `
+Encode each 0x prefixed or bare 40 digit hex address into the FFF form of the
+network. Addresses are read from standard input, one per line, if none are given
+as arguments. The encodings are printed one per line, invalid addresses are
+reported on standard error and make the command exit with status 1.`,
+	Action: func(ctx *cli.Context) error {
+		return process(ctx, func(res *result) {
+			input := res.Input
+			if len(input) == 2*common.AddressLength {
+				input = "0x" + input
+			}
+			if len(input) < 2 || !strings.EqualFold(input[:2], "0x") {
+				res.Error = "not a hex address"
+				return
+			}
+			addr, err := network.Decode(input)
+			if err != nil {
+				res.Error = err.Error()
+				return
+			}
+			res.Valid, res.Format = true, "hex"
+			res.Address, res.Hex = network.Encode(addr), common.AddressFormatHex.Address(addr)
+		}, func(res *result) string { return res.Address })
+	},
+}
+
+var commandDecode = cli.Command{
+	Name:      "decode",
+	Usage:     "decode FFF addresses into EIP-55 hex form",
+	ArgsUsage: "[<address>...]",
+	Description: `
+Decode each FFF address of the network into its EIP-55 checksummed hex form.
+Addresses are read from standard input, one per line, if none are given as
+arguments. The hex forms are printed one per line, invalid addresses are
+reported on standard error and make the command exit with status 1.`,
+	Action: func(ctx *cli.Context) error {
+		return process(ctx, func(res *result) {
+			if !network.Owns(res.Input) {
+				if _, err := network.Decode(res.Input); err != nil {
+					res.Error = err.Error()
+				} else {
+					res.Error = "not an FFF address"
+				}
+				return
+			}
+			decodeFFF(res)
+		}, func(res *result) string { return res.Hex })
+	},
+}
+
+var commandValidate = cli.Command{
+	Name:      "validate",
+	Usage:     "check addresses against the reference codecs",
+	ArgsUsage: "[<address>...]",
+	Description: `
+Check that each address, in FFF or 0x prefixed hex form, is valid on the network.
+Addresses are read from standard input, one per line, if none are given as
+arguments. Every address is decoded by both the node's codec and the network
+bound one, which must agree.
+
+The command exits with status 1 if any address is invalid, and with status 3 if
+the codecs disagree on any of them.`,
+	Action: func(ctx *cli.Context) error {
+		mismatch := false
+		err := process(ctx, func(res *result) {
+			decodeFFF(res)
+
+			addr, nodeErr := nodeDecode(res.Input)
+			switch {
+			case res.Valid && nodeErr != nil:
+				res.Error = fmt.Sprintf("codec mismatch: accepted, but node rejects: %v", nodeErr)
+			case !res.Valid && nodeErr == nil:
+				res.Error = fmt.Sprintf("codec mismatch: node accepts as %s, but %s", common.AddressFormatHex.Address(addr), res.Error)
+			case res.Valid && res.Hex != common.AddressFormatHex.Address(addr):
+				res.Error = fmt.Sprintf("codec mismatch: decoded to %s, node to %s", res.Hex, common.AddressFormatHex.Address(addr))
+			default:
+				return
+			}
+			res.Valid, mismatch = false, true
+		}, func(res *result) string { return res.Input })
 
-	// return hexutil.UnmarshalFixedJSON(addressT, input, a[:])
+		if mismatch {
+			return cli.NewExitError("", exitConformance)
+		}
+		return err
+	},
+}
+
+// nodeDecode decodes an address the way the node does, which only accepts
+// typed addresses where it expects them.
+func nodeDecode(input string) (common.Address, error) {
+	var addr common.Address
+	err := addr.UnmarshalText([]byte(input))
+	if err != nil {
+		if typed, typ, terr := common.DecodeTyped(input); terr == nil && typ != common.AddressTypeUnknown {
+			return typed, nil
+		}
+	}
+	return addr, err
+}
+
+// foreignPrefix returns the prefix of the other known network s belongs to, or
+// an empty string if none.
+func foreignPrefix(s string) string {
+	if network.Owns(s) {
+		return ""
+	}
+	for _, prefix := range common.AddressPrefixes() {
+		if strings.HasPrefix(s, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// decodeFFF decodes the input of a result on the configured network, or on the
+// network it belongs to if cross-network addresses are valid.
+func decodeFFF(res *result) {
+	addr, typ, err := network.DecodeTyped(res.Input)
+	if prefix := foreignPrefix(res.Input); err != nil && crossNetwork && prefix != "" {
+		addr, typ, err = fffenc.New(prefix, nil).DecodeTyped(res.Input)
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return
+	}
+	res.Valid, res.Format = true, "fff"
+	if strings.HasPrefix(strings.ToLower(res.Input), "0x") {
+		res.Format = "hex"
+	}
+	if typ != common.AddressTypeUnknown {
+		res.Type = typ.String()
+	}
+	res.Address, res.Hex = network.Encode(addr), common.AddressFormatHex.Address(addr)
+}
+
+// process runs a check over all the input addresses, writing the results as
+// they come. The text output of valid results is produced by output, invalid
+// ones are reported on standard error.
+func process(ctx *cli.Context, check func(res *result), output func(res *result) string) error {
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	var (
+		enc     = json.NewEncoder(out)
+		invalid = false
+	)
+	err := inputs(ctx, func(input string) error {
+		res := &result{Input: input}
+		check(res)
+		invalid = invalid || !res.Valid
+
+		if ctx.GlobalBool(jsonFlag.Name) {
+			return enc.Encode(res)
+		}
+		if !res.Valid {
+			// Keep the standard output in sync with the input when piped
+			out.Flush()
+			fmt.Fprintf(os.Stderr, "%q: %s\n", res.Input, res.Error)
+			return nil
+		}
+		_, err := fmt.Fprintln(out, output(res))
+		return err
+	})
+	if err != nil {
+		return cli.NewExitError(err, exitUsage)
+	}
+	if invalid {
+		return cli.NewExitError("", exitInvalid)
+	}
+	return nil
+}
+
+// inputs feeds the addresses given as arguments, or read line by line from the
+// standard input if there are none, to fn. Lines are taken as is besides their
+// terminators, as whitespace makes an address invalid.
+func inputs(ctx *cli.Context, fn func(input string) error) error {
+	if ctx.NArg() > 0 {
+		for _, arg := range ctx.Args() {
+			if err := fn(arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	in := bufio.NewReader(os.Stdin)
+	for {
+		line, err := in.ReadString('\n')
+		if line != "" {
+			line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+			if err := fn(line); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+var commandRoundtripFuzz = cli.Command{
+	Name:      "roundtrip-fuzz",
+	Usage:     "round-trip random addresses through the reference codecs",
+	ArgsUsage: "<iterations>",
+	Description: `
+Generate the given number of random addresses, biased towards the edge cases of
+the codec, and check that each survives encoding and decoding through all codec
+entry points. Each iteration also corrupts a valid address, which all decoders
+must reject, on top of a fixed corpus of corrupt inputs.
+
+Failures are printed as they are found, the seed is reported so any failure can
+be reproduced with --seed. The command exits with status 3 if anything failed.`,
+	Flags: []cli.Flag{
+		seedFlag,
+	},
+	Action: func(ctx *cli.Context) error {
+		if ctx.NArg() != 1 {
+			return cli.NewExitError("number of iterations required", exitUsage)
+		}
+		iterations, err := strconv.Atoi(ctx.Args().First())
+		if err != nil || iterations < 0 {
+			return cli.NewExitError(fmt.Sprintf("invalid number of iterations %q", ctx.Args().First()), exitUsage)
+		}
+		seed := ctx.Int64(seedFlag.Name)
+		if !ctx.IsSet(seedFlag.Name) {
+			seed = time.Now().UnixNano()
+		}
+		report := fuzz(seed, iterations, func(failure string) {
+			if !ctx.GlobalBool(jsonFlag.Name) {
+				fmt.Println("FAIL", failure)
+			}
+		})
+		if ctx.GlobalBool(jsonFlag.Name) {
+			out, _ := json.MarshalIndent(report, "", "  ")
+			fmt.Println(string(out))
+		} else {
+			fmt.Printf("seed %d: %d round-trips, %d rejections, %d failures\n", report.Seed, report.Roundtrips, report.Rejections, report.Failed)
+		}
+		if report.Failed > 0 {
+			return cli.NewExitError("", exitConformance)
+		}
+		return nil
+	},
+}
+
+// fuzzReport is the outcome of a fuzzing run.
+type fuzzReport struct {
+	Seed       int64    `json:"seed"`
+	Roundtrips int      `json:"roundtrips"` // Number of addresses round-tripped
+	Rejections int      `json:"rejections"` // Number of corrupt inputs checked
+	Failed     int      `json:"failed"`
+	Failures   []string `json:"failures,omitempty"` // First failures found
+}
+
+// fuzz round-trips random addresses and checks random and fixed corrupt inputs
+// are rejected, reporting each failure to the callback as it's found.
+func fuzz(seed int64, iterations int, report func(failure string)) *fuzzReport {
+	res := &fuzzReport{Seed: seed}
+	fail := func(format string, args ...interface{}) {
+		failure := fmt.Sprintf(format, args...)
+		if res.Failed++; len(res.Failures) < maxFailures {
+			res.Failures = append(res.Failures, failure)
+		}
+		report(failure)
+	}
+	reject := func(name, input string) {
+		// Foreign addresses are only corrupt if they are not accepted
+		if crossNetwork && foreignPrefix(input) != "" {
+			return
+		}
+		res.Rejections++
+		if err := ffftest.CheckRejected(input); err != nil {
+			fail("%s: %v", name, err)
+		}
+		if addr, err := network.Decode(input); err == nil {
+			fail("%s: network codec accepted %q as %x", name, input, addr)
+		}
+	}
+	for _, c := range ffftest.Corpus() {
+		reject(c.Name, c.Input)
+	}
+	r := rand.New(rand.NewSource(seed))
+	for i := 0; i < iterations; i++ {
+		addr := ffftest.Address(r)
+
+		res.Roundtrips++
+		if err := ffftest.CheckRoundTrip(addr); err != nil {
+			fail("roundtrip %x: %v", addr, err)
+		}
+		if enc := network.Encode(addr); enc != addr.String() {
+			fail("roundtrip %x: network codec encoded to %s, node to %s", addr, enc, addr.String())
+		} else if dec, err := network.Decode(enc); err != nil || dec != addr {
+			fail("roundtrip %x: network codec decoded %s to %x (%v)", addr, enc, dec, err)
+		}
+		corrupt, mutation := ffftest.NearValid(r)
+		reject(mutation.Name, corrupt)
+	}
+	return res
+}
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitUsage)
+	}
 }
@@ -169,6 +169,14 @@ var (
 		Usage: "Explicitly set network id (integer)(For testnets: use --ropsten, --rinkeby, --goerli instead)",
 		Value: ethconfig.Defaults.NetworkId,
 	}
+	AddressCrossNetworkFlag = cli.BoolFlag{
+		Name:  "address.crossnetwork",
+		Usage: "Accept FFF addresses carrying the prefix of a different network",
+	}
+	AddressCacheFlag = cli.IntFlag{
+		Name:  "address.cache",
+		Usage: "Number of recent FFF address conversions to cache for RPC serialization (0 = disabled)",
+	}
 	MainnetFlag = cli.BoolFlag{
 		Name:  "mainnet",
 		Usage: "Ethereum mainnet",
@@ -1572,6 +1580,12 @@ func SetEthConfig(ctx *cli.Context, stack *node.Node, cfg *ethconfig.Config) {
 	if keystores := stack.AccountManager().Backends(keystore.KeyStoreType); len(keystores) > 0 {
 		ks = keystores[0].(*keystore.KeyStore)
 	}
+	if ctx.GlobalBool(AddressCrossNetworkFlag.Name) {
+		common.SetCrossNetworkAddresses(true)
+	}
+	if size := ctx.GlobalInt(AddressCacheFlag.Name); size > 0 {
+		setAddressCache(size)
+	}
 	setEtherbase(ctx, ks, cfg)
 	setGPO(ctx, &cfg.GPO, ctx.GlobalString(SyncModeFlag.Name) == "light")
 	setTxPool(ctx, &cfg.TxPool)
@@ -1886,6 +1900,12 @@ func SetupMetrics(ctx *cli.Context) {
 	}
 }
 
+// setAddressCache enables the FFF address conversion cache.
+func setAddressCache(size int) {
+	log.Info("Enabling address conversion cache", "size", size)
+	common.EnableAddressCache(size)
+}
+
 func SplitTagsFlag(tagsFlag string) map[string]string {
 	tags := strings.Split(tagsFlag, ",")
 	tagsMap := map[string]string{}
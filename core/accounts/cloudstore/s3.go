@@ -0,0 +1,131 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package cloudstore implements remote object storages keystore.CloudSync can
+// mirror key files into.
+package cloudstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+)
+
+// S3Store is a keystore.BlobStore backed by a bucket of an S3 compatible object
+// storage. Google Cloud Storage is supported through its interoperability
+// endpoint (https://storage.googleapis.com) using HMAC keys and the region "auto".
+//
+// Conflict detection relies on the conditional If-Match and If-None-Match
+// writes of the storage service.
+type S3Store struct {
+	client *s3.Client
+	bucket string // Bucket to store the key files in
+	prefix string // Prefix of the object names within the bucket
+}
+
+// NewS3Store creates a BlobStore for the given bucket, accessed through client.
+func NewS3Store(client *s3.Client, bucket, prefix string) *S3Store {
+	return &S3Store{client: client, bucket: bucket, prefix: prefix}
+}
+
+// NewS3Client creates a client of the S3 compatible service at endpoint,
+// authenticating with a static access key. Clients using the AWS credentials of
+// the environment can be created via the config package of the SDK instead.
+func NewS3Client(endpoint, region, accessKey, secretKey string) *s3.Client {
+	return s3.New(s3.Options{
+		Region:           region,
+		Credentials:      credentials.NewStaticCredentialsProvider(accessKey, secretKey, ""),
+		EndpointResolver: s3.EndpointResolverFromURL(endpoint),
+		UsePathStyle:     true,
+	})
+}
+
+// List implements keystore.BlobStore.
+func (s *S3Store) List(ctx context.Context) (map[string]string, error) {
+	objects := make(map[string]string)
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(s.prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			objects[strings.TrimPrefix(aws.ToString(object.Key), s.prefix)] = etag(object.ETag)
+		}
+	}
+	return objects, nil
+}
+
+// Get implements keystore.BlobStore.
+func (s *S3Store) Get(ctx context.Context, name string) ([]byte, string, error) {
+	res, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + name),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, etag(res.ETag), nil
+}
+
+// Put implements keystore.BlobStore.
+func (s *S3Store) Put(ctx context.Context, name string, data []byte, version string) (string, error) {
+	condition := smithyhttp.SetHeaderValue("If-None-Match", "*")
+	if version != "" {
+		condition = smithyhttp.SetHeaderValue("If-Match", `"`+version+`"`)
+	}
+	res, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.prefix + name),
+		Body:   bytes.NewReader(data),
+	}, s3.WithAPIOptions(condition))
+
+	var resErr *awshttp.ResponseError
+	if errors.As(err, &resErr) {
+		if code := resErr.HTTPStatusCode(); code == http.StatusPreconditionFailed || code == http.StatusConflict {
+			return "", keystore.ErrSyncConflict
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	return etag(res.ETag), nil
+}
+
+// etag strips the quotes off an entity tag.
+func etag(tag *string) string {
+	return strings.Trim(aws.ToString(tag), `"`)
+}
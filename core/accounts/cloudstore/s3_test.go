@@ -0,0 +1,119 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package cloudstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts/keystore"
+)
+
+// fakeS3 is a minimal in-memory S3 service, serving path style requests for
+// object listings, reads and conditional writes.
+type fakeS3 struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+	etags   map[string]string
+	counter int
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	path := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	switch {
+	case r.Method == http.MethodGet && len(path) == 1:
+		fmt.Fprint(w, `<ListBucketResult><IsTruncated>false</IsTruncated>`)
+		for key, tag := range s.etags {
+			if strings.HasPrefix(key, r.URL.Query().Get("prefix")) {
+				fmt.Fprintf(w, `<Contents><Key>%s</Key><ETag>"%s"</ETag></Contents>`, key, tag)
+			}
+		}
+		fmt.Fprint(w, `</ListBucketResult>`)
+
+	case r.Method == http.MethodGet:
+		data, ok := s.objects[path[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			return
+		}
+		w.Header().Set("ETag", `"`+s.etags[path[1]]+`"`)
+		w.Write(data)
+
+	case r.Method == http.MethodPut:
+		tag, exists := s.etags[path[1]]
+		if (r.Header.Get("If-None-Match") == "*" && exists) || (r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != `"`+tag+`"`) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			fmt.Fprint(w, `<Error><Code>PreconditionFailed</Code></Error>`)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		s.counter++
+		s.objects[path[1]] = data
+		s.etags[path[1]] = fmt.Sprintf("etag-%d", s.counter)
+		w.Header().Set("ETag", `"`+s.etags[path[1]]+`"`)
+	}
+}
+
+// Tests that objects are written conditionally, listed and read back.
+func TestS3Store(t *testing.T) {
+	server := httptest.NewServer(&fakeS3{objects: make(map[string][]byte), etags: make(map[string]string)})
+	defer server.Close()
+
+	var (
+		ctx   = context.Background()
+		store = NewS3Store(NewS3Client(server.URL, "auto", "access", "secret"), "bucket", "keys/")
+	)
+	version, err := store.Put(ctx, "a", []byte("v1"), "")
+	if err != nil {
+		t.Fatalf("failed to create object: %v", err)
+	}
+	if _, err := store.Put(ctx, "a", []byte("v1"), ""); !errors.Is(err, keystore.ErrSyncConflict) {
+		t.Errorf("duplicate creation error mismatch: have %v, want %v", err, keystore.ErrSyncConflict)
+	}
+	updated, err := store.Put(ctx, "a", []byte("v2"), version)
+	if err != nil {
+		t.Fatalf("failed to update object: %v", err)
+	}
+	if _, err := store.Put(ctx, "a", []byte("v3"), version); !errors.Is(err, keystore.ErrSyncConflict) {
+		t.Errorf("stale update error mismatch: have %v, want %v", err, keystore.ErrSyncConflict)
+	}
+	objects, err := store.List(ctx)
+	if err != nil {
+		t.Fatalf("failed to list objects: %v", err)
+	}
+	if len(objects) != 1 || objects["a"] != updated {
+		t.Errorf("listing mismatch: have %v, want map[a:%s]", objects, updated)
+	}
+	data, tag, err := store.Get(ctx, "a")
+	if err != nil {
+		t.Fatalf("failed to read object: %v", err)
+	}
+	if string(data) != "v2" || tag != updated {
+		t.Errorf("object mismatch: have %s (%s), want v2 (%s)", data, tag, updated)
+	}
+}
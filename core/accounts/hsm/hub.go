@@ -0,0 +1,175 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package hsm implements an account backend signing through PKCS#11 hardware
+// security modules such as SoftHSM, CloudHSM or YubiHSM.
+//
+// Every token slot of the module is surfaced as a wallet, every secp256k1 key
+// pair stored within the slot as an account of that wallet. Opening a wallet
+// logs into the token with the user PIN, private keys never leave the device.
+//
+// Vendor libraries are loaded at runtime through OpenModule, which requires cgo
+// and is unavailable on Windows. Other implementations can be supplied through
+// the Module interface.
+package hsm
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/event"
+	"github.com/liuguodong24-8/3fcoin/core/log"
+)
+
+// Scheme is the protocol scheme prefixing account and wallet URLs.
+const Scheme = "pkcs11"
+
+// refreshThrottling is the minimum time between slot refreshes to avoid
+// hammering the module with enumeration requests.
+const refreshThrottling = time.Second
+
+// KeyHandle identifies a key pair object within a token session.
+type KeyHandle uint
+
+// Module is the subset of a PKCS#11 library the backend relies on.
+type Module interface {
+	// Slots returns the identifiers of all slots with a token present.
+	Slots() ([]uint, error)
+
+	// OpenSession opens a read-write session on the token in the given slot and
+	// logs in as the user with the supplied PIN.
+	OpenSession(slot uint, pin string) (Session, error)
+}
+
+// Session is a logged in PKCS#11 session on a single token.
+type Session interface {
+	// Keys lists all secp256k1 key pairs stored on the token.
+	Keys() ([]KeyHandle, error)
+
+	// PublicKey returns the uncompressed SEC1 encoding of the key's public part.
+	PublicKey(key KeyHandle) ([]byte, error)
+
+	// Sign performs a raw CKM_ECDSA signature over the given hash, returning the
+	// 64 byte concatenation of r and s.
+	Sign(key KeyHandle, hash []byte) ([]byte, error)
+
+	// GenerateKey creates a new secp256k1 key pair on the token, marking the
+	// private part sensitive and non-extractable.
+	GenerateKey(label string) (KeyHandle, error)
+
+	// Close logs out and closes the session.
+	Close() error
+}
+
+// Hub is an accounts.Backend exposing the token slots of a PKCS#11 module as
+// wallets.
+type Hub struct {
+	module Module // PKCS#11 library the tokens are accessed through
+
+	refreshed   time.Time               // Time instance when the list of wallets was last refreshed
+	wallets     map[uint]*Wallet        // Wallets tracked per token slot
+	updateFeed  event.Feed              // Event feed to notify wallet additions/removals
+	updateScope event.SubscriptionScope // Subscription scope tracking current live listeners
+
+	stateLock sync.RWMutex // Protects the internals of the hub from racey access
+}
+
+// NewHub creates a wallet backend for the tokens reachable through module.
+func NewHub(module Module) (*Hub, error) {
+	hub := &Hub{
+		module:  module,
+		wallets: make(map[uint]*Wallet),
+	}
+	if err := hub.refreshWallets(); err != nil {
+		return nil, err
+	}
+	return hub, nil
+}
+
+// Wallets implements accounts.Backend, returning all the currently tracked
+// token slots.
+func (hub *Hub) Wallets() []accounts.Wallet {
+	if err := hub.refreshWallets(); err != nil {
+		log.Error("Failed to enumerate PKCS#11 slots", "err", err)
+	}
+	hub.stateLock.RLock()
+	defer hub.stateLock.RUnlock()
+
+	wallets := make([]accounts.Wallet, 0, len(hub.wallets))
+	for _, wallet := range hub.wallets {
+		wallets = append(wallets, wallet)
+	}
+	sort.Sort(accounts.WalletsByURL(wallets))
+	return wallets
+}
+
+// refreshWallets enumerates the slots of the module and updates the list of
+// wallets, firing events for arrived and dropped tokens.
+func (hub *Hub) refreshWallets() error {
+	hub.stateLock.RLock()
+	elapsed := time.Since(hub.refreshed)
+	hub.stateLock.RUnlock()
+
+	if elapsed < refreshThrottling {
+		return nil
+	}
+	slots, err := hub.module.Slots()
+	if err != nil {
+		return err
+	}
+	hub.stateLock.Lock()
+
+	var (
+		events []accounts.WalletEvent
+		seen   = make(map[uint]bool)
+	)
+	for _, slot := range slots {
+		seen[slot] = true
+		if _, ok := hub.wallets[slot]; ok {
+			continue
+		}
+		wallet := &Wallet{
+			hub:  hub,
+			slot: slot,
+			url:  accounts.URL{Scheme: Scheme, Path: fmt.Sprintf("slot/%d", slot)},
+		}
+		hub.wallets[slot] = wallet
+		events = append(events, accounts.WalletEvent{Wallet: wallet, Kind: accounts.WalletArrived})
+	}
+	for slot, wallet := range hub.wallets {
+		if !seen[slot] {
+			wallet.Close()
+			delete(hub.wallets, slot)
+			events = append(events, accounts.WalletEvent{Wallet: wallet, Kind: accounts.WalletDropped})
+		}
+	}
+	hub.refreshed = time.Now()
+	hub.stateLock.Unlock()
+
+	for _, event := range events {
+		hub.updateFeed.Send(event)
+	}
+	return nil
+}
+
+// Subscribe implements accounts.Backend, creating an async subscription to
+// receive notifications on the addition or removal of tokens.
+func (hub *Hub) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return hub.updateScope.Track(hub.updateFeed.Subscribe(sink))
+}
@@ -0,0 +1,546 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build cgo && !windows
+// +build cgo,!windows
+
+package hsm
+
+/*
+#cgo linux LDFLAGS: -ldl
+
+#include <dlfcn.h>
+#include <stdlib.h>
+#include <string.h>
+
+typedef unsigned long ck_ulong;
+typedef ck_ulong ck_rv;
+
+typedef struct {
+	ck_ulong type;
+	void *value;
+	ck_ulong length;
+} ck_attribute;
+
+typedef struct {
+	ck_ulong mechanism;
+	void *parameter;
+	ck_ulong length;
+} ck_mechanism;
+
+typedef struct {
+	void *create_mutex;
+	void *destroy_mutex;
+	void *lock_mutex;
+	void *unlock_mutex;
+	ck_ulong flags;
+	void *reserved;
+} ck_initialize_args;
+
+// ck_function_list mirrors CK_FUNCTION_LIST of PKCS#11 v2.x. Only the entries
+// used are typed, the others are kept as placeholders to retain the layout.
+typedef struct {
+	unsigned char version[2];
+	ck_rv (*C_Initialize)(void *);
+	ck_rv (*C_Finalize)(void *);
+	void *C_GetInfo, *C_GetFunctionList;
+	ck_rv (*C_GetSlotList)(unsigned char, ck_ulong *, ck_ulong *);
+	void *C_GetSlotInfo, *C_GetTokenInfo, *C_GetMechanismList, *C_GetMechanismInfo;
+	void *C_InitToken, *C_InitPIN, *C_SetPIN;
+	ck_rv (*C_OpenSession)(ck_ulong, ck_ulong, void *, void *, ck_ulong *);
+	ck_rv (*C_CloseSession)(ck_ulong);
+	void *C_CloseAllSessions, *C_GetSessionInfo, *C_GetOperationState, *C_SetOperationState;
+	ck_rv (*C_Login)(ck_ulong, ck_ulong, unsigned char *, ck_ulong);
+	ck_rv (*C_Logout)(ck_ulong);
+	void *C_CreateObject, *C_CopyObject, *C_DestroyObject, *C_GetObjectSize;
+	ck_rv (*C_GetAttributeValue)(ck_ulong, ck_ulong, ck_attribute *, ck_ulong);
+	void *C_SetAttributeValue;
+	ck_rv (*C_FindObjectsInit)(ck_ulong, ck_attribute *, ck_ulong);
+	ck_rv (*C_FindObjects)(ck_ulong, ck_ulong *, ck_ulong, ck_ulong *);
+	ck_rv (*C_FindObjectsFinal)(ck_ulong);
+	void *C_EncryptInit, *C_Encrypt, *C_EncryptUpdate, *C_EncryptFinal;
+	void *C_DecryptInit, *C_Decrypt, *C_DecryptUpdate, *C_DecryptFinal;
+	void *C_DigestInit, *C_Digest, *C_DigestUpdate, *C_DigestKey, *C_DigestFinal;
+	ck_rv (*C_SignInit)(ck_ulong, ck_mechanism *, ck_ulong);
+	ck_rv (*C_Sign)(ck_ulong, unsigned char *, ck_ulong, unsigned char *, ck_ulong *);
+	void *C_SignUpdate, *C_SignFinal, *C_SignRecoverInit, *C_SignRecover;
+	void *C_VerifyInit, *C_Verify, *C_VerifyUpdate, *C_VerifyFinal, *C_VerifyRecoverInit, *C_VerifyRecover;
+	void *C_DigestEncryptUpdate, *C_DecryptDigestUpdate, *C_SignEncryptUpdate, *C_DecryptVerifyUpdate;
+	void *C_GenerateKey;
+	ck_rv (*C_GenerateKeyPair)(ck_ulong, ck_mechanism *, ck_attribute *, ck_ulong, ck_attribute *, ck_ulong, ck_ulong *, ck_ulong *);
+	void *C_WrapKey, *C_UnwrapKey, *C_DeriveKey, *C_SeedRandom, *C_GenerateRandom;
+	void *C_GetFunctionStatus, *C_CancelFunction, *C_WaitForSlotEvent;
+} ck_function_list;
+
+static ck_rv p11_load(void *lib, ck_function_list **funcs) {
+	ck_rv (*get)(ck_function_list **) = (ck_rv (*)(ck_function_list **))dlsym(lib, "C_GetFunctionList");
+	if (get == NULL) {
+		return 0x54; // CKR_FUNCTION_NOT_SUPPORTED
+	}
+	return get(funcs);
+}
+
+static ck_rv p11_initialize(ck_function_list *f) {
+	ck_initialize_args args;
+	memset(&args, 0, sizeof(args));
+	args.flags = 0x2; // CKF_OS_LOCKING_OK
+	return f->C_Initialize(&args);
+}
+static ck_rv p11_finalize(ck_function_list *f) { return f->C_Finalize(NULL); }
+static ck_rv p11_get_slot_list(ck_function_list *f, ck_ulong *slots, ck_ulong *count) {
+	return f->C_GetSlotList(1, slots, count);
+}
+static ck_rv p11_open_session(ck_function_list *f, ck_ulong slot, ck_ulong *session) {
+	return f->C_OpenSession(slot, 0x4 | 0x2, NULL, NULL, session); // CKF_SERIAL_SESSION | CKF_RW_SESSION
+}
+static ck_rv p11_close_session(ck_function_list *f, ck_ulong session) { return f->C_CloseSession(session); }
+static ck_rv p11_login(ck_function_list *f, ck_ulong session, unsigned char *pin, ck_ulong length) {
+	return f->C_Login(session, 1, pin, length); // CKU_USER
+}
+static ck_rv p11_logout(ck_function_list *f, ck_ulong session) { return f->C_Logout(session); }
+static ck_rv p11_get_attribute_value(ck_function_list *f, ck_ulong session, ck_ulong object, ck_attribute *attrs, ck_ulong count) {
+	return f->C_GetAttributeValue(session, object, attrs, count);
+}
+static ck_rv p11_find_objects_init(ck_function_list *f, ck_ulong session, ck_attribute *attrs, ck_ulong count) {
+	return f->C_FindObjectsInit(session, attrs, count);
+}
+static ck_rv p11_find_objects(ck_function_list *f, ck_ulong session, ck_ulong *objects, ck_ulong max, ck_ulong *count) {
+	return f->C_FindObjects(session, objects, max, count);
+}
+static ck_rv p11_find_objects_final(ck_function_list *f, ck_ulong session) { return f->C_FindObjectsFinal(session); }
+static ck_rv p11_sign(ck_function_list *f, ck_ulong session, ck_ulong key, unsigned char *data, ck_ulong length, unsigned char *sig, ck_ulong *siglen) {
+	ck_mechanism mech = {0x1041, NULL, 0}; // CKM_ECDSA
+	ck_rv rv = f->C_SignInit(session, &mech, key);
+	if (rv != 0) {
+		return rv;
+	}
+	return f->C_Sign(session, data, length, sig, siglen);
+}
+static ck_rv p11_generate_key_pair(ck_function_list *f, ck_ulong session, ck_attribute *pub, ck_ulong npub, ck_attribute *priv, ck_ulong npriv, ck_ulong *pubkey, ck_ulong *privkey) {
+	ck_mechanism mech = {0x1040, NULL, 0}; // CKM_EC_KEY_PAIR_GEN
+	return f->C_GenerateKeyPair(session, &mech, pub, npub, priv, npriv, pubkey, privkey);
+}
+*/
+import "C"
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+// PKCS#11 constants used by the module, see the Cryptoki specification.
+const (
+	ckaClass       = 0x000
+	ckaToken       = 0x001
+	ckaPrivate     = 0x002
+	ckaLabel       = 0x003
+	ckaKeyType     = 0x100
+	ckaID          = 0x102
+	ckaSensitive   = 0x103
+	ckaSign        = 0x108
+	ckaVerify      = 0x10a
+	ckaExtractable = 0x162
+	ckaECParams    = 0x180
+	ckaECPoint     = 0x181
+
+	ckoPublicKey  = 2
+	ckoPrivateKey = 3
+	ckkEC         = 3
+
+	ckrOK                          = 0x000
+	ckrUserAlreadyLoggedIn         = 0x100
+	ckrCryptokiAlreadyInitialized  = 0x191
+	ckUnavailableInformation       = ^C.ck_ulong(0)
+	secp256k1SignatureLength       = 64
+	secp256k1UncompressedKeyLength = 65
+)
+
+// secp256k1Params is the DER encoded OID of the secp256k1 curve (1.3.132.0.10),
+// the CKA_EC_PARAMS value of keys on the curve.
+var secp256k1Params = []byte{0x06, 0x05, 0x2b, 0x81, 0x04, 0x00, 0x0a}
+
+// returnValues names the PKCS#11 error codes users are most likely to hit.
+var returnValues = map[C.ck_rv]string{
+	0x003: "CKR_SLOT_ID_INVALID",
+	0x005: "CKR_GENERAL_ERROR",
+	0x007: "CKR_ARGUMENTS_BAD",
+	0x030: "CKR_DEVICE_ERROR",
+	0x054: "CKR_FUNCTION_NOT_SUPPORTED",
+	0x060: "CKR_KEY_HANDLE_INVALID",
+	0x070: "CKR_MECHANISM_INVALID",
+	0x0a0: "CKR_PIN_INCORRECT",
+	0x0a4: "CKR_PIN_LOCKED",
+	0x0b3: "CKR_SESSION_HANDLE_INVALID",
+	0x0d1: "CKR_TEMPLATE_INCONSISTENT",
+	0x0e0: "CKR_TOKEN_NOT_PRESENT",
+	0x101: "CKR_USER_NOT_LOGGED_IN",
+	0x130: "CKR_DOMAIN_PARAMS_INVALID",
+	0x140: "CKR_CURVE_NOT_SUPPORTED",
+	0x190: "CKR_CRYPTOKI_NOT_INITIALIZED",
+}
+
+// Error is a failure reported by a PKCS#11 module.
+type Error struct {
+	Op   string // PKCS#11 function that failed
+	Code uint   // CKR_* return value of the function
+}
+
+func (e *Error) Error() string {
+	if name, ok := returnValues[C.ck_rv(e.Code)]; ok {
+		return fmt.Sprintf("pkcs11: %s failed: %s", e.Op, name)
+	}
+	return fmt.Sprintf("pkcs11: %s failed: 0x%x", e.Op, e.Code)
+}
+
+// check converts a PKCS#11 return value into an error.
+func check(op string, rv C.ck_rv) error {
+	if rv == ckrOK {
+		return nil
+	}
+	return &Error{Op: op, Code: uint(rv)}
+}
+
+// PKCS11Module is a Module backed by a vendor PKCS#11 library, such as
+// libsofthsm2.so, loaded at runtime.
+type PKCS11Module struct {
+	lib   unsafe.Pointer      // Handle of the dynamically loaded library
+	funcs *C.ck_function_list // Entry points of the library
+
+	lock sync.Mutex // Guards against use after Close
+}
+
+// OpenModule loads the PKCS#11 library at path and initializes it for use from
+// multiple threads.
+func OpenModule(path string) (*PKCS11Module, error) {
+	cpath := C.CString(path)
+	defer C.free(unsafe.Pointer(cpath))
+
+	lib := C.dlopen(cpath, C.RTLD_NOW)
+	if lib == nil {
+		return nil, fmt.Errorf("pkcs11: failed to load %s: %s", path, C.GoString(C.dlerror()))
+	}
+	var funcs *C.ck_function_list
+	if err := check("C_GetFunctionList", C.p11_load(lib, &funcs)); err != nil {
+		C.dlclose(lib)
+		return nil, err
+	}
+	// Tolerate libraries already initialized by another user in the process
+	if rv := C.p11_initialize(funcs); rv != ckrOK && rv != ckrCryptokiAlreadyInitialized {
+		C.dlclose(lib)
+		return nil, check("C_Initialize", rv)
+	}
+	return &PKCS11Module{lib: lib, funcs: funcs}, nil
+}
+
+// functions returns the entry points of the library, failing if the module was
+// closed already.
+func (m *PKCS11Module) functions() (*C.ck_function_list, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.funcs == nil {
+		return nil, errors.New("pkcs11: module closed")
+	}
+	return m.funcs, nil
+}
+
+// Close finalizes and unloads the library. Sessions opened through the module
+// must not be used afterwards.
+func (m *PKCS11Module) Close() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.funcs == nil {
+		return nil
+	}
+	err := check("C_Finalize", C.p11_finalize(m.funcs))
+	C.dlclose(m.lib)
+
+	m.funcs, m.lib = nil, nil
+	return err
+}
+
+// Slots implements Module, returning the identifiers of all slots with a token
+// present.
+func (m *PKCS11Module) Slots() ([]uint, error) {
+	funcs, err := m.functions()
+	if err != nil {
+		return nil, err
+	}
+	var count C.ck_ulong
+	if err := check("C_GetSlotList", C.p11_get_slot_list(funcs, nil, &count)); err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, nil
+	}
+	list := (*C.ck_ulong)(C.calloc(C.size_t(count), C.size_t(unsafe.Sizeof(C.ck_ulong(0)))))
+	defer C.free(unsafe.Pointer(list))
+
+	if err := check("C_GetSlotList", C.p11_get_slot_list(funcs, list, &count)); err != nil {
+		return nil, err
+	}
+	slots := make([]uint, 0, count)
+	for _, slot := range unsafe.Slice(list, count) {
+		slots = append(slots, uint(slot))
+	}
+	return slots, nil
+}
+
+// OpenSession implements Module, opening a read-write session on the token in
+// slot and logging in as the user with pin.
+func (m *PKCS11Module) OpenSession(slot uint, pin string) (Session, error) {
+	funcs, err := m.functions()
+	if err != nil {
+		return nil, err
+	}
+	var handle C.ck_ulong
+	if err := check("C_OpenSession", C.p11_open_session(funcs, C.ck_ulong(slot), &handle)); err != nil {
+		return nil, err
+	}
+	cpin := C.CBytes([]byte(pin))
+	defer C.free(cpin)
+
+	// Logins are per token, another session of the process may hold one already
+	if rv := C.p11_login(funcs, handle, (*C.uchar)(cpin), C.ck_ulong(len(pin))); rv != ckrOK && rv != ckrUserAlreadyLoggedIn {
+		C.p11_close_session(funcs, handle)
+		return nil, check("C_Login", rv)
+	}
+	return &pkcs11Session{funcs: funcs, handle: handle}, nil
+}
+
+// pkcs11Session is a logged in session on a token of a PKCS11Module.
+type pkcs11Session struct {
+	funcs  *C.ck_function_list // Entry points of the library the session belongs to
+	handle C.ck_ulong          // Session handle issued by the library
+}
+
+// Keys implements Session, listing the private keys on the secp256k1 curve.
+func (s *pkcs11Session) Keys() ([]KeyHandle, error) {
+	objects, err := s.find([]attribute{
+		ulongAttribute(ckaClass, ckoPrivateKey),
+		ulongAttribute(ckaKeyType, ckkEC),
+		{ckaECParams, secp256k1Params},
+	})
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]KeyHandle, len(objects))
+	for i, object := range objects {
+		keys[i] = KeyHandle(object)
+	}
+	return keys, nil
+}
+
+// PublicKey implements Session, looking up the public key sharing the CKA_ID
+// of the private key and returning its uncompressed point.
+func (s *pkcs11Session) PublicKey(key KeyHandle) ([]byte, error) {
+	id, err := s.attribute(C.ck_ulong(key), ckaID)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := s.find([]attribute{
+		ulongAttribute(ckaClass, ckoPublicKey),
+		ulongAttribute(ckaKeyType, ckkEC),
+		{ckaID, id},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(objects) != 1 {
+		return nil, fmt.Errorf("pkcs11: key %d has %d public keys", key, len(objects))
+	}
+	point, err := s.attribute(objects[0], ckaECPoint)
+	if err != nil {
+		return nil, err
+	}
+	return unwrapECPoint(point)
+}
+
+// Sign implements Session, signing hash with CKM_ECDSA.
+func (s *pkcs11Session) Sign(key KeyHandle, hash []byte) ([]byte, error) {
+	data := C.CBytes(hash)
+	defer C.free(data)
+
+	sig := (*C.uchar)(C.malloc(secp256k1SignatureLength))
+	defer C.free(unsafe.Pointer(sig))
+
+	length := C.ck_ulong(secp256k1SignatureLength)
+	if err := check("C_Sign", C.p11_sign(s.funcs, s.handle, C.ck_ulong(key), (*C.uchar)(data), C.ck_ulong(len(hash)), sig, &length)); err != nil {
+		return nil, err
+	}
+	return C.GoBytes(unsafe.Pointer(sig), C.int(length)), nil
+}
+
+// GenerateKey implements Session, generating a secp256k1 key pair stored on the
+// token. The private key is sensitive and can't be extracted, the two halves
+// are linked through a random CKA_ID.
+func (s *pkcs11Session) GenerateKey(label string) (KeyHandle, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return 0, err
+	}
+	public, npublic, freePublic := makeTemplate([]attribute{
+		ulongAttribute(ckaClass, ckoPublicKey),
+		ulongAttribute(ckaKeyType, ckkEC),
+		boolAttribute(ckaToken, true),
+		boolAttribute(ckaVerify, true),
+		{ckaECParams, secp256k1Params},
+		{ckaLabel, []byte(label)},
+		{ckaID, id},
+	})
+	defer freePublic()
+
+	private, nprivate, freePrivate := makeTemplate([]attribute{
+		ulongAttribute(ckaClass, ckoPrivateKey),
+		ulongAttribute(ckaKeyType, ckkEC),
+		boolAttribute(ckaToken, true),
+		boolAttribute(ckaPrivate, true),
+		boolAttribute(ckaSensitive, true),
+		boolAttribute(ckaExtractable, false),
+		boolAttribute(ckaSign, true),
+		{ckaLabel, []byte(label)},
+		{ckaID, id},
+	})
+	defer freePrivate()
+
+	var pubkey, privkey C.ck_ulong
+	if err := check("C_GenerateKeyPair", C.p11_generate_key_pair(s.funcs, s.handle, public, npublic, private, nprivate, &pubkey, &privkey)); err != nil {
+		return 0, err
+	}
+	return KeyHandle(privkey), nil
+}
+
+// Close implements Session, logging out and closing the session.
+func (s *pkcs11Session) Close() error {
+	logout := check("C_Logout", C.p11_logout(s.funcs, s.handle))
+	if err := check("C_CloseSession", C.p11_close_session(s.funcs, s.handle)); err != nil {
+		return err
+	}
+	return logout
+}
+
+// find returns the handles of all objects matching the template.
+func (s *pkcs11Session) find(attrs []attribute) ([]C.ck_ulong, error) {
+	template, count, free := makeTemplate(attrs)
+	defer free()
+
+	if err := check("C_FindObjectsInit", C.p11_find_objects_init(s.funcs, s.handle, template, count)); err != nil {
+		return nil, err
+	}
+	defer C.p11_find_objects_final(s.funcs, s.handle)
+
+	const batch = 16
+	buffer := (*C.ck_ulong)(C.calloc(batch, C.size_t(unsafe.Sizeof(C.ck_ulong(0)))))
+	defer C.free(unsafe.Pointer(buffer))
+
+	var objects []C.ck_ulong
+	for {
+		var found C.ck_ulong
+		if err := check("C_FindObjects", C.p11_find_objects(s.funcs, s.handle, buffer, batch, &found)); err != nil {
+			return nil, err
+		}
+		if found == 0 {
+			return objects, nil
+		}
+		objects = append(objects, unsafe.Slice(buffer, found)...)
+	}
+}
+
+// attribute retrieves the value of a single attribute of an object.
+func (s *pkcs11Session) attribute(object C.ck_ulong, typ C.ck_ulong) ([]byte, error) {
+	attr := (*C.ck_attribute)(C.calloc(1, C.size_t(unsafe.Sizeof(C.ck_attribute{}))))
+	defer C.free(unsafe.Pointer(attr))
+
+	// Query the length of the value first, then the value itself
+	attr._type = typ
+	if err := check("C_GetAttributeValue", C.p11_get_attribute_value(s.funcs, s.handle, object, attr, 1)); err != nil {
+		return nil, err
+	}
+	if attr.length == ckUnavailableInformation {
+		return nil, fmt.Errorf("pkcs11: attribute 0x%x of object %d unavailable", typ, object)
+	}
+	if attr.length == 0 {
+		return nil, nil
+	}
+	attr.value = C.malloc(C.size_t(attr.length))
+	defer C.free(attr.value)
+
+	if err := check("C_GetAttributeValue", C.p11_get_attribute_value(s.funcs, s.handle, object, attr, 1)); err != nil {
+		return nil, err
+	}
+	return C.GoBytes(attr.value, C.int(attr.length)), nil
+}
+
+// attribute is an object attribute of a template, with its value encoded the
+// way the module expects it.
+type attribute struct {
+	typ   C.ck_ulong
+	value []byte
+}
+
+// ulongAttribute creates an attribute holding a native CK_ULONG.
+func ulongAttribute(typ C.ck_ulong, value C.ck_ulong) attribute {
+	blob := make([]byte, unsafe.Sizeof(value))
+	*(*C.ck_ulong)(unsafe.Pointer(&blob[0])) = value
+	return attribute{typ, blob}
+}
+
+// boolAttribute creates an attribute holding a CK_BBOOL.
+func boolAttribute(typ C.ck_ulong, value bool) attribute {
+	if value {
+		return attribute{typ, []byte{1}}
+	}
+	return attribute{typ, []byte{0}}
+}
+
+// makeTemplate copies attributes into C memory, as cgo doesn't permit passing
+// Go memory holding pointers. The returned function releases the copy.
+func makeTemplate(attrs []attribute) (*C.ck_attribute, C.ck_ulong, func()) {
+	template := (*C.ck_attribute)(C.calloc(C.size_t(len(attrs)), C.size_t(unsafe.Sizeof(C.ck_attribute{}))))
+	entries := unsafe.Slice(template, len(attrs))
+
+	for i, attr := range attrs {
+		entries[i]._type = attr.typ
+		entries[i].length = C.ck_ulong(len(attr.value))
+		if len(attr.value) > 0 {
+			entries[i].value = C.CBytes(attr.value)
+		}
+	}
+	free := func() {
+		for _, entry := range entries {
+			C.free(entry.value)
+		}
+		C.free(unsafe.Pointer(template))
+	}
+	return template, C.ck_ulong(len(attrs)), free
+}
+
+// unwrapECPoint extracts the uncompressed SEC1 point from a CKA_EC_POINT value.
+// The specification mandates a DER octet string, but some modules return the
+// raw point instead.
+func unwrapECPoint(point []byte) ([]byte, error) {
+	switch {
+	case len(point) == secp256k1UncompressedKeyLength && point[0] == 0x04:
+		return point, nil
+	case len(point) == secp256k1UncompressedKeyLength+2 && point[0] == 0x04 && point[1] == secp256k1UncompressedKeyLength:
+		return point[2:], nil
+	}
+	return nil, fmt.Errorf("pkcs11: unsupported EC point encoding %x", point)
+}
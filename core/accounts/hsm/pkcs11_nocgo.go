@@ -0,0 +1,46 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !cgo || windows
+// +build !cgo windows
+
+package hsm
+
+import "errors"
+
+// errUnsupported is returned by every PKCS11Module method if the package was
+// built without cgo or on Windows, where vendor libraries can't be loaded.
+var errUnsupported = errors.New("pkcs11: loading modules is not supported on this platform")
+
+// PKCS11Module is a Module backed by a vendor PKCS#11 library. This build does
+// not support loading them.
+type PKCS11Module struct{}
+
+// OpenModule always fails, as this build can't load PKCS#11 libraries.
+func OpenModule(path string) (*PKCS11Module, error) {
+	return nil, errUnsupported
+}
+
+// Close implements the PKCS11Module closer, a noop for this build.
+func (m *PKCS11Module) Close() error { return nil }
+
+// Slots implements Module, always failing for this build.
+func (m *PKCS11Module) Slots() ([]uint, error) { return nil, errUnsupported }
+
+// OpenSession implements Module, always failing for this build.
+func (m *PKCS11Module) OpenSession(slot uint, pin string) (Session, error) {
+	return nil, errUnsupported
+}
@@ -0,0 +1,59 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build cgo && !windows
+// +build cgo,!windows
+
+package hsm
+
+import (
+	"bytes"
+	"testing"
+)
+
+// Tests that EC points are accepted both DER wrapped as the specification
+// mandates and raw as some modules return them.
+func TestUnwrapECPoint(t *testing.T) {
+	point := append([]byte{0x04}, bytes.Repeat([]byte{0x11}, 64)...)
+
+	tests := []struct {
+		input []byte
+		ok    bool
+	}{
+		{point, true},
+		{append([]byte{0x04, 0x41}, point...), true},
+		{append([]byte{0x04, 0x40}, point[1:]...), false},
+		{append([]byte{0x02}, point[1:33]...), false},
+		{nil, false},
+	}
+	for i, tt := range tests {
+		have, err := unwrapECPoint(tt.input)
+		if (err == nil) != tt.ok {
+			t.Errorf("test %d: error mismatch: have %v, want ok %v", i, err, tt.ok)
+			continue
+		}
+		if tt.ok && !bytes.Equal(have, point) {
+			t.Errorf("test %d: point mismatch: have %x, want %x", i, have, point)
+		}
+	}
+}
+
+// Tests that loading a missing library fails instead of crashing.
+func TestOpenMissingModule(t *testing.T) {
+	if _, err := OpenModule("/nonexistent/libpkcs11.so"); err == nil {
+		t.Fatalf("missing module loaded")
+	}
+}
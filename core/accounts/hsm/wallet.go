@@ -0,0 +1,273 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hsm
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	ethereum "github.com/liuguodong24-8/3fcoin"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+var (
+	secp256k1N     = crypto.S256().Params().N
+	secp256k1halfN = new(big.Int).Rsh(secp256k1N, 1)
+)
+
+// ErrSignerMismatch is returned if a signature produced by the token does not
+// recover to the account it was requested from.
+var ErrSignerMismatch = errors.New("signature does not match signing key")
+
+// Wallet represents a single PKCS#11 token slot and the secp256k1 key pairs
+// stored on it.
+type Wallet struct {
+	hub  *Hub         // Hub the wallet was discovered by
+	slot uint         // Token slot identifier within the module
+	url  accounts.URL // Textual URL uniquely identifying this wallet
+
+	session  Session                      // Logged in session, nil if the wallet is closed
+	accounts []accounts.Account           // Accounts backed by key pairs on the token
+	keys     map[common.Address]KeyHandle // Key handles to sign with per account
+
+	lock sync.Mutex // PKCS#11 sessions are not safe for concurrent use
+}
+
+// URL implements accounts.Wallet, returning the URL of the token slot.
+func (w *Wallet) URL() accounts.URL {
+	return w.url
+}
+
+// Status implements accounts.Wallet, returning whether the token is logged in.
+func (w *Wallet) Status() (string, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.session == nil {
+		return "Closed", nil
+	}
+	return fmt.Sprintf("Open, %d keys", len(w.accounts)), nil
+}
+
+// Open implements accounts.Wallet, logging into the token with passphrase as
+// the user PIN and loading the key pairs stored on it.
+func (w *Wallet) Open(passphrase string) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.session != nil {
+		return accounts.ErrWalletAlreadyOpen
+	}
+	session, err := w.hub.module.OpenSession(w.slot, passphrase)
+	if err != nil {
+		return err
+	}
+	handles, err := session.Keys()
+	if err != nil {
+		session.Close()
+		return err
+	}
+	w.session = session
+	w.accounts = nil
+	w.keys = make(map[common.Address]KeyHandle)
+
+	for _, handle := range handles {
+		if _, err := w.track(handle); err != nil {
+			w.close()
+			return err
+		}
+	}
+	return nil
+}
+
+// track resolves the address of a key pair and adds it to the tracked accounts.
+// The lock must be held by the caller.
+func (w *Wallet) track(handle KeyHandle) (accounts.Account, error) {
+	blob, err := w.session.PublicKey(handle)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	pubkey, err := crypto.UnmarshalPubkey(blob)
+	if err != nil {
+		return accounts.Account{}, fmt.Errorf("key %d: %v", handle, err)
+	}
+	account := accounts.Account{
+		Address: crypto.PubkeyToAddress(*pubkey),
+		URL:     accounts.URL{Scheme: Scheme, Path: fmt.Sprintf("%s/key/%d", w.url.Path, handle)},
+	}
+	w.keys[account.Address] = handle
+	w.accounts = append(w.accounts, account)
+	sort.Sort(accounts.AccountsByURL(w.accounts))
+
+	return account, nil
+}
+
+// Close implements accounts.Wallet, logging out of the token.
+func (w *Wallet) Close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	return w.close()
+}
+
+// close is the internal wallet closer that assumes the lock is held.
+func (w *Wallet) close() error {
+	if w.session == nil {
+		return nil
+	}
+	err := w.session.Close()
+
+	w.session = nil
+	w.accounts, w.keys = nil, nil
+	return err
+}
+
+// Accounts implements accounts.Wallet, returning the list of accounts backed by
+// key pairs on the token.
+func (w *Wallet) Accounts() []accounts.Account {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	cpy := make([]accounts.Account, len(w.accounts))
+	copy(cpy, w.accounts)
+	return cpy
+}
+
+// Contains implements accounts.Wallet, returning whether a particular account is
+// or is not stored on this token.
+func (w *Wallet) Contains(account accounts.Account) bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	_, exists := w.keys[account.Address]
+	return exists
+}
+
+// Derive implements accounts.Wallet, but token keys are not hierarchical, so
+// this method will always return an error.
+func (w *Wallet) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but token keys are not hierarchical,
+// so this method is a noop.
+func (w *Wallet) SelfDerive(bases []accounts.DerivationPath, chain ethereum.ChainStateReader) {
+}
+
+// NewAccount generates a new key pair on the token and starts tracking it.
+func (w *Wallet) NewAccount(label string) (accounts.Account, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.session == nil {
+		return accounts.Account{}, accounts.ErrWalletClosed
+	}
+	handle, err := w.session.GenerateKey(label)
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	return w.track(handle)
+}
+
+// signHash requests the token to sign hash with the key backing account and
+// converts the raw ECDSA signature into the canonical [R || S || V] format.
+func (w *Wallet) signHash(account accounts.Account, hash []byte) ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.session == nil {
+		return nil, accounts.ErrWalletClosed
+	}
+	handle, ok := w.keys[account.Address]
+	if !ok {
+		return nil, accounts.ErrUnknownAccount
+	}
+	sig, err := w.session.Sign(handle, hash)
+	if err != nil {
+		return nil, err
+	}
+	return recoverableSignature(hash, sig, account.Address)
+}
+
+// recoverableSignature normalizes a raw 64 byte ECDSA signature to the lower
+// half of the curve order and appends the recovery id matching signer.
+func recoverableSignature(hash []byte, raw []byte, signer common.Address) ([]byte, error) {
+	if len(raw) != 64 {
+		return nil, fmt.Errorf("invalid signature length %d", len(raw))
+	}
+	s := new(big.Int).SetBytes(raw[32:])
+	if s.Cmp(secp256k1halfN) > 0 {
+		s.Sub(secp256k1N, s)
+	}
+	sig := make([]byte, crypto.SignatureLength)
+	copy(sig, raw[:32])
+	s.FillBytes(sig[32:64])
+
+	for v := byte(0); v < 2; v++ {
+		sig[crypto.RecoveryIDOffset] = v
+		if pubkey, err := crypto.SigToPub(hash, sig); err == nil && crypto.PubkeyToAddress(*pubkey) == signer {
+			return sig, nil
+		}
+	}
+	return nil, ErrSignerMismatch
+}
+
+// SignData implements accounts.Wallet, signing keccak256(data) on the token.
+func (w *Wallet) SignData(account accounts.Account, mimeType string, data []byte) ([]byte, error) {
+	return w.signHash(account, crypto.Keccak256(data))
+}
+
+// SignDataWithPassphrase implements accounts.Wallet. The token is unlocked on
+// open, so the passphrase is silently ignored.
+func (w *Wallet) SignDataWithPassphrase(account accounts.Account, passphrase, mimeType string, data []byte) ([]byte, error) {
+	return w.SignData(account, mimeType, data)
+}
+
+// SignText implements accounts.Wallet, signing the hash of the given text
+// prefixed by the Ethereum prefix scheme.
+func (w *Wallet) SignText(account accounts.Account, text []byte) ([]byte, error) {
+	return w.signHash(account, accounts.TextHash(text))
+}
+
+// SignTextWithPassphrase implements accounts.Wallet. The token is unlocked on
+// open, so the passphrase is silently ignored.
+func (w *Wallet) SignTextWithPassphrase(account accounts.Account, passphrase string, text []byte) ([]byte, error) {
+	return w.SignText(account, text)
+}
+
+// SignTx implements accounts.Wallet, signing the transaction on the token.
+func (w *Wallet) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(chainID)
+
+	sig, err := w.signHash(account, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, sig)
+}
+
+// SignTxWithPassphrase implements accounts.Wallet. The token is unlocked on
+// open, so the passphrase is silently ignored.
+func (w *Wallet) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return w.SignTx(account, tx, chainID)
+}
@@ -0,0 +1,167 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hsm
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// testModule is a software PKCS#11 stand-in with a single slot.
+type testModule struct {
+	pin    string
+	keys   []*ecdsa.PrivateKey
+	highS  bool // Whether to return signatures in the upper half of the curve order
+	closed bool
+}
+
+func (m *testModule) Slots() ([]uint, error) { return []uint{7}, nil }
+
+func (m *testModule) OpenSession(slot uint, pin string) (Session, error) {
+	if pin != m.pin {
+		return nil, errors.New("CKR_PIN_INCORRECT")
+	}
+	m.closed = false
+	return m, nil
+}
+
+func (m *testModule) Keys() ([]KeyHandle, error) {
+	handles := make([]KeyHandle, len(m.keys))
+	for i := range m.keys {
+		handles[i] = KeyHandle(i)
+	}
+	return handles, nil
+}
+
+func (m *testModule) PublicKey(key KeyHandle) ([]byte, error) {
+	return crypto.FromECDSAPub(&m.keys[key].PublicKey), nil
+}
+
+func (m *testModule) Sign(key KeyHandle, hash []byte) ([]byte, error) {
+	sig, err := crypto.Sign(hash, m.keys[key])
+	if err != nil {
+		return nil, err
+	}
+	if m.highS {
+		s := new(big.Int).Sub(secp256k1N, new(big.Int).SetBytes(sig[32:64]))
+		s.FillBytes(sig[32:64])
+	}
+	return sig[:64], nil
+}
+
+func (m *testModule) GenerateKey(label string) (KeyHandle, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return 0, err
+	}
+	m.keys = append(m.keys, key)
+	return KeyHandle(len(m.keys) - 1), nil
+}
+
+func (m *testModule) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestWalletLifecycle(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	module := &testModule{pin: "1234", keys: []*ecdsa.PrivateKey{key}}
+
+	hub, err := NewHub(module)
+	if err != nil {
+		t.Fatalf("failed to create hub: %v", err)
+	}
+	wallets := hub.Wallets()
+	if len(wallets) != 1 {
+		t.Fatalf("wallet count mismatch: have %d, want 1", len(wallets))
+	}
+	wallet := wallets[0].(*Wallet)
+	if wallet.URL().String() != "pkcs11://slot/7" {
+		t.Errorf("wallet URL mismatch: have %s", wallet.URL())
+	}
+	if err := wallet.Open("0000"); err == nil {
+		t.Fatalf("opened wallet with wrong PIN")
+	}
+	if err := wallet.Open("1234"); err != nil {
+		t.Fatalf("failed to open wallet: %v", err)
+	}
+	accs := wallet.Accounts()
+	if len(accs) != 1 || accs[0].Address != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("account mismatch: have %v", accs)
+	}
+	created, err := wallet.NewAccount("treasury")
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if !wallet.Contains(created) {
+		t.Errorf("generated account not tracked")
+	}
+	if err := wallet.Close(); err != nil {
+		t.Fatalf("failed to close wallet: %v", err)
+	}
+	if !module.closed {
+		t.Errorf("session not closed")
+	}
+	if _, err := wallet.SignText(created, []byte("hello")); err != accounts.ErrWalletClosed {
+		t.Errorf("closed wallet signing error mismatch: have %v, want %v", err, accounts.ErrWalletClosed)
+	}
+}
+
+func TestWalletSigning(t *testing.T) {
+	for _, highS := range []bool{false, true} {
+		key, _ := crypto.GenerateKey()
+		module := &testModule{pin: "1234", keys: []*ecdsa.PrivateKey{key}, highS: highS}
+
+		hub, _ := NewHub(module)
+		wallet := hub.Wallets()[0]
+		if err := wallet.Open("1234"); err != nil {
+			t.Fatalf("failed to open wallet: %v", err)
+		}
+		account := wallet.Accounts()[0]
+
+		sig, err := wallet.SignText(account, []byte("hello"))
+		if err != nil {
+			t.Fatalf("highS %v: failed to sign text: %v", highS, err)
+		}
+		r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:64])
+		if !crypto.ValidateSignatureValues(sig[64], r, s, true) {
+			t.Errorf("highS %v: non canonical signature %x", highS, sig)
+		}
+		pubkey, err := crypto.SigToPub(accounts.TextHash([]byte("hello")), sig)
+		if err != nil || crypto.PubkeyToAddress(*pubkey) != account.Address {
+			t.Errorf("highS %v: signature does not recover to signer", highS)
+		}
+		chainID := big.NewInt(98888)
+		tx := types.NewTransaction(0, common.Address{1}, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+		signed, err := wallet.SignTx(account, tx, chainID)
+		if err != nil {
+			t.Fatalf("highS %v: failed to sign transaction: %v", highS, err)
+		}
+		sender, err := types.Sender(types.LatestSignerForChainID(chainID), signed)
+		if err != nil || sender != account.Address {
+			t.Errorf("highS %v: sender mismatch: have %v, want %v (%v)", highS, sender, account.Address, err)
+		}
+	}
+}
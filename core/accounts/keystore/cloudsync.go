@@ -0,0 +1,460 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// cloudSyncStateFile is the hidden file within the keystore directory that
+	// tracks the versions of the key files last synced. Hidden files are ignored
+	// by the keystore itself.
+	cloudSyncStateFile = ".cloudsync.json"
+
+	// cloudEnvelopeVersion is the version of the encrypted object format.
+	cloudEnvelopeVersion = 1
+)
+
+var (
+	// ErrSyncConflict is returned by a BlobStore if a conditional write failed
+	// because the object was modified concurrently.
+	ErrSyncConflict = errors.New("remote key file modified concurrently")
+
+	// ErrSyncCorrupted is returned if a remote object can't be authenticated.
+	ErrSyncCorrupted = errors.New("remote key file corrupted")
+)
+
+// BlobStore is a remote object storage key files can be mirrored into, such as
+// an S3 or GCS bucket (see package cloudstore). Objects are versioned by an
+// opaque tag (e.g. an ETag) changing on every modification.
+type BlobStore interface {
+	// List returns the current versions of all objects, keyed by name.
+	List(ctx context.Context) (map[string]string, error)
+
+	// Get retrieves an object and its current version.
+	Get(ctx context.Context, name string) ([]byte, string, error)
+
+	// Put writes an object if its current version matches the given one, or
+	// if it doesn't exist yet when the version is empty. ErrSyncConflict is
+	// returned otherwise. The new version of the object is returned.
+	Put(ctx context.Context, name string, data []byte, version string) (string, error)
+}
+
+// KeyWrapper encrypts the data keys protecting the synced key files, the key
+// encryption key never leaving the wrapper. NewPassphraseKeyWrapper provides
+// one deriving the key encryption key from a passphrase.
+type KeyWrapper interface {
+	WrapKey(ctx context.Context, key []byte) ([]byte, error)
+	UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// passphraseWrapper is a KeyWrapper encrypting data keys with a key derived
+// from a passphrase via scrypt.
+type passphraseWrapper struct {
+	passphrase string
+	scryptN    int
+	scryptP    int
+	salt       []byte            // Salt used for wrapping new keys
+	lock       sync.Mutex        // Lock protecting the derived key cache
+	derived    map[string][]byte // Derived keys cached by salt, scrypt is slow
+}
+
+// NewPassphraseKeyWrapper creates a KeyWrapper deriving the key encryption key
+// from a passphrase with the given scrypt parameters.
+func NewPassphraseKeyWrapper(passphrase string, scryptN, scryptP int) KeyWrapper {
+	return &passphraseWrapper{
+		passphrase: passphrase,
+		scryptN:    scryptN,
+		scryptP:    scryptP,
+		derived:    make(map[string][]byte),
+	}
+}
+
+// kek derives the key encryption key for a salt.
+func (w *passphraseWrapper) kek(salt []byte) ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if key, ok := w.derived[string(salt)]; ok {
+		return key, nil
+	}
+	key, err := scrypt.Key([]byte(w.passphrase), salt, w.scryptN, scryptR, w.scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	w.derived[string(salt)] = key
+	return key, nil
+}
+
+// WrapKey implements KeyWrapper, producing salt || nonce || ciphertext.
+func (w *passphraseWrapper) WrapKey(ctx context.Context, key []byte) ([]byte, error) {
+	w.lock.Lock()
+	if w.salt == nil {
+		w.salt = make([]byte, 32)
+		if _, err := io.ReadFull(crand.Reader, w.salt); err != nil {
+			w.lock.Unlock()
+			return nil, err
+		}
+	}
+	salt := w.salt
+	w.lock.Unlock()
+
+	kek, err := w.kek(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce, sealed, err := sealGCM(kek, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(append(append([]byte{}, salt...), nonce...), sealed...), nil
+}
+
+// UnwrapKey implements KeyWrapper.
+func (w *passphraseWrapper) UnwrapKey(ctx context.Context, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 32+12 {
+		return nil, ErrSyncCorrupted
+	}
+	kek, err := w.kek(wrapped[:32])
+	if err != nil {
+		return nil, err
+	}
+	key, err := openGCM(kek, wrapped[32:44], wrapped[44:], nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return key, nil
+}
+
+// cloudEnvelope is the encrypted form of a key file stored remotely. The file
+// is encrypted with a random data key, which is itself wrapped by the
+// KeyWrapper. The object name is authenticated as additional data, preventing
+// objects from being swapped.
+type cloudEnvelope struct {
+	Version    int           `json:"version"`
+	WrappedKey hexutil.Bytes `json:"wrappedKey"`
+	Nonce      hexutil.Bytes `json:"nonce"`
+	Ciphertext hexutil.Bytes `json:"ciphertext"`
+}
+
+// cloudPayload is the plaintext content of an envelope.
+type cloudPayload struct {
+	Name    string `json:"name"`
+	Content []byte `json:"content"`
+}
+
+// SyncReport lists the key files transferred by a sync run.
+type SyncReport struct {
+	Uploaded   []string // Key files mirrored into the remote store
+	Downloaded []string // Key files restored from the remote store
+	Conflicts  []string // Key files modified both locally and remotely
+}
+
+// cloudSyncEntry is the state of a key file at the time it was last synced.
+type cloudSyncEntry struct {
+	File   string `json:"file"`   // Name of the key file in the keystore
+	Local  string `json:"local"`  // SHA256 of the local content
+	Remote string `json:"remote"` // Version of the remote object
+}
+
+// CloudSync mirrors a keystore directory into a remote BlobStore, encrypting
+// every key file client side so that no plaintext reaches the store.
+//
+// Syncing is two-way: new and modified key files are uploaded, remote ones
+// missing or outdated locally are restored. Key files modified on both sides
+// since the last sync are reported as conflicts and left untouched. Remote
+// copies are never deleted, so keys survive the loss of the host.
+type CloudSync struct {
+	keydir  string
+	store   BlobStore
+	wrapper KeyWrapper
+	lock    sync.Mutex
+}
+
+// NewCloudSync creates a syncer of a keystore directory.
+func NewCloudSync(keydir string, store BlobStore, wrapper KeyWrapper) *CloudSync {
+	return &CloudSync{keydir: keydir, store: store, wrapper: wrapper}
+}
+
+// localKeyFile is a key file read from the keystore directory.
+type localKeyFile struct {
+	name    string
+	content []byte
+	hash    string
+}
+
+// Sync runs a two-way synchronisation of the keystore with the remote store.
+func (s *CloudSync) Sync(ctx context.Context) (*SyncReport, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	state, err := s.loadState()
+	if err != nil {
+		return nil, err
+	}
+	local, err := s.localFiles()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := s.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]string, 0, len(local)+len(remote))
+	for object := range local {
+		objects = append(objects, object)
+	}
+	for object := range remote {
+		if _, ok := local[object]; !ok {
+			objects = append(objects, object)
+		}
+	}
+	sort.Strings(objects)
+
+	report := new(SyncReport)
+	for _, object := range objects {
+		var (
+			entry, known        = state[object]
+			file, haveLocal     = local[object]
+			version, haveRemote = remote[object]
+
+			localChanged  = haveLocal && (!known || entry.Local != file.hash)
+			remoteChanged = haveRemote && (!known || entry.Remote != version)
+		)
+		switch {
+		case haveLocal && !remoteChanged && (localChanged || !haveRemote):
+			// New or modified locally (or lost remotely), upload
+			if !haveRemote {
+				version = ""
+			}
+			updated, err := s.upload(ctx, object, file, version)
+			if err == ErrSyncConflict {
+				report.Conflicts = append(report.Conflicts, file.name)
+				continue
+			}
+			if err != nil {
+				return report, err
+			}
+			state[object] = cloudSyncEntry{File: file.name, Local: file.hash, Remote: updated}
+			report.Uploaded = append(report.Uploaded, file.name)
+
+		case haveRemote && !localChanged && (remoteChanged || !haveLocal):
+			// New or modified remotely (or lost locally), restore
+			payload, updated, err := s.download(ctx, object)
+			if err != nil {
+				return report, err
+			}
+			if err := writeKeyFile(filepath.Join(s.keydir, payload.Name), payload.Content); err != nil {
+				return report, err
+			}
+			state[object] = cloudSyncEntry{File: payload.Name, Local: contentHash(payload.Content), Remote: updated}
+			report.Downloaded = append(report.Downloaded, payload.Name)
+
+		case localChanged && remoteChanged:
+			// Modified on both sides, only fine if the same change was made
+			payload, updated, err := s.download(ctx, object)
+			if err != nil {
+				return report, err
+			}
+			if !bytes.Equal(payload.Content, file.content) {
+				report.Conflicts = append(report.Conflicts, file.name)
+				continue
+			}
+			state[object] = cloudSyncEntry{File: file.name, Local: file.hash, Remote: updated}
+		}
+	}
+	return report, s.saveState(state)
+}
+
+// localFiles reads all key files from the keystore directory, keyed by the
+// name of the object they are stored under remotely.
+func (s *CloudSync) localFiles() (map[string]*localKeyFile, error) {
+	files, err := ioutil.ReadDir(s.keydir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[string]*localKeyFile{}, nil
+		}
+		return nil, err
+	}
+	local := make(map[string]*localKeyFile)
+	for _, fi := range files {
+		if nonKeyFile(fi) {
+			continue
+		}
+		content, err := ioutil.ReadFile(filepath.Join(s.keydir, fi.Name()))
+		if err != nil {
+			return nil, err
+		}
+		local[cloudObjectName(fi.Name())] = &localKeyFile{
+			name:    fi.Name(),
+			content: content,
+			hash:    contentHash(content),
+		}
+	}
+	return local, nil
+}
+
+// upload encrypts and writes a key file into the remote store.
+func (s *CloudSync) upload(ctx context.Context, object string, file *localKeyFile, version string) (string, error) {
+	payload, err := json.Marshal(&cloudPayload{Name: file.name, Content: file.content})
+	if err != nil {
+		return "", err
+	}
+	dataKey := make([]byte, 32)
+	if _, err := io.ReadFull(crand.Reader, dataKey); err != nil {
+		return "", err
+	}
+	nonce, ciphertext, err := sealGCM(dataKey, payload, []byte(object))
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := s.wrapper.WrapKey(ctx, dataKey)
+	if err != nil {
+		return "", err
+	}
+	blob, err := json.Marshal(&cloudEnvelope{
+		Version:    cloudEnvelopeVersion,
+		WrappedKey: wrapped,
+		Nonce:      nonce,
+		Ciphertext: ciphertext,
+	})
+	if err != nil {
+		return "", err
+	}
+	return s.store.Put(ctx, object, blob, version)
+}
+
+// download retrieves and decrypts a key file from the remote store.
+func (s *CloudSync) download(ctx context.Context, object string) (*cloudPayload, string, error) {
+	blob, version, err := s.store.Get(ctx, object)
+	if err != nil {
+		return nil, "", err
+	}
+	var envelope cloudEnvelope
+	if err := json.Unmarshal(blob, &envelope); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrSyncCorrupted, err)
+	}
+	if envelope.Version != cloudEnvelopeVersion {
+		return nil, "", fmt.Errorf("unsupported remote key file version %d", envelope.Version)
+	}
+	dataKey, err := s.wrapper.UnwrapKey(ctx, envelope.WrappedKey)
+	if err != nil {
+		return nil, "", err
+	}
+	plaintext, err := openGCM(dataKey, envelope.Nonce, envelope.Ciphertext, []byte(object))
+	if err != nil {
+		return nil, "", ErrSyncCorrupted
+	}
+	payload := new(cloudPayload)
+	if err := json.Unmarshal(plaintext, payload); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrSyncCorrupted, err)
+	}
+	// Never write outside of the keystore or into files it ignores
+	if payload.Name != filepath.Base(payload.Name) || strings.HasPrefix(payload.Name, ".") || cloudObjectName(payload.Name) != object {
+		return nil, "", fmt.Errorf("%w: invalid key file name %q", ErrSyncCorrupted, payload.Name)
+	}
+	return payload, version, nil
+}
+
+func (s *CloudSync) loadState() (map[string]cloudSyncEntry, error) {
+	state := make(map[string]cloudSyncEntry)
+
+	blob, err := ioutil.ReadFile(filepath.Join(s.keydir, cloudSyncStateFile))
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(blob, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *CloudSync) saveState(state map[string]cloudSyncEntry) error {
+	blob, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(filepath.Join(s.keydir, cloudSyncStateFile), blob)
+}
+
+// cloudObjectName returns the remote object name of a key file. The file name
+// is hashed so that the accounts held aren't revealed by the bucket listing.
+func cloudObjectName(file string) string {
+	hash := sha256.Sum256([]byte(file))
+	return hex.EncodeToString(hash[:])
+}
+
+func contentHash(content []byte) string {
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:])
+}
+
+// sealGCM encrypts plaintext with AES-256-GCM under a random nonce.
+func sealGCM(key, plaintext, additional []byte) ([]byte, []byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(crand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+	return nonce, aead.Seal(nil, nonce, plaintext, additional), nil
+}
+
+// openGCM decrypts and authenticates an AES-256-GCM ciphertext.
+func openGCM(key, nonce, ciphertext, additional []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, ErrSyncCorrupted
+	}
+	return aead.Open(nil, nonce, ciphertext, additional)
+}
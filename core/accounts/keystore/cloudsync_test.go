@@ -0,0 +1,168 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// memoryBlobStore is an in-memory BlobStore for testing.
+type memoryBlobStore struct {
+	lock     sync.Mutex
+	objects  map[string][]byte
+	versions map[string]string
+	counter  int
+}
+
+func newMemoryBlobStore() *memoryBlobStore {
+	return &memoryBlobStore{objects: make(map[string][]byte), versions: make(map[string]string)}
+}
+
+func (s *memoryBlobStore) List(ctx context.Context) (map[string]string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	versions := make(map[string]string)
+	for name, version := range s.versions {
+		versions[name] = version
+	}
+	return versions, nil
+}
+
+func (s *memoryBlobStore) Get(ctx context.Context, name string) ([]byte, string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	data, ok := s.objects[name]
+	if !ok {
+		return nil, "", errors.New("not found")
+	}
+	return data, s.versions[name], nil
+}
+
+func (s *memoryBlobStore) Put(ctx context.Context, name string, data []byte, version string) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.versions[name] != version {
+		return "", ErrSyncConflict
+	}
+	s.counter++
+	s.objects[name] = data
+	s.versions[name] = strconv.Itoa(s.counter)
+	return s.versions[name], nil
+}
+
+// Tests that key files are mirrored encrypted and restored on a new host.
+func TestCloudSync(t *testing.T) {
+	var (
+		store   = newMemoryBlobStore()
+		wrapper = NewPassphraseKeyWrapper("bucket secret", veryLightScryptN, veryLightScryptP)
+		hostA   = t.TempDir()
+		hostB   = t.TempDir()
+		key     = []byte(`{"address":"2c7536e3605d9c16a7a3d7b1898e529396a65c23","crypto":"secret-material"}`)
+		name    = "UTC--2021-01-01T00-00-00.000000000Z--2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+	)
+	if err := ioutil.WriteFile(filepath.Join(hostA, name), key, 0600); err != nil {
+		t.Fatal(err)
+	}
+	report, err := NewCloudSync(hostA, store, wrapper).Sync(context.Background())
+	if err != nil {
+		t.Fatalf("failed to sync: %v", err)
+	}
+	if len(report.Uploaded) != 1 || report.Uploaded[0] != name {
+		t.Fatalf("upload mismatch: %v", report.Uploaded)
+	}
+	// Nothing about the key must be visible in the bucket
+	for object, data := range store.objects {
+		if strings.Contains(object, "2c7536e3") || bytes.Contains(data, []byte("2c7536e3")) || bytes.Contains(data, []byte("secret-material")) {
+			t.Errorf("plaintext leaked into object %s", object)
+		}
+	}
+	// A second run must be a noop
+	if report, err := NewCloudSync(hostA, store, wrapper).Sync(context.Background()); err != nil || len(report.Uploaded)+len(report.Downloaded)+len(report.Conflicts) != 0 {
+		t.Fatalf("resync not a noop: %+v (%v)", report, err)
+	}
+	// A fresh host must be able to restore the key
+	report, err = NewCloudSync(hostB, store, wrapper).Sync(context.Background())
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if len(report.Downloaded) != 1 {
+		t.Fatalf("restore mismatch: %v", report.Downloaded)
+	}
+	if restored, err := ioutil.ReadFile(filepath.Join(hostB, name)); err != nil || !bytes.Equal(restored, key) {
+		t.Fatalf("restored key mismatch: %s (%v)", restored, err)
+	}
+	// A wrong passphrase must not decrypt anything
+	other := NewPassphraseKeyWrapper("wrong", veryLightScryptN, veryLightScryptP)
+	if _, err := NewCloudSync(t.TempDir(), store, other).Sync(context.Background()); err == nil {
+		t.Errorf("restored with wrong passphrase")
+	}
+}
+
+// Tests that concurrent modifications on two hosts are detected.
+func TestCloudSyncConflict(t *testing.T) {
+	var (
+		store   = newMemoryBlobStore()
+		wrapper = NewPassphraseKeyWrapper("bucket secret", veryLightScryptN, veryLightScryptP)
+		hostA   = t.TempDir()
+		hostB   = t.TempDir()
+		name    = "UTC--2021-01-01T00-00-00.000000000Z--2c7536e3605d9c16a7a3d7b1898e529396a65c23"
+	)
+	ioutil.WriteFile(filepath.Join(hostA, name), []byte("v1"), 0600)
+	if _, err := NewCloudSync(hostA, store, wrapper).Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCloudSync(hostB, store, wrapper).Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// Host A changes the key first, host B concurrently changes it differently
+	ioutil.WriteFile(filepath.Join(hostA, name), []byte("v2a"), 0600)
+	ioutil.WriteFile(filepath.Join(hostB, name), []byte("v2b"), 0600)
+
+	if report, err := NewCloudSync(hostA, store, wrapper).Sync(context.Background()); err != nil || len(report.Uploaded) != 1 {
+		t.Fatalf("upload failed: %+v (%v)", report, err)
+	}
+	report, err := NewCloudSync(hostB, store, wrapper).Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Conflicts) != 1 || report.Conflicts[0] != name {
+		t.Fatalf("conflict not detected: %+v", report)
+	}
+	if content, _ := ioutil.ReadFile(filepath.Join(hostB, name)); string(content) != "v2b" {
+		t.Errorf("conflicting local key overwritten: %s", content)
+	}
+	// Resolving the conflict locally by deleting the key restores the remote one
+	os.Remove(filepath.Join(hostB, name))
+	if _, err := NewCloudSync(hostB, store, wrapper).Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := ioutil.ReadFile(filepath.Join(hostB, name)); string(content) != "v2a" {
+		t.Errorf("remote key not restored: %s", content)
+	}
+}
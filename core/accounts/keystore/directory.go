@@ -0,0 +1,178 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// directoryVersion is the version of the account directory format.
+const directoryVersion = 1
+
+// ErrDirectorySignature is returned if an account directory isn't signed by
+// its declared signer, or its entries are inconsistent.
+var ErrDirectorySignature = errors.New("invalid account directory signature")
+
+// Directory is a signed inventory of keystore accounts holding only their public
+// metadata, suitable for handing to auditors or inventory systems.
+type Directory struct {
+	Version   int              `json:"version"`
+	Created   time.Time        `json:"created"`
+	Signer    string           `json:"signer"` // EIP-55 hex address of the signing account
+	Accounts  []DirectoryEntry `json:"accounts"`
+	Signature hexutil.Bytes    `json:"signature,omitempty"`
+}
+
+// DirectoryEntry is the public metadata of a single account.
+type DirectoryEntry struct {
+	Address   string        `json:"address"`           // EIP-55 hex address
+	FFF       string        `json:"fff"`               // FFF encoded address
+	Created   *time.Time    `json:"created,omitempty"` // Creation time of the key file, if known
+	Tags      []string      `json:"tags,omitempty"`
+	PublicKey hexutil.Bytes `json:"publicKey"` // Uncompressed secp256k1 public key
+}
+
+// ExportDirectory exports the public metadata of the given accounts (all of the
+// keystore if none are given) as a directory signed by the signer account.
+// Accounts which aren't unlocked need to be decryptable with the passphrase, so
+// the directory proves their public keys; no private material is included.
+func (ks *KeyStore) ExportDirectory(signer accounts.Account, passphrase string, accs []accounts.Account, tags map[common.Address][]string) ([]byte, error) {
+	if len(accs) == 0 {
+		accs = ks.Accounts()
+	}
+	dir := &Directory{
+		Version:  directoryVersion,
+		Created:  time.Now().UTC().Truncate(time.Second),
+		Accounts: make([]DirectoryEntry, 0, len(accs)),
+	}
+	for _, a := range accs {
+		key, err := ks.directoryKey(a, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", a.Address.Hex(), err)
+		}
+		entry := DirectoryEntry{
+			Address:   common.AddressFormatHex.Address(key.Address),
+			FFF:       key.Address.String(),
+			Tags:      tags[key.Address],
+			PublicKey: crypto.FromECDSAPub(&key.PrivateKey.PublicKey),
+		}
+		if a, err := ks.Find(a); err == nil {
+			entry.Created = keyFileCreated(a.URL.Path)
+		}
+		zeroKey(key.PrivateKey)
+		dir.Accounts = append(dir.Accounts, entry)
+	}
+	key, err := ks.directoryKey(signer, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("signer %s: %w", signer.Address.Hex(), err)
+	}
+	defer zeroKey(key.PrivateKey)
+
+	dir.Signer = common.AddressFormatHex.Address(key.Address)
+	if dir.Signature, err = crypto.Sign(dir.sigHash(), key.PrivateKey); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(dir, "", "  ")
+}
+
+// directoryKey returns a copy of the key of an unlocked account, or decrypts it
+// with the passphrase.
+func (ks *KeyStore) directoryKey(a accounts.Account, passphrase string) (*Key, error) {
+	ks.mu.RLock()
+	unlockedKey, found := ks.unlocked[a.Address]
+	ks.mu.RUnlock()
+
+	if found {
+		priv, err := crypto.ToECDSA(crypto.FromECDSA(unlockedKey.PrivateKey))
+		if err != nil {
+			return nil, err
+		}
+		return &Key{Address: a.Address, PrivateKey: priv}, nil
+	}
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	return key, err
+}
+
+// keyFileCreated extracts the creation time from the name of a key file, or
+// falls back to its modification time.
+func keyFileCreated(path string) *time.Time {
+	if name := filepath.Base(path); strings.HasPrefix(name, "UTC--") {
+		if parts := strings.Split(name, "--"); len(parts) == 3 {
+			if t, err := time.Parse("2006-01-02T15-04-05.999999999Z", parts[1]); err == nil {
+				return &t
+			}
+		}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	t := info.ModTime().UTC()
+	return &t
+}
+
+// sigHash returns the hash the directory signature is made over: the JSON of
+// the directory without the signature.
+func (dir *Directory) sigHash() []byte {
+	unsigned := *dir
+	unsigned.Signature = nil
+
+	blob, _ := json.Marshal(&unsigned)
+	return crypto.Keccak256(blob)
+}
+
+// VerifyDirectory parses an exported account directory and verifies that it's
+// signed by its declared signer, and that the public keys of all its entries
+// match their addresses.
+func VerifyDirectory(data []byte) (*Directory, error) {
+	dir := new(Directory)
+	if err := json.Unmarshal(data, dir); err != nil {
+		return nil, err
+	}
+	if dir.Version != directoryVersion {
+		return nil, fmt.Errorf("unsupported account directory version %d", dir.Version)
+	}
+	pubkey, err := crypto.SigToPub(dir.sigHash(), dir.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDirectorySignature, err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); !strings.EqualFold(dir.Signer, common.AddressFormatHex.Address(signer)) {
+		return nil, fmt.Errorf("%w: signed by %s", ErrDirectorySignature, common.AddressFormatHex.Address(signer))
+	}
+	for _, entry := range dir.Accounts {
+		pubkey, err := crypto.UnmarshalPubkey(entry.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: account %s: %v", ErrDirectorySignature, entry.Address, err)
+		}
+		addr := crypto.PubkeyToAddress(*pubkey)
+		if !strings.EqualFold(entry.Address, common.AddressFormatHex.Address(addr)) || entry.FFF != addr.String() {
+			return nil, fmt.Errorf("%w: account %s doesn't match its public key", ErrDirectorySignature, entry.Address)
+		}
+	}
+	return dir, nil
+}
@@ -0,0 +1,79 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// Tests that account directories carry the public keys of the accounts, are
+// verifiable and that tampering with them is detected.
+func TestDirectoryExport(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	signer, _ := ks.NewAccount("foo")
+	other, _ := ks.NewAccount("foo")
+
+	if _, err := ks.ExportDirectory(signer, "bar", nil, nil); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("export with wrong passphrase: have %v, want %v", err, ErrDecrypt)
+	}
+	tags := map[common.Address][]string{other.Address: {"treasury", "cold"}}
+	blob, err := ks.ExportDirectory(signer, "foo", []accounts.Account{other}, tags)
+	if err != nil {
+		t.Fatalf("failed to export directory: %v", err)
+	}
+	if strings.Contains(string(blob), "ciphertext") || strings.Contains(string(blob), "privateKey") {
+		t.Fatalf("directory leaks private material: %s", blob)
+	}
+	exported, err := VerifyDirectory(blob)
+	if err != nil {
+		t.Fatalf("failed to verify directory: %v", err)
+	}
+	if len(exported.Accounts) != 1 {
+		t.Fatalf("account count mismatch: have %d, want 1", len(exported.Accounts))
+	}
+	entry := exported.Accounts[0]
+	if entry.FFF != other.Address.String() || common.BytesToAddress(common.FromHex(entry.Address)) != other.Address {
+		t.Errorf("address mismatch: have %s/%s, want %s", entry.Address, entry.FFF, other.Address.String())
+	}
+	if entry.Created == nil || len(entry.Tags) != 2 || len(entry.PublicKey) != 65 {
+		t.Errorf("metadata mismatch: %+v", entry)
+	}
+	// Unlocked accounts are exported without the passphrase
+	if err := ks.Unlock(signer, "foo"); err != nil {
+		t.Fatalf("failed to unlock signer: %v", err)
+	}
+	if _, err := ks.ExportDirectory(signer, "", []accounts.Account{signer}, nil); err != nil {
+		t.Errorf("failed to export unlocked account: %v", err)
+	}
+	// Tampering with either the metadata or the keys must be detected
+	for _, tamper := range []func(string) string{
+		func(s string) string { return strings.Replace(s, "treasury", "hot", 1) },
+		func(s string) string { return strings.Replace(s, entry.FFF, signer.Address.String(), 1) },
+	} {
+		if _, err := VerifyDirectory([]byte(tamper(string(blob)))); !errors.Is(err, ErrDirectorySignature) {
+			t.Errorf("tampered directory: have %v, want %v", err, ErrDirectorySignature)
+		}
+	}
+}
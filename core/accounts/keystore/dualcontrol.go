@@ -0,0 +1,103 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+)
+
+// DualControlPassphrase requires two independent passphrases, both of which are
+// fed into the key derivation, so neither holder can decrypt alone.
+const DualControlPassphrase = "passphrase"
+
+// dualAuthSeparator separates the two secrets of a dual-control auth.
+const dualAuthSeparator = "\x00"
+
+// ErrDualControl is returned if a dual-control key is unlocked without two
+// distinct secrets.
+var ErrDualControl = errors.New("key requires two independent secrets to unlock")
+
+// dualControlJSON is the dual-control policy section of an encrypted key file.
+// It is informational only: the key is encrypted with both secrets, so removing
+// the section doesn't make it decryptable with either one of them.
+type dualControlJSON struct {
+	Mode string `json:"mode"`
+}
+
+// dualControl is the decrypted dual-control policy of a key.
+type dualControl struct {
+	mode string
+}
+
+// JoinDualAuth combines the two secrets of a dual-control key into the single
+// auth string accepted by DecryptKey and the keystore unlock and sign methods.
+func JoinDualAuth(first, second string) string {
+	return first + dualAuthSeparator + second
+}
+
+// splitDualAuth splits a dual-control auth string into its two secrets.
+func splitDualAuth(auth string) (string, string, error) {
+	parts := strings.Split(auth, dualAuthSeparator)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || parts[0] == parts[1] {
+		return "", "", ErrDualControl
+	}
+	return parts[0], parts[1], nil
+}
+
+// encrypt validates the auth a dual-control key is about to be encrypted with.
+func (dc *dualControl) encrypt(auth string) (*dualControlJSON, error) {
+	if dc.mode != DualControlPassphrase {
+		return nil, fmt.Errorf("unsupported dual-control mode: %s", dc.mode)
+	}
+	if _, _, err := splitDualAuth(auth); err != nil {
+		return nil, err
+	}
+	return &dualControlJSON{Mode: dc.mode}, nil
+}
+
+// decrypt checks the auth an encrypted dual-control key is about to be decrypted
+// with, returning the decrypted policy.
+func (dc *dualControlJSON) decrypt(auth string) (*dualControl, error) {
+	if dc.Mode != DualControlPassphrase {
+		return nil, fmt.Errorf("unsupported dual-control mode: %s", dc.Mode)
+	}
+	if _, _, err := splitDualAuth(auth); err != nil {
+		return nil, err
+	}
+	return &dualControl{mode: dc.Mode}, nil
+}
+
+// SetDualControl places the key of an account under a dual-control policy,
+// re-encrypting it with newAuth, which must be built with JoinDualAuth from the
+// two passphrases.
+func (ks *KeyStore) SetDualControl(a accounts.Account, passphrase, mode, newAuth string) error {
+	if mode != DualControlPassphrase {
+		return fmt.Errorf("unsupported dual-control mode: %s", mode)
+	}
+	a, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	key.dualControl = &dualControl{mode: mode}
+	return ks.keyStorage().StoreKey(a.URL.Path, key, newAuth)
+}
@@ -0,0 +1,99 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"testing"
+)
+
+// Tests that a key under two-passphrase control can only be decrypted with
+// both passphrases, and keeps its policy when re-encrypted.
+func TestDualControlPassphrase(t *testing.T) {
+	key, err := newKey(crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key.dualControl = &dualControl{mode: DualControlPassphrase}
+
+	if _, err := EncryptKey(key, "alice", veryLightScryptN, veryLightScryptP); err != ErrDualControl {
+		t.Fatalf("single passphrase accepted: %v", err)
+	}
+	if _, err := EncryptKey(key, JoinDualAuth("alice", "alice"), veryLightScryptN, veryLightScryptP); err != ErrDualControl {
+		t.Fatalf("duplicate passphrases accepted: %v", err)
+	}
+	auth := JoinDualAuth("alice", "bob")
+	keyjson, err := EncryptKey(key, auth, veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, bad := range []string{"alice", "bob", JoinDualAuth("alice", "eve"), JoinDualAuth("bob", "alice")} {
+		if _, err := DecryptKey(keyjson, bad); err == nil {
+			t.Errorf("key decrypted with %q", bad)
+		}
+	}
+	// Stripping the policy from the file must not weaken the encryption
+	stripped := bytes.Replace(keyjson, []byte(`,"dualcontrol":{"mode":"passphrase"}`), nil, 1)
+	if bytes.Equal(stripped, keyjson) {
+		t.Fatalf("policy not found in key file: %s", keyjson)
+	}
+	for _, bad := range []string{"alice", "bob"} {
+		if _, err := DecryptKey(stripped, bad); err == nil {
+			t.Errorf("stripped key decrypted with %q", bad)
+		}
+	}
+	dec, err := DecryptKey(keyjson, auth)
+	if err != nil {
+		t.Fatalf("failed to decrypt: %v", err)
+	}
+	if dec.Address != key.Address {
+		t.Fatalf("address mismatch: have %x, want %x", dec.Address, key.Address)
+	}
+	if _, err := EncryptKey(dec, "carol", veryLightScryptN, veryLightScryptP); err != ErrDualControl {
+		t.Fatalf("policy dropped on re-encryption: %v", err)
+	}
+}
+
+// Tests that accounts placed under dual control only unlock with both
+// passphrases, and that unknown modes are rejected.
+func TestSetDualControl(t *testing.T) {
+	dir := t.TempDir()
+	ks := NewKeyStore(dir, veryLightScryptN, veryLightScryptP)
+
+	a, err := ks.NewAccount("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.SetDualControl(a, "alice", "totp", "alice2"); err == nil {
+		t.Fatalf("unsupported mode accepted")
+	}
+	if err := ks.SetDualControl(a, "alice", DualControlPassphrase, "alice2"); err != ErrDualControl {
+		t.Fatalf("single new passphrase accepted: %v", err)
+	}
+	if err := ks.SetDualControl(a, "alice", DualControlPassphrase, JoinDualAuth("alice2", "bob")); err != nil {
+		t.Fatalf("failed to set policy: %v", err)
+	}
+	for _, bad := range []string{"alice", "alice2", "bob"} {
+		if err := ks.Unlock(a, bad); err == nil {
+			t.Errorf("unlocked with %q", bad)
+		}
+	}
+	if err := ks.Unlock(a, JoinDualAuth("alice2", "bob")); err != nil {
+		t.Fatalf("failed to unlock with both passphrases: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/common/math"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// erc2335Version is the keystore version defined by ERC-2335.
+const erc2335Version = 4
+
+var (
+	// ErrERC2335Pubkey is returned if the public key of an ERC-2335 keystore
+	// doesn't belong to its secret, e.g. because it holds a BLS key.
+	ErrERC2335Pubkey = errors.New("keystore pubkey doesn't match its secp256k1 secret")
+
+	// ErrERC2335DualControl is returned when exporting a key under a dual-control
+	// policy, which ERC-2335 keystores can't express.
+	ErrERC2335DualControl = errors.New("dual-control keys can't be exported to ERC-2335")
+)
+
+// ValidatorInfo is the metadata of a key intended for validating. It is kept in
+// the key file and carried along in ERC-2335 keystores, so keys can be moved
+// between staking tools without decrypting them to plaintext.
+type ValidatorInfo struct {
+	Path        string       `json:"path"`                  // EIP-2334 derivation path of the key, empty if not derived
+	Description string       `json:"description,omitempty"` // Free form description of the key
+	Deposit     *DepositData `json:"deposit,omitempty"`     // Deposit made for the key, if any
+}
+
+// DepositData is the deposit made to activate a validator key, in the layout of
+// the deposit data files of the staking tools.
+type DepositData struct {
+	WithdrawalCredentials hexutil.Bytes `json:"withdrawal_credentials"`
+	Amount                uint64        `json:"amount"` // Deposited amount in gwei
+	Signature             hexutil.Bytes `json:"signature"`
+	DepositMessageRoot    common.Hash   `json:"deposit_message_root"`
+	DepositDataRoot       common.Hash   `json:"deposit_data_root"`
+	ForkVersion           hexutil.Bytes `json:"fork_version"`
+	NetworkName           string        `json:"network_name,omitempty"`
+}
+
+// validate checks that the metadata is well formed.
+func (info *ValidatorInfo) validate() error {
+	if info.Path == "" {
+		return nil
+	}
+	if _, err := accounts.ParseDerivationPath(info.Path); err != nil {
+		return fmt.Errorf("invalid derivation path %q: %v", info.Path, err)
+	}
+	return nil
+}
+
+// erc2335Module is a step of the ERC-2335 crypto pipeline.
+type erc2335Module struct {
+	Function string                 `json:"function"`
+	Params   map[string]interface{} `json:"params"`
+	Message  string                 `json:"message"`
+}
+
+// erc2335CryptoJSON is the crypto section of an ERC-2335 keystore.
+type erc2335CryptoJSON struct {
+	KDF      erc2335Module `json:"kdf"`
+	Checksum erc2335Module `json:"checksum"`
+	Cipher   erc2335Module `json:"cipher"`
+}
+
+// erc2335JSON is an ERC-2335 keystore. The deposit isn't part of the standard,
+// it's only present if the key has one.
+type erc2335JSON struct {
+	Crypto      erc2335CryptoJSON `json:"crypto"`
+	Description string            `json:"description,omitempty"`
+	Pubkey      string            `json:"pubkey"`
+	Path        string            `json:"path"`
+	UUID        string            `json:"uuid"`
+	Version     int               `json:"version"`
+	Deposit     *DepositData      `json:"deposit,omitempty"`
+}
+
+// erc2335Password processes a password as mandated by ERC-2335: normalized to
+// NFKD with all control codes stripped.
+func erc2335Password(auth string) []byte {
+	return []byte(strings.Map(func(r rune) rune {
+		if r < 0x20 || (r >= 0x7f && r <= 0x9f) {
+			return -1
+		}
+		return r
+	}, norm.NFKD.String(auth)))
+}
+
+// EncryptKeyERC2335 encrypts a key into an ERC-2335 keystore using scrypt with
+// the given parameters, embedding the validator metadata of the key.
+func EncryptKeyERC2335(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
+	if key.dualControl != nil {
+		return nil, ErrERC2335DualControl
+	}
+	info := key.Validator
+	if info == nil {
+		info = new(ValidatorInfo)
+	}
+	if err := info.validate(); err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		panic("reading from crypto/rand failed: " + err.Error())
+	}
+	derivedKey, err := scrypt.Key(erc2335Password(auth), salt, scryptN, scryptR, scryptP, scryptDKLen)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		panic("reading from crypto/rand failed: " + err.Error())
+	}
+	cipherText, err := aesCTRXOR(derivedKey[:16], math.PaddedBigBytes(key.PrivateKey.D, 32), iv)
+	if err != nil {
+		return nil, err
+	}
+	checksum := sha256.Sum256(append(derivedKey[16:32:32], cipherText...))
+
+	return json.Marshal(&erc2335JSON{
+		Crypto: erc2335CryptoJSON{
+			KDF: erc2335Module{
+				Function: keyHeaderKDF,
+				Params: map[string]interface{}{
+					"dklen": scryptDKLen,
+					"n":     scryptN,
+					"r":     scryptR,
+					"p":     scryptP,
+					"salt":  hex.EncodeToString(salt),
+				},
+			},
+			Checksum: erc2335Module{
+				Function: "sha256",
+				Params:   map[string]interface{}{},
+				Message:  hex.EncodeToString(checksum[:]),
+			},
+			Cipher: erc2335Module{
+				Function: "aes-128-ctr",
+				Params:   map[string]interface{}{"iv": hex.EncodeToString(iv)},
+				Message:  hex.EncodeToString(cipherText),
+			},
+		},
+		Description: info.Description,
+		Pubkey:      hex.EncodeToString(crypto.CompressPubkey(&key.PrivateKey.PublicKey)),
+		Path:        info.Path,
+		UUID:        key.Id.String(),
+		Version:     erc2335Version,
+		Deposit:     info.Deposit,
+	})
+}
+
+// DecryptKeyERC2335 decrypts a secp256k1 key from an ERC-2335 keystore, along
+// with its validator metadata.
+func DecryptKeyERC2335(keyjson []byte, auth string) (*Key, error) {
+	keystore := new(erc2335JSON)
+	if err := json.Unmarshal(keyjson, keystore); err != nil {
+		return nil, err
+	}
+	secret, err := decryptERC2335(keystore, auth)
+	if err != nil {
+		return nil, err
+	}
+	privkey, err := crypto.ToECDSA(secret)
+	if err != nil {
+		return nil, err
+	}
+	if keystore.Pubkey != "" {
+		pubkey, err := hex.DecodeString(strings.TrimPrefix(keystore.Pubkey, "0x"))
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(pubkey, crypto.CompressPubkey(&privkey.PublicKey)) && !bytes.Equal(pubkey, crypto.FromECDSAPub(&privkey.PublicKey)) {
+			zeroKey(privkey)
+			return nil, ErrERC2335Pubkey
+		}
+	}
+	info := &ValidatorInfo{Path: keystore.Path, Description: keystore.Description, Deposit: keystore.Deposit}
+	if err := info.validate(); err != nil {
+		zeroKey(privkey)
+		return nil, err
+	}
+	key := newKeyFromECDSA(privkey)
+	if id, err := uuid.Parse(keystore.UUID); err == nil {
+		key.Id = id
+	}
+	key.Validator = info
+	return key, nil
+}
+
+// decryptERC2335 verifies the checksum of an ERC-2335 keystore and decrypts the
+// secret within.
+func decryptERC2335(keystore *erc2335JSON, auth string) ([]byte, error) {
+	if keystore.Version != erc2335Version {
+		return nil, fmt.Errorf("version not supported: %v", keystore.Version)
+	}
+	c := keystore.Crypto
+	if c.Checksum.Function != "sha256" {
+		return nil, fmt.Errorf("checksum not supported: %v", c.Checksum.Function)
+	}
+	if c.Cipher.Function != "aes-128-ctr" {
+		return nil, fmt.Errorf("cipher not supported: %v", c.Cipher.Function)
+	}
+	checksum, err := hex.DecodeString(c.Checksum.Message)
+	if err != nil {
+		return nil, err
+	}
+	ivHex, _ := c.Cipher.Params["iv"].(string)
+	iv, err := hex.DecodeString(ivHex)
+	if err != nil {
+		return nil, err
+	}
+	cipherText, err := hex.DecodeString(c.Cipher.Message)
+	if err != nil {
+		return nil, err
+	}
+	// The KDF parameters of ERC-2335 match those of Web3 Secret Storage
+	if err := checkERC2335KDF(c.KDF); err != nil {
+		return nil, err
+	}
+	derivedKey, err := getKDFKey(CryptoJSON{KDF: c.KDF.Function, KDFParams: c.KDF.Params}, string(erc2335Password(auth)))
+	if err != nil {
+		return nil, err
+	}
+	if len(derivedKey) < 32 {
+		return nil, fmt.Errorf("derived key too short: %d bytes", len(derivedKey))
+	}
+	calculated := sha256.Sum256(append(derivedKey[16:32:32], cipherText...))
+	if !bytes.Equal(calculated[:], checksum) {
+		return nil, ErrDecrypt
+	}
+	return aesCTRXOR(derivedKey[:16], cipherText, iv)
+}
+
+// erc2335KDFParams are the numeric parameters of the supported KDFs.
+var erc2335KDFParams = map[string][]string{
+	keyHeaderKDF: {"dklen", "n", "r", "p"},
+	"pbkdf2":     {"dklen", "c"},
+}
+
+// checkERC2335KDF checks that the KDF of a keystore has all the parameters the
+// key derivation needs, as keystores from other tools are untrusted input.
+func checkERC2335KDF(kdf erc2335Module) error {
+	params, ok := erc2335KDFParams[kdf.Function]
+	if !ok {
+		return fmt.Errorf("unsupported KDF: %s", kdf.Function)
+	}
+	for _, param := range params {
+		if _, ok := kdf.Params[param].(float64); !ok {
+			return fmt.Errorf("missing KDF parameter %q", param)
+		}
+	}
+	if _, ok := kdf.Params["salt"].(string); !ok {
+		return errors.New("missing KDF salt")
+	}
+	if _, ok := kdf.Params["prf"].(string); kdf.Function == "pbkdf2" && !ok {
+		return errors.New("missing KDF PRF")
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// erc2335Vector is the PBKDF2 test vector of ERC-2335, securing a BLS secret.
+const erc2335Vector = `{
+	"crypto": {
+		"kdf": {
+			"function": "pbkdf2",
+			"params": {
+				"dklen": 32,
+				"c": 262144,
+				"prf": "hmac-sha256",
+				"salt": "d4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"
+			},
+			"message": ""
+		},
+		"checksum": {
+			"function": "sha256",
+			"params": {},
+			"message": "8a9f5d9912ed7e75ea794bc5a89bca5f193721d30868ade6f73043c6ea6febf1"
+		},
+		"cipher": {
+			"function": "aes-128-ctr",
+			"params": {
+				"iv": "264daa3f303d7259501c93d997d84fe6"
+			},
+			"message": "cee03fde2af33149775b7223e7845e4fb2c8ae1792e5f99fe9ecf474cc8c16ad"
+		}
+	},
+	"description": "This is a test keystore that uses PBKDF2 to secure the secret.",
+	"pubkey": "9612d7a727c9d0a22e185a1c768478dfe919cada9266988cb32359c11f2b7b27f4ae4040902382ae2910c15e2b420d07",
+	"path": "m/12381/60/0/0",
+	"uuid": "64625def-3331-4eea-ab6f-782f3ed16a83",
+	"version": 4
+}`
+
+// Tests that the ERC-2335 test vector decrypts with the normalized password,
+// and that its BLS key isn't mistaken for a secp256k1 one.
+func TestERC2335Vector(t *testing.T) {
+	keystore := new(erc2335JSON)
+	if err := json.Unmarshal([]byte(erc2335Vector), keystore); err != nil {
+		t.Fatalf("failed to parse test vector: %v", err)
+	}
+	// Control codes are stripped from the password before deriving the key
+	secret, err := decryptERC2335(keystore, "𝔱𝔢𝔰𝔱\x7f𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑")
+	if err != nil {
+		t.Fatalf("failed to decrypt test vector: %v", err)
+	}
+	if have, want := hex.EncodeToString(secret), "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"; have != want {
+		t.Errorf("secret mismatch: have %s, want %s", have, want)
+	}
+	if _, err := decryptERC2335(keystore, "testpassword"); err != ErrDecrypt {
+		t.Errorf("wrong password error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := DecryptKeyERC2335([]byte(erc2335Vector), "𝔱𝔢𝔰𝔱𝔭𝔞𝔰𝔰𝔴𝔬𝔯𝔡🔑"); err != ErrERC2335Pubkey {
+		t.Errorf("BLS key error mismatch: have %v, want %v", err, ErrERC2335Pubkey)
+	}
+	// Keystores with missing KDF parameters must be rejected, not crash
+	delete(keystore.Crypto.KDF.Params, "c")
+	if _, err := decryptERC2335(keystore, "testpassword"); err == nil {
+		t.Errorf("keystore without iteration count decrypted")
+	}
+}
+
+// Tests that keys and their validator metadata survive an ERC-2335 round trip.
+func TestERC2335RoundTrip(t *testing.T) {
+	priv, _ := crypto.GenerateKey()
+	key := newKeyFromECDSA(priv)
+	key.Validator = &ValidatorInfo{
+		Path:        "m/12381/3600/0/0/0",
+		Description: "validator 0",
+		Deposit: &DepositData{
+			WithdrawalCredentials: common.FromHex("0x00f50428677c60f997aadeab24aabf7fceaef491c96a52b463ae91f95611cf71"),
+			Amount:                32000000000,
+			Signature:             make([]byte, 96),
+			DepositDataRoot:       common.Hash{0x01},
+			ForkVersion:           []byte{0, 0, 0, 0},
+			NetworkName:           "mainnet",
+		},
+	}
+	keyjson, err := EncryptKeyERC2335(key, "pass\u00e9", veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatalf("failed to encrypt key: %v", err)
+	}
+	// The password is normalized, so composed and decomposed forms are equal
+	decrypted, err := DecryptKeyERC2335(keyjson, "passé")
+	if err != nil {
+		t.Fatalf("failed to decrypt key: %v", err)
+	}
+	if decrypted.Address != key.Address || decrypted.Id != key.Id {
+		t.Errorf("key mismatch: have %x/%v, want %x/%v", decrypted.Address, decrypted.Id, key.Address, key.Id)
+	}
+	if !reflect.DeepEqual(decrypted.Validator, key.Validator) {
+		t.Errorf("validator metadata mismatch: have %+v, want %+v", decrypted.Validator, key.Validator)
+	}
+	// Invalid paths are refused and dual-control keys can't be expressed
+	key.Validator.Path = "m/invalid"
+	if _, err := EncryptKeyERC2335(key, "pass", veryLightScryptN, veryLightScryptP); err == nil {
+		t.Errorf("invalid derivation path exported")
+	}
+	key.Validator, key.dualControl = nil, new(dualControl)
+	if _, err := EncryptKeyERC2335(key, "pass", veryLightScryptN, veryLightScryptP); err != ErrERC2335DualControl {
+		t.Errorf("dual-control export error mismatch: have %v, want %v", err, ErrERC2335DualControl)
+	}
+}
+
+// Tests that validator metadata is kept in the key file, exported along with
+// the key and restored on import.
+func TestKeyStoreERC2335(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	a, err := ks.NewAccount("pass")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	info := &ValidatorInfo{
+		Path: "m/12381/3600/7/0/0",
+		Deposit: &DepositData{
+			WithdrawalCredentials: []byte{0x01},
+			Amount:                32000000000,
+			Signature:             []byte{0x02},
+			ForkVersion:           []byte{0, 0, 0, 0},
+		},
+	}
+	if err := ks.SetValidatorInfo(a, "pass", info); err != nil {
+		t.Fatalf("failed to set validator metadata: %v", err)
+	}
+	if err := ks.SetValidatorInfo(a, "pass", &ValidatorInfo{Path: "12381/x"}); err == nil {
+		t.Errorf("invalid derivation path accepted")
+	}
+	keyjson, err := ks.ExportERC2335(a, "pass", "export")
+	if err != nil {
+		t.Fatalf("failed to export key: %v", err)
+	}
+	if err := ks.Delete(a, "pass"); err != nil {
+		t.Fatalf("failed to delete account: %v", err)
+	}
+	if _, err := ks.ImportERC2335(keyjson, "wrong", "new"); !errors.Is(err, ErrDecrypt) {
+		t.Errorf("wrong password error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	imported, err := ks.ImportERC2335(keyjson, "export", "new")
+	if err != nil {
+		t.Fatalf("failed to import key: %v", err)
+	}
+	if imported.Address != a.Address {
+		t.Errorf("imported address mismatch: have %x, want %x", imported.Address, a.Address)
+	}
+	if _, err := ks.ImportERC2335(keyjson, "export", "new"); err != ErrAccountAlreadyExists {
+		t.Errorf("duplicate import error mismatch: have %v, want %v", err, ErrAccountAlreadyExists)
+	}
+	_, key, err := ks.getDecryptedKey(imported, "new")
+	if err != nil {
+		t.Fatalf("failed to decrypt imported key: %v", err)
+	}
+	if !reflect.DeepEqual(key.Validator, info) {
+		t.Errorf("validator metadata mismatch: have %+v, want %+v", key.Validator, info)
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"crypto/ecdsa"
+	crand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"github.com/liuguodong24-8/3fcoin/core/crypto/ecies"
+)
+
+// escrowVersion is the version of the escrow blob format.
+const escrowVersion = 1
+
+var (
+	// ErrEscrowMissing is returned if a key has no escrow blob to recover from.
+	ErrEscrowMissing = errors.New("no escrow blob for key")
+
+	// ErrEscrowRecipient is returned if an escrow blob was encrypted to another
+	// recovery key than the one attempting to decrypt it.
+	ErrEscrowRecipient = errors.New("escrow blob encrypted to different recovery key")
+)
+
+// escrowJSON is the escrow blob of a key, its private key encrypted to the
+// organizational recovery public key.
+type escrowJSON struct {
+	Address    string `json:"address"`
+	Id         string `json:"id"`
+	Recovery   string `json:"recovery"`   // Compressed recovery public key the blob is encrypted to
+	Ciphertext string `json:"ciphertext"` // ECIES ciphertext of the private key
+	Version    int    `json:"version"`
+}
+
+// escrowKeyStore wraps a key storage backend, escrowing every stored key to a
+// recovery public key before writing the key file itself.
+type escrowKeyStore struct {
+	keyStore
+	recovery *ecdsa.PublicKey
+}
+
+// escrowFileName returns the path of the escrow blob belonging to a key file.
+// The blob is a hidden file, so the keystore doesn't attempt to load it.
+func escrowFileName(keyfile string) string {
+	return filepath.Join(filepath.Dir(keyfile), "."+filepath.Base(keyfile)+".escrow")
+}
+
+// StoreKey escrows the key to the recovery public key, then writes and encrypts
+// it via the wrapped storage. The escrow blob is staged in a temporary file and
+// only moved into place once the key is stored, so a failure on either side
+// leaves no stray blob behind, nor replaces the blob of a key being rewritten.
+func (ks *escrowKeyStore) StoreKey(filename string, key *Key, auth string) error {
+	blob, err := EscrowKey(key, ks.recovery)
+	if err != nil {
+		return err
+	}
+	tmpName, err := writeTemporaryKeyFile(escrowFileName(filename), blob)
+	if err != nil {
+		return err
+	}
+	if err := ks.keyStore.StoreKey(filename, key, auth); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
+	return os.Rename(tmpName, escrowFileName(filename))
+}
+
+// EscrowKey encrypts the private key to the given recovery public key. The key
+// address is authenticated too, so blobs can't be swapped between keys.
+func EscrowKey(key *Key, recovery *ecdsa.PublicKey) ([]byte, error) {
+	ciphertext, err := ecies.Encrypt(crand.Reader, ecies.ImportECDSAPublic(recovery), crypto.FromECDSA(key.PrivateKey), nil, key.Address.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&escrowJSON{
+		Address:    hex.EncodeToString(key.Address[:]),
+		Id:         key.Id.String(),
+		Recovery:   hex.EncodeToString(crypto.CompressPubkey(recovery)),
+		Ciphertext: hex.EncodeToString(ciphertext),
+		Version:    escrowVersion,
+	})
+}
+
+// DecryptEscrow recovers a key from its escrow blob using the organizational
+// recovery private key.
+func DecryptEscrow(escrowjson []byte, recovery *ecdsa.PrivateKey) (*Key, error) {
+	var blob escrowJSON
+	if err := json.Unmarshal(escrowjson, &blob); err != nil {
+		return nil, err
+	}
+	if blob.Version != escrowVersion {
+		return nil, fmt.Errorf("unsupported escrow version %d", blob.Version)
+	}
+	if blob.Recovery != hex.EncodeToString(crypto.CompressPubkey(&recovery.PublicKey)) {
+		return nil, ErrEscrowRecipient
+	}
+	address := common.BytesToAddress(common.FromHex(blob.Address))
+	ciphertext, err := hex.DecodeString(blob.Ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := ecies.ImportECDSA(recovery).Decrypt(ciphertext, nil, address.Bytes())
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	defer func() {
+		for i := range plaintext {
+			plaintext[i] = 0
+		}
+	}()
+
+	id, err := uuid.Parse(blob.Id)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := crypto.ToECDSA(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if have := crypto.PubkeyToAddress(privateKey.PublicKey); have != address {
+		return nil, fmt.Errorf("escrow content mismatch: have account %x, want %x", have, address)
+	}
+	return &Key{Id: id, Address: address, PrivateKey: privateKey}, nil
+}
+
+// SetEscrowKey makes the keystore escrow every key it stores from now on to the
+// given organizational recovery public key, in a hidden blob next to the key
+// file. Keys lost to a forgotten passphrase can then be restored with the
+// recovery private key via RecoverEscrow. A nil key disables escrowing.
+//
+// Escrow blobs are kept when their key is deleted, so that deleted keys remain
+// recoverable under the same policy.
+func (ks *KeyStore) SetEscrowKey(recovery *ecdsa.PublicKey) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	storage := unwrapEscrow(ks.storage)
+	if recovery != nil {
+		storage = &escrowKeyStore{keyStore: storage, recovery: recovery}
+	}
+	ks.storage = storage
+}
+
+// RecoverEscrow restores access to an account whose passphrase was lost by
+// decrypting its escrow blob with the recovery private key and re-encrypting
+// the key file with a new passphrase.
+func (ks *KeyStore) RecoverEscrow(a accounts.Account, recovery *ecdsa.PrivateKey, newPassphrase string) error {
+	a, err := ks.Find(a)
+	if err != nil {
+		return err
+	}
+	escrowjson, err := readEscrow(a.URL.Path)
+	if err != nil {
+		return err
+	}
+	key, err := DecryptEscrow(escrowjson, recovery)
+	if err != nil {
+		return err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	if key.Address != a.Address {
+		return fmt.Errorf("escrow content mismatch: have account %x, want %x", key.Address, a.Address)
+	}
+	return ks.keyStorage().StoreKey(a.URL.Path, key, newPassphrase)
+}
+
+// readEscrow returns the escrow blob of a key file, either from the blob next to
+// it or inlined in the key file by the v4 schema.
+func readEscrow(keyfile string) ([]byte, error) {
+	if escrowjson, err := ioutil.ReadFile(escrowFileName(keyfile)); err == nil {
+		return escrowjson, nil
+	}
+	keyjson, err := ioutil.ReadFile(keyfile)
+	if err != nil {
+		return nil, ErrEscrowMissing
+	}
+	var inline struct {
+		Escrow json.RawMessage `json:"escrow"`
+	}
+	if err := json.Unmarshal(keyjson, &inline); err != nil || len(inline.Escrow) == 0 {
+		return nil, ErrEscrowMissing
+	}
+	return inline.Escrow, nil
+}
+
+// baseStorage returns the storage backend of the keystore, unwrapping escrow.
+func (ks *KeyStore) baseStorage() keyStore {
+	return unwrapEscrow(ks.keyStorage())
+}
+
+// unwrapEscrow returns the storage backend wrapped by an escrowing one.
+func unwrapEscrow(storage keyStore) keyStore {
+	if escrow, ok := storage.(*escrowKeyStore); ok {
+		return escrow.keyStore
+	}
+	return storage
+}
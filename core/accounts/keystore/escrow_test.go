@@ -0,0 +1,139 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// Tests that keys stored while escrowing is enabled can be recovered with the
+// recovery key after their passphrase is lost.
+func TestEscrowRecovery(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	recovery, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ks.SetEscrowKey(&recovery.PublicKey)
+
+	a, err := ks.NewAccount("lost")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if _, err := os.Stat(escrowFileName(a.URL.Path)); err != nil {
+		t.Fatalf("escrow blob missing: %v", err)
+	}
+	if accs := ks.Accounts(); len(accs) != 1 {
+		t.Fatalf("escrow blob picked up as account: %v", accs)
+	}
+	// Recovery with an unrelated key must fail
+	other, _ := crypto.GenerateKey()
+	if err := ks.RecoverEscrow(a, other, "new"); err != ErrEscrowRecipient {
+		t.Fatalf("foreign recovery key error mismatch: have %v, want %v", err, ErrEscrowRecipient)
+	}
+	if err := ks.RecoverEscrow(a, recovery, "new"); err != nil {
+		t.Fatalf("failed to recover key: %v", err)
+	}
+	if err := ks.Unlock(a, "new"); err != nil {
+		t.Fatalf("failed to unlock with new passphrase: %v", err)
+	}
+	// Disabled escrowing must not produce blobs any more
+	ks.SetEscrowKey(nil)
+	b, err := ks.NewAccount("pass")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	if _, err := os.Stat(escrowFileName(b.URL.Path)); !os.IsNotExist(err) {
+		t.Errorf("escrow blob created while disabled: %v", err)
+	}
+	if err := ks.RecoverEscrow(b, recovery, "new"); err != ErrEscrowMissing {
+		t.Errorf("missing escrow error mismatch: have %v, want %v", err, ErrEscrowMissing)
+	}
+	if err := ks.RecoverEscrow(accounts.Account{Address: b.Address, URL: accounts.URL{Scheme: KeyStoreScheme, Path: "missing"}}, recovery, "new"); err == nil {
+		t.Errorf("recovery of unknown account succeeded")
+	}
+}
+
+// Tests that escrow blobs can't be moved over to another key.
+func TestEscrowSwap(t *testing.T) {
+	recovery, _ := crypto.GenerateKey()
+
+	key, err := newKey(crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blob, err := EscrowKey(key, &recovery.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to escrow key: %v", err)
+	}
+	have, err := DecryptEscrow(blob, recovery)
+	if err != nil {
+		t.Fatalf("failed to decrypt escrow: %v", err)
+	}
+	if have.Address != key.Address || have.Id != key.Id || !have.PrivateKey.Equal(key.PrivateKey) {
+		t.Fatalf("recovered key mismatch")
+	}
+	other, _ := newKey(crand.Reader)
+	swapped := []byte(strings.Replace(string(blob), hex.EncodeToString(key.Address[:]), hex.EncodeToString(other.Address[:]), 1))
+	if _, err := DecryptEscrow(swapped, recovery); err != ErrDecrypt {
+		t.Errorf("swapped escrow error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+}
+
+// failingKeyStore is a key storage backend failing every write.
+type failingKeyStore struct {
+	keyStore
+}
+
+func (failingKeyStore) StoreKey(filename string, key *Key, auth string) error {
+	return errors.New("write failed")
+}
+
+// Tests that a failed key write doesn't leave an escrow blob behind.
+func TestEscrowStoreFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore-escrow-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	recovery, _ := crypto.GenerateKey()
+	ks := &escrowKeyStore{keyStore: failingKeyStore{&keyStorePlain{dir}}, recovery: &recovery.PublicKey}
+
+	key, err := newKey(crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.StoreKey(filepath.Join(dir, keyFileName(key.Address)), key, ""); err == nil {
+		t.Fatalf("failed key write succeeded")
+	}
+	if entries, _ := ioutil.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("stray files left behind: %d", len(entries))
+	}
+}
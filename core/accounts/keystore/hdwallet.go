@@ -0,0 +1,306 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	ethereum "github.com/liuguodong24-8/3fcoin"
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// DefaultGapLimit is the number of consecutive unused accounts after which
+// discovery stops, as recommended by BIP-44.
+const DefaultGapLimit = 20
+
+// hdStateFile is the name of the file within the keystore directory tracking
+// the HD seeds and the accounts derived from them. Being hidden, the keystore
+// doesn't mistake it for a key file.
+const hdStateFile = ".hdwallet.json"
+
+// ErrUnknownSeed is returned if an HD operation references a seed that was not
+// added to the manager.
+var ErrUnknownSeed = errors.New("unknown HD seed")
+
+// HDAccount is an account derived from an HD seed and imported into the
+// keystore.
+type HDAccount struct {
+	accounts.Account
+	Seed string                  `json:"seed"` // Identifier of the seed the account is derived from
+	Path accounts.DerivationPath `json:"path"` // Derivation path of the account
+	Used bool                    `json:"used"` // Whether the account was found used on chain
+}
+
+// hdSeedJSON is the persisted state of a single HD seed.
+type hdSeedJSON struct {
+	Crypto CryptoJSON             `json:"crypto"` // Seed, encrypted with the passphrase of its accounts
+	Bases  map[string]*hdBaseJSON `json:"bases"`  // Bookkeeping per base derivation path
+}
+
+// hdBaseJSON is the bookkeeping of the accounts derived under a base path.
+type hdBaseJSON struct {
+	Next     uint32       `json:"next"` // Index of the next account to hand out
+	Accounts []*HDAccount `json:"accounts"`
+}
+
+// HDManager derives accounts from BIP-39 seeds along BIP-44 derivation paths,
+// importing them into a keystore. It keeps track of the accounts handed out per
+// seed and base path, so that wallets don't need to reimplement the bookkeeping.
+type HDManager struct {
+	ks      *KeyStore
+	path    string                 // Path of the persisted state
+	seeds   map[string]*hdSeedJSON // Tracked seeds, keyed by fingerprint
+	scryptN int                    // Scrypt parameters to encrypt the seeds with
+	scryptP int
+	lock    sync.Mutex
+}
+
+// NewHDManager creates an HD account manager on top of a keystore, loading the
+// seeds previously added to it.
+func NewHDManager(ks *KeyStore) (*HDManager, error) {
+	m := &HDManager{
+		ks:      ks,
+		path:    ks.keyStorage().JoinPath(hdStateFile),
+		seeds:   make(map[string]*hdSeedJSON),
+		scryptN: StandardScryptN,
+		scryptP: StandardScryptP,
+	}
+	if storage, ok := ks.baseStorage().(*keyStorePassphrase); ok {
+		m.scryptN, m.scryptP = storage.scryptN, storage.scryptP
+	}
+	blob, err := ioutil.ReadFile(m.path)
+	switch {
+	case os.IsNotExist(err):
+		return m, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(blob, &m.seeds); err != nil {
+		return nil, fmt.Errorf("corrupt HD wallet state: %v", err)
+	}
+	return m, nil
+}
+
+// AddSeed adds the seed of a BIP-39 mnemonic to the manager, returning its
+// fingerprint used to reference it. The seed is stored encrypted with the
+// passphrase, which also protects the accounts derived from it. The mnemonic is
+// not checked against the BIP-39 word list.
+func (m *HDManager) AddSeed(mnemonic, mnemonicPassphrase, passphrase string) (string, error) {
+	seed := mnemonicSeed(mnemonic, mnemonicPassphrase)
+	master, err := deriveHDKey(seed, nil)
+	if err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(crypto.Keccak256(crypto.CompressPubkey(&master.PublicKey))[:4])
+	zeroKey(master)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.seeds[id]; ok {
+		return id, nil
+	}
+	sealed, err := EncryptDataV3(seed, []byte(passphrase), m.scryptN, m.scryptP)
+	if err != nil {
+		return "", err
+	}
+	m.seeds[id] = &hdSeedJSON{Crypto: sealed, Bases: make(map[string]*hdBaseJSON)}
+	if err := m.flush(); err != nil {
+		delete(m.seeds, id)
+		return "", err
+	}
+	return id, nil
+}
+
+// Seeds returns the fingerprints of the seeds tracked by the manager.
+func (m *HDManager) Seeds() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	ids := make([]string, 0, len(m.seeds))
+	for id := range m.seeds {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Accounts returns the accounts derived from a seed under a base path, ordered
+// by their derivation index.
+func (m *HDManager) Accounts(seed string, base accounts.DerivationPath) []HDAccount {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	state, ok := m.seeds[seed]
+	if !ok || state.Bases[base.String()] == nil {
+		return nil
+	}
+	accs := make([]HDAccount, len(state.Bases[base.String()].Accounts))
+	for i, acc := range state.Bases[base.String()].Accounts {
+		accs[i] = *acc
+	}
+	return accs
+}
+
+// NextAccount derives the next account of a seed under a base path, imports it
+// into the keystore and hands it out.
+func (m *HDManager) NextAccount(seed string, base accounts.DerivationPath, passphrase string) (HDAccount, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key, state, err := m.unlockSeed(seed, base, passphrase)
+	if err != nil {
+		return HDAccount{}, err
+	}
+	acc, err := m.derive(seed, key, state, base, state.Next, passphrase)
+	if err != nil {
+		return HDAccount{}, err
+	}
+	state.Next++
+	return *acc, m.flush()
+}
+
+// Discover scans the accounts of a seed under a base path for on-chain activity,
+// stopping after gapLimit consecutive unused ones. Used accounts are imported
+// into the keystore and the next account to hand out is moved past them.
+func (m *HDManager) Discover(ctx context.Context, seed string, base accounts.DerivationPath, passphrase string, chain ethereum.ChainStateReader, gapLimit int) ([]HDAccount, error) {
+	if gapLimit <= 0 {
+		gapLimit = DefaultGapLimit
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key, state, err := m.unlockSeed(seed, base, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var used []HDAccount
+	for index, gap := uint32(0), 0; gap < gapLimit; index++ {
+		path := append(append(accounts.DerivationPath{}, base...), index)
+		priv, err := deriveHDKey(key, path)
+		if err != nil {
+			return nil, err
+		}
+		address := crypto.PubkeyToAddress(priv.PublicKey)
+		zeroKey(priv)
+
+		active, err := accountUsed(ctx, chain, address)
+		if err != nil {
+			return nil, err
+		}
+		if !active {
+			gap++
+			continue
+		}
+		gap = 0
+
+		acc, err := m.derive(seed, key, state, base, index, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		acc.Used = true
+		if state.Next <= index {
+			state.Next = index + 1
+		}
+		used = append(used, *acc)
+	}
+	return used, m.flush()
+}
+
+// unlockSeed decrypts a seed and returns it along with the bookkeeping of the
+// base path, creating it if needed. The lock must be held.
+func (m *HDManager) unlockSeed(seed string, base accounts.DerivationPath, passphrase string) ([]byte, *hdBaseJSON, error) {
+	state, ok := m.seeds[seed]
+	if !ok {
+		return nil, nil, ErrUnknownSeed
+	}
+	key, err := DecryptDataV3(state.Crypto, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	if state.Bases[base.String()] == nil {
+		state.Bases[base.String()] = new(hdBaseJSON)
+	}
+	return key, state.Bases[base.String()], nil
+}
+
+// derive derives the account at an index under a base path, importing it into
+// the keystore if not yet present. The lock must be held.
+func (m *HDManager) derive(seed string, key []byte, state *hdBaseJSON, base accounts.DerivationPath, index uint32, passphrase string) (*HDAccount, error) {
+	for _, acc := range state.Accounts {
+		if acc.Path[len(acc.Path)-1] == index {
+			return acc, nil
+		}
+	}
+	path := append(append(accounts.DerivationPath{}, base...), index)
+	priv, err := deriveHDKey(key, path)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(priv)
+
+	account, err := m.ks.ImportECDSA(priv, passphrase)
+	if err == ErrAccountAlreadyExists {
+		account, err = m.ks.Find(accounts.Account{Address: crypto.PubkeyToAddress(priv.PublicKey)})
+	}
+	if err != nil {
+		return nil, err
+	}
+	acc := &HDAccount{Account: account, Seed: seed, Path: path}
+
+	state.Accounts = append(state.Accounts, acc)
+	sort.Slice(state.Accounts, func(i, j int) bool {
+		return state.Accounts[i].Path[len(base)] < state.Accounts[j].Path[len(base)]
+	})
+	return acc, nil
+}
+
+// flush persists the state of the manager. The lock must be held.
+func (m *HDManager) flush() error {
+	blob, err := json.MarshalIndent(m.seeds, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeKeyFile(m.path, blob)
+}
+
+// accountUsed reports whether an account has any on-chain activity, either
+// sent transactions or a balance.
+func accountUsed(ctx context.Context, chain ethereum.ChainStateReader, address common.Address) (bool, error) {
+	nonce, err := chain.NonceAt(ctx, address, nil)
+	if err != nil {
+		return false, err
+	}
+	if nonce > 0 {
+		return true, nil
+	}
+	balance, err := chain.BalanceAt(ctx, address, nil)
+	if err != nil {
+		return false, err
+	}
+	return balance.Sign() > 0, nil
+}
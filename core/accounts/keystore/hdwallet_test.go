@@ -0,0 +1,132 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// testChainState is a ChainStateReader reporting a fixed set of used accounts.
+type testChainState map[common.Address]bool
+
+func (s testChainState) BalanceAt(ctx context.Context, account common.Address, number *big.Int) (*big.Int, error) {
+	if s[account] {
+		return big.NewInt(1), nil
+	}
+	return new(big.Int), nil
+}
+
+func (s testChainState) StorageAt(ctx context.Context, account common.Address, key common.Hash, number *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (s testChainState) CodeAt(ctx context.Context, account common.Address, number *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+func (s testChainState) NonceAt(ctx context.Context, account common.Address, number *big.Int) (uint64, error) {
+	return 0, nil
+}
+
+// Tests that accounts are handed out along the derivation path and that the
+// bookkeeping survives a restart.
+func TestHDManagerNextAccount(t *testing.T) {
+	dir := t.TempDir()
+	ks := NewKeyStore(dir, veryLightScryptN, veryLightScryptP)
+
+	m, err := NewHDManager(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := m.AddSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "", "foo")
+	if err != nil {
+		t.Fatalf("failed to add seed: %v", err)
+	}
+	first, err := m.NextAccount(seed, accounts.DefaultRootDerivationPath, "foo")
+	if err != nil {
+		t.Fatalf("failed to derive account: %v", err)
+	}
+	if want := common.BytesToAddress(common.FromHex("0x9858effd232b4033e47d90003d41ec34ecaeda94")); first.Address != want {
+		t.Fatalf("account mismatch: have %x, want %x", first.Address, want)
+	}
+	if !ks.HasAddress(first.Address) {
+		t.Fatalf("account not imported into keystore")
+	}
+	if _, err := m.NextAccount(seed, accounts.DefaultRootDerivationPath, "bar"); err != ErrDecrypt {
+		t.Fatalf("derived with wrong passphrase: %v", err)
+	}
+	// Reloading the manager must continue where it left off
+	m, err = NewHDManager(ks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := m.NextAccount(seed, accounts.DefaultRootDerivationPath, "foo")
+	if err != nil {
+		t.Fatalf("failed to derive account: %v", err)
+	}
+	if second.Path.String() != "m/44'/60'/0'/0/1" {
+		t.Errorf("path mismatch: %v", second.Path)
+	}
+	if accs := m.Accounts(seed, accounts.DefaultRootDerivationPath); len(accs) != 2 || accs[0].Address != first.Address {
+		t.Errorf("tracked accounts mismatch: %v", accs)
+	}
+}
+
+// Tests that discovery finds used accounts up to the gap limit.
+func TestHDManagerDiscover(t *testing.T) {
+	ks := NewKeyStore(t.TempDir(), veryLightScryptN, veryLightScryptP)
+
+	m, _ := NewHDManager(ks)
+	seed, _ := m.AddSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", "", "foo")
+
+	// Derive the addresses at the interesting indices out of band
+	var addrs []common.Address
+	for _, index := range []uint32{0, 3, 6} {
+		key, _ := deriveHDKey(mnemonicSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", ""), append(append(accounts.DerivationPath{}, accounts.DefaultRootDerivationPath...), index))
+		addrs = append(addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	// With a gap limit of 3, the account at index 6 is out of reach
+	chain := testChainState{addrs[0]: true, addrs[1]: true, addrs[2]: true}
+	used, err := m.Discover(context.Background(), seed, accounts.DefaultRootDerivationPath, "foo", chain, 2)
+	if err != nil {
+		t.Fatalf("failed to discover: %v", err)
+	}
+	if len(used) != 1 || used[0].Address != addrs[0] {
+		t.Fatalf("discovered accounts mismatch: %v", used)
+	}
+	used, err = m.Discover(context.Background(), seed, accounts.DefaultRootDerivationPath, "foo", chain, 3)
+	if err != nil {
+		t.Fatalf("failed to discover: %v", err)
+	}
+	if len(used) != 3 || used[2].Address != addrs[2] || !used[2].Used {
+		t.Fatalf("discovered accounts mismatch: %v", used)
+	}
+	// The next handed out account must be past the used ones
+	next, err := m.NextAccount(seed, accounts.DefaultRootDerivationPath, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Path.String() != "m/44'/60'/0'/0/7" {
+		t.Errorf("next account path mismatch: %v", next.Path)
+	}
+}
@@ -46,6 +46,10 @@ type Key struct {
 	// we only store privkey as pubkey/address can be derived from it
 	// privkey in this struct is always in plaintext
 	PrivateKey *ecdsa.PrivateKey
+	// dual-control policy the key file is protected with, if any
+	dualControl *dualControl
+	// validator metadata of the key, carried along in ERC-2335 keystores
+	Validator *ValidatorInfo
 }
 
 type keyStore interface {
@@ -65,10 +69,12 @@ type plainKeyJSON struct {
 }
 
 type encryptedKeyJSONV3 struct {
-	Address string     `json:"address"`
-	Crypto  CryptoJSON `json:"crypto"`
-	Id      string     `json:"id"`
-	Version int        `json:"version"`
+	Address     string           `json:"address"`
+	Crypto      CryptoJSON       `json:"crypto"`
+	Id          string           `json:"id"`
+	Version     int              `json:"version"`
+	DualControl *dualControlJSON `json:"dualcontrol,omitempty"`
+	Validator   *ValidatorInfo   `json:"validator,omitempty"`
 }
 
 type encryptedKeyJSONV1 struct {
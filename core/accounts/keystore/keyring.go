@@ -0,0 +1,118 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// keyringService is the service name keystore passphrases are stored under in
+// the keyring of the operating system.
+const keyringService = "fff-keystore"
+
+var (
+	// ErrKeyringNotFound is returned by a Keyring if it holds no secret for the
+	// requested service and user.
+	ErrKeyringNotFound = errors.New("secret not found in keyring")
+
+	// ErrKeyringUnsupported is returned if the operating system provides no
+	// keyring, or its tooling isn't installed.
+	ErrKeyringUnsupported = errors.New("keyring not supported on this platform")
+)
+
+// Keyring is a secret store provided by the operating system: the Secret Service
+// on Linux, the Keychain on macOS or the Credential Manager on Windows.
+type Keyring interface {
+	// Get retrieves the secret of a user of a service, or ErrKeyringNotFound.
+	Get(service, user string) (string, error)
+
+	// Set stores the secret of a user of a service, replacing any previous one.
+	Set(service, user, secret string) error
+
+	// Delete removes the secret of a user of a service, if any.
+	Delete(service, user string) error
+}
+
+// OSKeyring returns the keyring of the operating system, or ErrKeyringUnsupported
+// if none is available.
+func OSKeyring() (Keyring, error) {
+	return osKeyring()
+}
+
+// keyringEntry is a passphrase cached in a keyring along with its expiry.
+type keyringEntry struct {
+	Passphrase string `json:"passphrase"`
+	Expires    int64  `json:"expires"` // Unix time the entry expires at
+}
+
+// PassphraseCache caches the passphrases of keystore accounts in a keyring, each
+// for a limited time, so interactive users don't need to retype them for every
+// operation. The key files themselves remain encrypted at rest.
+type PassphraseCache struct {
+	ring Keyring
+	ttl  time.Duration
+	now  func() time.Time // Time source, replaceable by tests
+}
+
+// NewPassphraseCache creates a passphrase cache backed by a keyring, expiring
+// the cached passphrases after the given time.
+func NewPassphraseCache(ring Keyring, ttl time.Duration) *PassphraseCache {
+	return &PassphraseCache{ring: ring, ttl: ttl, now: time.Now}
+}
+
+// Get returns the cached passphrase of an account, if any. Expired passphrases
+// are removed from the keyring.
+func (c *PassphraseCache) Get(addr common.Address) (string, bool) {
+	user := common.AddressFormatHex.Address(addr)
+
+	blob, err := c.ring.Get(keyringService, user)
+	if err != nil {
+		return "", false
+	}
+	var entry keyringEntry
+	if err := json.Unmarshal([]byte(blob), &entry); err != nil || c.now().Unix() >= entry.Expires {
+		c.ring.Delete(keyringService, user)
+		return "", false
+	}
+	return entry.Passphrase, true
+}
+
+// Put caches the passphrase of an account, restarting its expiry.
+func (c *PassphraseCache) Put(addr common.Address, passphrase string) error {
+	blob, err := json.Marshal(&keyringEntry{
+		Passphrase: passphrase,
+		Expires:    c.now().Add(c.ttl).Unix(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := c.ring.Set(keyringService, common.AddressFormatHex.Address(addr), string(blob)); err != nil {
+		return fmt.Errorf("failed to cache passphrase: %w", err)
+	}
+	return nil
+}
+
+// Forget removes the cached passphrase of an account, e.g. after it was found
+// to be wrong.
+func (c *PassphraseCache) Forget(addr common.Address) error {
+	return c.ring.Delete(keyringService, common.AddressFormatHex.Address(addr))
+}
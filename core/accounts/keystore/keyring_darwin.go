@@ -0,0 +1,90 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// keychainNotFound is the exit code of the security command if an item doesn't
+// exist in the keychain.
+const keychainNotFound = 44
+
+// keychainKeyring is the login keychain of macOS, accessed through the security
+// command. Secrets are stored hex encoded, as the keychain would otherwise hand
+// back non-ASCII passwords hex encoded anyway.
+type keychainKeyring struct {
+	path string // Path of the security executable
+}
+
+func osKeyring() (Keyring, error) {
+	path, err := exec.LookPath("security")
+	if err != nil {
+		return nil, fmt.Errorf("%w: security command not found", ErrKeyringUnsupported)
+	}
+	return &keychainKeyring{path: path}, nil
+}
+
+// Get implements Keyring, retrieving a secret from the keychain.
+func (k *keychainKeyring) Get(service, user string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(k.path, "find-generic-password", "-s", service, "-a", user, "-w")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+
+	var exit *exec.ExitError
+	switch {
+	case errors.As(err, &exit) && exit.ExitCode() == keychainNotFound:
+		return "", ErrKeyringNotFound
+	case err != nil:
+		return "", fmt.Errorf("keychain lookup failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	secret, err := hex.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return "", fmt.Errorf("malformed keychain item: %v", err)
+	}
+	return string(secret), nil
+}
+
+// Set implements Keyring, storing a secret in the keychain. The command is fed
+// to an interactive security session, keeping the secret out of the process
+// list.
+func (k *keychainKeyring) Set(service, user, secret string) error {
+	cmd := exec.Command(k.path, "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %q -a %q -w %s\n", service, user, hex.EncodeToString([]byte(secret))))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("keychain store failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Delete implements Keyring, removing a secret from the keychain.
+func (k *keychainKeyring) Delete(service, user string) error {
+	out, err := exec.Command(k.path, "delete-generic-password", "-s", service, "-a", user).CombinedOutput()
+
+	var exit *exec.ExitError
+	if err != nil && !(errors.As(err, &exit) && exit.ExitCode() == keychainNotFound) {
+		return fmt.Errorf("keychain delete failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// secretServiceKeyring is the Secret Service of Linux desktops (GNOME Keyring,
+// KWallet), accessed through the secret-tool command of libsecret.
+type secretServiceKeyring struct {
+	path string // Path of the secret-tool executable
+}
+
+func osKeyring() (Keyring, error) {
+	path, err := exec.LookPath("secret-tool")
+	if err != nil {
+		return nil, fmt.Errorf("%w: secret-tool not installed", ErrKeyringUnsupported)
+	}
+	return &secretServiceKeyring{path: path}, nil
+}
+
+// Get implements Keyring, retrieving a secret from the Secret Service.
+func (k *secretServiceKeyring) Get(service, user string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(k.path, "lookup", "service", service, "account", user)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+
+	var exit *exec.ExitError
+	switch {
+	case errors.As(err, &exit) && stderr.Len() == 0:
+		return "", ErrKeyringNotFound // Missing secrets fail silently
+	case err != nil:
+		return "", fmt.Errorf("secret-tool lookup failed: %v: %s", err, strings.TrimSpace(stderr.String()))
+	case len(out) == 0:
+		return "", ErrKeyringNotFound
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Set implements Keyring, storing a secret in the Secret Service. The secret is
+// passed on stdin, keeping it out of the process list.
+func (k *secretServiceKeyring) Set(service, user, secret string) error {
+	cmd := exec.Command(k.path, "store", "--label", service+" "+user, "service", service, "account", user)
+	cmd.Stdin = strings.NewReader(secret)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool store failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// Delete implements Keyring, removing a secret from the Secret Service.
+func (k *secretServiceKeyring) Delete(service, user string) error {
+	if out, err := exec.Command(k.path, "clear", "service", service, "account", user).CombinedOutput(); err != nil {
+		return fmt.Errorf("secret-tool clear failed: %v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// memoryKeyring is an in-memory Keyring for testing.
+type memoryKeyring map[string]string
+
+func (k memoryKeyring) Get(service, user string) (string, error) {
+	if secret, ok := k[service+"/"+user]; ok {
+		return secret, nil
+	}
+	return "", ErrKeyringNotFound
+}
+
+func (k memoryKeyring) Set(service, user, secret string) error {
+	k[service+"/"+user] = secret
+	return nil
+}
+
+func (k memoryKeyring) Delete(service, user string) error {
+	delete(k, service+"/"+user)
+	return nil
+}
+
+// Tests that passphrases are cached per account and expire after their TTL.
+func TestPassphraseCache(t *testing.T) {
+	var (
+		ring  = make(memoryKeyring)
+		cache = NewPassphraseCache(ring, time.Minute)
+		now   = time.Unix(1600000000, 0)
+		alice = common.Address{0x01}
+		bob   = common.Address{0x02}
+	)
+	cache.now = func() time.Time { return now }
+
+	if _, ok := cache.Get(alice); ok {
+		t.Fatalf("passphrase cached before put")
+	}
+	if err := cache.Put(alice, "foo"); err != nil {
+		t.Fatalf("failed to cache passphrase: %v", err)
+	}
+	if pass, ok := cache.Get(alice); !ok || pass != "foo" {
+		t.Errorf("cached passphrase mismatch: have %q, %v", pass, ok)
+	}
+	if _, ok := cache.Get(bob); ok {
+		t.Errorf("passphrase of other account returned")
+	}
+	// Expired passphrases are dropped from the keyring
+	now = now.Add(time.Minute)
+	if _, ok := cache.Get(alice); ok {
+		t.Errorf("expired passphrase returned")
+	}
+	if len(ring) != 0 {
+		t.Errorf("expired passphrase left in keyring: %v", ring)
+	}
+	// Forgotten passphrases are gone immediately
+	cache.Put(bob, "bar")
+	cache.Forget(bob)
+	if _, ok := cache.Get(bob); ok {
+		t.Errorf("forgotten passphrase returned")
+	}
+}
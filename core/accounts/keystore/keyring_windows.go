@@ -0,0 +1,127 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32   = syscall.NewLazyDLL("advapi32.dll")
+	credReadW  = advapi32.NewProc("CredReadW")
+	credWriteW = advapi32.NewProc("CredWriteW")
+	credDelete = advapi32.NewProc("CredDeleteW")
+	credFree   = advapi32.NewProc("CredFree")
+)
+
+const (
+	credTypeGeneric         = 1    // CRED_TYPE_GENERIC
+	credPersistLocalMachine = 2    // CRED_PERSIST_LOCAL_MACHINE
+	errorNotFound           = 1168 // ERROR_NOT_FOUND
+)
+
+// credential mirrors the CREDENTIALW structure of the Windows API.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// credentialKeyring is the Windows Credential Manager, storing the secrets as
+// generic credentials of the current user.
+type credentialKeyring struct{}
+
+func osKeyring() (Keyring, error) {
+	if err := credReadW.Find(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrKeyringUnsupported, err)
+	}
+	return credentialKeyring{}, nil
+}
+
+// credentialTarget returns the name of the credential of a user of a service.
+func credentialTarget(service, user string) (*uint16, error) {
+	return syscall.UTF16PtrFromString(service + ":" + user)
+}
+
+// Get implements Keyring, retrieving a secret from the Credential Manager.
+func (credentialKeyring) Get(service, user string) (string, error) {
+	target, err := credentialTarget(service, user)
+	if err != nil {
+		return "", err
+	}
+	var cred *credential
+	if ret, _, err := credReadW.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred))); ret == 0 {
+		if err == syscall.Errno(errorNotFound) {
+			return "", ErrKeyringNotFound
+		}
+		return "", fmt.Errorf("credential lookup failed: %v", err)
+	}
+	defer credFree.Call(uintptr(unsafe.Pointer(cred)))
+
+	blob := unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)
+	return string(blob), nil
+}
+
+// Set implements Keyring, storing a secret in the Credential Manager.
+func (credentialKeyring) Set(service, user, secret string) error {
+	target, err := credentialTarget(service, user)
+	if err != nil {
+		return err
+	}
+	username, err := syscall.UTF16PtrFromString(user)
+	if err != nil {
+		return err
+	}
+	blob := []byte(secret)
+	cred := credential{
+		Type:               credTypeGeneric,
+		TargetName:         target,
+		CredentialBlobSize: uint32(len(blob)),
+		Persist:            credPersistLocalMachine,
+		UserName:           username,
+	}
+	if len(blob) > 0 {
+		cred.CredentialBlob = &blob[0]
+	}
+	if ret, _, err := credWriteW.Call(uintptr(unsafe.Pointer(&cred)), 0); ret == 0 {
+		return fmt.Errorf("credential store failed: %v", err)
+	}
+	return nil
+}
+
+// Delete implements Keyring, removing a secret from the Credential Manager.
+func (credentialKeyring) Delete(service, user string) error {
+	target, err := credentialTarget(service, user)
+	if err != nil {
+		return err
+	}
+	if ret, _, err := credDelete.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0); ret == 0 && err != syscall.Errno(errorNotFound) {
+		return fmt.Errorf("credential delete failed: %v", err)
+	}
+	return nil
+}
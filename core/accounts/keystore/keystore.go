@@ -24,6 +24,7 @@ import (
 	"crypto/ecdsa"
 	crand "crypto/rand"
 	"errors"
+	"fmt"
 	"math/big"
 	"os"
 	"path/filepath"
@@ -64,6 +65,8 @@ type KeyStore struct {
 	cache    *accountCache                // In-memory account cache over the filesystem storage
 	changes  chan struct{}                // Channel receiving change notifications from the cache
 	unlocked map[common.Address]*unlocked // Currently unlocked account (decrypted private keys)
+	limiter  *decryptLimiter              // Brute-force protection of decryptions, nil if disabled
+	policy   *policyEngine                // Signature policy enforced before signing, nil if disabled
 
 	wallets     []accounts.Wallet       // Wallet wrappers around the individual key files
 	updateFeed  event.Feed              // Event feed to notify wallet additions/removals
@@ -261,6 +264,8 @@ func (ks *KeyStore) Delete(a accounts.Account, passphrase string) error {
 // SignHash calculates a ECDSA signature for the given hash. The produced
 // signature is in the [R || S || V] format where V is 0 or 1.
 func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
+	policy := ks.signPolicy()
+
 	// Look up the key to sign with and abort if it cannot be found
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
@@ -269,12 +274,19 @@ func (ks *KeyStore) SignHash(a accounts.Account, hash []byte) ([]byte, error) {
 	if !found {
 		return nil, ErrLocked
 	}
+	if policy != nil {
+		if err := policy.authorizeRaw(a.Address); err != nil {
+			return nil, err
+		}
+	}
 	// Sign the hash using plain ECDSA operations
 	return crypto.Sign(hash, unlockedKey.PrivateKey)
 }
 
 // SignTx signs the given transaction with the requested account.
 func (ks *KeyStore) SignTx(a accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	policy := ks.signPolicy()
+
 	// Look up the key to sign with and abort if it cannot be found
 	ks.mu.RLock()
 	defer ks.mu.RUnlock()
@@ -283,6 +295,11 @@ func (ks *KeyStore) SignTx(a accounts.Account, tx *types.Transaction, chainID *b
 	if !found {
 		return nil, ErrLocked
 	}
+	if policy != nil {
+		if err := policy.authorizeTx(a.Address, tx); err != nil {
+			return nil, err
+		}
+	}
 	// Depending on the presence of the chain ID, sign with 2718 or homestead
 	signer := types.LatestSignerForChainID(chainID)
 	return types.SignTx(tx, signer, unlockedKey.PrivateKey)
@@ -297,6 +314,12 @@ func (ks *KeyStore) SignHashWithPassphrase(a accounts.Account, passphrase string
 		return nil, err
 	}
 	defer zeroKey(key.PrivateKey)
+
+	if policy := ks.signPolicy(); policy != nil {
+		if err := policy.authorizeRaw(a.Address); err != nil {
+			return nil, err
+		}
+	}
 	return crypto.Sign(hash, key.PrivateKey)
 }
 
@@ -308,11 +331,80 @@ func (ks *KeyStore) SignTxWithPassphrase(a accounts.Account, passphrase string,
 		return nil, err
 	}
 	defer zeroKey(key.PrivateKey)
+
+	if policy := ks.signPolicy(); policy != nil {
+		if err := policy.authorizeTx(a.Address, tx); err != nil {
+			return nil, err
+		}
+	}
 	// Depending on the presence of the chain ID, sign with or without replay protection.
 	signer := types.LatestSignerForChainID(chainID)
 	return types.SignTx(tx, signer, key.PrivateKey)
 }
 
+// SignBatch signs a batch of hashes if the private key matching the given address
+// can be decrypted with the given passphrase. The key is decrypted only once for
+// the whole batch and wiped afterwards, sparing the key derivation of signing
+// each hash separately. The produced signatures are in the [R || S || V] format
+// where V is 0 or 1, in the order of the hashes.
+func (ks *KeyStore) SignBatch(a accounts.Account, passphrase string, hashes [][]byte) ([][]byte, error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	if policy := ks.signPolicy(); policy != nil {
+		if err := policy.authorizeRaw(a.Address); err != nil {
+			return nil, err
+		}
+	}
+	signatures := make([][]byte, len(hashes))
+	for i, hash := range hashes {
+		if signatures[i], err = crypto.Sign(hash, key.PrivateKey); err != nil {
+			return nil, batchError(len(hashes), i, err)
+		}
+	}
+	return signatures, nil
+}
+
+// SignTxBatch signs a batch of transactions if the private key matching the given
+// address can be decrypted with the given passphrase, decrypting the key only
+// once like SignBatch. The batch is checked against the signature policy as a
+// whole before anything is signed, so either all or none of the transactions
+// are signed.
+func (ks *KeyStore) SignTxBatch(a accounts.Account, passphrase string, txs []*types.Transaction, chainID *big.Int) ([]*types.Transaction, error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	if policy := ks.signPolicy(); policy != nil {
+		if err := policy.authorizeTxs(a.Address, txs); err != nil {
+			return nil, err
+		}
+	}
+	signer := types.LatestSignerForChainID(chainID)
+
+	signed := make([]*types.Transaction, len(txs))
+	for i, tx := range txs {
+		if signed[i], err = types.SignTx(tx, signer, key.PrivateKey); err != nil {
+			return nil, batchError(len(txs), i, err)
+		}
+	}
+	return signed, nil
+}
+
+// batchError annotates the error of an item of a batch with its index, unless
+// the batch consists of that single item.
+func batchError(size int, index int, err error) error {
+	if size == 1 {
+		return err
+	}
+	return fmt.Errorf("batch item %d: %w", index, err)
+}
+
 // Unlock unlocks the given account indefinitely.
 func (ks *KeyStore) Unlock(a accounts.Account, passphrase string) error {
 	return ks.TimedUnlock(a, passphrase, 0)
@@ -375,12 +467,32 @@ func (ks *KeyStore) Find(a accounts.Account) (accounts.Account, error) {
 	return a, err
 }
 
+// keyStorage returns the storage backend of the keystore, which SetEscrowKey may
+// swap at any time.
+func (ks *KeyStore) keyStorage() keyStore {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return ks.storage
+}
+
 func (ks *KeyStore) getDecryptedKey(a accounts.Account, auth string) (accounts.Account, *Key, error) {
 	a, err := ks.Find(a)
 	if err != nil {
 		return a, nil, err
 	}
-	key, err := ks.storage.GetKey(a.Address, a.URL.Path, auth)
+	ks.mu.RLock()
+	limiter := ks.limiter
+	ks.mu.RUnlock()
+
+	if limiter != nil {
+		if err := limiter.allow(a.Address); err != nil {
+			return a, nil, err
+		}
+	}
+	key, err := ks.keyStorage().GetKey(a.Address, a.URL.Path, auth)
+	if limiter != nil {
+		limiter.record(a.Address, err)
+	}
 	return a, key, err
 }
 
@@ -407,7 +519,7 @@ func (ks *KeyStore) expire(addr common.Address, u *unlocked, timeout time.Durati
 // NewAccount generates a new key and stores it into the key directory,
 // encrypting it with the passphrase.
 func (ks *KeyStore) NewAccount(passphrase string) (accounts.Account, error) {
-	_, account, err := storeNewKey(ks.storage, crand.Reader, passphrase)
+	_, account, err := storeNewKey(ks.keyStorage(), crand.Reader, passphrase)
 	if err != nil {
 		return accounts.Account{}, err
 	}
@@ -425,7 +537,7 @@ func (ks *KeyStore) Export(a accounts.Account, passphrase, newPassphrase string)
 		return nil, err
 	}
 	var N, P int
-	if store, ok := ks.storage.(*keyStorePassphrase); ok {
+	if store, ok := ks.baseStorage().(*keyStorePassphrase); ok {
 		N, P = store.scryptN, store.scryptP
 	} else {
 		N, P = StandardScryptN, StandardScryptP
@@ -453,6 +565,61 @@ func (ks *KeyStore) Import(keyJSON []byte, passphrase, newPassphrase string) (ac
 	return ks.importKey(key, newPassphrase)
 }
 
+// ExportERC2335 exports as an ERC-2335 keystore, the format of validator keys,
+// embedding the validator metadata of the key.
+func (ks *KeyStore) ExportERC2335(a accounts.Account, passphrase, newPassphrase string) (keyJSON []byte, err error) {
+	_, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var N, P int
+	if store, ok := ks.baseStorage().(*keyStorePassphrase); ok {
+		N, P = store.scryptN, store.scryptP
+	} else {
+		N, P = StandardScryptN, StandardScryptP
+	}
+	return EncryptKeyERC2335(key, newPassphrase, N, P)
+}
+
+// ImportERC2335 stores the key of the given ERC-2335 keystore into the key
+// directory, keeping its derivation path and deposit as validator metadata.
+func (ks *KeyStore) ImportERC2335(keyJSON []byte, passphrase, newPassphrase string) (accounts.Account, error) {
+	key, err := DecryptKeyERC2335(keyJSON, passphrase)
+	if key != nil && key.PrivateKey != nil {
+		defer zeroKey(key.PrivateKey)
+	}
+	if err != nil {
+		return accounts.Account{}, err
+	}
+	ks.importMu.Lock()
+	defer ks.importMu.Unlock()
+
+	if ks.cache.hasAddress(key.Address) {
+		return accounts.Account{
+			Address: key.Address,
+		}, ErrAccountAlreadyExists
+	}
+	return ks.importKey(key, newPassphrase)
+}
+
+// SetValidatorInfo replaces the validator metadata of an existing account, nil
+// dropping it.
+func (ks *KeyStore) SetValidatorInfo(a accounts.Account, passphrase string, info *ValidatorInfo) error {
+	if info != nil {
+		if err := info.validate(); err != nil {
+			return err
+		}
+	}
+	a, key, err := ks.getDecryptedKey(a, passphrase)
+	if err != nil {
+		return err
+	}
+	defer zeroKey(key.PrivateKey)
+
+	key.Validator = info
+	return ks.keyStorage().StoreKey(a.URL.Path, key, passphrase)
+}
+
 // ImportECDSA stores the given key into the key directory, encrypting it with the passphrase.
 func (ks *KeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (accounts.Account, error) {
 	ks.importMu.Lock()
@@ -468,8 +635,9 @@ func (ks *KeyStore) ImportECDSA(priv *ecdsa.PrivateKey, passphrase string) (acco
 }
 
 func (ks *KeyStore) importKey(key *Key, passphrase string) (accounts.Account, error) {
-	a := accounts.Account{Address: key.Address, URL: accounts.URL{Scheme: KeyStoreScheme, Path: ks.storage.JoinPath(keyFileName(key.Address))}}
-	if err := ks.storage.StoreKey(a.URL.Path, key, passphrase); err != nil {
+	storage := ks.keyStorage()
+	a := accounts.Account{Address: key.Address, URL: accounts.URL{Scheme: KeyStoreScheme, Path: storage.JoinPath(keyFileName(key.Address))}}
+	if err := storage.StoreKey(a.URL.Path, key, passphrase); err != nil {
 		return accounts.Account{}, err
 	}
 	ks.cache.add(a)
@@ -483,13 +651,13 @@ func (ks *KeyStore) Update(a accounts.Account, passphrase, newPassphrase string)
 	if err != nil {
 		return err
 	}
-	return ks.storage.StoreKey(a.URL.Path, key, newPassphrase)
+	return ks.keyStorage().StoreKey(a.URL.Path, key, newPassphrase)
 }
 
 // ImportPreSaleKey decrypts the given Ethereum presale wallet and stores
 // a key file in the key directory. The key file is encrypted with the same passphrase.
 func (ks *KeyStore) ImportPreSaleKey(keyJSON []byte, passphrase string) (accounts.Account, error) {
-	a, _, err := importPreSaleKey(ks.storage, keyJSON, passphrase)
+	a, _, err := importPreSaleKey(ks.keyStorage(), keyJSON, passphrase)
 	if err != nil {
 		return a, err
 	}
@@ -17,7 +17,10 @@
 package keystore
 
 import (
+	"bytes"
+	"errors"
 	"io/ioutil"
+	"math/big"
 	"math/rand"
 	"os"
 	"runtime"
@@ -30,6 +33,7 @@ import (
 
 	"github.com/liuguodong24-8/3fcoin/core/accounts"
 	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
 	"github.com/liuguodong24-8/3fcoin/core/crypto"
 	"github.com/liuguodong24-8/3fcoin/core/event"
 )
@@ -116,6 +120,60 @@ func TestSignWithPassphrase(t *testing.T) {
 	}
 }
 
+// Tests that batches are signed with a single decryption, and the signatures
+// match the ones of individual signing.
+func TestSignBatch(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	pass := "passwd"
+	acc, err := ks.NewAccount(pass)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := [][]byte{testSigData, crypto.Keccak256([]byte("batch")), crypto.Keccak256(nil)}
+	signatures, err := ks.SignBatch(acc, pass, hashes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(signatures) != len(hashes) {
+		t.Fatalf("signature count mismatch: have %d, want %d", len(signatures), len(hashes))
+	}
+	for i, hash := range hashes {
+		want, err := ks.SignHashWithPassphrase(acc, pass, hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(signatures[i], want) {
+			t.Errorf("signature %d mismatch: have %x, want %x", i, signatures[i], want)
+		}
+	}
+	if _, unlocked := ks.unlocked[acc.Address]; unlocked {
+		t.Fatal("expected account to be locked")
+	}
+	if _, err := ks.SignBatch(acc, "invalid passwd", hashes); !errors.Is(err, ErrDecrypt) {
+		t.Fatalf("invalid password error mismatch: have %v, want %v", err, ErrDecrypt)
+	}
+	if _, err := ks.SignBatch(acc, pass, [][]byte{testSigData, {0x01}}); err == nil || !strings.Contains(err.Error(), "batch item 1") {
+		t.Fatalf("invalid hash error mismatch: %v", err)
+	}
+	// Transactions signed in a batch recover to the signing account
+	txs := []*types.Transaction{
+		types.NewTransaction(0, common.Address{0xaa}, big.NewInt(1), 21000, big.NewInt(1), nil),
+		types.NewTransaction(1, common.Address{0xbb}, big.NewInt(2), 21000, big.NewInt(1), nil),
+	}
+	signed, err := ks.SignTxBatch(acc, pass, txs, big.NewInt(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	for i, tx := range signed {
+		if from, err := types.Sender(signer, tx); err != nil || from != acc.Address {
+			t.Errorf("transaction %d sender mismatch: have %x, want %x (%v)", i, from, acc.Address, err)
+		}
+	}
+}
+
 func TestTimedUnlock(t *testing.T) {
 	dir, ks := tmpKeyStore(t, true)
 	defer os.RemoveAll(dir)
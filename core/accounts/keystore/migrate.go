@@ -0,0 +1,380 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common/math"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Wallet formats the migration importer understands.
+const (
+	MigrateKeystore = "keystore" // Web3 secret storage files (geth, MyEtherWallet V3)
+	MigrateMetaMask = "metamask" // MetaMask vault exports
+	MigrateMEW      = "mew"      // Legacy MyEtherWallet JSON wallets
+)
+
+// metamaskIterations is the PBKDF2 iteration count of vaults created before
+// MetaMask started recording it in the key metadata.
+const metamaskIterations = 10000
+
+// ErrUnknownWalletFormat is returned if a wallet export is in none of the
+// formats supported by the migration importer.
+var ErrUnknownWalletFormat = errors.New("unknown wallet format")
+
+// metamaskVault is the encrypted keyring vault of a MetaMask export.
+type metamaskVault struct {
+	Data        string `json:"data"`
+	IV          string `json:"iv"`
+	Salt        string `json:"salt"`
+	KeyMetadata *struct {
+		Algorithm string `json:"algorithm"`
+		Params    struct {
+			Iterations int `json:"iterations"`
+		} `json:"params"`
+	} `json:"keyMetadata,omitempty"`
+}
+
+// metamaskKeyring is a single decrypted keyring of a MetaMask vault.
+type metamaskKeyring struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// mewWallet is a legacy MyEtherWallet JSON wallet.
+type mewWallet struct {
+	Address   string `json:"address"`
+	Encrypted bool   `json:"encrypted"`
+	Private   string `json:"private"`
+}
+
+// DetectWalletFormat returns the migration format of a wallet export.
+func DetectWalletFormat(data []byte) (string, error) {
+	m := make(map[string]interface{})
+	if err := json.Unmarshal(data, &m); err != nil {
+		return "", err
+	}
+	switch {
+	case m["crypto"] != nil || m["Crypto"] != nil:
+		return MigrateKeystore, nil
+	case m["vault"] != nil || m["KeyringController"] != nil || (m["data"] != nil && m["iv"] != nil && m["salt"] != nil):
+		return MigrateMetaMask, nil
+	case m["private"] != nil:
+		return MigrateMEW, nil
+	}
+	return "", ErrUnknownWalletFormat
+}
+
+// DecryptWallet decrypts all private keys contained in a wallet export of any
+// of the supported migration formats.
+func DecryptWallet(data []byte, password string) ([]*ecdsa.PrivateKey, error) {
+	format, err := DetectWalletFormat(data)
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case MigrateKeystore:
+		key, err := DecryptKey(data, password)
+		if err != nil {
+			return nil, err
+		}
+		return []*ecdsa.PrivateKey{key.PrivateKey}, nil
+
+	case MigrateMetaMask:
+		return decryptMetaMaskVault(data, password)
+
+	default:
+		return decryptMEWWallet(data, password)
+	}
+}
+
+// Migrate decrypts a wallet export of any of the supported migration formats
+// and stores the contained keys in the keystore, encrypted with newPassphrase.
+// Keys already present in the keystore are skipped.
+func (ks *KeyStore) Migrate(data []byte, password, newPassphrase string) ([]accounts.Account, error) {
+	keys, err := DecryptWallet(data, password)
+	if err != nil {
+		return nil, err
+	}
+	var imported []accounts.Account
+	for _, key := range keys {
+		a, err := ks.ImportECDSA(key, newPassphrase)
+		zeroKey(key)
+
+		switch err {
+		case nil:
+			imported = append(imported, a)
+		case ErrAccountAlreadyExists:
+		default:
+			return imported, err
+		}
+	}
+	return imported, nil
+}
+
+// decryptMetaMaskVault decrypts a MetaMask vault, returning the keys of all
+// simple and HD keyrings within.
+func decryptMetaMaskVault(data []byte, password string) ([]*ecdsa.PrivateKey, error) {
+	// Unwrap the vault from a full state export if needed
+	var wrapper struct {
+		Vault             string `json:"vault"`
+		KeyringController *struct {
+			Vault string `json:"vault"`
+		} `json:"KeyringController"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, err
+	}
+	if wrapper.KeyringController != nil {
+		wrapper.Vault = wrapper.KeyringController.Vault
+	}
+	if wrapper.Vault != "" {
+		data = []byte(wrapper.Vault)
+	}
+	var vault metamaskVault
+	if err := json.Unmarshal(data, &vault); err != nil {
+		return nil, err
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(vault.Data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault data: %v", err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(vault.IV)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault iv: %v", err)
+	}
+	salt, err := base64.StdEncoding.DecodeString(vault.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vault salt: %v", err)
+	}
+	iterations := metamaskIterations
+	if vault.KeyMetadata != nil {
+		if vault.KeyMetadata.Algorithm != "PBKDF2" {
+			return nil, fmt.Errorf("unsupported vault KDF: %s", vault.KeyMetadata.Algorithm)
+		}
+		iterations = vault.KeyMetadata.Params.Iterations
+	}
+	block, err := aes.NewCipher(pbkdf2.Key([]byte(password), salt, iterations, 32, sha256.New))
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, iv, ciphertext, nil)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	var keyrings []metamaskKeyring
+	if err := json.Unmarshal(plaintext, &keyrings); err != nil {
+		return nil, fmt.Errorf("invalid vault content: %v", err)
+	}
+	var keys []*ecdsa.PrivateKey
+	for _, keyring := range keyrings {
+		switch keyring.Type {
+		case "Simple Key Pair":
+			var privs []string
+			if err := json.Unmarshal(keyring.Data, &privs); err != nil {
+				return nil, fmt.Errorf("invalid simple keyring: %v", err)
+			}
+			for _, priv := range privs {
+				key, err := crypto.HexToECDSA(strings.TrimPrefix(priv, "0x"))
+				if err != nil {
+					return nil, fmt.Errorf("invalid simple keyring key: %v", err)
+				}
+				keys = append(keys, key)
+			}
+
+		case "HD Key Tree":
+			hdkeys, err := deriveMetaMaskKeyring(keyring.Data)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, hdkeys...)
+
+		default:
+			// Hardware wallet keyrings hold no secrets, skip them
+		}
+	}
+	return keys, nil
+}
+
+// deriveMetaMaskKeyring derives the accounts of a MetaMask HD keyring.
+func deriveMetaMaskKeyring(data []byte) ([]*ecdsa.PrivateKey, error) {
+	var keyring struct {
+		Mnemonic         json.RawMessage `json:"mnemonic"`
+		NumberOfAccounts int             `json:"numberOfAccounts"`
+		HDPath           string          `json:"hdPath"`
+	}
+	if err := json.Unmarshal(data, &keyring); err != nil {
+		return nil, fmt.Errorf("invalid HD keyring: %v", err)
+	}
+	// Newer vaults store the mnemonic as an array of UTF-8 bytes
+	var mnemonic string
+	if err := json.Unmarshal(keyring.Mnemonic, &mnemonic); err != nil {
+		var raw []byte
+		var codes []int
+		if err := json.Unmarshal(keyring.Mnemonic, &codes); err != nil {
+			return nil, fmt.Errorf("invalid HD keyring mnemonic: %v", err)
+		}
+		for _, code := range codes {
+			raw = append(raw, byte(code))
+		}
+		mnemonic = string(raw)
+	}
+	base := accounts.DefaultRootDerivationPath
+	if keyring.HDPath != "" {
+		var err error
+		if base, err = accounts.ParseDerivationPath(keyring.HDPath); err != nil {
+			return nil, err
+		}
+	}
+	seed := mnemonicSeed(mnemonic, "")
+
+	keys := make([]*ecdsa.PrivateKey, 0, keyring.NumberOfAccounts)
+	for i := 0; i < keyring.NumberOfAccounts; i++ {
+		path := append(accounts.DerivationPath{}, base...)
+		key, err := deriveHDKey(seed, append(path, uint32(i)))
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// decryptMEWWallet decrypts a legacy MyEtherWallet JSON wallet. Encrypted ones
+// hold the hex private key as a CryptoJS (OpenSSL compatible) AES ciphertext.
+func decryptMEWWallet(data []byte, password string) ([]*ecdsa.PrivateKey, error) {
+	var wallet mewWallet
+	if err := json.Unmarshal(data, &wallet); err != nil {
+		return nil, err
+	}
+	priv := wallet.Private
+	if wallet.Encrypted {
+		blob, err := base64.StdEncoding.DecodeString(priv)
+		if err != nil || len(blob) < 16 || !bytes.HasPrefix(blob, []byte("Salted__")) {
+			return nil, errors.New("invalid MEW ciphertext")
+		}
+		key, iv := opensslKeyIV([]byte(password), blob[8:16])
+		plaintext, err := aesCBCDecrypt(key, blob[16:], iv)
+		if err != nil {
+			return nil, err
+		}
+		priv = string(plaintext)
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(priv, "0x"))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	if wallet.Address != "" {
+		addr := crypto.PubkeyToAddress(key.PublicKey)
+		if want := strings.ToLower(strings.TrimPrefix(wallet.Address, "0x")); hex.EncodeToString(addr[:]) != want {
+			return nil, fmt.Errorf("decrypted address %x not equal to expected %s", addr, want)
+		}
+	}
+	return []*ecdsa.PrivateKey{key}, nil
+}
+
+// opensslKeyIV derives an AES-256 key and IV from a password the same way as
+// OpenSSL's EVP_BytesToKey with MD5 and a single iteration.
+func opensslKeyIV(password, salt []byte) ([]byte, []byte) {
+	var (
+		derived []byte
+		prev    []byte
+	)
+	for len(derived) < 48 {
+		h := md5.New()
+		h.Write(prev)
+		h.Write(password)
+		h.Write(salt)
+		prev = h.Sum(nil)
+		derived = append(derived, prev...)
+	}
+	return derived[:32], derived[32:48]
+}
+
+// mnemonicSeed converts a BIP-39 mnemonic into the seed of the HD wallet. The
+// mnemonic is expected to already be in NFKD form, as all English ones are.
+func mnemonicSeed(mnemonic, passphrase string) []byte {
+	mnemonic = strings.Join(strings.Fields(mnemonic), " ")
+	return pbkdf2.Key([]byte(mnemonic), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
+
+// deriveHDKey derives the BIP-32 private key at the given path from a seed.
+func deriveHDKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	var (
+		curveN = crypto.S256().Params().N
+		key    = new(big.Int).SetBytes(sum[:32])
+		chain  = sum[32:]
+	)
+	if key.Sign() == 0 || key.Cmp(curveN) >= 0 {
+		return nil, errors.New("invalid HD master key")
+	}
+	for _, index := range path {
+		var data []byte
+		if index >= 0x80000000 {
+			data = append([]byte{0}, math.PaddedBigBytes(key, 32)...)
+		} else {
+			priv, err := crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&priv.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chain)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(curveN) >= 0 {
+			return nil, fmt.Errorf("invalid HD child key at index %d", index)
+		}
+		key.Add(key, tweak).Mod(key, curveN)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("invalid HD child key at index %d", index)
+		}
+		chain = sum[32:]
+	}
+	return crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+}
@@ -0,0 +1,125 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/hex"
+	"strconv"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+const (
+	// MetaMask vault with a simple keyring and an HD keyring of the well-known
+	// "test ... junk" mnemonic, encrypted with "testpassword".
+	testMetaMaskVault = `{"data":"XJl36vMDWYMujSe/i4sqe0eQkrVLM27Z0X/3HqIcgFxVnh1xy2gXsmYHvhdS1MqJ7PawBjmdPct1g/747F9dYPnQopDv7/HLX42HcWz+S75friz3nUtEcVQxhJKvfgYe9UG3lAeazJ9H1P9SgN3SLhhcMqfZRuwYrOpp//PYoYuF12Q+QdRln7dEIH+WpLIE0nwMN6zSTkXFlwZrWpqfUlKfsbX5+Dtlvq6ShsuX1YFzvtDKWszYeDOe7tWMuSvu1suVieT4TUar0hJ73UXUwHlsnmMS4y0But1oFtGn9ka9IoQMr16q+IoMwyP9c9Kf0J8s3plaZ5LEQNr/t6pmgjxU79EvHmW6v1od42fnc3g=","iv":"ZmVkY2JhOTg3NjU0MzIxMA==","salt":"MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}`
+
+	// Legacy MyEtherWallet wallet encrypted with "testpassword".
+	testMEWWallet = `{"address":"0x2c7536e3605d9c16a7a3d7b1898e529396a65c23","encrypted":true,"locked":true,"private":"U2FsdGVkX18BAgMEBQYHCJwaUEelFjkirPW08MWuHycAF1FNHMJ9O8opyrOP/ZhACCmudphjQj1F7zfkEEffnnOScS+QBxT083eF9MaUfFUSZwnO6YbwNkotjV1ofRbD"}`
+)
+
+var (
+	testMigrateSimple = common.BytesToAddress(common.FromHex("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"))
+	testMigrateHD0    = common.BytesToAddress(common.FromHex("0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"))
+	testMigrateHD1    = common.BytesToAddress(common.FromHex("0x70997970c51812dc3a010c7d01b50e0d17dc79c8"))
+)
+
+// Tests HD key derivation against the BIP-32 test vector 1.
+func TestDeriveHDKey(t *testing.T) {
+	seed := common.FromHex("0x000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	}
+	for _, tt := range tests {
+		path, err := accounts.ParseDerivationPath(tt.path)
+		if err != nil {
+			t.Fatalf("%s: invalid path: %v", tt.path, err)
+		}
+		key, err := deriveHDKey(seed, path)
+		if err != nil {
+			t.Fatalf("%s: derivation failed: %v", tt.path, err)
+		}
+		if have := hex.EncodeToString(crypto.FromECDSA(key)); have != tt.key {
+			t.Errorf("%s: key mismatch: have %s, want %s", tt.path, have, tt.key)
+		}
+	}
+}
+
+// Tests that foreign wallet exports are detected and decrypted.
+func TestDecryptWallet(t *testing.T) {
+	tests := []struct {
+		data   string
+		format string
+		want   []common.Address
+	}{
+		{testMetaMaskVault, MigrateMetaMask, []common.Address{testMigrateSimple, testMigrateHD0, testMigrateHD1}},
+		{`{"vault":` + strconv.Quote(testMetaMaskVault) + `}`, MigrateMetaMask, []common.Address{testMigrateSimple, testMigrateHD0, testMigrateHD1}},
+		{testMEWWallet, MigrateMEW, []common.Address{testMigrateSimple}},
+	}
+	for i, tt := range tests {
+		format, err := DetectWalletFormat([]byte(tt.data))
+		if err != nil || format != tt.format {
+			t.Errorf("test %d: format mismatch: have %s (%v), want %s", i, format, err, tt.format)
+		}
+		if _, err := DecryptWallet([]byte(tt.data), "wrongpassword"); err == nil {
+			t.Errorf("test %d: decrypted with wrong password", i)
+		}
+		keys, err := DecryptWallet([]byte(tt.data), "testpassword")
+		if err != nil {
+			t.Fatalf("test %d: failed to decrypt: %v", i, err)
+		}
+		if len(keys) != len(tt.want) {
+			t.Fatalf("test %d: key count mismatch: have %d, want %d", i, len(keys), len(tt.want))
+		}
+		for j, key := range keys {
+			if have := crypto.PubkeyToAddress(key.PublicKey); have != tt.want[j] {
+				t.Errorf("test %d, key %d: address mismatch: have %x, want %x", i, j, have, tt.want[j])
+			}
+		}
+	}
+	if _, err := DetectWalletFormat([]byte(`{"foo":"bar"}`)); err != ErrUnknownWalletFormat {
+		t.Errorf("unknown format error mismatch: have %v, want %v", err, ErrUnknownWalletFormat)
+	}
+}
+
+// Tests that migrating a wallet imports all keys once.
+func TestMigrate(t *testing.T) {
+	ks := NewKeyStore(t.TempDir(), veryLightScryptN, veryLightScryptP)
+
+	imported, err := ks.Migrate([]byte(testMetaMaskVault), "testpassword", "new")
+	if err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	if len(imported) != 3 {
+		t.Fatalf("imported account count mismatch: have %d, want 3", len(imported))
+	}
+	// Migrating an overlapping wallet again should skip existing keys
+	if imported, err = ks.Migrate([]byte(testMEWWallet), "testpassword", "new"); err != nil || len(imported) != 0 {
+		t.Fatalf("re-migration mismatch: have %d accounts (%v), want 0", len(imported), err)
+	}
+	if err := ks.Unlock(accounts.Account{Address: testMigrateHD1}, "new"); err != nil {
+		t.Fatalf("failed to unlock migrated account: %v", err)
+	}
+}
@@ -108,6 +108,9 @@ func (ks keyStorePassphrase) StoreKey(filename string, key *Key, auth string) er
 	if err != nil {
 		return err
 	}
+	if keyjson, err = keepKeySchema(filename, keyjson); err != nil {
+		return err
+	}
 	// Write into temporary file
 	tmpName, err := writeTemporaryKeyFile(filename, keyjson)
 	if err != nil {
@@ -182,8 +185,16 @@ func EncryptDataV3(data, auth []byte, scryptN, scryptP int) (CryptoJSON, error)
 }
 
 // EncryptKey encrypts a key using the specified scrypt parameters into a json
-// blob that can be decrypted later on.
+// blob that can be decrypted later on. Keys under a dual-control policy keep
+// it, in which case auth must satisfy the policy.
 func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
+	var policy *dualControlJSON
+	if key.dualControl != nil {
+		var err error
+		if policy, err = key.dualControl.encrypt(auth); err != nil {
+			return nil, err
+		}
+	}
 	keyBytes := math.PaddedBigBytes(key.PrivateKey.D, 32)
 	cryptoStruct, err := EncryptDataV3(keyBytes, []byte(auth), scryptN, scryptP)
 	if err != nil {
@@ -194,6 +205,8 @@ func EncryptKey(key *Key, auth string, scryptN, scryptP int) ([]byte, error) {
 		cryptoStruct,
 		key.Id.String(),
 		version,
+		policy,
+		key.Validator,
 	}
 	return json.Marshal(encryptedKeyJSONV3)
 }
@@ -208,6 +221,8 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 	// Depending on the version try to parse one way or another
 	var (
 		keyBytes, keyId []byte
+		policy          *dualControl
+		validator       *ValidatorInfo
 		err             error
 	)
 	if version, ok := m["version"].(string); ok && version == "1" {
@@ -221,7 +236,14 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 		if err := json.Unmarshal(keyjson, k); err != nil {
 			return nil, err
 		}
+		// Enforce any dual-control policy before touching the key
+		if k.DualControl != nil {
+			if policy, err = k.DualControl.decrypt(auth); err != nil {
+				return nil, err
+			}
+		}
 		keyBytes, keyId, err = decryptKeyV3(k, auth)
+		validator = k.Validator
 	}
 	// Handle any decryption errors and return the key
 	if err != nil {
@@ -233,9 +255,11 @@ func DecryptKey(keyjson []byte, auth string) (*Key, error) {
 		return nil, err
 	}
 	return &Key{
-		Id:         id,
-		Address:    crypto.PubkeyToAddress(key.PublicKey),
-		PrivateKey: key,
+		Id:          id,
+		Address:     crypto.PubkeyToAddress(key.PublicKey),
+		PrivateKey:  key,
+		dualControl: policy,
+		Validator:   validator,
 	}, nil
 }
 
@@ -276,7 +300,7 @@ func DecryptDataV3(cryptoJson CryptoJSON, auth string) ([]byte, error) {
 }
 
 func decryptKeyV3(keyProtected *encryptedKeyJSONV3, auth string) (keyBytes []byte, keyId []byte, err error) {
-	if keyProtected.Version != version {
+	if keyProtected.Version != version && keyProtected.Version != keySchemaVersion {
 		return nil, nil, fmt.Errorf("version not supported: %v", keyProtected.Version)
 	}
 	keyUUID, err := uuid.Parse(keyProtected.Id)
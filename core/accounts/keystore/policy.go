@@ -0,0 +1,375 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/common/math"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// policyVersion is the version of the signature policy format.
+const policyVersion = 1
+
+// Codes of the policy violations, stable for machine consumption.
+const (
+	PolicyValuePerTx      = "value-per-tx"            // Transaction value above the per transaction limit
+	PolicyValuePerDay     = "value-per-day"           // Transaction value exceeding the daily limit
+	PolicyDestinationDeny = "destination-denied"      // Destination on the denylist
+	PolicyDestinationList = "destination-not-allowed" // Destination missing from the allowlist
+	PolicyContractCreate  = "contract-creation"       // Contract creation while denied
+	PolicyMethod          = "method-not-allowed"      // Contract call of a method missing from the allowlist
+	PolicyRawSigning      = "raw-signing"             // Signing of opaque hashes while denied
+)
+
+// ErrPolicySignature is returned if a signature policy isn't signed by the
+// trusted policy signer.
+var ErrPolicySignature = errors.New("invalid signature policy signature")
+
+// PolicyViolation is returned if a sign operation is rejected by the signature
+// policy of the keystore.
+type PolicyViolation struct {
+	Code    string         `json:"code"`
+	Account common.Address `json:"account"`
+	Detail  string         `json:"detail"`
+}
+
+// Error implements error.
+func (v *PolicyViolation) Error() string {
+	return fmt.Sprintf("signature policy violation (%s): %s", v.Code, v.Detail)
+}
+
+// AccountPolicy restricts what a single account may sign. Unset limits and
+// empty lists don't restrict anything. Raw hashes, messages and data are never
+// signed for accounts with a policy unless explicitly allowed, as they could be
+// the signing hash of a transaction the policy would reject.
+type AccountPolicy struct {
+	MaxValuePerTx  *math.HexOrDecimal256 `json:"maxValuePerTx,omitempty"`  // Maximum value of a single transaction in wei
+	MaxValuePerDay *math.HexOrDecimal256 `json:"maxValuePerDay,omitempty"` // Maximum value signed per UTC day in wei
+
+	Allow []common.Address `json:"allow,omitempty"` // Only destinations transactions may be sent to
+	Deny  []common.Address `json:"deny,omitempty"`  // Destinations transactions may never be sent to
+
+	Methods         []hexutil.Bytes `json:"methods,omitempty"`         // Only 4 byte selectors contracts may be called with
+	DenyCreate      bool            `json:"denyCreate,omitempty"`      // Whether contract creations are refused
+	AllowRawSigning bool            `json:"allowRawSigning,omitempty"` // Whether hashes, messages and data may be signed
+}
+
+// Policy is the signed set of account policies a keystore enforces before
+// every sign operation.
+type Policy struct {
+	Version   int                               `json:"version"`
+	Accounts  map[common.Address]*AccountPolicy `json:"accounts"`
+	Default   *AccountPolicy                    `json:"default,omitempty"` // Policy of unlisted accounts, nil if unrestricted
+	Signer    common.Address                    `json:"signer"`
+	Signature hexutil.Bytes                     `json:"signature,omitempty"`
+}
+
+// canonicalAccountPolicy is the encoding of an account policy the policy
+// signature is made over, with all addresses in lowercase hex.
+type canonicalAccountPolicy struct {
+	MaxValuePerTx   *math.HexOrDecimal256 `json:"maxValuePerTx,omitempty"`
+	MaxValuePerDay  *math.HexOrDecimal256 `json:"maxValuePerDay,omitempty"`
+	Allow           []string              `json:"allow,omitempty"`
+	Deny            []string              `json:"deny,omitempty"`
+	Methods         []hexutil.Bytes       `json:"methods,omitempty"`
+	DenyCreate      bool                  `json:"denyCreate,omitempty"`
+	AllowRawSigning bool                  `json:"allowRawSigning,omitempty"`
+}
+
+// canonicalPolicy is the encoding of a policy the policy signature is made
+// over, with all addresses in lowercase hex.
+type canonicalPolicy struct {
+	Version  int                                `json:"version"`
+	Accounts map[string]*canonicalAccountPolicy `json:"accounts"`
+	Default  *canonicalAccountPolicy            `json:"default,omitempty"`
+	Signer   string                             `json:"signer"`
+}
+
+// canonicalAddresses encodes a list of addresses in lowercase hex.
+func canonicalAddresses(addrs []common.Address) []string {
+	var hexes []string
+	for _, addr := range addrs {
+		hexes = append(hexes, hexutil.Encode(addr.Bytes()))
+	}
+	return hexes
+}
+
+// canonical converts an account policy into its signed encoding.
+func (p *AccountPolicy) canonical() *canonicalAccountPolicy {
+	if p == nil {
+		return nil
+	}
+	return &canonicalAccountPolicy{
+		MaxValuePerTx:   p.MaxValuePerTx,
+		MaxValuePerDay:  p.MaxValuePerDay,
+		Allow:           canonicalAddresses(p.Allow),
+		Deny:            canonicalAddresses(p.Deny),
+		Methods:         p.Methods,
+		DenyCreate:      p.DenyCreate,
+		AllowRawSigning: p.AllowRawSigning,
+	}
+}
+
+// sigHash returns the hash the policy signature is made over. The policy is
+// encoded without the signature and with its addresses in lowercase hex rather
+// than FFF, so the hash doesn't depend on the active address prefix; map keys
+// are encoded sorted, which makes the encoding canonical.
+func (p *Policy) sigHash() []byte {
+	unsigned := &canonicalPolicy{
+		Version:  p.Version,
+		Accounts: make(map[string]*canonicalAccountPolicy),
+		Default:  p.Default.canonical(),
+		Signer:   hexutil.Encode(p.Signer.Bytes()),
+	}
+	for addr, policy := range p.Accounts {
+		unsigned.Accounts[hexutil.Encode(addr.Bytes())] = policy.canonical()
+	}
+	blob, _ := json.Marshal(unsigned)
+	return crypto.Keccak256(blob)
+}
+
+// Sign signs the policy with the given policy administration key.
+func (p *Policy) Sign(key *ecdsa.PrivateKey) error {
+	p.Version = policyVersion
+	p.Signer = crypto.PubkeyToAddress(key.PublicKey)
+
+	sig, err := crypto.Sign(p.sigHash(), key)
+	if err != nil {
+		return err
+	}
+	p.Signature = sig
+	return nil
+}
+
+// ParsePolicy parses a signature policy and verifies that it's signed by the
+// trusted policy signer and well formed.
+func ParsePolicy(data []byte, trusted common.Address) (*Policy, error) {
+	p := new(Policy)
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, err
+	}
+	if p.Version != policyVersion {
+		return nil, fmt.Errorf("unsupported signature policy version %d", p.Version)
+	}
+	pubkey, err := crypto.SigToPub(p.sigHash(), p.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrPolicySignature, err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubkey); signer != p.Signer || signer != trusted {
+		return nil, fmt.Errorf("%w: signed by %s", ErrPolicySignature, signer)
+	}
+	for addr, policy := range p.Accounts {
+		if err := policy.validate(); err != nil {
+			return nil, fmt.Errorf("account %s: %v", addr, err)
+		}
+	}
+	if p.Default != nil {
+		if err := p.Default.validate(); err != nil {
+			return nil, fmt.Errorf("default policy: %v", err)
+		}
+	}
+	return p, nil
+}
+
+// validate checks that an account policy is well formed.
+func (p *AccountPolicy) validate() error {
+	if p == nil {
+		return errors.New("empty policy")
+	}
+	for _, method := range p.Methods {
+		if len(method) != 4 {
+			return fmt.Errorf("invalid method selector %x", []byte(method))
+		}
+	}
+	return nil
+}
+
+// dailySpend is the value an account signed for on a single UTC day.
+type dailySpend struct {
+	day   int64
+	value *big.Int
+}
+
+// policyEngine enforces the signature policy of a keystore.
+type policyEngine struct {
+	policy *Policy
+	spent  map[common.Address]*dailySpend
+	now    func() time.Time // Time source, replaceable for tests
+	lock   sync.Mutex
+}
+
+func newPolicyEngine(policy *Policy) *policyEngine {
+	return &policyEngine{
+		policy: policy,
+		spent:  make(map[common.Address]*dailySpend),
+		now:    time.Now,
+	}
+}
+
+// accountPolicy returns the policy of an account, nil if unrestricted.
+func (e *policyEngine) accountPolicy(addr common.Address) *AccountPolicy {
+	if policy, ok := e.policy.Accounts[addr]; ok {
+		return policy
+	}
+	return e.policy.Default
+}
+
+// authorizeTx checks a transaction against the policy of the signing account,
+// and accounts its value against the daily limit if allowed.
+func (e *policyEngine) authorizeTx(addr common.Address, tx *types.Transaction) error {
+	return e.authorizeTxs(addr, []*types.Transaction{tx})
+}
+
+// authorizeTxs checks a batch of transactions against the policy of the signing
+// account. The batch is authorized as a whole, the values only count against the
+// daily limit if all transactions are allowed.
+func (e *policyEngine) authorizeTxs(addr common.Address, txs []*types.Transaction) error {
+	policy := e.accountPolicy(addr)
+	if policy == nil {
+		return nil
+	}
+	for i, tx := range txs {
+		if err := checkTx(addr, policy, tx); err != nil {
+			return batchError(len(txs), i, err)
+		}
+	}
+	if policy.MaxValuePerDay == nil {
+		return nil
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	day := e.now().UTC().Unix() / 86400
+	spent := e.spent[addr]
+	if spent == nil || spent.day != day {
+		spent = &dailySpend{day: day, value: new(big.Int)}
+		e.spent[addr] = spent
+	}
+	var (
+		limit = (*big.Int)(policy.MaxValuePerDay)
+		total = new(big.Int).Set(spent.value)
+	)
+	for i, tx := range txs {
+		signed := new(big.Int).Set(total)
+		if total.Add(total, tx.Value()); total.Cmp(limit) > 0 {
+			err := &PolicyViolation{Code: PolicyValuePerDay, Account: addr, Detail: fmt.Sprintf("value %v exceeds daily limit %v, %v already signed", tx.Value(), limit, signed)}
+			return batchError(len(txs), i, err)
+		}
+	}
+	spent.value = total
+	return nil
+}
+
+// checkTx checks the limits of a policy not depending on previously signed
+// transactions.
+func checkTx(addr common.Address, policy *AccountPolicy, tx *types.Transaction) error {
+	violation := func(code, format string, args ...interface{}) error {
+		return &PolicyViolation{Code: code, Account: addr, Detail: fmt.Sprintf(format, args...)}
+	}
+	value := tx.Value()
+	if limit := (*big.Int)(policy.MaxValuePerTx); limit != nil && value.Cmp(limit) > 0 {
+		return violation(PolicyValuePerTx, "value %v above limit %v", value, limit)
+	}
+	if to := tx.To(); to == nil {
+		if policy.DenyCreate {
+			return violation(PolicyContractCreate, "contract creation denied")
+		}
+	} else {
+		for _, denied := range policy.Deny {
+			if *to == denied {
+				return violation(PolicyDestinationDeny, "destination %s denied", to)
+			}
+		}
+		if len(policy.Allow) > 0 {
+			allowed := false
+			for _, dest := range policy.Allow {
+				if *to == dest {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				return violation(PolicyDestinationList, "destination %s not allowed", to)
+			}
+		}
+		if data := tx.Data(); len(data) > 0 && len(policy.Methods) > 0 {
+			allowed := false
+			for _, method := range policy.Methods {
+				if len(data) >= 4 && bytes.Equal(data[:4], method) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				selector := data
+				if len(selector) > 4 {
+					selector = selector[:4]
+				}
+				return violation(PolicyMethod, "method %x not allowed", selector)
+			}
+		}
+	}
+	return nil
+}
+
+// authorizeRaw checks whether an account may sign opaque hashes, which the
+// policy can't inspect.
+func (e *policyEngine) authorizeRaw(addr common.Address) error {
+	if policy := e.accountPolicy(addr); policy != nil && !policy.AllowRawSigning {
+		return &PolicyViolation{Code: PolicyRawSigning, Account: addr, Detail: "signing of raw hashes denied"}
+	}
+	return nil
+}
+
+// SetPolicy makes the keystore enforce the given signature policy before every
+// sign operation, rejecting violations with a *PolicyViolation error. The policy
+// should be obtained via ParsePolicy to ensure it's authentic. A nil policy
+// disables enforcement.
+//
+// Transaction values count against the daily limits as soon as the transaction
+// is signed, whether or not it's ever sent.
+func (ks *KeyStore) SetPolicy(policy *Policy) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if policy == nil {
+		ks.policy = nil
+		return
+	}
+	ks.policy = newPolicyEngine(policy)
+}
+
+// signPolicy returns the signature policy engine of the keystore, nil if no
+// policy is enforced.
+func (ks *KeyStore) signPolicy() *policyEngine {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+
+	return ks.policy
+}
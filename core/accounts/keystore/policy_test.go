@@ -0,0 +1,263 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+	"github.com/liuguodong24-8/3fcoin/core/common/math"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// signedPolicy signs a policy and parses it back as the keystore would.
+func signedPolicy(t *testing.T, policy *Policy) *Policy {
+	admin, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Sign(admin); err != nil {
+		t.Fatalf("failed to sign policy: %v", err)
+	}
+	blob, err := json.Marshal(policy)
+	if err != nil {
+		t.Fatalf("failed to encode policy: %v", err)
+	}
+	parsed, err := ParsePolicy(blob, crypto.PubkeyToAddress(admin.PublicKey))
+	if err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+	return parsed
+}
+
+// Tests that policies are only accepted from the trusted signer and that
+// tampering invalidates them.
+func TestPolicySignature(t *testing.T) {
+	admin, _ := crypto.GenerateKey()
+	policy := &Policy{
+		Accounts: map[common.Address]*AccountPolicy{
+			{0x01}: {MaxValuePerTx: (*math.HexOrDecimal256)(big.NewInt(100))},
+		},
+	}
+	if err := policy.Sign(admin); err != nil {
+		t.Fatalf("failed to sign policy: %v", err)
+	}
+	blob, _ := json.Marshal(policy)
+
+	if _, err := ParsePolicy(blob, crypto.PubkeyToAddress(admin.PublicKey)); err != nil {
+		t.Fatalf("failed to parse signed policy: %v", err)
+	}
+	// The signature must not depend on the address prefix the policy is encoded with
+	common.SetAddressPrefix(common.TFFHeader)
+	reencoded, _ := json.Marshal(policy)
+	common.SetAddressPrefix(common.FFFHeader)
+	if string(reencoded) == string(blob) {
+		t.Fatalf("policy encoding independent of the address prefix")
+	}
+	common.SetCrossNetworkAddresses(true)
+	_, err := ParsePolicy(reencoded, crypto.PubkeyToAddress(admin.PublicKey))
+	common.SetCrossNetworkAddresses(false)
+	if err != nil {
+		t.Fatalf("failed to parse re-encoded policy: %v", err)
+	}
+	if _, err := ParsePolicy(blob, common.Address{0xff}); !errors.Is(err, ErrPolicySignature) {
+		t.Errorf("untrusted signer error mismatch: have %v, want %v", err, ErrPolicySignature)
+	}
+	policy.Accounts[common.Address{0x01}].MaxValuePerTx = (*math.HexOrDecimal256)(big.NewInt(1000))
+	tampered, _ := json.Marshal(policy)
+	if _, err := ParsePolicy(tampered, crypto.PubkeyToAddress(admin.PublicKey)); !errors.Is(err, ErrPolicySignature) {
+		t.Errorf("tampered policy error mismatch: have %v, want %v", err, ErrPolicySignature)
+	}
+	// Malformed method selectors must be rejected even if signed
+	policy.Accounts[common.Address{0x01}].Methods = []hexutil.Bytes{{0x01, 0x02}}
+	policy.Sign(admin)
+	malformed, _ := json.Marshal(policy)
+	if _, err := ParsePolicy(malformed, crypto.PubkeyToAddress(admin.PublicKey)); err == nil {
+		t.Errorf("malformed method selector accepted")
+	}
+}
+
+// Tests that transactions are checked against the account policy before being
+// signed, and rejected with the matching violation code.
+func TestPolicyTransactions(t *testing.T) {
+	var (
+		allowed  = common.Address{0xaa}
+		denied   = common.Address{0xdd}
+		unlisted = common.Address{0xee}
+		transfer = hexutil.Bytes{0xa9, 0x05, 0x9c, 0xbb}
+	)
+	policy := &AccountPolicy{
+		MaxValuePerTx:  (*math.HexOrDecimal256)(big.NewInt(100)),
+		MaxValuePerDay: (*math.HexOrDecimal256)(big.NewInt(250)),
+		Allow:          []common.Address{allowed, denied},
+		Deny:           []common.Address{denied},
+		Methods:        []hexutil.Bytes{transfer},
+		DenyCreate:     true,
+	}
+	now := time.Date(2021, 6, 1, 23, 0, 0, 0, time.UTC)
+	engine := newPolicyEngine(&Policy{Default: policy})
+	engine.now = func() time.Time { return now }
+
+	tests := []struct {
+		tx   *types.Transaction
+		code string
+	}{
+		{types.NewTransaction(0, allowed, big.NewInt(101), 21000, big.NewInt(1), nil), PolicyValuePerTx},
+		{types.NewTransaction(0, denied, big.NewInt(1), 21000, big.NewInt(1), nil), PolicyDestinationDeny},
+		{types.NewTransaction(0, unlisted, big.NewInt(1), 21000, big.NewInt(1), nil), PolicyDestinationList},
+		{types.NewTransaction(0, allowed, big.NewInt(1), 21000, big.NewInt(1), []byte{0x01, 0x02, 0x03, 0x04}), PolicyMethod},
+		{types.NewTransaction(0, allowed, big.NewInt(1), 21000, big.NewInt(1), []byte{0xa9}), PolicyMethod},
+		{types.NewContractCreation(0, big.NewInt(0), 21000, big.NewInt(1), []byte{0x60}), PolicyContractCreate},
+		{types.NewTransaction(0, allowed, big.NewInt(100), 21000, big.NewInt(1), append(transfer, 0x00)), ""},
+		{types.NewTransaction(0, allowed, big.NewInt(100), 21000, big.NewInt(1), nil), ""},
+		{types.NewTransaction(0, allowed, big.NewInt(100), 21000, big.NewInt(1), nil), PolicyValuePerDay},
+		{types.NewTransaction(0, allowed, big.NewInt(50), 21000, big.NewInt(1), nil), ""},
+	}
+	for i, tt := range tests {
+		err := engine.authorizeTx(common.Address{0x01}, tt.tx)
+		if tt.code == "" {
+			if err != nil {
+				t.Errorf("test %d: allowed transaction rejected: %v", i, err)
+			}
+			continue
+		}
+		var violation *PolicyViolation
+		if !errors.As(err, &violation) {
+			t.Errorf("test %d: error mismatch: have %v, want violation %q", i, err, tt.code)
+			continue
+		}
+		if violation.Code != tt.code || violation.Account != (common.Address{0x01}) {
+			t.Errorf("test %d: violation mismatch: have %+v, want code %q", i, violation, tt.code)
+		}
+	}
+	// The daily limit resets at midnight UTC
+	now = now.Add(time.Hour)
+	if err := engine.authorizeTx(common.Address{0x01}, types.NewTransaction(0, allowed, big.NewInt(100), 21000, big.NewInt(1), nil)); err != nil {
+		t.Errorf("daily limit not reset: %v", err)
+	}
+}
+
+// Tests that transaction batches are authorized as a whole, only counting
+// against the daily limit if all of them are allowed.
+func TestPolicyTransactionBatch(t *testing.T) {
+	allowed := common.Address{0xaa}
+	engine := newPolicyEngine(&Policy{Default: &AccountPolicy{
+		MaxValuePerDay: (*math.HexOrDecimal256)(big.NewInt(100)),
+		Deny:           []common.Address{{0xdd}},
+	}})
+	batch := func(values ...int64) []*types.Transaction {
+		txs := make([]*types.Transaction, len(values))
+		for i, value := range values {
+			txs[i] = types.NewTransaction(uint64(i), allowed, big.NewInt(value), 21000, big.NewInt(1), nil)
+		}
+		return txs
+	}
+	var violation *PolicyViolation
+
+	// A batch exceeding the daily limit is rejected without spending any of it
+	if err := engine.authorizeTxs(common.Address{0x01}, batch(40, 40, 40)); !errors.As(err, &violation) || violation.Code != PolicyValuePerDay {
+		t.Fatalf("batch above daily limit error mismatch: %v", err)
+	}
+	if err := engine.authorizeTxs(common.Address{0x01}, batch(50, 50)); err != nil {
+		t.Fatalf("batch within daily limit rejected: %v", err)
+	}
+	if err := engine.authorizeTx(common.Address{0x01}, batch(1)[0]); !errors.As(err, &violation) || violation.Code != PolicyValuePerDay {
+		t.Fatalf("transaction above spent daily limit error mismatch: %v", err)
+	}
+	// A single violating transaction rejects the whole batch
+	txs := append(batch(1), types.NewTransaction(1, common.Address{0xdd}, big.NewInt(1), 21000, big.NewInt(1), nil))
+	if err := engine.authorizeTxs(common.Address{0x02}, txs); !errors.As(err, &violation) || violation.Code != PolicyDestinationDeny {
+		t.Fatalf("batch with denied destination error mismatch: %v", err)
+	}
+	if spent := engine.spent[common.Address{0x02}]; spent != nil && spent.value.Sign() != 0 {
+		t.Errorf("rejected batch counted against the daily limit: %v", spent.value)
+	}
+}
+
+// Tests that the keystore enforces its policy on all sign operations, and only
+// on the accounts the policy restricts.
+func TestKeyStorePolicy(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	restricted, err := ks.NewAccount("pass")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	free, err := ks.NewAccount("pass")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	ks.SetPolicy(signedPolicy(t, &Policy{
+		Accounts: map[common.Address]*AccountPolicy{
+			restricted.Address: {
+				MaxValuePerTx: (*math.HexOrDecimal256)(big.NewInt(10)),
+			},
+		},
+	}))
+	tx := types.NewTransaction(0, common.Address{0xaa}, big.NewInt(11), 21000, big.NewInt(1), nil)
+
+	var violation *PolicyViolation
+	if _, err := ks.SignTxWithPassphrase(restricted, "pass", tx, big.NewInt(1)); !errors.As(err, &violation) || violation.Code != PolicyValuePerTx {
+		t.Errorf("transaction above limit error mismatch: %v", err)
+	}
+	if _, err := ks.SignHashWithPassphrase(restricted, "pass", make([]byte, 32)); !errors.As(err, &violation) || violation.Code != PolicyRawSigning {
+		t.Errorf("raw signing error mismatch: %v", err)
+	}
+	if _, err := ks.SignBatch(restricted, "pass", [][]byte{make([]byte, 32)}); !errors.As(err, &violation) || violation.Code != PolicyRawSigning {
+		t.Errorf("batch raw signing error mismatch: %v", err)
+	}
+	if _, err := ks.SignTxBatch(restricted, "pass", []*types.Transaction{tx}, big.NewInt(1)); !errors.As(err, &violation) || violation.Code != PolicyValuePerTx {
+		t.Errorf("batch transaction above limit error mismatch: %v", err)
+	}
+	if _, err := ks.SignTxWithPassphrase(free, "pass", tx, big.NewInt(1)); err != nil {
+		t.Errorf("unrestricted account rejected: %v", err)
+	}
+	if err := ks.Unlock(restricted, "pass"); err != nil {
+		t.Fatalf("failed to unlock account: %v", err)
+	}
+	if _, err := ks.SignTx(restricted, tx, big.NewInt(1)); !errors.As(err, &violation) || violation.Code != PolicyValuePerTx {
+		t.Errorf("unlocked transaction above limit error mismatch: %v", err)
+	}
+	if _, err := ks.SignHash(restricted, make([]byte, 32)); !errors.As(err, &violation) || violation.Code != PolicyRawSigning {
+		t.Errorf("unlocked raw signing error mismatch: %v", err)
+	}
+	// Raw signing must be allowed explicitly for accounts with a policy
+	ks.SetPolicy(signedPolicy(t, &Policy{
+		Accounts: map[common.Address]*AccountPolicy{
+			restricted.Address: {
+				MaxValuePerTx:   (*math.HexOrDecimal256)(big.NewInt(10)),
+				AllowRawSigning: true,
+			},
+		},
+	}))
+	if _, err := ks.SignHash(restricted, make([]byte, 32)); err != nil {
+		t.Errorf("allowed raw signing rejected: %v", err)
+	}
+	// Dropping the policy lifts all restrictions
+	ks.SetPolicy(nil)
+	if _, err := ks.SignTx(restricted, tx, big.NewInt(1)); err != nil {
+		t.Errorf("transaction rejected without policy: %v", err)
+	}
+}
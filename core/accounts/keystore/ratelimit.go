@@ -0,0 +1,186 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// ErrLockedOut is returned if decryption of a key is refused because too many
+// wrong passphrases were tried against it recently.
+var ErrLockedOut = errors.New("too many failed decryption attempts")
+
+// DecryptLimits configures the brute-force protection of key decryptions, as
+// needed when the keystore is exposed through long-running services.
+type DecryptLimits struct {
+	MaxFailures int           // Failed attempts tolerated per address within the window
+	Window      time.Duration // Period over which failed attempts are counted
+	Backoff     time.Duration // Initial lockout period, doubled on each subsequent lockout
+	MaxBackoff  time.Duration // Upper bound of the lockout period (0 = unbounded)
+
+	// OnLockout is called whenever an address gets locked out, allowing operators
+	// to page on repeated failures. It must not block.
+	OnLockout func(LockoutEvent)
+}
+
+// DefaultDecryptLimits are sensible brute-force protection limits for keystores
+// serving remote requests.
+var DefaultDecryptLimits = DecryptLimits{
+	MaxFailures: 5,
+	Window:      10 * time.Minute,
+	Backoff:     time.Minute,
+	MaxBackoff:  24 * time.Hour,
+}
+
+// LockoutEvent is reported via DecryptLimits.OnLockout when an address gets
+// locked out.
+type LockoutEvent struct {
+	Address  common.Address // Address of the key under attack
+	Failures int            // Failed attempts within the window triggering the lockout
+	Lockouts int            // Consecutive lockouts of the address, including this one
+	Until    time.Time      // Time until decryption attempts are refused
+}
+
+// decryptAttempts tracks the recent failed decryptions of a single address.
+type decryptAttempts struct {
+	failures int       // Failed attempts within the current window
+	pending  int       // Attempts allowed whose outcome is not recorded yet
+	start    time.Time // Start of the current window
+	lockouts int       // Consecutive lockouts without a successful decryption
+	until    time.Time // End of the current lockout
+}
+
+// expire starts a new window if the current one elapsed, forgetting earlier
+// lockouts too if the address was left alone for a full window.
+func (att *decryptAttempts) expire(now time.Time, window time.Duration) {
+	if now.Sub(att.start) > window {
+		if now.Sub(att.until) > window {
+			att.lockouts = 0
+		}
+		att.failures, att.start = 0, now
+	}
+}
+
+// decryptLimiter enforces the decryption limits of a keystore.
+type decryptLimiter struct {
+	limits   DecryptLimits
+	attempts map[common.Address]*decryptAttempts
+	now      func() time.Time // Time source, replaceable for tests
+	lock     sync.Mutex
+}
+
+func newDecryptLimiter(limits DecryptLimits) *decryptLimiter {
+	return &decryptLimiter{
+		limits:   limits,
+		attempts: make(map[common.Address]*decryptAttempts),
+		now:      time.Now,
+	}
+}
+
+// allow checks whether a decryption attempt for the address is permitted, and
+// if so counts it as in flight until its outcome is recorded. Attempts in flight
+// use up the failure budget like failed ones, so guesses made in parallel can't
+// outrun the limit.
+func (l *decryptLimiter) allow(addr common.Address) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	att := l.attempts[addr]
+	if att == nil {
+		att = &decryptAttempts{start: now}
+		l.attempts[addr] = att
+	}
+	if wait := att.until.Sub(now); wait > 0 {
+		return fmt.Errorf("%w: retry in %v", ErrLockedOut, wait.Round(time.Second))
+	}
+	att.expire(now, l.limits.Window)
+	if att.failures+att.pending >= l.limits.MaxFailures {
+		return fmt.Errorf("%w: %d attempts in progress", ErrLockedOut, att.pending)
+	}
+	att.pending++
+	return nil
+}
+
+// record accounts for the outcome of a decryption attempt permitted by allow,
+// locking the address out if it exceeded the failure limit. Only wrong
+// passphrases count as failures, any other error merely ends the attempt.
+func (l *decryptLimiter) record(addr common.Address, err error) {
+	l.lock.Lock()
+	now := l.now()
+
+	att := l.attempts[addr]
+	if att == nil {
+		l.lock.Unlock()
+		return
+	}
+	att.pending--
+
+	if err == nil {
+		// A successful decryption resets the tracking
+		att.failures, att.lockouts, att.until, att.start = 0, 0, time.Time{}, now
+	}
+	if !errors.Is(err, ErrDecrypt) {
+		if att.pending == 0 && att.failures == 0 && att.lockouts == 0 {
+			delete(l.attempts, addr)
+		}
+		l.lock.Unlock()
+		return
+	}
+	att.expire(now, l.limits.Window)
+	att.failures++
+	if att.failures < l.limits.MaxFailures {
+		l.lock.Unlock()
+		return
+	}
+	backoff := l.limits.Backoff
+	for i := 0; i < att.lockouts && (l.limits.MaxBackoff == 0 || backoff < l.limits.MaxBackoff); i++ {
+		backoff *= 2
+	}
+	if l.limits.MaxBackoff > 0 && backoff > l.limits.MaxBackoff {
+		backoff = l.limits.MaxBackoff
+	}
+	att.lockouts++
+	att.until = now.Add(backoff)
+
+	event := LockoutEvent{Address: addr, Failures: att.failures, Lockouts: att.lockouts, Until: att.until}
+	att.failures, att.start = 0, att.until
+	l.lock.Unlock()
+
+	if l.limits.OnLockout != nil {
+		l.limits.OnLockout(event)
+	}
+}
+
+// SetDecryptLimits enables brute-force protection of the key decryptions done
+// by unlocking, signing with passphrase, exporting, updating and deleting. A
+// zero MaxFailures disables the protection.
+func (ks *KeyStore) SetDecryptLimits(limits DecryptLimits) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	if limits.MaxFailures <= 0 {
+		ks.limiter = nil
+		return
+	}
+	ks.limiter = newDecryptLimiter(limits)
+}
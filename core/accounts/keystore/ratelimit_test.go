@@ -0,0 +1,159 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// Tests that repeated failures lock an address out with exponential backoff.
+func TestDecryptLimiter(t *testing.T) {
+	var (
+		events []LockoutEvent
+		now    = time.Unix(1600000000, 0)
+		addr   = common.Address{0x01}
+		other  = common.Address{0x02}
+	)
+	limiter := newDecryptLimiter(DecryptLimits{
+		MaxFailures: 3,
+		Window:      time.Minute,
+		Backoff:     time.Second,
+		MaxBackoff:  3 * time.Second,
+		OnLockout:   func(ev LockoutEvent) { events = append(events, ev) },
+	})
+	limiter.now = func() time.Time { return now }
+
+	fail := func(n int) {
+		for i := 0; i < n; i++ {
+			if err := limiter.allow(addr); err != nil {
+				t.Fatalf("attempt refused: %v", err)
+			}
+			limiter.record(addr, ErrDecrypt)
+		}
+	}
+	// Failures below the limit are tolerated, the next one locks out
+	fail(2)
+	if len(events) != 0 {
+		t.Fatalf("premature lockout: %v", events)
+	}
+	fail(1)
+	if len(events) != 1 || events[0].Until != now.Add(time.Second) || events[0].Failures != 3 {
+		t.Fatalf("lockout mismatch: %+v", events)
+	}
+	if err := limiter.allow(addr); !errors.Is(err, ErrLockedOut) {
+		t.Fatalf("locked out address allowed: %v", err)
+	}
+	if err := limiter.allow(other); err != nil {
+		t.Fatalf("unrelated address refused: %v", err)
+	}
+	// Subsequent lockouts double the backoff up to the limit
+	for i, want := range []time.Duration{2 * time.Second, 3 * time.Second, 3 * time.Second} {
+		now = events[len(events)-1].Until
+		fail(3)
+		if have := events[len(events)-1].Until.Sub(now); have != want {
+			t.Errorf("lockout %d: backoff mismatch: have %v, want %v", i+2, have, want)
+		}
+	}
+	// A successful decryption resets the tracking
+	now = events[len(events)-1].Until
+	if err := limiter.allow(addr); err != nil {
+		t.Fatalf("attempt refused: %v", err)
+	}
+	limiter.record(addr, nil)
+	fail(3)
+	if have := events[len(events)-1].Until.Sub(now); have != time.Second {
+		t.Errorf("backoff not reset: %v", have)
+	}
+}
+
+// Tests that attempts in flight count against the failure limit, so parallel
+// guesses can't exceed it.
+func TestDecryptLimiterConcurrent(t *testing.T) {
+	var (
+		events []LockoutEvent
+		addr   = common.Address{0x01}
+	)
+	limiter := newDecryptLimiter(DecryptLimits{
+		MaxFailures: 3,
+		Window:      time.Minute,
+		Backoff:     time.Minute,
+		OnLockout:   func(ev LockoutEvent) { events = append(events, ev) },
+	})
+	// Attempts beyond the limit are refused while the others are in flight
+	for i := 0; i < 3; i++ {
+		if err := limiter.allow(addr); err != nil {
+			t.Fatalf("attempt %d refused: %v", i, err)
+		}
+	}
+	if err := limiter.allow(addr); !errors.Is(err, ErrLockedOut) {
+		t.Fatalf("attempt beyond limit allowed: %v", err)
+	}
+	// Attempts failing for other reasons release their slot without counting
+	limiter.record(addr, ErrNoMatch)
+	if err := limiter.allow(addr); err != nil {
+		t.Fatalf("released attempt refused: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		limiter.record(addr, ErrDecrypt)
+	}
+	if len(events) != 1 || events[0].Failures != 3 {
+		t.Fatalf("lockout mismatch: %+v", events)
+	}
+	if err := limiter.allow(addr); !errors.Is(err, ErrLockedOut) {
+		t.Fatalf("locked out address allowed: %v", err)
+	}
+}
+
+// Tests that the keystore refuses decryptions of locked out accounts, even
+// with the correct passphrase.
+func TestKeyStoreDecryptLimits(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	account, err := ks.NewAccount("foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var locked int
+	ks.SetDecryptLimits(DecryptLimits{
+		MaxFailures: 2,
+		Window:      time.Minute,
+		Backoff:     time.Hour,
+		OnLockout:   func(LockoutEvent) { locked++ },
+	})
+	for i := 0; i < 2; i++ {
+		if err := ks.Unlock(account, "bar"); err != ErrDecrypt {
+			t.Fatalf("attempt %d: error mismatch: %v", i, err)
+		}
+	}
+	if locked != 1 {
+		t.Fatalf("lockout not reported")
+	}
+	if err := ks.Unlock(account, "foo"); !errors.Is(err, ErrLockedOut) {
+		t.Fatalf("locked out account unlocked: %v", err)
+	}
+	// Disabling the protection lifts the lockout
+	ks.SetDecryptLimits(DecryptLimits{})
+	if err := ks.Unlock(account, "foo"); err != nil {
+		t.Fatalf("failed to unlock: %v", err)
+	}
+}
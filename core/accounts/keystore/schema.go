@@ -0,0 +1,194 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// keySchemaVersion is the newest version of the key file schema. Version 4
+// extends the Web3 Secret Storage (v3) format with key metadata and an inline
+// escrow blob; the crypto section is unchanged, so v4 files are v3 files with a
+// bumped version and extra fields. Other tools only read v3 files though, so new
+// keys are written as v3 and upgrading is opt-in through UpgradeKeyDir.
+const keySchemaVersion = 4
+
+// keyMigration upgrades a key file by one schema version. The key file is given
+// as its raw top level fields, so fields unknown to the migration are carried
+// over untouched.
+type keyMigration func(file string, fields map[string]json.RawMessage) error
+
+// keyMigrations are the schema migrations, keyed by the version they upgrade
+// from. Version 1 files can't be migrated without their passphrase and are left
+// as they are.
+var keyMigrations = map[int]keyMigration{
+	3: migrateKeyV3,
+}
+
+// keyMetaJSON is the metadata of a v4 key file.
+type keyMetaJSON struct {
+	Created *time.Time `json:"created,omitempty"` // Creation time of the key, if known
+}
+
+// migrateKeyV3 upgrades a v3 key file to v4, recording its creation time and
+// inlining its escrow blob if it has one.
+func migrateKeyV3(file string, fields map[string]json.RawMessage) error {
+	blob, err := json.Marshal(&keyMetaJSON{Created: keyFileCreated(file)})
+	if err != nil {
+		return err
+	}
+	fields["meta"] = blob
+
+	if escrow, err := ioutil.ReadFile(escrowFileName(file)); err == nil {
+		if !json.Valid(escrow) {
+			return fmt.Errorf("corrupt escrow blob %s", escrowFileName(file))
+		}
+		fields["escrow"] = escrow
+	}
+	return nil
+}
+
+// keyFileVersion returns the schema version of a key file, 0 if unknown.
+func keyFileVersion(fields map[string]json.RawMessage) int {
+	raw, ok := fields["version"]
+	if !ok {
+		return 0
+	}
+	var version int
+	if err := json.Unmarshal(raw, &version); err == nil {
+		return version
+	}
+	var legacy string
+	if err := json.Unmarshal(raw, &legacy); err == nil {
+		version, _ = strconv.Atoi(legacy)
+	}
+	return version
+}
+
+// upgradeKeyJSON migrates an encrypted key file to the current schema version,
+// returning the upgraded JSON and whether anything changed. The file name is
+// used to find the data living next to the key file, it's not read itself.
+func upgradeKeyJSON(file string, keyjson []byte) ([]byte, bool, error) {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(keyjson, &fields); err != nil {
+		return nil, false, err
+	}
+	version, changed := keyFileVersion(fields), false
+	for version < keySchemaVersion {
+		migrate, ok := keyMigrations[version]
+		if !ok {
+			break
+		}
+		if err := migrate(file, fields); err != nil {
+			return nil, false, fmt.Errorf("migrating from v%d: %v", version, err)
+		}
+		version++
+		fields["version"] = json.RawMessage(strconv.Itoa(version))
+		changed = true
+	}
+	if !changed {
+		return keyjson, false, nil
+	}
+	upgraded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, err
+	}
+	return upgraded, true, nil
+}
+
+// keepKeySchema brings freshly encrypted key JSON up to the schema version of
+// the key file it replaces, so rewriting an upgraded key file doesn't downgrade
+// it. New key files are left in the v3 format.
+func keepKeySchema(filename string, keyjson []byte) ([]byte, error) {
+	blob, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return keyjson, nil
+	}
+	existing := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(blob, &existing); err != nil || keyFileVersion(existing) < keySchemaVersion {
+		return keyjson, nil
+	}
+	upgraded, _, err := upgradeKeyJSON(filename, keyjson)
+	if err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(upgraded, &fields); err != nil {
+		return nil, err
+	}
+	// Carry over the metadata, and the inlined escrow blob unless a fresh one
+	// was picked up from next to the key file
+	if meta, ok := existing["meta"]; ok {
+		fields["meta"] = meta
+	}
+	if _, ok := fields["escrow"]; !ok {
+		if escrow, ok := existing["escrow"]; ok {
+			fields["escrow"] = escrow
+		}
+	}
+	return json.Marshal(fields)
+}
+
+// UpgradeKeyDir upgrades all the encrypted key files within a keystore directory
+// to the current schema version in place, returning the paths of the files
+// upgraded. No passphrase is needed as the encrypted key material is carried
+// over unchanged.
+func UpgradeKeyDir(dir string) ([]string, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var migrated []string
+	for _, fi := range entries {
+		if strings.HasSuffix(fi.Name(), "~") || strings.HasPrefix(fi.Name(), ".") {
+			continue
+		}
+		if fi.IsDir() || fi.Mode()&os.ModeType != 0 {
+			continue
+		}
+		path := filepath.Join(dir, fi.Name())
+		keyjson, err := ioutil.ReadFile(path)
+		if err != nil {
+			return migrated, err
+		}
+		var probe struct {
+			Crypto *CryptoJSON `json:"crypto"`
+		}
+		if err := json.Unmarshal(keyjson, &probe); err != nil || probe.Crypto == nil {
+			continue // Not an encrypted key file
+		}
+		upgraded, changed, err := upgradeKeyJSON(path, keyjson)
+		if err != nil {
+			return migrated, fmt.Errorf("%s: %v", path, err)
+		}
+		if !changed {
+			continue
+		}
+		if err := writeKeyFile(path, upgraded); err != nil {
+			return migrated, err
+		}
+		migrated = append(migrated, path)
+	}
+	return migrated, nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package keystore
+
+import (
+	crand "crypto/rand"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/accounts"
+	"github.com/liuguodong24-8/3fcoin/core/crypto"
+)
+
+// writeV3Key writes a key file in the v3 format into dir, returning its path.
+func writeV3Key(t *testing.T, dir string, scryptN, scryptP int) (string, *Key) {
+	key, err := newKey(crand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyjson, err := EncryptKey(key, "pass", scryptN, scryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, keyFileName(key.Address))
+	if err := writeKeyFile(path, keyjson); err != nil {
+		t.Fatal(err)
+	}
+	return path, key
+}
+
+// readKeyFields reads the top level fields of a key file.
+func readKeyFields(t *testing.T, path string) map[string]json.RawMessage {
+	keyjson, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(keyjson, &fields); err != nil {
+		t.Fatal(err)
+	}
+	return fields
+}
+
+// Tests that v3 key files are migrated to v4 in place, retaining their key and
+// inlining their escrow blobs.
+func TestMigrateKeyDir(t *testing.T) {
+	dir, ks := tmpKeyStore(t, true)
+	defer os.RemoveAll(dir)
+
+	path, key := writeV3Key(t, dir, veryLightScryptN, veryLightScryptP)
+
+	recovery, _ := crypto.GenerateKey()
+	blob, err := EscrowKey(key, &recovery.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeKeyFile(escrowFileName(path), blob); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err := UpgradeKeyDir(dir)
+	if err != nil {
+		t.Fatalf("failed to migrate keystore: %v", err)
+	}
+	if len(migrated) != 1 || migrated[0] != path {
+		t.Fatalf("migrated files mismatch: have %v, want [%s]", migrated, path)
+	}
+	fields := readKeyFields(t, path)
+	if version := keyFileVersion(fields); version != keySchemaVersion {
+		t.Errorf("schema version mismatch: have %d, want %d", version, keySchemaVersion)
+	}
+	var meta keyMetaJSON
+	if err := json.Unmarshal(fields["meta"], &meta); err != nil || meta.Created == nil || time.Since(*meta.Created) > time.Minute {
+		t.Errorf("metadata mismatch: %s, %v", fields["meta"], err)
+	}
+	if len(fields["escrow"]) == 0 {
+		t.Errorf("escrow blob not inlined")
+	}
+	keyjson, _ := ioutil.ReadFile(path)
+	if dec, err := DecryptKey(keyjson, "pass"); err != nil || dec.Address != key.Address {
+		t.Fatalf("failed to decrypt migrated key: %v", err)
+	}
+	// Migrating again must be a no-op
+	if migrated, err := UpgradeKeyDir(dir); err != nil || len(migrated) != 0 {
+		t.Errorf("repeated migration mismatch: have %v, %v", migrated, err)
+	}
+	// The inlined escrow blob must suffice for recovery
+	os.Remove(escrowFileName(path))
+	if err := ks.RecoverEscrow(accounts.Account{Address: key.Address}, recovery, "new"); err != nil {
+		t.Fatalf("failed to recover from inlined escrow: %v", err)
+	}
+}
+
+// Tests that new key files are written as v3, that decrypting a key file leaves
+// it untouched and that rewriting an upgraded key file keeps it at v4.
+func TestKeySchemaOptIn(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore-schema-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ks := &keyStorePassphrase{dir, veryLightScryptN, veryLightScryptP, false}
+	key, a, err := storeNewKey(ks, crand.Reader, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version := keyFileVersion(readKeyFields(t, a.URL.Path)); version != 3 {
+		t.Errorf("new key file version mismatch: have %d, want 3", version)
+	}
+	before, _ := ioutil.ReadFile(a.URL.Path)
+	if _, err := ks.GetKey(key.Address, a.URL.Path, "pass"); err != nil {
+		t.Fatalf("failed to decrypt key: %v", err)
+	}
+	if after, _ := ioutil.ReadFile(a.URL.Path); string(after) != string(before) {
+		t.Errorf("key file rewritten on decryption")
+	}
+	// Once upgraded, a key file stays at v4 when re-encrypted
+	if _, err := UpgradeKeyDir(dir); err != nil {
+		t.Fatalf("failed to upgrade keystore: %v", err)
+	}
+	meta := readKeyFields(t, a.URL.Path)["meta"]
+	if err := ks.StoreKey(a.URL.Path, key, "new"); err != nil {
+		t.Fatalf("failed to re-encrypt key: %v", err)
+	}
+	fields := readKeyFields(t, a.URL.Path)
+	if version := keyFileVersion(fields); version != keySchemaVersion {
+		t.Errorf("re-encrypted key file version mismatch: have %d, want %d", version, keySchemaVersion)
+	}
+	if string(fields["meta"]) != string(meta) {
+		t.Errorf("metadata mismatch: have %s, want %s", fields["meta"], meta)
+	}
+	if _, err := ks.GetKey(key.Address, a.URL.Path, "new"); err != nil {
+		t.Fatalf("failed to decrypt re-encrypted key: %v", err)
+	}
+}
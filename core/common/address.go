@@ -1,15 +1,135 @@
 package common
 
 import (
+	"fmt"
 	"strings"
+	"sync"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
 )
 
 var (
 	FFFHeader = "FFF"
+	TFFHeader = "TFF"
 	ETHHeader = "0x"
 )
 
+var (
+	// ErrAddressNetwork is returned when an FFF address carries the prefix of a
+	// network other than the one the process is configured for.
+	ErrAddressNetwork = hexutil.NewErrorClass(hexutil.CodeNetwork, "address belongs to a different network")
+
+	// ErrAddressSyntax is returned when an FFF address doesn't decode to an
+	// address.
+	ErrAddressSyntax = hexutil.NewErrorClass(hexutil.CodeSyntax, "invalid FFF address")
+)
+
+// Descriptions of the address formats accepted by the parsers.
+const (
+	expectAddress    = "0x prefixed hex or FFF encoded address"
+	expectFFFAddress = "FFF encoded address of the configured network"
+)
+
+var (
+	prefixLock   sync.RWMutex
+	prefixes     = []string{FFFHeader, TFFHeader} // all known network prefixes
+	activePrefix = FFFHeader                      // prefix used for encoding
+	crossNetwork = false                          // whether foreign prefixes decode
+)
+
+// RegisterAddressPrefix adds prefix to the set of network identifiers that are
+// recognised when decoding FFF addresses.
+func RegisterAddressPrefix(prefix string) {
+	prefixLock.Lock()
+	defer prefixLock.Unlock()
+
+	for _, p := range prefixes {
+		if p == prefix {
+			return
+		}
+	}
+	prefixes = append(prefixes, prefix)
+	purgeAddressCache()
+}
+
+// SetAddressPrefix sets the network prefix used when encoding addresses and
+// expected when decoding them. Unknown prefixes are registered implicitly.
+func SetAddressPrefix(prefix string) {
+	if prefix == "" {
+		prefix = FFFHeader
+	}
+	RegisterAddressPrefix(prefix)
+
+	prefixLock.Lock()
+	activePrefix = prefix
+	prefixLock.Unlock()
+
+	purgeAddressCache()
+}
+
+// AddressPrefix returns the network prefix currently used for encoding.
+func AddressPrefix() string {
+	prefixLock.RLock()
+	defer prefixLock.RUnlock()
+	return activePrefix
+}
+
+// AddressPrefixes returns all network prefixes recognised when decoding.
+func AddressPrefixes() []string {
+	prefixLock.RLock()
+	defer prefixLock.RUnlock()
+	return append([]string(nil), prefixes...)
+}
+
+// SetCrossNetworkAddresses toggles whether addresses carrying the prefix of a
+// different network are accepted by the decoder.
+func SetCrossNetworkAddresses(allow bool) {
+	prefixLock.Lock()
+	crossNetwork = allow
+	prefixLock.Unlock()
+
+	purgeAddressCache()
+}
+
+// splitAddressPrefix separates a known network prefix from the base58 body of
+// an FFF address. An empty prefix is returned if none of the registered ones
+// match, prefixes being case sensitive like the base58 body.
+func splitAddressPrefix(s string) (prefix string, body string) {
+	prefixLock.RLock()
+	defer prefixLock.RUnlock()
+
+	for _, p := range prefixes {
+		if hasAddressPrefix(s, p) {
+			return p, s[len(p):]
+		}
+	}
+	return "", s
+}
+
+// hasAddressPrefix reports whether s starts with the network prefix.
+func hasAddressPrefix(s string, prefix string) bool {
+	return strings.HasPrefix(s, prefix)
+}
+
+// CheckAddressNetwork verifies that an FFF encoded address belongs to the
+// configured network. Plain hex addresses and unprefixed input always pass.
+func CheckAddressNetwork(s string) error {
+	prefix, _ := splitAddressPrefix(s)
+	if prefix == "" {
+		return nil
+	}
+	prefixLock.RLock()
+	defer prefixLock.RUnlock()
+
+	if crossNetwork || prefix == activePrefix {
+		return nil
+	}
+	return ErrAddressNetwork
+}
+
 func FFFAddressEncode(hex string) string {
+	recordAddressEncode()
+
 	hex = strings.ToLower(hex)
 	var relHex = ""
 	if strings.ToLower(hex[0:2]) == "0x" {
@@ -17,15 +137,61 @@ func FFFAddressEncode(hex string) string {
 	} else {
 		relHex = hex
 	}
-	return FFFHeader + Base58Encoding(relHex)
+	return AddressPrefix() + Base58Encoding(relHex)
 }
 
+// FFFAddressDecode converts an FFF address into its hex form. Inputs that are
+// not FFF addresses of the configured network are returned untouched.
 func FFFAddressDecode(hex string) string {
-	var relHex = ""
-	if strings.ToLower(hex[0:3]) != "fff" {
-		relHex = hex
-	} else {
-		relHex = hex[3:]
+	recordAddressDecode(hex)
+	return decodeFFFAddress(hex)
+}
+
+// FFFAddressDecodeStrict is FFFAddressDecode rejecting the inputs it would
+// pass through. Addresses of another network fail with ErrAddressNetwork and
+// corrupt ones with ErrAddressSyntax, while unprefixed input is decoded as a
+// bare body.
+func FFFAddressDecodeStrict(hex string) (string, error) {
+	recordAddressDecode(hex)
+
+	if err := CheckAddressNetwork(hex); err != nil {
+		return "", hexutil.AnnotateError(err, hex, expectFFFAddress)
+	}
+	if prefix, body := splitAddressPrefix(hex); prefix != "" && !isBase58(body) {
+		return "", hexutil.AnnotateError(fmt.Errorf("%w %q", ErrAddressSyntax, hex), hex, expectFFFAddress)
+	}
+	return decodeFFFAddress(hex), nil
+}
+
+// decodeFFFAddress is FFFAddressDecode without the telemetry, used internally
+// where the decoding is accounted for by the caller.
+func decodeFFFAddress(hex string) string {
+	if CheckAddressNetwork(hex) != nil {
+		// Leave foreign addresses untouched so hex validation rejects them
+		return hex
+	}
+	c := currentAddressCache()
+	if c != nil {
+		if dec, ok := cachedAddress(c.decode, hex); ok {
+			return dec
+		}
+	}
+	prefix, relHex := splitAddressPrefix(hex)
+	if prefix != "" && !isBase58(relHex) {
+		// Leave corrupt bodies untouched so hex validation rejects them
+		return hex
+	}
+	payload := Base58Decoding(relHex)
+	if len(payload) == typedHexLength {
+		// Typed addresses carry the account kind in front, drop it if valid and
+		// leave the input untouched otherwise so hex validation rejects it
+		if typ := FromHex(payload[:2]); len(typ) != 1 || !AddressType(typ[0]).Valid() {
+			return hex
+		}
+		payload = payload[2:]
+	}
+	if c != nil {
+		c.decode.Add(hex, ETHHeader+payload)
 	}
-	return ETHHeader + Base58Decoding(relHex)
+	return ETHHeader + payload
 }
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"sync"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/liuguodong24-8/3fcoin/core/metrics"
+)
+
+// addressCache is the optional cache of FFF address conversions. It is nil,
+// and conversions are done from scratch every time, unless enabled.
+var (
+	addressCacheLock sync.RWMutex
+	addressCache     *addressCaches
+)
+
+// Metrics of the address conversion cache, summed over both directions.
+var (
+	addressCacheHitCounter  = metrics.NewRegisteredCounter("common/addresscache/hits", nil)
+	addressCacheMissCounter = metrics.NewRegisteredCounter("common/addresscache/misses", nil)
+)
+
+func init() {
+	metrics.NewRegisteredFunctionalGauge("common/addresscache/entries", nil, func() int64 {
+		if c := currentAddressCache(); c != nil {
+			return int64(c.encode.Len() + c.decode.Len())
+		}
+		return 0
+	})
+	metrics.NewRegisteredFunctionalGaugeFloat64("common/addresscache/hitrate", nil, func() float64 {
+		hits, misses := addressCacheHitCounter.Count(), addressCacheMissCounter.Count()
+		if hits+misses == 0 {
+			return 0
+		}
+		return float64(hits) / float64(hits+misses)
+	})
+}
+
+// addressCaches holds the caches of both conversion directions.
+type addressCaches struct {
+	encode *lru.Cache // raw address bytes -> FFF form
+	decode *lru.Cache // FFF form -> hex form
+}
+
+// EnableAddressCache turns on caching of the most recent FFF<->hex address
+// conversions, keeping at most size entries per direction. Hot addresses are
+// converted over and over when serializing RPC responses, which the cache
+// saves the hashing and base58 work of. A size of zero disables the cache.
+func EnableAddressCache(size int) {
+	addressCacheLock.Lock()
+	defer addressCacheLock.Unlock()
+
+	if size <= 0 {
+		addressCache = nil
+		return
+	}
+	encode, _ := lru.New(size)
+	decode, _ := lru.New(size)
+	addressCache = &addressCaches{encode: encode, decode: decode}
+}
+
+// purgeAddressCache drops all cached conversions, needed whenever the network
+// prefix configuration changes.
+func purgeAddressCache() {
+	if c := currentAddressCache(); c != nil {
+		c.encode.Purge()
+		c.decode.Purge()
+	}
+}
+
+func currentAddressCache() *addressCaches {
+	addressCacheLock.RLock()
+	defer addressCacheLock.RUnlock()
+	return addressCache
+}
+
+// cachedAddress retrieves a cached conversion, counting the hit or miss.
+func cachedAddress(cache *lru.Cache, key string) (string, bool) {
+	if value, ok := cache.Get(key); ok {
+		addressCacheHitCounter.Inc(1)
+		return value.(string), true
+	}
+	addressCacheMissCounter.Inc(1)
+	return "", false
+}
@@ -0,0 +1,200 @@
+package common
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+)
+
+// AddressFormat selects how addresses are rendered in API responses, allowing
+// consumers to negotiate the representation per request.
+type AddressFormat byte
+
+const (
+	AddressFormatFFF AddressFormat = iota // FFF encoding, the default
+	AddressFormatHex                      // EIP-55 checksummed hex, as expected by Ethereum tooling
+)
+
+// AddressFormatField is the name of the request option carrying the address
+// format consumers ask for.
+const AddressFormatField = "addressFormat"
+
+// ErrAddressFormat is returned when parsing the name of an unknown address
+// format.
+var ErrAddressFormat = hexutil.NewErrorClass(hexutil.CodeFormat, "unknown address format")
+
+var addressFormatNames = map[AddressFormat]string{
+	AddressFormatFFF: "fff",
+	AddressFormatHex: "hex",
+}
+
+// ParseAddressFormat parses the name of an address format, case insensitively.
+// The empty string selects the default FFF format.
+func ParseAddressFormat(s string) (AddressFormat, error) {
+	if s == "" {
+		return AddressFormatFFF, nil
+	}
+	for format, name := range addressFormatNames {
+		if strings.EqualFold(s, name) {
+			return format, nil
+		}
+	}
+	return AddressFormatFFF, hexutil.AnnotateError(fmt.Errorf("%w %q", ErrAddressFormat, s), s, "fff or hex")
+}
+
+// String implements fmt.Stringer.
+func (f AddressFormat) String() string {
+	if name, ok := addressFormatNames[f]; ok {
+		return name
+	}
+	return fmt.Sprintf("AddressFormat(%d)", byte(f))
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (f AddressFormat) MarshalText() ([]byte, error) {
+	if _, ok := addressFormatNames[f]; !ok {
+		return nil, fmt.Errorf("unknown address format %d", byte(f))
+	}
+	return []byte(f.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *AddressFormat) UnmarshalText(input []byte) error {
+	format, err := ParseAddressFormat(string(input))
+	if err != nil {
+		return err
+	}
+	*f = format
+	return nil
+}
+
+// Address renders an address in the format.
+func (f AddressFormat) Address(a Address) string {
+	if f == AddressFormatHex {
+		return string(a.checksumHex())
+	}
+	return a.String()
+}
+
+// addressFormatKey is the context key of the negotiated address format.
+type addressFormatKey struct{}
+
+// WithAddressFormat returns a copy of ctx carrying the address format the
+// response to the request should be rendered with.
+func WithAddressFormat(ctx context.Context, format AddressFormat) context.Context {
+	return context.WithValue(ctx, addressFormatKey{}, format)
+}
+
+// AddressFormatFromContext returns the address format negotiated for the
+// request, or the default FFF format if none was.
+func AddressFormatFromContext(ctx context.Context) AddressFormat {
+	if format, ok := ctx.Value(addressFormatKey{}).(AddressFormat); ok {
+		return format
+	}
+	return AddressFormatFFF
+}
+
+// MarshalJSONContext marshals v to JSON with the addresses rendered in the
+// format negotiated for the request.
+func MarshalJSONContext(ctx context.Context, v interface{}) ([]byte, error) {
+	blob, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return ConvertAddressFormat(blob, AddressFormatFromContext(ctx))
+}
+
+// ConvertAddressFormat rewrites the FFF addresses within an encoded JSON value,
+// both values and object keys, into the given format. Since addresses marshal
+// in FFF form, responses can be produced by a single handler stack and only be
+// converted at the edge. The layout of the document is not preserved.
+func ConvertAddressFormat(data []byte, format AddressFormat) ([]byte, error) {
+	if format == AddressFormatFFF {
+		return data, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	out := new(bytes.Buffer)
+	if err := convertJSONValue(dec, out, format); err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: trailing data")
+	}
+	return out.Bytes(), nil
+}
+
+// convertJSONValue copies the next JSON value from dec to out, converting the
+// addresses it contains.
+func convertJSONValue(dec *json.Decoder, out *bytes.Buffer, format AddressFormat) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	switch tok := tok.(type) {
+	case json.Delim:
+		closing := byte('}')
+		if tok == '[' {
+			closing = ']'
+		}
+		out.WriteByte(byte(tok))
+		for i := 0; dec.More(); i++ {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if closing == '}' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				writeJSONString(out, convertAddressString(key.(string), format))
+				out.WriteByte(':')
+			}
+			if err := convertJSONValue(dec, out, format); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		out.WriteByte(closing)
+
+	case string:
+		writeJSONString(out, convertAddressString(tok, format))
+
+	case json.Number:
+		out.WriteString(tok.String())
+
+	case bool:
+		fmt.Fprintf(out, "%t", tok)
+
+	case nil:
+		out.WriteString("null")
+	}
+	return nil
+}
+
+// convertAddressString converts s into the format if it is an FFF address of
+// the configured network, returning it unchanged otherwise.
+func convertAddressString(s string, format AddressFormat) string {
+	prefix, _ := splitAddressPrefix(s)
+	if prefix == "" || len(s) < len(prefix)+2*AddressLength {
+		return s
+	}
+	addr, _, err := DecodeTyped(s)
+	if err != nil {
+		return s
+	}
+	return format.Address(addr)
+}
+
+func writeJSONString(out *bytes.Buffer, s string) {
+	blob, _ := json.Marshal(s)
+	out.Write(blob)
+}
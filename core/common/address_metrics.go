@@ -0,0 +1,94 @@
+package common
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/metrics"
+)
+
+// Telemetry of the address codecs, so operators can spot downstream services
+// sending malformed addresses.
+//
+// The decode counters account for direct FFFAddressDecode(Strict) calls, the
+// json ones for addresses unmarshalled from JSON or text. Checksum failures are
+// mixed case hex addresses not matching their EIP-55 checksum, which are
+// accepted but most likely mistyped. Prefix failures are addresses of another
+// network, unprefixed ones carry neither a network prefix nor 0x.
+var (
+	addressEncodeCounter = metrics.NewRegisteredCounter("common/address/encode", nil)
+
+	addressDecodeCounters = newAddressCounters("common/address/decode")
+	addressJSONCounters   = newAddressCounters("common/address/json")
+)
+
+// addressCounters counts the outcomes of decoding addresses.
+type addressCounters struct {
+	success    metrics.Counter
+	checksum   metrics.Counter
+	prefix     metrics.Counter
+	unprefixed metrics.Counter
+	invalid    metrics.Counter
+}
+
+func newAddressCounters(name string) *addressCounters {
+	return &addressCounters{
+		success:    metrics.NewRegisteredCounter(name+"/success", nil),
+		checksum:   metrics.NewRegisteredCounter(name+"/checksum", nil),
+		prefix:     metrics.NewRegisteredCounter(name+"/prefix", nil),
+		unprefixed: metrics.NewRegisteredCounter(name+"/unprefixed", nil),
+		invalid:    metrics.NewRegisteredCounter(name+"/invalid", nil),
+	}
+}
+
+// recordAddressEncode counts an FFFAddressEncode call.
+func recordAddressEncode() {
+	addressEncodeCounter.Inc(1)
+}
+
+// recordAddressDecode classifies an input of FFFAddressDecode(Strict).
+func recordAddressDecode(s string) {
+	prefix, body := splitAddressPrefix(s)
+	switch {
+	case prefix == "":
+		addressDecodeCounters.unprefixed.Inc(1)
+	case CheckAddressNetwork(s) != nil:
+		addressDecodeCounters.prefix.Inc(1)
+	case !validFFFBody(body):
+		addressDecodeCounters.invalid.Inc(1)
+	default:
+		addressDecodeCounters.success.Inc(1)
+	}
+}
+
+// recordAddressUnmarshal classifies an address unmarshalled from JSON or text
+// along with the resulting error.
+func recordAddressUnmarshal(s string, addr *Address, err error) {
+	switch {
+	case errors.Is(err, ErrAddressNetwork):
+		addressJSONCounters.prefix.Inc(1)
+	case err != nil:
+		if prefix, _ := splitAddressPrefix(s); prefix == "" && !has0xPrefix(s) {
+			addressJSONCounters.unprefixed.Inc(1)
+		} else {
+			addressJSONCounters.invalid.Inc(1)
+		}
+	case has0xPrefix(s) && strings.ToLower(s) != s && strings.ToUpper(s[2:]) != s[2:] && s != string(addr.checksumHex()):
+		addressJSONCounters.checksum.Inc(1)
+	default:
+		addressJSONCounters.success.Inc(1)
+	}
+}
+
+// validFFFBody reports whether the base58 body of an FFF address decodes into
+// a plain or typed address payload.
+func validFFFBody(body string) bool {
+	if !isBase58(body) {
+		return false
+	}
+	payload := Base58Decoding(body)
+	if len(payload) == typedHexLength {
+		payload = payload[2:]
+	}
+	return len(payload) == 2*AddressLength && isHex(payload)
+}
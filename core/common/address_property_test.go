@@ -0,0 +1,49 @@
+package common_test
+
+import (
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/ffftest"
+)
+
+func TestAddressRoundTripProperty(t *testing.T) {
+	roundtrip := func(seed int64) bool {
+		addr := ffftest.Address(rand.New(rand.NewSource(seed)))
+		if err := ffftest.CheckRoundTrip(addr); err != nil {
+			t.Log(err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(roundtrip, nil); err != nil {
+		t.Error(err)
+	}
+	valid := func(enc ffftest.ValidFFF) bool {
+		var addr common.Address
+		return addr.UnmarshalText([]byte(enc)) == nil && common.IsHexAddress(string(enc))
+	}
+	if err := quick.Check(valid, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddressCorruptInputs(t *testing.T) {
+	for _, c := range ffftest.Corpus() {
+		if err := ffftest.CheckRejected(c.Input); err != nil {
+			t.Errorf("%s: %v", c.Name, err)
+		}
+	}
+	corrupt := func(enc ffftest.CorruptFFF) bool {
+		if err := ffftest.CheckRejected(string(enc)); err != nil {
+			t.Log(err)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(corrupt, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
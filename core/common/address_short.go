@@ -0,0 +1,81 @@
+package common
+
+import "sync"
+
+const (
+	shortAffixLength = 6     // Leading and trailing body characters kept by default
+	shortEllipsis    = "..." // Marker of the characters left out
+)
+
+// shortAddressLimit is the maximum number of addresses the short form registry
+// holds, bounding its memory. Addresses beyond it aren't abbreviated.
+var shortAddressLimit = 1 << 16
+
+var (
+	shortLock   sync.RWMutex
+	shortForms  = make(map[Address]string) // Abbreviated bodies of the registered addresses
+	shortLookup = make(map[string]Address) // Registered addresses by abbreviated body
+)
+
+// ShortAddress returns an abbreviated FFF form of an address, keeping the first
+// and last characters of its body, e.g. FFFabcdef...uvwxyz. The first address
+// abbreviated to a given form keeps it for the lifetime of the process, while
+// colliding ones keep more characters until unambiguous, so a short form always
+// expands back to the address it was handed out for. Once the registry is full,
+// addresses not yet in it are returned in full.
+func ShortAddress(a Address) string {
+	shortLock.RLock()
+	short, ok := shortForms[a]
+	shortLock.RUnlock()
+
+	if ok {
+		return AddressPrefix() + short
+	}
+	body := displayBody(a)
+
+	shortLock.Lock()
+	defer shortLock.Unlock()
+
+	if short, ok := shortForms[a]; ok {
+		return AddressPrefix() + short
+	}
+	if len(shortForms) >= shortAddressLimit {
+		return AddressPrefix() + body
+	}
+	for n := shortAffixLength; ; n++ {
+		short := abbreviateBody(body, n)
+		if _, taken := shortLookup[short]; taken && short != body {
+			continue
+		}
+		shortForms[a] = short
+		shortLookup[short] = a
+		return AddressPrefix() + short
+	}
+}
+
+// Short returns the abbreviated FFF form of the address, see ShortAddress.
+func (a Address) Short() string {
+	return ShortAddress(a)
+}
+
+// ExpandShortAddress returns the address a short form was handed out for by
+// ShortAddress. The network prefix is optional.
+func ExpandShortAddress(short string) (Address, bool) {
+	if prefix, body := splitAddressPrefix(short); prefix != "" {
+		short = body
+	}
+	shortLock.RLock()
+	defer shortLock.RUnlock()
+
+	a, ok := shortLookup[short]
+	return a, ok
+}
+
+// abbreviateBody keeps the first and last n characters of an address body,
+// returning it as is if there is nothing to gain.
+func abbreviateBody(body string, n int) string {
+	if 2*n+len(shortEllipsis) >= len(body) {
+		return body
+	}
+	return body[:n] + shortEllipsis + body[len(body)-n:]
+}
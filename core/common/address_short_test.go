@@ -0,0 +1,90 @@
+package common
+
+import (
+	"strings"
+	"testing"
+)
+
+// resetShortAddresses clears the short form registry.
+func resetShortAddresses() {
+	shortLock.Lock()
+	defer shortLock.Unlock()
+
+	shortForms = make(map[Address]string)
+	shortLookup = make(map[string]Address)
+}
+
+// Tests that short forms keep the ends of the address body, are stable and
+// expand back to the full address.
+func TestShortAddress(t *testing.T) {
+	resetShortAddresses()
+	defer resetShortAddresses()
+
+	addr := BytesToAddress(FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	body := displayBody(addr)
+
+	short := ShortAddress(addr)
+	if want := AddressPrefix() + body[:shortAffixLength] + shortEllipsis + body[len(body)-shortAffixLength:]; short != want {
+		t.Fatalf("short form mismatch: have %s, want %s", short, want)
+	}
+	if again := addr.Short(); again != short {
+		t.Errorf("short form not stable: have %s, want %s", again, short)
+	}
+	for _, input := range []string{short, strings.TrimPrefix(short, AddressPrefix())} {
+		if expanded, ok := ExpandShortAddress(input); !ok || expanded != addr {
+			t.Errorf("%s: expansion mismatch: have %x (%v), want %x", input, expanded, ok, addr)
+		}
+	}
+	if _, ok := ExpandShortAddress(AddressPrefix() + "abcdef...uvwxyz"); ok {
+		t.Errorf("unknown short form expanded")
+	}
+	if _, ok := ExpandShortAddress(strings.ToLower(AddressPrefix()) + strings.TrimPrefix(short, AddressPrefix())); ok {
+		t.Errorf("short form with lowercase prefix expanded")
+	}
+}
+
+// Tests that colliding addresses keep more characters, while the address that
+// was abbreviated first keeps its short form.
+func TestShortAddressCollision(t *testing.T) {
+	resetShortAddresses()
+	defer resetShortAddresses()
+
+	first := BytesToAddress(FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	second := BytesToAddress(FromHex("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"))
+
+	// Occupy the default short form of the second address with the first one
+	taken := abbreviateBody(displayBody(second), shortAffixLength)
+	shortLock.Lock()
+	shortForms[first], shortLookup[taken] = taken, first
+	shortLock.Unlock()
+
+	short := ShortAddress(second)
+	if want := AddressPrefix() + abbreviateBody(displayBody(second), shortAffixLength+1); short != want {
+		t.Fatalf("colliding short form mismatch: have %s, want %s", short, want)
+	}
+	if expanded, _ := ExpandShortAddress(short); expanded != second {
+		t.Errorf("colliding short form expanded to %x, want %x", expanded, second)
+	}
+	if expanded, _ := ExpandShortAddress(AddressPrefix() + taken); expanded != first {
+		t.Errorf("first short form expanded to %x, want %x", expanded, first)
+	}
+}
+
+// Tests that addresses beyond the registry limit are not abbreviated.
+func TestShortAddressLimit(t *testing.T) {
+	resetShortAddresses()
+	defer resetShortAddresses()
+
+	defer func(limit int) { shortAddressLimit = limit }(shortAddressLimit)
+	shortAddressLimit = 1
+
+	first := BytesToAddress(FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	second := BytesToAddress(FromHex("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"))
+
+	if short := ShortAddress(first); short == first.String() {
+		t.Errorf("first address not abbreviated")
+	}
+	if short := ShortAddress(second); short != second.String() {
+		t.Errorf("address beyond limit abbreviated: %s", short)
+	}
+}
@@ -0,0 +1,108 @@
+package common
+
+import (
+	"encoding/hex"
+	"strings"
+)
+
+// poisonAffixLength is the number of leading and trailing display characters
+// wallets commonly show of a shortened address. Distinct addresses sharing both
+// are the hallmark of an address-poisoning attempt.
+const poisonAffixLength = 4
+
+// AddressSimilarity describes how alike two addresses look to a user.
+type AddressSimilarity struct {
+	Identical bool // Whether both refer to the same address
+	Hex       bool // Whether the comparison was done on the hex form
+	Length    int  // Length of the compared display forms
+	Prefix    int  // Number of shared leading display characters
+	Suffix    int  // Number of shared trailing display characters
+	Distance  int  // Edit distance between the display forms
+}
+
+// CompareAddresses computes the visual similarity of two FFF or hex encoded
+// addresses. Two hex addresses are compared on their hex form, anything else
+// on the FFF form, which is what users of this chain are shown by default.
+func CompareAddresses(a, b string) (*AddressSimilarity, error) {
+	var addrA, addrB Address
+	if err := addrA.UnmarshalText([]byte(a)); err != nil {
+		return nil, err
+	}
+	if err := addrB.UnmarshalText([]byte(b)); err != nil {
+		return nil, err
+	}
+	sim := &AddressSimilarity{
+		Identical: addrA == addrB,
+		Hex:       has0xPrefix(a) && has0xPrefix(b),
+	}
+	var x, y string
+	if sim.Hex {
+		x, y = hex.EncodeToString(addrA[:]), hex.EncodeToString(addrB[:])
+	} else {
+		x, y = displayBody(addrA), displayBody(addrB)
+	}
+	sim.Length = len(x)
+	if len(y) > sim.Length {
+		sim.Length = len(y)
+	}
+	for sim.Prefix < len(x) && sim.Prefix < len(y) && x[sim.Prefix] == y[sim.Prefix] {
+		sim.Prefix++
+	}
+	for sim.Suffix < len(x) && sim.Suffix < len(y) && x[len(x)-1-sim.Suffix] == y[len(y)-1-sim.Suffix] {
+		sim.Suffix++
+	}
+	sim.Distance = editDistance(x, y)
+	return sim, nil
+}
+
+// Score returns the similarity as a value between 0 (nothing in common) and 1
+// (identical display forms).
+func (s *AddressSimilarity) Score() float64 {
+	if s.Length == 0 {
+		return 1
+	}
+	return 1 - float64(s.Distance)/float64(s.Length)
+}
+
+// Suspicious reports whether two distinct addresses look alike enough in their
+// shortened display form to be mistaken for each other.
+func (s *AddressSimilarity) Suspicious() bool {
+	return !s.Identical && s.Prefix >= poisonAffixLength && s.Suffix >= poisonAffixLength
+}
+
+// displayBody returns the FFF form of an address without the network prefix,
+// which is shared by all addresses and carries no information.
+func displayBody(a Address) string {
+	return strings.TrimPrefix(a.String(), AddressPrefix())
+}
+
+// editDistance computes the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
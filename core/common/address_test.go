@@ -0,0 +1,285 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/metrics"
+)
+
+func TestAddressNetworkPrefix(t *testing.T) {
+	defer SetAddressPrefix(FFFHeader)
+	defer SetCrossNetworkAddresses(false)
+
+	var (
+		hex     = "0x0d023dfc9c025e263d974985f3367d99f91e071b"
+		mainnet = FFFAddressEncode(hex)
+	)
+	if !strings.HasPrefix(mainnet, FFFHeader) {
+		t.Fatalf("mainnet encoding %s lacks prefix %s", mainnet, FFFHeader)
+	}
+	SetAddressPrefix(TFFHeader)
+
+	testnet := FFFAddressEncode(hex)
+	if testnet != TFFHeader+mainnet[len(FFFHeader):] {
+		t.Fatalf("testnet encoding mismatch: have %s", testnet)
+	}
+	if have := FFFAddressDecode(testnet); have != hex {
+		t.Errorf("testnet decode mismatch: have %s, want %s", have, hex)
+	}
+	if have := FFFAddressDecode(mainnet); have != mainnet {
+		t.Errorf("mainnet decode on testnet not passed through: have %s", have)
+	}
+	if have, err := FFFAddressDecodeStrict(testnet); err != nil || have != hex {
+		t.Errorf("testnet strict decode mismatch: have %s (%v), want %s", have, err, hex)
+	}
+	if have, err := FFFAddressDecodeStrict(mainnet); !errors.Is(err, ErrAddressNetwork) {
+		t.Errorf("mainnet decode on testnet: have %s (%v), want %v", have, err, ErrAddressNetwork)
+	}
+	if _, err := FFFAddressDecodeStrict(TFFHeader + "0OIl"); !errors.Is(err, ErrAddressSyntax) {
+		t.Errorf("corrupt decode: have %v, want %v", err, ErrAddressSyntax)
+	}
+	if err := CheckAddressNetwork(mainnet); err != ErrAddressNetwork {
+		t.Errorf("mainnet address on testnet: have %v, want %v", err, ErrAddressNetwork)
+	}
+	var addr Address
+	if err := addr.UnmarshalText([]byte(mainnet)); !errors.Is(err, ErrAddressNetwork) {
+		t.Errorf("unmarshal of mainnet address on testnet: have %v, want %v", err, ErrAddressNetwork)
+	}
+	// Prefixes are case sensitive
+	lower := strings.ToLower(TFFHeader) + testnet[len(TFFHeader):]
+	if have := FFFAddressDecode(lower); have != lower {
+		t.Errorf("lowercase prefix decoded: have %s", have)
+	}
+	if err := addr.UnmarshalText([]byte(lower)); err == nil {
+		t.Errorf("unmarshal of lowercase prefixed %s succeeded", lower)
+	}
+	SetCrossNetworkAddresses(true)
+	if err := addr.UnmarshalText([]byte(mainnet)); err != nil {
+		t.Fatalf("cross-network unmarshal failed: %v", err)
+	}
+	if want := BytesToAddress(FromHex(hex)); addr != want {
+		t.Errorf("cross-network unmarshal mismatch: have %v, want %v", addr, want)
+	}
+}
+
+func TestTypedAddress(t *testing.T) {
+	addr := BytesToAddress(FromHex("0x0d023dfc9c025e263d974985f3367d99f91e071b"))
+
+	for _, typ := range []AddressType{AddressTypeEOA, AddressTypeContract, AddressTypeValidator, AddressTypeSystem} {
+		enc, err := EncodeTyped(addr, typ)
+		if err != nil {
+			t.Fatalf("%v: failed to encode: %v", typ, err)
+		}
+		have, haveType, err := DecodeTyped(enc)
+		if err != nil {
+			t.Fatalf("%v: failed to decode %s: %v", typ, enc, err)
+		}
+		if have != addr || haveType != typ {
+			t.Errorf("%v: decode mismatch: have %v/%v, want %v/%v", typ, have, haveType, addr, typ)
+		}
+		var legacy Address
+		if err := legacy.UnmarshalText([]byte(enc)); err != nil || legacy != addr {
+			t.Errorf("%v: legacy unmarshal mismatch: have %v (%v), want %v", typ, legacy, err, addr)
+		}
+	}
+	have, haveType, err := DecodeTyped(addr.String())
+	if err != nil || have != addr || haveType != AddressTypeUnknown {
+		t.Errorf("untyped decode mismatch: have %v/%v (%v), want %v/%v", have, haveType, err, addr, AddressTypeUnknown)
+	}
+	for _, typ := range []AddressType{AddressTypeUnknown, AddressType(0x42)} {
+		if _, err := EncodeTyped(addr, typ); !errors.Is(err, ErrAddressType) {
+			t.Errorf("%v: encoding error mismatch: have %v, want %v", typ, err, ErrAddressType)
+		}
+	}
+	// Payloads of unknown types must not decode through any entry point
+	forged := AddressPrefix() + Base58Encoding("42"+Bytes2Hex(addr[:]))
+	if _, _, err := DecodeTyped(forged); !errors.Is(err, ErrAddressType) {
+		t.Errorf("unknown type error mismatch: have %v, want %v", err, ErrAddressType)
+	}
+	if have := FFFAddressDecode(forged); have != forged {
+		t.Errorf("unknown type decoded: have %s", have)
+	}
+	var legacy Address
+	if err := legacy.UnmarshalText([]byte(forged)); err == nil {
+		t.Errorf("unknown type unmarshalled to %v", legacy)
+	}
+}
+
+func TestCompareAddresses(t *testing.T) {
+	var (
+		real   = "0x0d023dfc9c025e263d974985f3367d99f91e071b"
+		poison = "0x0d02ffffffffffffffffffffffffffffffff071b"
+		other  = "0x9a1c3dfc9c025e263d974985f3367d99f91e0abc"
+	)
+	sim, err := CompareAddresses(real, poison)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sim.Hex || sim.Prefix != 4 || sim.Suffix != 4 || !sim.Suspicious() {
+		t.Errorf("poisoned hex address not flagged: %+v", sim)
+	}
+	if sim, _ = CompareAddresses(real, other); sim.Suspicious() {
+		t.Errorf("unrelated hex address flagged: %+v", sim)
+	}
+	fff := BytesToAddress(FromHex(real)).String()
+	if sim, err = CompareAddresses(fff, real); err != nil {
+		t.Fatal(err)
+	}
+	if !sim.Identical || sim.Hex || sim.Distance != 0 || sim.Score() != 1 || sim.Suspicious() {
+		t.Errorf("identical address mismatch: %+v", sim)
+	}
+	if _, err := CompareAddresses(real, "0x1234"); err == nil {
+		t.Error("invalid address accepted")
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"kitten", "sitting", 3},
+		{"FFFabc", "FFFacb", 2},
+	}
+	for _, tt := range tests {
+		if have := editDistance(tt.a, tt.b); have != tt.want {
+			t.Errorf("%q/%q: have %d, want %d", tt.a, tt.b, have, tt.want)
+		}
+	}
+}
+
+func TestAddressCache(t *testing.T) {
+	EnableAddressCache(2)
+	defer EnableAddressCache(0)
+	defer SetAddressPrefix(FFFHeader)
+
+	var (
+		a = BytesToAddress(FromHex("0x0d023dfc9c025e263d974985f3367d99f91e071b"))
+		b = BytesToAddress(FromHex("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23"))
+		c = BytesToAddress(FromHex("0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"))
+	)
+	encA := a.String()
+	if have := a.String(); have != encA {
+		t.Fatalf("cached encoding mismatch: have %s, want %s", have, encA)
+	}
+	for i := 0; i < 2; i++ {
+		if have := BytesToAddress(FromHex(FFFAddressDecode(encA))); have != a {
+			t.Fatalf("decoding %d mismatch: have %v, want %v", i, have, a)
+		}
+	}
+	cache := currentAddressCache()
+	if !cache.encode.Contains(string(a[:])) || !cache.decode.Contains(encA) {
+		t.Fatalf("conversions of %v not cached", a)
+	}
+	// Overflow the encoding cache and check that a got evicted
+	for _, addr := range []Address{b, c} {
+		_ = addr.String()
+	}
+	if cache.encode.Contains(string(a[:])) || cache.encode.Len() != 2 {
+		t.Fatalf("encoding cache not bounded: %d entries", cache.encode.Len())
+	}
+	// Switching networks must not serve stale encodings
+	SetAddressPrefix(TFFHeader)
+	if cache.encode.Len() != 0 || cache.decode.Len() != 0 {
+		t.Errorf("cache not purged on prefix switch")
+	}
+	if have := b.String(); !strings.HasPrefix(have, TFFHeader) {
+		t.Errorf("stale encoding after prefix switch: %s", have)
+	}
+}
+
+func TestConvertAddressFormat(t *testing.T) {
+	addr := BytesToAddress(FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+
+	blob, err := json.Marshal(map[string]interface{}{
+		"from":  addr,
+		"to":    []Address{addr},
+		"owner": map[Address]uint64{addr: 1},
+		"name":  "FFF not an address",
+		"value": 1.5,
+		"ok":    true,
+		"data":  nil,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if same, _ := ConvertAddressFormat(blob, AddressFormatFFF); string(same) != string(blob) {
+		t.Errorf("FFF conversion modified document: %s", same)
+	}
+	ctx := WithAddressFormat(context.Background(), AddressFormatHex)
+	if format := AddressFormatFromContext(ctx); format != AddressFormatHex {
+		t.Fatalf("context format mismatch: %v", format)
+	}
+	conv, err := ConvertAddressFormat(blob, AddressFormatFromContext(ctx))
+	if err != nil {
+		t.Fatalf("failed to convert: %v", err)
+	}
+	hex := "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	want := `{"data":null,"from":"` + hex + `","name":"FFF not an address","ok":true,"owner":{"` + hex + `":1},"to":["` + hex + `"],"value":1.5}`
+	if string(conv) != want {
+		t.Errorf("converted document mismatch:\nhave %s\nwant %s", conv, want)
+	}
+	// Converted documents must still decode into addresses
+	var decoded struct{ From Address }
+	if err := json.Unmarshal(conv, &decoded); err != nil || decoded.From != addr {
+		t.Errorf("converted address decoding mismatch: %v (%v)", decoded.From, err)
+	}
+	var format AddressFormat
+	if err := format.UnmarshalText([]byte("HEX")); err != nil || format != AddressFormatHex {
+		t.Errorf("format parsing mismatch: %v (%v)", format, err)
+	}
+	if err := format.UnmarshalText([]byte("base64")); err == nil {
+		t.Errorf("unknown format accepted")
+	}
+}
+
+func TestAddressTelemetry(t *testing.T) {
+	// The package counters were created with metrics disabled, use live ones
+	metrics.Enabled = true
+	defer func() { metrics.Enabled = false }()
+
+	defer func(decode, unmarshal *addressCounters) {
+		addressDecodeCounters, addressJSONCounters = decode, unmarshal
+	}(addressDecodeCounters, addressJSONCounters)
+
+	newCounters := func() *addressCounters {
+		return &addressCounters{metrics.NewCounter(), metrics.NewCounter(), metrics.NewCounter(), metrics.NewCounter(), metrics.NewCounter()}
+	}
+	addressDecodeCounters, addressJSONCounters = newCounters(), newCounters()
+
+	addr := BytesToAddress(FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	enc := addr.String()
+
+	counts := func(c *addressCounters) [5]int64 {
+		return [5]int64{c.success.Count(), c.checksum.Count(), c.prefix.Count(), c.unprefixed.Count(), c.invalid.Count()}
+	}
+	FFFAddressDecode(enc)
+	FFFAddressDecode(TFFHeader + enc[len(FFFHeader):])
+	FFFAddressDecode(FFFHeader + "0OIl")
+	FFFAddressDecode("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")
+
+	var a Address
+	for _, input := range []string{
+		`"` + enc + `"`,
+		`"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"`,
+		`"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"`,
+		`"0x5AAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"`,
+		`"` + TFFHeader + enc[len(FFFHeader):] + `"`,
+		`"0x5aaeb6"`,
+		`"5aaeb6"`,
+	} {
+		a.UnmarshalJSON([]byte(input))
+	}
+	if have, want := counts(addressDecodeCounters), [5]int64{1, 0, 1, 1, 1}; have != want {
+		t.Errorf("decode counters mismatch: have %v, want %v", have, want)
+	}
+	if have, want := counts(addressJSONCounters), [5]int64{3, 1, 1, 1, 1}; have != want {
+		t.Errorf("json counters mismatch: have %v, want %v", have, want)
+	}
+}
@@ -0,0 +1,140 @@
+package common
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+)
+
+// AddressType classifies the kind of account an FFF address refers to. It is
+// carried as a single byte in front of the address in the typed encoding.
+type AddressType byte
+
+const (
+	AddressTypeUnknown   AddressType = iota // Legacy untyped address
+	AddressTypeEOA                          // Externally owned account
+	AddressTypeContract                     // Contract account
+	AddressTypeValidator                    // Consensus validator
+	AddressTypeSystem                       // System contract
+)
+
+// ErrAddressType is returned when decoding a typed address with an unknown type.
+var ErrAddressType = hexutil.NewErrorClass(hexutil.CodeAddressType, "invalid address type")
+
+// errHexAddress is returned by DecodeAddressWith for malformed 0x prefixed
+// addresses.
+var errHexAddress = hexutil.NewErrorClass(hexutil.CodeSyntax, "invalid hex address")
+
+var addressTypeNames = map[AddressType]string{
+	AddressTypeUnknown:   "unknown",
+	AddressTypeEOA:       "eoa",
+	AddressTypeContract:  "contract",
+	AddressTypeValidator: "validator",
+	AddressTypeSystem:    "system",
+}
+
+// String implements fmt.Stringer.
+func (t AddressType) String() string {
+	if name, ok := addressTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("AddressType(%d)", byte(t))
+}
+
+// Valid reports whether t can tag a typed address. AddressTypeUnknown is only
+// used for legacy addresses and never encoded.
+func (t AddressType) Valid() bool {
+	return t > AddressTypeUnknown && t <= AddressTypeSystem
+}
+
+// typedHexLength is the length of the hex payload of a typed FFF address, the
+// type byte followed by the address.
+const typedHexLength = 2 * (1 + AddressLength)
+
+// EncodeTyped returns the FFF encoding of addr tagged with the given type. Types
+// that don't decode are rejected with ErrAddressType.
+func EncodeTyped(addr Address, typ AddressType) (string, error) {
+	if !typ.Valid() {
+		return "", fmt.Errorf("%w %v", ErrAddressType, typ)
+	}
+	payload := make([]byte, 1+AddressLength)
+	payload[0] = byte(typ)
+	copy(payload[1:], addr[:])
+
+	return AddressPrefix() + Base58Encoding(hex.EncodeToString(payload)), nil
+}
+
+// DecodeTyped parses a typed or legacy FFF address, returning the address and
+// its type. Legacy addresses decode with AddressTypeUnknown.
+func DecodeTyped(s string) (Address, AddressType, error) {
+	if err := CheckAddressNetwork(s); err != nil {
+		return Address{}, AddressTypeUnknown, hexutil.AnnotateError(err, s, expectFFFAddress)
+	}
+	_, body := splitAddressPrefix(s)
+	addr, typ, err := decodeAddressBody(body, Base58Alphabet)
+	if err != nil {
+		return Address{}, AddressTypeUnknown, annotateAddressError(err, s, expectFFFAddress)
+	}
+	return addr, typ, nil
+}
+
+// DecodeAddressWith parses an FFF address of the network with the given prefix,
+// its body encoded with the given base58 alphabet, or a 0x prefixed hex address.
+// It doesn't depend on the configured network: addresses carrying any other
+// registered prefix are rejected with ErrAddressNetwork.
+func DecodeAddressWith(s string, prefix string, alphabet string) (Address, AddressType, error) {
+	expect := fmt.Sprintf("0x prefixed hex or %s prefixed FFF address", prefix)
+
+	if !hasAddressPrefix(s, prefix) {
+		if has0xPrefix(s) {
+			if len(s) == 2+2*AddressLength && isHex(s[2:]) {
+				return BytesToAddress(FromHex(s)), AddressTypeUnknown, nil
+			}
+			return Address{}, AddressTypeUnknown, annotateAddressError(errHexAddress, s, expect)
+		}
+		if p, _ := splitAddressPrefix(s); p != "" {
+			return Address{}, AddressTypeUnknown, annotateAddressError(ErrAddressNetwork, s, expect)
+		}
+		return Address{}, AddressTypeUnknown, annotateAddressError(ErrAddressSyntax, s, expect)
+	}
+	addr, typ, err := decodeAddressBody(s[len(prefix):], alphabet)
+	if err != nil {
+		return Address{}, AddressTypeUnknown, annotateAddressError(err, s, expect)
+	}
+	return addr, typ, nil
+}
+
+// decodeAddressBody decodes the body of a typed or legacy FFF address encoded
+// with the given alphabet, failing with the bare ErrAddressSyntax or
+// ErrAddressType class.
+func decodeAddressBody(body string, alphabet string) (Address, AddressType, error) {
+	if !inAlphabet(body, alphabet) {
+		return Address{}, AddressTypeUnknown, ErrAddressSyntax
+	}
+	payload := decodeBase58(body, alphabet)
+
+	switch {
+	case len(payload) == 2*AddressLength && isHex(payload):
+		return BytesToAddress(FromHex(payload)), AddressTypeUnknown, nil
+
+	case len(payload) == typedHexLength && isHex(payload):
+		raw, _ := hex.DecodeString(payload)
+		typ := AddressType(raw[0])
+		if !typ.Valid() {
+			return Address{}, AddressTypeUnknown, ErrAddressType
+		}
+		return BytesToAddress(raw[1:]), typ, nil
+	}
+	return Address{}, AddressTypeUnknown, ErrAddressSyntax
+}
+
+// annotateAddressError attaches the offending input to an address decoding
+// error, quoting it in the message of the syntax errors.
+func annotateAddressError(class error, s string, expected string) error {
+	err := class
+	if class == ErrAddressSyntax || class == errHexAddress {
+		err = fmt.Errorf("%w %q", class, s)
+	}
+	return hexutil.AnnotateError(err, s, expected)
+}
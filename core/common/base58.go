@@ -1,33 +1,64 @@
 package common
 
 import (
-	"bytes"
 	"math/big"
+	"strings"
 )
 
+// Base58Alphabet is the Bitcoin base58 alphabet FFF address bodies are encoded
+// with.
+const Base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
 var (
-	base58 = []byte("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+	base58 = []byte(Base58Alphabet)
 )
 
 func Base58Encoding(str string) string {
-	strByte := []byte(str)
-	strTen := big.NewInt(0).SetBytes(strByte)
-	var modSlice []byte
-	for strTen.Cmp(big.NewInt(0)) > 0 {
-		mod := big.NewInt(0)
-		strTen58 := big.NewInt(58)
-		strTen.DivMod(strTen, strTen58, mod)
-		modSlice = append(modSlice, base58[mod.Int64()])
-	}
-	for _, elem := range strByte {
-		if elem != 0 {
-			break
-		} else if elem == 0 {
-			modSlice = append(modSlice, byte('1'))
+	return string(appendBase58(nil, []byte(str)))
+}
+
+// maxFFFLength is the length of the FFF form of an address with the longest
+// built-in network prefix, enough to render most addresses without growing.
+const maxFFFLength = 3 + 55
+
+// appendBase58 appends the base58 encoding of src to dst, producing the same
+// digits as the big integer arithmetic of Base58Encoding, but without any
+// allocation for inputs up to 64 bytes.
+func appendBase58(dst []byte, src []byte) []byte {
+	// Leading zero bytes are encoded as a '1' each
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+	for i := 0; i < zeros; i++ {
+		dst = append(dst, base58[0])
+	}
+	// Convert the remainder to base 58, log(256)/log(58) < 1.38 digits per byte
+	var (
+		scratch [89]byte
+		digits  []byte
+		size    = (len(src)-zeros)*138/100 + 1
+	)
+	if size <= len(scratch) {
+		digits = scratch[:size]
+	} else {
+		digits = make([]byte, size)
+	}
+	length := 0
+	for _, b := range src[zeros:] {
+		carry := int(b)
+		i := 0
+		for j := size - 1; (carry != 0 || i < length) && j >= 0; j, i = j-1, i+1 {
+			carry += 256 * int(digits[j])
+			digits[j] = byte(carry % 58)
+			carry /= 58
 		}
+		length = i
+	}
+	for _, digit := range digits[size-length:] {
+		dst = append(dst, base58[digit])
 	}
-	ReverseModSlice := ReverseByteArr(modSlice)
-	return string(ReverseModSlice)
+	return dst
 }
 
 func ReverseByteArr(bytes []byte) []byte {
@@ -37,11 +68,31 @@ func ReverseByteArr(bytes []byte) []byte {
 	return bytes
 }
 
+// isBase58 reports whether str consists of base58 digits only.
+func isBase58(str string) bool {
+	return inAlphabet(str, Base58Alphabet)
+}
+
+// inAlphabet reports whether str consists of digits of alphabet only.
+func inAlphabet(str string, alphabet string) bool {
+	for i := 0; i < len(str); i++ {
+		if strings.IndexByte(alphabet, str[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
 func Base58Decoding(str string) string {
+	return decodeBase58(str, Base58Alphabet)
+}
+
+// decodeBase58 is Base58Decoding using the digits of the given alphabet.
+func decodeBase58(str string, alphabet string) string {
 	strByte := []byte(str)
 	ret := big.NewInt(0)
 	for _, byteElem := range strByte {
-		index := bytes.IndexByte(base58, byteElem)
+		index := strings.IndexByte(alphabet, byteElem)
 		ret.Mul(ret, big.NewInt(58))
 		ret.Add(ret, big.NewInt(int64(index)))
 	}
@@ -1 +1,51 @@
 package common
+
+import (
+	"bytes"
+	"math/big"
+	"math/rand"
+	"testing"
+)
+
+// base58BigInt is the big integer base58 encoding appendBase58 replaced.
+func base58BigInt(src []byte) string {
+	num := new(big.Int).SetBytes(src)
+	var digits []byte
+	for num.Sign() > 0 {
+		mod := new(big.Int)
+		num.DivMod(num, big.NewInt(58), mod)
+		digits = append(digits, base58[mod.Int64()])
+	}
+	for _, b := range src {
+		if b != 0 {
+			break
+		}
+		digits = append(digits, '1')
+	}
+	return string(ReverseByteArr(digits))
+}
+
+// Tests that the fast base58 encoding matches the big integer one, including
+// leading zero bytes and inputs too long for the scratch space.
+func TestBase58Encoding(t *testing.T) {
+	inputs := [][]byte{nil, {0}, {0, 0}, {0, 1}, {57}, {58}, {0xff}, []byte("5aaeb6053f3e94c9b9a09f33669435e7ef1beaed")}
+	rng := rand.New(rand.NewSource(1))
+	for _, size := range []int{1, 20, 40, 64, 65, 100} {
+		for i := 0; i < 20; i++ {
+			input := make([]byte, size)
+			rng.Read(input)
+			if i%4 == 0 {
+				input[0] = 0
+			}
+			inputs = append(inputs, input)
+		}
+	}
+	for _, input := range inputs {
+		if have, want := Base58Encoding(string(input)), base58BigInt(input); have != want {
+			t.Errorf("%x: encoding mismatch: have %s, want %s", input, have, want)
+		}
+		if dec := Base58Decoding(Base58Encoding(string(input))); len(bytes.TrimLeft(input, "\x00")) > 0 && dec != string(bytes.TrimLeft(input, "\x00")) {
+			t.Errorf("%x: roundtrip mismatch: have %x", input, dec)
+		}
+	}
+}
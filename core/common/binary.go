@@ -0,0 +1,124 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// This file implements the binary encodings services exchanging chain data use:
+// SSZ (fastssz method set) and protobuf (gogoproto customtype method set), so
+// Address and Hash can be embedded directly into generated messages. The JSON
+// form of such messages keeps using the regular text encodings, so addresses
+// are rendered in FFF form.
+
+// SizeSSZ returns the size of the SSZ encoding of an address.
+func (a Address) SizeSSZ() int { return AddressLength }
+
+// MarshalSSZ encodes the address as an SSZ Vector[byte, 20].
+func (a Address) MarshalSSZ() ([]byte, error) { return a.MarshalSSZTo(nil) }
+
+// MarshalSSZTo appends the SSZ encoding of the address to buf.
+func (a Address) MarshalSSZTo(buf []byte) ([]byte, error) { return append(buf, a[:]...), nil }
+
+// UnmarshalSSZ decodes an SSZ encoded address.
+func (a *Address) UnmarshalSSZ(buf []byte) error {
+	if len(buf) != AddressLength {
+		return fmt.Errorf("invalid SSZ address length %d, want %d", len(buf), AddressLength)
+	}
+	copy(a[:], buf)
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the address, which is its
+// single chunk right padded to 32 bytes.
+func (a Address) HashTreeRoot() ([32]byte, error) {
+	var root [32]byte
+	copy(root[:], a[:])
+	return root, nil
+}
+
+// Size returns the size of the protobuf encoding of an address.
+func (a Address) Size() int { return AddressLength }
+
+// Marshal encodes the address as the content of a protobuf bytes field.
+func (a Address) Marshal() ([]byte, error) { return a.Bytes(), nil }
+
+// MarshalTo writes the protobuf encoding of the address into data.
+func (a Address) MarshalTo(data []byte) (int, error) {
+	if len(data) < AddressLength {
+		return 0, fmt.Errorf("buffer too small for address: %d < %d", len(data), AddressLength)
+	}
+	return copy(data, a[:]), nil
+}
+
+// Unmarshal decodes the protobuf encoding of an address. An empty field is
+// the zero address, as protobuf omits empty bytes.
+func (a *Address) Unmarshal(data []byte) error {
+	switch len(data) {
+	case 0:
+		*a = Address{}
+	case AddressLength:
+		copy(a[:], data)
+	default:
+		return fmt.Errorf("invalid protobuf address length %d, want %d", len(data), AddressLength)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the address as a JSON string in FFF form, as needed by
+// the JSON mapping of protobuf custom types.
+func (a Address) MarshalJSON() ([]byte, error) { return json.Marshal(a.String()) }
+
+// SizeSSZ returns the size of the SSZ encoding of a hash.
+func (h Hash) SizeSSZ() int { return HashLength }
+
+// MarshalSSZ encodes the hash as an SSZ Vector[byte, 32].
+func (h Hash) MarshalSSZ() ([]byte, error) { return h.MarshalSSZTo(nil) }
+
+// MarshalSSZTo appends the SSZ encoding of the hash to buf.
+func (h Hash) MarshalSSZTo(buf []byte) ([]byte, error) { return append(buf, h[:]...), nil }
+
+// UnmarshalSSZ decodes an SSZ encoded hash.
+func (h *Hash) UnmarshalSSZ(buf []byte) error {
+	if len(buf) != HashLength {
+		return fmt.Errorf("invalid SSZ hash length %d, want %d", len(buf), HashLength)
+	}
+	copy(h[:], buf)
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of the hash, which is the hash
+// itself being a single chunk.
+func (h Hash) HashTreeRoot() ([32]byte, error) { return h, nil }
+
+// Size returns the size of the protobuf encoding of a hash.
+func (h Hash) Size() int { return HashLength }
+
+// Marshal encodes the hash as the content of a protobuf bytes field.
+func (h Hash) Marshal() ([]byte, error) { return h.Bytes(), nil }
+
+// MarshalTo writes the protobuf encoding of the hash into data.
+func (h Hash) MarshalTo(data []byte) (int, error) {
+	if len(data) < HashLength {
+		return 0, fmt.Errorf("buffer too small for hash: %d < %d", len(data), HashLength)
+	}
+	return copy(data, h[:]), nil
+}
+
+// Unmarshal decodes the protobuf encoding of a hash. An empty field is the
+// zero hash, as protobuf omits empty bytes.
+func (h *Hash) Unmarshal(data []byte) error {
+	switch len(data) {
+	case 0:
+		*h = Hash{}
+	case HashLength:
+		copy(h[:], data)
+	default:
+		return fmt.Errorf("invalid protobuf hash length %d, want %d", len(data), HashLength)
+	}
+	return nil
+}
+
+// MarshalJSON encodes the hash as a hex JSON string, as needed by the JSON
+// mapping of protobuf custom types.
+func (h Hash) MarshalJSON() ([]byte, error) { return json.Marshal(h.Hex()) }
@@ -57,13 +57,13 @@ func HexToFFFAddress(s string) FFFAddress {
 }
 
 func FFFFromHex(s string) []byte {
-	return FromHex(FFFAddressDecode(s))
+	return FromHex(decodeFFFAddress(s))
 }
 
 // IsHexFFFAddress verifies whether a string can represent a valid hex-encoded
 // FFF address or not.
 func IsHexFFFAddress(s string) bool {
-	return IsHexAddress(FFFAddressDecode(s))
+	return IsHexAddress(decodeFFFAddress(s))
 }
 
 // Bytes gets the string representation of the underlying address.
@@ -104,8 +104,8 @@ func (a FFFAddress) MarshalText() ([]byte, error) {
 
 // UnmarshalText parses a hash in hex syntax.
 func (a *FFFAddress) UnmarshalText(input []byte) error {
-	s := HexToAddress(FFFAddressDecode(string(input)))
-	a.SetBytes(HexToAddress(FFFAddressDecode(string(input))).Bytes())
+	s := HexToAddress(decodeFFFAddress(string(input)))
+	a.SetBytes(HexToAddress(decodeFFFAddress(string(input))).Bytes())
 	return hexutil.UnmarshalFixedText("Address", []byte(s.hex()), a[:])
 }
 
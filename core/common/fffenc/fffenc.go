@@ -0,0 +1,193 @@
+// Package fffenc implements FFF address codecs bound to an explicit network
+// configuration, so that tooling serving several networks at once (e.g. an
+// explorer indexing mainnet and testnet) doesn't depend on the process wide
+// prefix of package common.
+package fffenc
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+// Alphabet is the version of the alphabet the address bodies are encoded with.
+type Alphabet uint8
+
+// AlphabetBase58 is the Bitcoin base58 alphabet, the only one defined so far.
+const AlphabetBase58 Alphabet = 1
+
+var (
+	// ErrAlphabet is returned if a configuration uses an unknown alphabet.
+	ErrAlphabet = errors.New("unsupported FFF alphabet version")
+
+	// ErrPrefix is returned if a configuration carries an invalid prefix.
+	ErrPrefix = errors.New("invalid FFF address prefix")
+
+	// ErrChainConflict is returned if a configuration is registered for a chain
+	// which already has a different one.
+	ErrChainConflict = errors.New("conflicting FFF configuration for chain")
+)
+
+// Config is the FFF address encoding of a network. It is immutable once
+// constructed and safe for concurrent use.
+type Config struct {
+	Prefix   string   // Network prefix of the encoded addresses
+	Alphabet Alphabet // Alphabet of the encoded address bodies
+	ChainID  *big.Int // Chain the addresses belong to, nil if not bound to one
+}
+
+// New creates the configuration of a network using the given prefix, bound to
+// the given chain ID if not nil.
+func New(prefix string, chainID *big.Int) *Config {
+	c := &Config{Prefix: prefix, Alphabet: AlphabetBase58}
+	if chainID != nil {
+		c.ChainID = new(big.Int).Set(chainID)
+	}
+	return c
+}
+
+// FromChainConfig creates the configuration of the network described by a
+// chain config.
+func FromChainConfig(config *params.ChainConfig) *Config {
+	return New(config.FFFAddressPrefix(), config.ChainID)
+}
+
+// Validate checks that the configuration is usable.
+func (c *Config) Validate() error {
+	if c.Alphabet != AlphabetBase58 {
+		return fmt.Errorf("%w: %d", ErrAlphabet, c.Alphabet)
+	}
+	if c.Prefix == "" || strings.EqualFold(c.Prefix, common.ETHHeader) {
+		return fmt.Errorf("%w: %q", ErrPrefix, c.Prefix)
+	}
+	for _, ch := range c.Prefix {
+		if !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9') {
+			return fmt.Errorf("%w: %q", ErrPrefix, c.Prefix)
+		}
+	}
+	return nil
+}
+
+// String implements fmt.Stringer.
+func (c *Config) String() string {
+	if c.ChainID == nil {
+		return fmt.Sprintf("%s (alphabet v%d)", c.Prefix, c.Alphabet)
+	}
+	return fmt.Sprintf("%s (alphabet v%d, chain %v)", c.Prefix, c.Alphabet, c.ChainID)
+}
+
+// Encode returns the FFF encoding of an address on the network.
+func (c *Config) Encode(addr common.Address) string {
+	return c.Prefix + common.Base58Encoding(hex.EncodeToString(addr[:]))
+}
+
+// EncodeTyped returns the FFF encoding of an address on the network tagged
+// with the given account type. Types that don't decode are rejected with
+// common.ErrAddressType.
+func (c *Config) EncodeTyped(addr common.Address, typ common.AddressType) (string, error) {
+	if !typ.Valid() {
+		return "", fmt.Errorf("%w %v", common.ErrAddressType, typ)
+	}
+	payload := make([]byte, 1+common.AddressLength)
+	payload[0] = byte(typ)
+	copy(payload[1:], addr[:])
+
+	return c.Prefix + common.Base58Encoding(hex.EncodeToString(payload)), nil
+}
+
+// Owns reports whether s carries the prefix of the network.
+func (c *Config) Owns(s string) bool {
+	return strings.HasPrefix(s, c.Prefix)
+}
+
+// Decode parses an FFF address of the network or a 0x prefixed hex address.
+// Addresses carrying the prefix of another known network are rejected with
+// common.ErrAddressNetwork.
+func (c *Config) Decode(s string) (common.Address, error) {
+	addr, _, err := c.DecodeTyped(s)
+	return addr, err
+}
+
+// DecodeTyped is Decode also returning the account type of typed addresses.
+// Legacy and hex addresses decode with common.AddressTypeUnknown.
+func (c *Config) DecodeTyped(s string) (common.Address, common.AddressType, error) {
+	return common.DecodeAddressWith(s, c.Prefix, c.Alphabet.digits())
+}
+
+// digits returns the digits of the alphabet, empty for unknown versions so
+// that no address body decodes.
+func (a Alphabet) digits() string {
+	if a == AlphabetBase58 {
+		return common.Base58Alphabet
+	}
+	return ""
+}
+
+var (
+	registryLock sync.Mutex
+	chains       = make(map[string]*Config) // Registered configurations by chain ID
+	active       atomic.Value               // Process wide default configuration
+)
+
+// Register makes a configuration known to the process, allowing it to be
+// looked up by chain ID and its addresses to be told apart from malformed ones
+// by the codecs of other networks.
+func Register(c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if c.ChainID != nil {
+		if have, ok := chains[c.ChainID.String()]; ok && (have.Prefix != c.Prefix || have.Alphabet != c.Alphabet) {
+			return fmt.Errorf("%w %v: have %v, want %v", ErrChainConflict, c.ChainID, have, c)
+		}
+		chains[c.ChainID.String()] = c
+	}
+	common.RegisterAddressPrefix(c.Prefix)
+	return nil
+}
+
+// ForChain returns the configuration registered for a chain, nil if none.
+func ForChain(chainID *big.Int) *Config {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	return chains[chainID.String()]
+}
+
+// Activate registers a configuration and makes it the process wide default,
+// also used by the encoding functions and the text marshalling of package
+// common. It's meant to be called once at startup.
+func Activate(c *Config) error {
+	if err := Register(c); err != nil {
+		return err
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	common.SetAddressPrefix(c.Prefix)
+	active.Store(c)
+	return nil
+}
+
+// Active returns the process wide default configuration, the mainnet one if
+// none was activated.
+func Active() *Config {
+	if c, ok := active.Load().(*Config); ok {
+		return c
+	}
+	return mainnet
+}
+
+// mainnet is the configuration of the FFF mainnet, the default if none was
+// activated.
+var mainnet = New(common.FFFHeader, params.MainnetChainConfig.ChainID)
@@ -0,0 +1,135 @@
+package fffenc
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+// Tests that codecs of different networks work side by side, each rejecting
+// the addresses of the other.
+func TestMultiNetwork(t *testing.T) {
+	var (
+		mainnet = New(common.FFFHeader, big.NewInt(1))
+		testnet = New(common.TFFHeader, big.NewInt(97))
+		addr    = common.BytesToAddress(common.FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	)
+	encMain, encTest := mainnet.Encode(addr), testnet.Encode(addr)
+	if !strings.HasPrefix(encMain, common.FFFHeader) || !strings.HasPrefix(encTest, common.TFFHeader) {
+		t.Fatalf("prefix mismatch: %s, %s", encMain, encTest)
+	}
+	if encMain[len(common.FFFHeader):] != encTest[len(common.TFFHeader):] {
+		t.Fatalf("body mismatch: %s, %s", encMain, encTest)
+	}
+	for _, tt := range []struct {
+		config *Config
+		input  string
+	}{
+		{mainnet, encMain}, {testnet, encTest},
+		{mainnet, "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+	} {
+		have, err := tt.config.Decode(tt.input)
+		if err != nil || have != addr {
+			t.Errorf("%v: decode %s mismatch: have %x, %v", tt.config, tt.input, have, err)
+		}
+	}
+	if _, err := mainnet.Decode(encTest); !errors.Is(err, common.ErrAddressNetwork) {
+		t.Errorf("foreign address error mismatch: have %v, want %v", err, common.ErrAddressNetwork)
+	}
+	if _, err := testnet.Decode(encMain); !errors.Is(err, common.ErrAddressNetwork) {
+		t.Errorf("foreign address error mismatch: have %v, want %v", err, common.ErrAddressNetwork)
+	}
+	for _, input := range []string{"", "FFF", encMain + "0", encMain[:len(encMain)-1] + "l", "0x1234", "XYZ" + encMain[3:], strings.ToLower(encMain[:3]) + encMain[3:]} {
+		if _, err := mainnet.Decode(input); err == nil {
+			t.Errorf("invalid address %q accepted", input)
+		}
+	}
+	// Typed addresses carry their account type along
+	enc, err := testnet.EncodeTyped(addr, common.AddressTypeValidator)
+	if err != nil {
+		t.Fatalf("failed to encode typed address: %v", err)
+	}
+	have, typ, err := testnet.DecodeTyped(enc)
+	if err != nil || have != addr || typ != common.AddressTypeValidator {
+		t.Errorf("typed decode mismatch: have %x %v, %v", have, typ, err)
+	}
+	for _, typ := range []common.AddressType{common.AddressTypeUnknown, common.AddressTypeSystem + 1} {
+		if _, err := testnet.EncodeTyped(addr, typ); !errors.Is(err, common.ErrAddressType) {
+			t.Errorf("%v: encoding error mismatch: have %v, want %v", typ, err, common.ErrAddressType)
+		}
+	}
+}
+
+// Tests that the codecs of a config agree with the ones of package common using
+// the same prefix.
+func TestCommonCompatibility(t *testing.T) {
+	addr := common.BytesToAddress(common.FromHex("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"))
+	if have, want := Active().Encode(addr), addr.String(); have != want {
+		t.Errorf("encoding mismatch: have %s, want %s", have, want)
+	}
+	if have, _ := Active().Decode(addr.String()); have != addr {
+		t.Errorf("decoding mismatch: have %x, want %x", have, addr)
+	}
+}
+
+// Tests configuration validation and derivation from chain configs.
+func TestConfig(t *testing.T) {
+	config := *params.TestChainConfig
+	config.AddressPrefix = "DEV"
+
+	c := FromChainConfig(&config)
+	if c.Prefix != "DEV" || c.Alphabet != AlphabetBase58 || c.ChainID.Cmp(config.ChainID) != 0 {
+		t.Fatalf("config mismatch: %+v", c)
+	}
+	if c.ChainID == config.ChainID {
+		t.Errorf("chain ID shared with chain config")
+	}
+	if err := c.Validate(); err != nil {
+		t.Errorf("valid config rejected: %v", err)
+	}
+	for _, prefix := range []string{"", "0x", "0X", "F-F"} {
+		if err := New(prefix, nil).Validate(); !errors.Is(err, ErrPrefix) {
+			t.Errorf("prefix %q error mismatch: have %v, want %v", prefix, err, ErrPrefix)
+		}
+	}
+	if err := (&Config{Prefix: "FFF", Alphabet: 2}).Validate(); !errors.Is(err, ErrAlphabet) {
+		t.Errorf("alphabet error mismatch: have %v, want %v", err, ErrAlphabet)
+	}
+}
+
+// Tests that configurations can be registered and looked up concurrently, and
+// that conflicting ones are refused.
+func TestRegister(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := Register(New("NET", big.NewInt(int64(9000+i%4)))); err != nil {
+				t.Errorf("registration failed: %v", err)
+			}
+			ForChain(big.NewInt(9000))
+		}(i)
+	}
+	wg.Wait()
+
+	if c := ForChain(big.NewInt(9003)); c == nil || c.Prefix != "NET" {
+		t.Fatalf("registered config mismatch: %v", c)
+	}
+	if c := ForChain(big.NewInt(9999)); c != nil {
+		t.Errorf("unregistered chain returned config %v", c)
+	}
+	if err := Register(New("ALT", big.NewInt(9000))); !errors.Is(err, ErrChainConflict) {
+		t.Errorf("conflict error mismatch: have %v, want %v", err, ErrChainConflict)
+	}
+	// Addresses of registered networks are recognised as foreign
+	addr := common.Address{0x01}
+	if _, err := Active().Decode(New("NET", nil).Encode(addr)); !errors.Is(err, common.ErrAddressNetwork) {
+		t.Errorf("registered network error mismatch: have %v, want %v", err, common.ErrAddressNetwork)
+	}
+}
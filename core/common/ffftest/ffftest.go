@@ -0,0 +1,230 @@
+// Package ffftest provides property based testing helpers for the FFF address
+// codec: generators of valid and near-valid addresses compatible with
+// testing/quick, and a corpus of corrupt inputs every decoder must reject.
+//
+// The helpers are shared by the tests and fuzzers of this repository and by
+// downstream SDKs reimplementing the codec, so all of them exercise the same
+// adversarial cases.
+package ffftest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+)
+
+// base58 is the alphabet of the FFF address body.
+const base58 = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// nonBase58 are the characters base58 leaves out for being ambiguous, which
+// are the most likely typos in a transcribed address.
+const nonBase58 = "0OIl"
+
+// Address generates random addresses, biased towards the edge cases of the
+// codec: the zero address, all-ones and addresses with leading zero bytes.
+func Address(r *rand.Rand) common.Address {
+	var addr common.Address
+	switch r.Intn(8) {
+	case 0:
+		// zero address
+	case 1:
+		for i := range addr {
+			addr[i] = 0xff
+		}
+	case 2:
+		r.Read(addr[1+r.Intn(common.AddressLength-1):])
+	default:
+		r.Read(addr[:])
+	}
+	return addr
+}
+
+// Encode returns the FFF encoding of an address with the given network prefix.
+func Encode(addr common.Address, prefix string) string {
+	return prefix + common.Base58Encoding(strings.ToLower(common.AddressFormatHex.Address(addr)[2:]))
+}
+
+// Valid generates a random valid FFF address of the configured network, along
+// with the address it encodes.
+func Valid(r *rand.Rand) (string, common.Address) {
+	addr := Address(r)
+	return Encode(addr, common.AddressPrefix()), addr
+}
+
+// Mutation is a corruption of a valid FFF address that every decoder must
+// reject.
+type Mutation struct {
+	Name  string
+	Apply func(r *rand.Rand, addr string) string
+}
+
+// Mutations are the corruptions near-valid addresses are generated with. All
+// of them are guaranteed to turn a valid address into an invalid one, as long
+// as cross-network addresses are not enabled.
+var Mutations = []Mutation{
+	{"foreign-prefix", func(r *rand.Rand, addr string) string {
+		prefix, body := split(addr)
+		if prefix == common.FFFHeader {
+			return common.TFFHeader + body
+		}
+		return common.FFFHeader + body
+	}},
+	{"invalid-char", func(r *rand.Rand, addr string) string {
+		prefix, body := split(addr)
+		i := r.Intn(len(body))
+		return prefix + body[:i] + string(nonBase58[r.Intn(len(nonBase58))]) + body[i+1:]
+	}},
+	{"truncated", func(r *rand.Rand, addr string) string {
+		return addr[:len(addr)-1-r.Intn(3)]
+	}},
+	{"extended", func(r *rand.Rand, addr string) string {
+		return addr + string(base58[r.Intn(len(base58))])
+	}},
+	{"double-prefix", func(r *rand.Rand, addr string) string {
+		prefix, _ := split(addr)
+		return prefix + addr
+	}},
+	{"whitespace", func(r *rand.Rand, addr string) string {
+		return addr + []string{" ", "\t", "\n", "\x00"}[r.Intn(4)]
+	}},
+	{"short-payload", func(r *rand.Rand, addr string) string {
+		prefix, _ := split(addr)
+		hex := strings.ToLower(common.AddressFormatHex.Address(Address(r))[2:])
+		return prefix + common.Base58Encoding(hex[:len(hex)-2])
+	}},
+	{"non-hex-payload", func(r *rand.Rand, addr string) string {
+		prefix, _ := split(addr)
+		hex := []byte(strings.ToLower(common.AddressFormatHex.Address(Address(r))[2:]))
+		hex[r.Intn(len(hex))] = "ghijklmnopqrstuvwxyz"[r.Intn(20)]
+		return prefix + common.Base58Encoding(string(hex))
+	}},
+}
+
+// NearValid generates a random valid FFF address and corrupts it with one of
+// the mutations, returning the corrupt address and the mutation applied.
+func NearValid(r *rand.Rand) (string, *Mutation) {
+	addr, _ := Valid(r)
+	mutation := &Mutations[r.Intn(len(Mutations))]
+	return mutation.Apply(r, addr), mutation
+}
+
+// ValidFFF is a valid FFF address of the configured network, generated by
+// testing/quick.
+type ValidFFF string
+
+// Generate implements quick.Generator.
+func (ValidFFF) Generate(r *rand.Rand, size int) reflect.Value {
+	addr, _ := Valid(r)
+	return reflect.ValueOf(ValidFFF(addr))
+}
+
+// CorruptFFF is a near-valid FFF address, generated by testing/quick.
+type CorruptFFF string
+
+// Generate implements quick.Generator.
+func (CorruptFFF) Generate(r *rand.Rand, size int) reflect.Value {
+	addr, _ := NearValid(r)
+	return reflect.ValueOf(CorruptFFF(addr))
+}
+
+// Case is an entry of the corrupt input corpus.
+type Case struct {
+	Name  string
+	Input string
+}
+
+// Corpus returns the corrupt inputs every decoder must reject: static edge
+// cases, plus every mutation applied to a few fixed addresses. The corpus is
+// deterministic for a given network prefix.
+func Corpus() []Case {
+	prefix := common.AddressPrefix()
+	cases := []Case{
+		{"empty", ""},
+		{"prefix-only", prefix},
+		{"prefix-space", prefix + " "},
+		{"hex-short", "0x" + strings.Repeat("ab", common.AddressLength-1)},
+		{"hex-long", "0x" + strings.Repeat("ab", common.AddressLength+1)},
+		{"hex-invalid", "0x" + strings.Repeat("zz", common.AddressLength)},
+		{"all-invalid", prefix + strings.Repeat(nonBase58, 8)},
+		{"unicode", prefix + "Ｆ" + Encode(common.Address{}, "")},
+	}
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 3; i++ {
+		addr, _ := Valid(r)
+		for _, mutation := range Mutations {
+			cases = append(cases, Case{fmt.Sprintf("%s-%d", mutation.Name, i), mutation.Apply(r, addr)})
+		}
+	}
+	return cases
+}
+
+// WriteCorpus seeds a go-fuzz corpus directory with the corrupt input corpus
+// and a few valid addresses.
+func WriteCorpus(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	cases := Corpus()
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 8; i++ {
+		addr, _ := Valid(r)
+		cases = append(cases, Case{fmt.Sprintf("valid-%d", i), addr})
+	}
+	for _, c := range cases {
+		if err := ioutil.WriteFile(filepath.Join(dir, c.Name), []byte(c.Input), 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckRoundTrip verifies that an address survives encoding and decoding via
+// all the codec entry points.
+func CheckRoundTrip(addr common.Address) error {
+	enc := addr.String()
+	if dec, err := common.FFFAddressDecodeStrict(enc); err != nil || dec != strings.ToLower(common.AddressFormatHex.Address(addr)) {
+		return fmt.Errorf("decoded %s to %s (%v), want %s", enc, dec, err, common.AddressFormatHex.Address(addr))
+	}
+	var text common.Address
+	if err := text.UnmarshalText([]byte(enc)); err != nil || text != addr {
+		return fmt.Errorf("text unmarshal of %s: have %x (%v), want %x", enc, text, err, addr)
+	}
+	blob, err := json.Marshal(addr)
+	if err != nil {
+		return err
+	}
+	var js common.Address
+	if err := json.Unmarshal(blob, &js); err != nil || js != addr {
+		return fmt.Errorf("json unmarshal of %s: have %x (%v), want %x", blob, js, err, addr)
+	}
+	return nil
+}
+
+// CheckRejected verifies that a corrupt input is refused by the decoders.
+func CheckRejected(input string) error {
+	var addr common.Address
+	if err := addr.UnmarshalText([]byte(input)); err == nil {
+		return fmt.Errorf("text unmarshal accepted %q as %x", input, addr)
+	}
+	blob, _ := json.Marshal(input)
+	if err := json.Unmarshal(blob, &addr); err == nil {
+		return fmt.Errorf("json unmarshal accepted %q as %x", input, addr)
+	}
+	if common.IsHexAddress(input) {
+		return fmt.Errorf("%q reported as valid address", input)
+	}
+	return nil
+}
+
+// split separates the network prefix of an FFF address from its body.
+func split(addr string) (string, string) {
+	n := len(common.AddressPrefix())
+	return addr[:n], addr[n:]
+}
@@ -0,0 +1,20 @@
+//go:build gofuzz
+// +build gofuzz
+
+package ffftest
+
+import "github.com/liuguodong24-8/3fcoin/core/common"
+
+// Fuzz is the go-fuzz entry point of the FFF address decoder, to be seeded with
+// WriteCorpus. Any input the decoder accepts must decode to an address that
+// round-trips through all the codec entry points.
+func Fuzz(data []byte) int {
+	var addr common.Address
+	if err := addr.UnmarshalText(data); err != nil {
+		return 0
+	}
+	if err := CheckRoundTrip(addr); err != nil {
+		panic(err)
+	}
+	return 1
+}
@@ -35,27 +35,23 @@ import (
 	"fmt"
 	"math/big"
 	"strconv"
-)
 
-const uintBits = 32 << (uint64(^uint(0)) >> 63)
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+)
 
 // Errors
 var (
-	ErrEmptyString   = &decError{"empty hex string"}
-	ErrSyntax        = &decError{"invalid hex string"}
-	ErrMissingPrefix = &decError{"hex string without 0x prefix"}
-	ErrOddLength     = &decError{"hex string of odd length"}
-	ErrEmptyNumber   = &decError{"hex string \"0x\""}
-	ErrLeadingZero   = &decError{"hex number with leading zero digits"}
-	ErrUint64Range   = &decError{"hex number > 64 bits"}
-	ErrUintRange     = &decError{fmt.Sprintf("hex number > %d bits", uintBits)}
-	ErrBig256Range   = &decError{"hex number > 256 bits"}
+	ErrEmptyString   = hexutil.ErrEmptyString
+	ErrSyntax        = hexutil.ErrSyntax
+	ErrMissingPrefix = hexutil.ErrMissingPrefix
+	ErrOddLength     = hexutil.ErrOddLength
+	ErrEmptyNumber   = hexutil.ErrEmptyNumber
+	ErrLeadingZero   = hexutil.ErrLeadingZero
+	ErrUint64Range   = hexutil.ErrUint64Range
+	ErrUintRange     = hexutil.ErrUintRange
+	ErrBig256Range   = hexutil.ErrBig256Range
 )
 
-type decError struct{ msg string }
-
-func (err decError) Error() string { return err.msg }
-
 // Decode decodes a hex string with 0x prefix.
 func Decode(input string) ([]byte, error) {
 	if len(input) == 0 {
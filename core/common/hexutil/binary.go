@@ -0,0 +1,175 @@
+// Copyright 2016 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package hexutil
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// The methods below implement the SSZ (fastssz) and protobuf (gogoproto
+// customtype) method sets, so the numeric wrappers can be used directly in
+// generated messages. Uint64 encodes as uint64, Big as uint256.
+
+var (
+	errNegativeBig = errors.New("negative integer not supported")
+	errBufferSize  = errors.New("buffer too small")
+)
+
+// SizeSSZ returns the size of the SSZ encoding of b.
+func (b Uint64) SizeSSZ() int { return 8 }
+
+// MarshalSSZ encodes b as an SSZ uint64.
+func (b Uint64) MarshalSSZ() ([]byte, error) { return b.MarshalSSZTo(nil) }
+
+// MarshalSSZTo appends the SSZ encoding of b to buf.
+func (b Uint64) MarshalSSZTo(buf []byte) ([]byte, error) {
+	return binary.LittleEndian.AppendUint64(buf, uint64(b)), nil
+}
+
+// UnmarshalSSZ decodes an SSZ uint64.
+func (b *Uint64) UnmarshalSSZ(buf []byte) error {
+	if len(buf) != 8 {
+		return fmt.Errorf("invalid SSZ uint64 length %d", len(buf))
+	}
+	*b = Uint64(binary.LittleEndian.Uint64(buf))
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of b.
+func (b Uint64) HashTreeRoot() ([32]byte, error) {
+	var root [32]byte
+	binary.LittleEndian.PutUint64(root[:], uint64(b))
+	return root, nil
+}
+
+// Size returns the size of the protobuf encoding of b.
+func (b Uint64) Size() int { return 8 }
+
+// Marshal encodes b as the content of a protobuf bytes field, as a big endian
+// fixed size integer.
+func (b Uint64) Marshal() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, uint64(b)), nil
+}
+
+// MarshalTo writes the protobuf encoding of b into data.
+func (b Uint64) MarshalTo(data []byte) (int, error) {
+	if len(data) < 8 {
+		return 0, errBufferSize
+	}
+	binary.BigEndian.PutUint64(data, uint64(b))
+	return 8, nil
+}
+
+// Unmarshal decodes the protobuf encoding of b. An empty field is zero.
+func (b *Uint64) Unmarshal(data []byte) error {
+	switch len(data) {
+	case 0:
+		*b = 0
+	case 8:
+		*b = Uint64(binary.BigEndian.Uint64(data))
+	default:
+		return fmt.Errorf("invalid protobuf uint64 length %d", len(data))
+	}
+	return nil
+}
+
+// SizeSSZ returns the size of the SSZ encoding of b.
+func (b Big) SizeSSZ() int { return 32 }
+
+// MarshalSSZ encodes b as an SSZ uint256.
+func (b Big) MarshalSSZ() ([]byte, error) { return b.MarshalSSZTo(nil) }
+
+// MarshalSSZTo appends the SSZ encoding of b to buf.
+func (b Big) MarshalSSZTo(buf []byte) ([]byte, error) {
+	root, err := b.HashTreeRoot()
+	if err != nil {
+		return nil, err
+	}
+	return append(buf, root[:]...), nil
+}
+
+// UnmarshalSSZ decodes an SSZ uint256.
+func (b *Big) UnmarshalSSZ(buf []byte) error {
+	if len(buf) != 32 {
+		return fmt.Errorf("invalid SSZ uint256 length %d", len(buf))
+	}
+	var be [32]byte
+	for i := range buf {
+		be[31-i] = buf[i]
+	}
+	*b = Big(*new(big.Int).SetBytes(be[:]))
+	return nil
+}
+
+// HashTreeRoot returns the SSZ hash tree root of b, which is its little endian
+// 32 byte encoding.
+func (b Big) HashTreeRoot() ([32]byte, error) {
+	var root [32]byte
+
+	num := (*big.Int)(&b)
+	if num.Sign() < 0 {
+		return root, errNegativeBig
+	}
+	if num.BitLen() > 256 {
+		return root, ErrBig256Range
+	}
+	num.FillBytes(root[:])
+	for i, j := 0, 31; i < j; i, j = i+1, j-1 {
+		root[i], root[j] = root[j], root[i]
+	}
+	return root, nil
+}
+
+// Size returns the size of the protobuf encoding of b.
+func (b Big) Size() int { return ((*big.Int)(&b).BitLen() + 7) / 8 }
+
+// Marshal encodes b as the content of a protobuf bytes field, as a minimal big
+// endian integer.
+func (b Big) Marshal() ([]byte, error) {
+	num := (*big.Int)(&b)
+	if num.Sign() < 0 {
+		return nil, errNegativeBig
+	}
+	if num.BitLen() > 256 {
+		return nil, ErrBig256Range
+	}
+	return num.Bytes(), nil
+}
+
+// MarshalTo writes the protobuf encoding of b into data.
+func (b Big) MarshalTo(data []byte) (int, error) {
+	enc, err := b.Marshal()
+	if err != nil {
+		return 0, err
+	}
+	if len(data) < len(enc) {
+		return 0, errBufferSize
+	}
+	return copy(data, enc), nil
+}
+
+// Unmarshal decodes the protobuf encoding of b. An empty field is zero.
+func (b *Big) Unmarshal(data []byte) error {
+	if len(data) > 32 {
+		return ErrBig256Range
+	}
+	*b = Big(*new(big.Int).SetBytes(data))
+	return nil
+}
@@ -0,0 +1,147 @@
+package hexutil
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrorCode is the machine readable class of a parse error. The codes are
+// stable, so API layers can map them onto their own error codes.
+type ErrorCode string
+
+// Classes of parse errors.
+const (
+	CodeEmpty         ErrorCode = "empty"          // Empty input
+	CodeMissingPrefix ErrorCode = "missing-prefix" // Input lacking its 0x prefix
+	CodeSyntax        ErrorCode = "syntax"         // Input with invalid characters
+	CodeOddLength     ErrorCode = "odd-length"     // Hex data of odd length
+	CodeLength        ErrorCode = "length"         // Data of the wrong length for a fixed size type
+	CodeLeadingZero   ErrorCode = "leading-zero"   // Number with leading zero digits
+	CodeRange         ErrorCode = "range"          // Number out of range
+	CodeNetwork       ErrorCode = "network"        // Address of a different network
+	CodeAddressType   ErrorCode = "address-type"   // Typed address of an unknown type
+	CodeFormat        ErrorCode = "format"         // Unknown format name
+)
+
+// Descriptions of the formats accepted by the parsers.
+const (
+	ExpectBytes    = "0x prefixed hex string of even length"
+	ExpectQuantity = "0x prefixed hex number without leading zero digits"
+)
+
+// maxErrorInput is the longest input snippet kept in a parse error.
+const maxErrorInput = 64
+
+// NewErrorClass creates an error class, a sentinel error that parse errors of
+// the class match with errors.Is.
+func NewErrorClass(code ErrorCode, msg string) error {
+	return &decError{code: code, msg: msg}
+}
+
+// ParseError is returned when parsing hex, number or address input fails. It
+// matches the error class it belongs to with errors.Is, e.g. ErrSyntax.
+type ParseError struct {
+	Code     ErrorCode `json:"code"`               // Class of the error
+	Input    string    `json:"input,omitempty"`    // Offending input, truncated if long
+	Expected string    `json:"expected,omitempty"` // Description of the accepted format
+	Err      error     `json:"-"`                  // Error class, unwrapped by errors.Is and errors.As
+
+	msg string // Message overriding the one of the class
+}
+
+// Error implements error, returning the message of the error class so that it
+// reads the same as the plain class did.
+func (e *ParseError) Error() string {
+	if e.msg != "" {
+		return e.msg
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the error class.
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// AnnotateError attaches the offending input and the expected format to a
+// parse error. Plain error classes are turned into a *ParseError, the input
+// and format of a *ParseError are replaced. Other errors are returned as is.
+func AnnotateError(err error, input string, expected string) error {
+	if err == nil {
+		return nil
+	}
+	if len(input) > maxErrorInput {
+		input = input[:maxErrorInput] + "..."
+	}
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		annotated := *perr
+		annotated.Input, annotated.Expected = input, expected
+		return &annotated
+	}
+	var class *decError
+	if errors.As(err, &class) {
+		return &ParseError{Code: class.code, Input: input, Expected: expected, Err: err}
+	}
+	return err
+}
+
+// NewLengthError returns the error of input decoding to the wrong number of hex
+// digits for a fixed size type.
+func NewLengthError(typname string, input []byte, have, want int) error {
+	err := AnnotateError(ErrLength, string(input), fmt.Sprintf("hex string of %d digits", want)).(*ParseError)
+	err.msg = fmt.Sprintf("hex string has length %d, want %d for %s", have, want, typname)
+	return err
+}
+
+// typeError is the *json.UnmarshalTypeError a parse error is reported as by a
+// json.Unmarshaler, carrying the parse error along.
+type typeError struct {
+	*json.UnmarshalTypeError
+	err error
+}
+
+// Unwrap returns the parse error.
+func (e *typeError) Unwrap() error { return e.err }
+
+// As lets errors.As find the *json.UnmarshalTypeError.
+func (e *typeError) As(target interface{}) bool {
+	if t, ok := target.(**json.UnmarshalTypeError); ok {
+		*t = e.UnmarshalTypeError
+		return true
+	}
+	return false
+}
+
+// WrapTypeError reports a parse error as a *json.UnmarshalTypeError, as expected
+// of json.Unmarshaler implementations. The parse error stays reachable through
+// errors.Is and errors.As. Length and other errors are returned as is.
+func WrapTypeError(err error, typ reflect.Type) error {
+	var class *decError
+	if errors.As(err, &class) && class != ErrLength {
+		return &typeError{&json.UnmarshalTypeError{Value: class.msg, Type: typ}, err}
+	}
+	return err
+}
+
+// AsParseError returns the parse error within err, if any.
+func AsParseError(err error) (*ParseError, bool) {
+	var perr *ParseError
+	if errors.As(err, &perr) {
+		return perr, true
+	}
+	var class *decError
+	if errors.As(err, &class) {
+		return &ParseError{Code: class.code, Err: class}, true
+	}
+	return nil, false
+}
+
+// ErrorCodeOf returns the class of the parse error within err, or the empty
+// string if err isn't a parse error.
+func ErrorCodeOf(err error) ErrorCode {
+	if perr, ok := AsParseError(err); ok {
+		return perr.Code
+	}
+	return ""
+}
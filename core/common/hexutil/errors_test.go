@@ -0,0 +1,70 @@
+package hexutil
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// Tests that parse errors match their class, carry the offending input and
+// read the same as the plain class.
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		decode func(string) error
+		input  string
+		class  error
+		code   ErrorCode
+	}{
+		{func(s string) error { _, err := Decode(s); return err }, "0x0g", ErrSyntax, CodeSyntax},
+		{func(s string) error { _, err := Decode(s); return err }, "01", ErrMissingPrefix, CodeMissingPrefix},
+		{func(s string) error { _, err := Decode(s); return err }, "0x123", ErrOddLength, CodeOddLength},
+		{func(s string) error { _, err := DecodeUint64(s); return err }, "0x01", ErrLeadingZero, CodeLeadingZero},
+		{func(s string) error { _, err := DecodeUint64(s); return err }, "0x10000000000000000", ErrUint64Range, CodeRange},
+		{func(s string) error { _, err := DecodeBig(s); return err }, "0x", ErrEmptyNumber, CodeEmpty},
+		{func(s string) error { return new(Big).UnmarshalText([]byte(s)) }, "0xz", ErrSyntax, CodeSyntax},
+		{func(s string) error { return new(Bytes).UnmarshalText([]byte(s)) }, "0x1", ErrOddLength, CodeOddLength},
+		{func(s string) error { return UnmarshalFixedText("Hash", []byte(s), make([]byte, 2)) }, "0x12", ErrLength, CodeLength},
+	}
+	for _, tt := range tests {
+		err := tt.decode(tt.input)
+		if !errors.Is(err, tt.class) {
+			t.Errorf("%q: error mismatch: have %v, want %v", tt.input, err, tt.class)
+			continue
+		}
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("%q: error %T is not a *ParseError", tt.input, err)
+			continue
+		}
+		if perr.Code != tt.code || perr.Input != tt.input || perr.Expected == "" {
+			t.Errorf("%q: parse error mismatch: %+v", tt.input, perr)
+		}
+		if tt.class != ErrLength && err.Error() != tt.class.Error() {
+			t.Errorf("%q: message mismatch: have %q, want %q", tt.input, err, tt.class)
+		}
+	}
+}
+
+// Tests that parse errors are recovered from the errors returned by JSON
+// unmarshalling, which reports them as *json.UnmarshalTypeError.
+func TestAsParseError(t *testing.T) {
+	var b Big
+	err := json.Unmarshal([]byte(`"0x01"`), &b)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Fatalf("error %T is not a *json.UnmarshalTypeError", err)
+	}
+	perr, ok := AsParseError(err)
+	if !ok || perr.Code != CodeLeadingZero || perr.Input != "0x01" || !errors.Is(perr, ErrLeadingZero) {
+		t.Errorf("recovered parse error mismatch: %+v", perr)
+	}
+	if code := ErrorCodeOf(errors.New("hex")); code != "" {
+		t.Errorf("unrelated error classified as %q", code)
+	}
+	// Long inputs are truncated
+	long := "0x" + strings.Repeat("zz", 100)
+	if _, err := Decode(long); !strings.HasSuffix(err.(*ParseError).Input, "...") || len(err.(*ParseError).Input) > maxErrorInput+3 {
+		t.Errorf("long input not truncated: %q", err.(*ParseError).Input)
+	}
+}
@@ -41,34 +41,39 @@ const uintBits = 32 << (uint64(^uint(0)) >> 63)
 
 // Errors
 var (
-	ErrEmptyString   = &decError{"empty hex string"}
-	ErrSyntax        = &decError{"invalid hex string"}
-	ErrMissingPrefix = &decError{"hex string without 0x prefix"}
-	ErrOddLength     = &decError{"hex string of odd length"}
-	ErrEmptyNumber   = &decError{"hex string \"0x\""}
-	ErrLeadingZero   = &decError{"hex number with leading zero digits"}
-	ErrUint64Range   = &decError{"hex number > 64 bits"}
-	ErrUintRange     = &decError{fmt.Sprintf("hex number > %d bits", uintBits)}
-	ErrBig256Range   = &decError{"hex number > 256 bits"}
+	ErrEmptyString   = NewErrorClass(CodeEmpty, "empty hex string")
+	ErrSyntax        = NewErrorClass(CodeSyntax, "invalid hex string")
+	ErrMissingPrefix = NewErrorClass(CodeMissingPrefix, "hex string without 0x prefix")
+	ErrOddLength     = NewErrorClass(CodeOddLength, "hex string of odd length")
+	ErrEmptyNumber   = NewErrorClass(CodeEmpty, "hex string \"0x\"")
+	ErrLeadingZero   = NewErrorClass(CodeLeadingZero, "hex number with leading zero digits")
+	ErrUint64Range   = NewErrorClass(CodeRange, "hex number > 64 bits")
+	ErrUintRange     = NewErrorClass(CodeRange, fmt.Sprintf("hex number > %d bits", uintBits))
+	ErrBig256Range   = NewErrorClass(CodeRange, "hex number > 256 bits")
+	ErrLength        = NewErrorClass(CodeLength, "hex string of wrong length")
 )
 
-type decError struct{ msg string }
+// decError is an error class, see NewErrorClass.
+type decError struct {
+	code ErrorCode
+	msg  string
+}
 
 func (err decError) Error() string { return err.msg }
 
 // Decode decodes a hex string with 0x prefix.
 func Decode(input string) ([]byte, error) {
 	if len(input) == 0 {
-		return nil, ErrEmptyString
+		return nil, AnnotateError(ErrEmptyString, input, ExpectBytes)
 	}
 	if !has0xPrefix(input) {
-		return nil, ErrMissingPrefix
+		return nil, AnnotateError(ErrMissingPrefix, input, ExpectBytes)
 	}
 	b, err := hex.DecodeString(input[2:])
 	if err != nil {
 		err = mapError(err)
 	}
-	return b, err
+	return b, AnnotateError(err, input, ExpectBytes)
 }
 
 // MustDecode decodes a hex string with 0x prefix. It panics for invalid input.
@@ -92,13 +97,13 @@ func Encode(b []byte) string {
 func DecodeUint64(input string) (uint64, error) {
 	raw, err := checkNumber(input)
 	if err != nil {
-		return 0, err
+		return 0, AnnotateError(err, input, ExpectQuantity)
 	}
 	dec, err := strconv.ParseUint(raw, 16, 64)
 	if err != nil {
 		err = mapError(err)
 	}
-	return dec, err
+	return dec, AnnotateError(err, input, ExpectQuantity)
 }
 
 // MustDecodeUint64 decodes a hex string with 0x prefix as a quantity.
@@ -139,10 +144,10 @@ func init() {
 func DecodeBig(input string) (*big.Int, error) {
 	raw, err := checkNumber(input)
 	if err != nil {
-		return nil, err
+		return nil, AnnotateError(err, input, ExpectQuantity)
 	}
 	if len(raw) > 64 {
-		return nil, ErrBig256Range
+		return nil, AnnotateError(ErrBig256Range, input, ExpectQuantity)
 	}
 	words := make([]big.Word, len(raw)/bigWordNibbles+1)
 	end := len(raw)
@@ -154,7 +159,7 @@ func DecodeBig(input string) (*big.Int, error) {
 		for ri := start; ri < end; ri++ {
 			nib := decodeNibble(raw[ri])
 			if nib == badNibble {
-				return nil, ErrSyntax
+				return nil, AnnotateError(ErrSyntax, input, ExpectQuantity)
 			}
 			words[i] *= 16
 			words[i] += big.Word(nib)
@@ -19,6 +19,7 @@ package hexutil
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
@@ -49,14 +50,14 @@ func (b *Bytes) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return errNonString(bytesT)
 	}
-	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bytesT)
+	return WrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bytesT)
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler.
 func (b *Bytes) UnmarshalText(input []byte) error {
 	raw, err := checkText(input, true)
 	if err != nil {
-		return err
+		return AnnotateError(err, string(input), ExpectBytes)
 	}
 	dec := make([]byte, len(raw)/2)
 	if _, err = hex.Decode(dec, raw); err != nil {
@@ -64,7 +65,7 @@ func (b *Bytes) UnmarshalText(input []byte) error {
 	} else {
 		*b = dec
 	}
-	return err
+	return AnnotateError(err, string(input), ExpectBytes)
 }
 
 // String returns the hex encoding of b.
@@ -98,7 +99,7 @@ func UnmarshalFixedJSON(typ reflect.Type, input, out []byte) error {
 	if !isString(input) {
 		return errNonString(typ)
 	}
-	return wrapTypeError(UnmarshalFixedText(typ.String(), input[1:len(input)-1], out), typ)
+	return WrapTypeError(UnmarshalFixedText(typ.String(), input[1:len(input)-1], out), typ)
 }
 
 // UnmarshalFixedText decodes the input as a string with 0x prefix. The length of out
@@ -107,15 +108,15 @@ func UnmarshalFixedJSON(typ reflect.Type, input, out []byte) error {
 func UnmarshalFixedText(typname string, input, out []byte) error {
 	raw, err := checkText(input, true)
 	if err != nil {
-		return err
+		return AnnotateError(err, string(input), fixedExpectation(len(out), true))
 	}
 	if len(raw)/2 != len(out) {
-		return fmt.Errorf("hex string has length %d, want %d for %s", len(raw), len(out)*2, typname)
+		return NewLengthError(typname, input, len(raw), len(out)*2)
 	}
 	// Pre-verify syntax before modifying out.
 	for _, b := range raw {
 		if decodeNibble(b) == badNibble {
-			return ErrSyntax
+			return AnnotateError(ErrSyntax, string(input), fixedExpectation(len(out), true))
 		}
 	}
 	hex.Decode(out, raw)
@@ -128,15 +129,15 @@ func UnmarshalFixedText(typname string, input, out []byte) error {
 func UnmarshalFixedUnprefixedText(typname string, input, out []byte) error {
 	raw, err := checkText(input, false)
 	if err != nil {
-		return err
+		return AnnotateError(err, string(input), fixedExpectation(len(out), false))
 	}
 	if len(raw)/2 != len(out) {
-		return fmt.Errorf("hex string has length %d, want %d for %s", len(raw), len(out)*2, typname)
+		return NewLengthError(typname, input, len(raw), len(out)*2)
 	}
 	// Pre-verify syntax before modifying out.
 	for _, b := range raw {
 		if decodeNibble(b) == badNibble {
-			return ErrSyntax
+			return AnnotateError(ErrSyntax, string(input), fixedExpectation(len(out), false))
 		}
 	}
 	hex.Decode(out, raw)
@@ -161,17 +162,17 @@ func (b *Big) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return errNonString(bigT)
 	}
-	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bigT)
+	return WrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bigT)
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
 func (b *Big) UnmarshalText(input []byte) error {
 	raw, err := checkNumberText(input)
 	if err != nil {
-		return err
+		return AnnotateError(err, string(input), ExpectQuantity)
 	}
 	if len(raw) > 64 {
-		return ErrBig256Range
+		return AnnotateError(ErrBig256Range, string(input), ExpectQuantity)
 	}
 	words := make([]big.Word, len(raw)/bigWordNibbles+1)
 	end := len(raw)
@@ -183,7 +184,7 @@ func (b *Big) UnmarshalText(input []byte) error {
 		for ri := start; ri < end; ri++ {
 			nib := decodeNibble(raw[ri])
 			if nib == badNibble {
-				return ErrSyntax
+				return AnnotateError(ErrSyntax, string(input), ExpectQuantity)
 			}
 			words[i] *= 16
 			words[i] += big.Word(nib)
@@ -242,23 +243,23 @@ func (b *Uint64) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return errNonString(uint64T)
 	}
-	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), uint64T)
+	return WrapTypeError(b.UnmarshalText(input[1:len(input)-1]), uint64T)
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
 func (b *Uint64) UnmarshalText(input []byte) error {
 	raw, err := checkNumberText(input)
 	if err != nil {
-		return err
+		return AnnotateError(err, string(input), ExpectQuantity)
 	}
 	if len(raw) > 16 {
-		return ErrUint64Range
+		return AnnotateError(ErrUint64Range, string(input), ExpectQuantity)
 	}
 	var dec uint64
 	for _, byte := range raw {
 		nib := decodeNibble(byte)
 		if nib == badNibble {
-			return ErrSyntax
+			return AnnotateError(ErrSyntax, string(input), ExpectQuantity)
 		}
 		dec *= 16
 		dec += nib
@@ -303,15 +304,15 @@ func (b *Uint) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return errNonString(uintT)
 	}
-	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), uintT)
+	return WrapTypeError(b.UnmarshalText(input[1:len(input)-1]), uintT)
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler.
 func (b *Uint) UnmarshalText(input []byte) error {
 	var u64 Uint64
 	err := u64.UnmarshalText(input)
-	if u64 > Uint64(^uint(0)) || err == ErrUint64Range {
-		return ErrUintRange
+	if u64 > Uint64(^uint(0)) || errors.Is(err, ErrUint64Range) {
+		return AnnotateError(ErrUintRange, string(input), ExpectQuantity)
 	} else if err != nil {
 		return err
 	}
@@ -364,11 +365,12 @@ func checkNumberText(input []byte) (raw []byte, err error) {
 	return input, nil
 }
 
-func wrapTypeError(err error, typ reflect.Type) error {
-	if _, ok := err.(*decError); ok {
-		return &json.UnmarshalTypeError{Value: err.Error(), Type: typ}
+// fixedExpectation describes the input accepted for a fixed size type.
+func fixedExpectation(size int, prefixed bool) string {
+	if prefixed {
+		return fmt.Sprintf("0x prefixed hex string of %d digits", size*2)
 	}
-	return err
+	return fmt.Sprintf("hex string of %d digits", size*2)
 }
 
 func errNonString(typ reflect.Type) error {
@@ -64,10 +64,10 @@ var unmarshalBytesTests = []unmarshalTest{
 	{input: "", wantErr: errJSONEOF},
 	{input: "null", wantErr: errNonString(bytesT)},
 	{input: "10", wantErr: errNonString(bytesT)},
-	{input: `"0"`, wantErr: wrapTypeError(ErrMissingPrefix, bytesT)},
-	{input: `"0x0"`, wantErr: wrapTypeError(ErrOddLength, bytesT)},
-	{input: `"0xxx"`, wantErr: wrapTypeError(ErrSyntax, bytesT)},
-	{input: `"0x01zz01"`, wantErr: wrapTypeError(ErrSyntax, bytesT)},
+	{input: `"0"`, wantErr: WrapTypeError(ErrMissingPrefix, bytesT)},
+	{input: `"0x0"`, wantErr: WrapTypeError(ErrOddLength, bytesT)},
+	{input: `"0xxx"`, wantErr: WrapTypeError(ErrSyntax, bytesT)},
+	{input: `"0x01zz01"`, wantErr: WrapTypeError(ErrSyntax, bytesT)},
 
 	// valid encoding
 	{input: `""`, want: referenceBytes("")},
@@ -129,14 +129,14 @@ var unmarshalBigTests = []unmarshalTest{
 	{input: "", wantErr: errJSONEOF},
 	{input: "null", wantErr: errNonString(bigT)},
 	{input: "10", wantErr: errNonString(bigT)},
-	{input: `"0"`, wantErr: wrapTypeError(ErrMissingPrefix, bigT)},
-	{input: `"0x"`, wantErr: wrapTypeError(ErrEmptyNumber, bigT)},
-	{input: `"0x01"`, wantErr: wrapTypeError(ErrLeadingZero, bigT)},
-	{input: `"0xx"`, wantErr: wrapTypeError(ErrSyntax, bigT)},
-	{input: `"0x1zz01"`, wantErr: wrapTypeError(ErrSyntax, bigT)},
+	{input: `"0"`, wantErr: WrapTypeError(ErrMissingPrefix, bigT)},
+	{input: `"0x"`, wantErr: WrapTypeError(ErrEmptyNumber, bigT)},
+	{input: `"0x01"`, wantErr: WrapTypeError(ErrLeadingZero, bigT)},
+	{input: `"0xx"`, wantErr: WrapTypeError(ErrSyntax, bigT)},
+	{input: `"0x1zz01"`, wantErr: WrapTypeError(ErrSyntax, bigT)},
 	{
 		input:   `"0x10000000000000000000000000000000000000000000000000000000000000000"`,
-		wantErr: wrapTypeError(ErrBig256Range, bigT),
+		wantErr: WrapTypeError(ErrBig256Range, bigT),
 	},
 
 	// valid encoding
@@ -210,12 +210,12 @@ var unmarshalUint64Tests = []unmarshalTest{
 	{input: "", wantErr: errJSONEOF},
 	{input: "null", wantErr: errNonString(uint64T)},
 	{input: "10", wantErr: errNonString(uint64T)},
-	{input: `"0"`, wantErr: wrapTypeError(ErrMissingPrefix, uint64T)},
-	{input: `"0x"`, wantErr: wrapTypeError(ErrEmptyNumber, uint64T)},
-	{input: `"0x01"`, wantErr: wrapTypeError(ErrLeadingZero, uint64T)},
-	{input: `"0xfffffffffffffffff"`, wantErr: wrapTypeError(ErrUint64Range, uint64T)},
-	{input: `"0xx"`, wantErr: wrapTypeError(ErrSyntax, uint64T)},
-	{input: `"0x1zz01"`, wantErr: wrapTypeError(ErrSyntax, uint64T)},
+	{input: `"0"`, wantErr: WrapTypeError(ErrMissingPrefix, uint64T)},
+	{input: `"0x"`, wantErr: WrapTypeError(ErrEmptyNumber, uint64T)},
+	{input: `"0x01"`, wantErr: WrapTypeError(ErrLeadingZero, uint64T)},
+	{input: `"0xfffffffffffffffff"`, wantErr: WrapTypeError(ErrUint64Range, uint64T)},
+	{input: `"0xx"`, wantErr: WrapTypeError(ErrSyntax, uint64T)},
+	{input: `"0x1zz01"`, wantErr: WrapTypeError(ErrSyntax, uint64T)},
 
 	// valid encoding
 	{input: `""`, want: uint64(0)},
@@ -300,13 +300,13 @@ var unmarshalUintTests = []unmarshalTest{
 	{input: "", wantErr: errJSONEOF},
 	{input: "null", wantErr: errNonString(uintT)},
 	{input: "10", wantErr: errNonString(uintT)},
-	{input: `"0"`, wantErr: wrapTypeError(ErrMissingPrefix, uintT)},
-	{input: `"0x"`, wantErr: wrapTypeError(ErrEmptyNumber, uintT)},
-	{input: `"0x01"`, wantErr: wrapTypeError(ErrLeadingZero, uintT)},
-	{input: `"0x100000000"`, want: uint(maxUint33bits), wantErr32bit: wrapTypeError(ErrUintRange, uintT)},
-	{input: `"0xfffffffffffffffff"`, wantErr: wrapTypeError(ErrUintRange, uintT)},
-	{input: `"0xx"`, wantErr: wrapTypeError(ErrSyntax, uintT)},
-	{input: `"0x1zz01"`, wantErr: wrapTypeError(ErrSyntax, uintT)},
+	{input: `"0"`, wantErr: WrapTypeError(ErrMissingPrefix, uintT)},
+	{input: `"0x"`, wantErr: WrapTypeError(ErrEmptyNumber, uintT)},
+	{input: `"0x01"`, wantErr: WrapTypeError(ErrLeadingZero, uintT)},
+	{input: `"0x100000000"`, want: uint(maxUint33bits), wantErr32bit: WrapTypeError(ErrUintRange, uintT)},
+	{input: `"0xfffffffffffffffff"`, wantErr: WrapTypeError(ErrUintRange, uintT)},
+	{input: `"0xx"`, wantErr: WrapTypeError(ErrSyntax, uintT)},
+	{input: `"0x1zz01"`, wantErr: WrapTypeError(ErrSyntax, uintT)},
 
 	// valid encoding
 	{input: `""`, want: uint(0)},
@@ -317,7 +317,7 @@ var unmarshalUintTests = []unmarshalTest{
 	{input: `"0x1122aaff"`, want: uint(0x1122aaff)},
 	{input: `"0xbbb"`, want: uint(0xbbb)},
 	{input: `"0xffffffff"`, want: uint(0xffffffff)},
-	{input: `"0xffffffffffffffff"`, want: uint(maxUint64bits), wantErr32bit: wrapTypeError(ErrUintRange, uintT)},
+	{input: `"0xffffffffffffffff"`, want: uint(maxUint64bits), wantErr32bit: WrapTypeError(ErrUintRange, uintT)},
 }
 
 func TestUnmarshalUint(t *testing.T) {
@@ -372,3 +372,52 @@ func TestUnmarshalFixedUnprefixedText(t *testing.T) {
 		}
 	}
 }
+
+func TestBinaryUint64(t *testing.T) {
+	for _, n := range []uint64{0, 1, 0x1122334455667788, ^uint64(0)} {
+		var (
+			in       = Uint64(n)
+			ssz, _   = in.MarshalSSZ()
+			proto, _ = in.Marshal()
+			dec      Uint64
+		)
+		if err := dec.UnmarshalSSZ(ssz); err != nil || dec != in {
+			t.Errorf("%d: SSZ round trip mismatch: %d (%v)", n, dec, err)
+		}
+		if root, _ := in.HashTreeRoot(); !bytes.Equal(root[:8], ssz) {
+			t.Errorf("%d: hash tree root mismatch: %x", n, root)
+		}
+		dec = 0
+		if err := dec.Unmarshal(proto); err != nil || dec != in {
+			t.Errorf("%d: protobuf round trip mismatch: %d (%v)", n, dec, err)
+		}
+	}
+}
+
+func TestBinaryBig(t *testing.T) {
+	for _, n := range []*big.Int{big.NewInt(0), big.NewInt(1), referenceBig("112233445566778899aabbccddeeff"), referenceBig("ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")} {
+		in := (*Big)(n)
+		ssz, err := in.MarshalSSZ()
+		if err != nil || len(ssz) != 32 || (n.Sign() > 0 && ssz[0] != byte(n.Uint64())) {
+			t.Fatalf("%v: SSZ encoding mismatch: %x (%v)", n, ssz, err)
+		}
+		var dec Big
+		if err := dec.UnmarshalSSZ(ssz); err != nil || dec.ToInt().Cmp(n) != 0 {
+			t.Errorf("%v: SSZ round trip mismatch: %v (%v)", n, dec.ToInt(), err)
+		}
+		proto, err := in.Marshal()
+		if err != nil || len(proto) != in.Size() {
+			t.Fatalf("%v: protobuf encoding mismatch: %x (%v)", n, proto, err)
+		}
+		dec = Big{}
+		if err := dec.Unmarshal(proto); err != nil || dec.ToInt().Cmp(n) != 0 {
+			t.Errorf("%v: protobuf round trip mismatch: %v (%v)", n, dec.ToInt(), err)
+		}
+	}
+	if _, err := (*Big)(big.NewInt(-1)).MarshalSSZ(); err == nil {
+		t.Errorf("negative integer encoded")
+	}
+	if _, err := (*Big)(new(big.Int).Lsh(big.NewInt(1), 256)).Marshal(); err != ErrBig256Range {
+		t.Errorf("oversized integer error mismatch: %v", err)
+	}
+}
@@ -19,11 +19,14 @@ package common
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math/big"
 	"reflect"
 	"strconv"
 	"strings"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
 )
 
 var (
@@ -50,7 +53,7 @@ func (b *Bytes) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return errNonString(bytesT)
 	}
-	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bytesT)
+	return hexutil.WrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bytesT)
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler.
@@ -101,11 +104,11 @@ func UnmarshalFixedJSON(typ reflect.Type, input, out []byte) error {
 	}
 	if strings.Compare(typ.String(), "common.Address") == 0 {
 		if !IsHexFFFAddress(string(input)) {
-			s := HexToAddress(FFFAddressDecode(string(input)))
-			return wrapTypeError(UnmarshalFixedText(typ.String(), []byte(s.hex()), out), typ)
+			s := HexToAddress(decodeFFFAddress(string(input)))
+			return hexutil.WrapTypeError(UnmarshalFixedText(typ.String(), []byte(s.hex()), out), typ)
 		}
 	}
-	return wrapTypeError(UnmarshalFixedText(typ.String(), input[1:len(input)-1], out), typ)
+	return hexutil.WrapTypeError(UnmarshalFixedText(typ.String(), input[1:len(input)-1], out), typ)
 }
 
 // UnmarshalFixedText decodes the input as a string with 0x prefix. The length of out
@@ -170,7 +173,7 @@ func (b *Big) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return errNonString(bigT)
 	}
-	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bigT)
+	return hexutil.WrapTypeError(b.UnmarshalText(input[1:len(input)-1]), bigT)
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
@@ -251,7 +254,7 @@ func (b *Uint64) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return errNonString(uint64T)
 	}
-	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), uint64T)
+	return hexutil.WrapTypeError(b.UnmarshalText(input[1:len(input)-1]), uint64T)
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
@@ -312,14 +315,14 @@ func (b *Uint) UnmarshalJSON(input []byte) error {
 	if !isString(input) {
 		return errNonString(uintT)
 	}
-	return wrapTypeError(b.UnmarshalText(input[1:len(input)-1]), uintT)
+	return hexutil.WrapTypeError(b.UnmarshalText(input[1:len(input)-1]), uintT)
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler.
 func (b *Uint) UnmarshalText(input []byte) error {
 	var u64 Uint64
 	err := u64.UnmarshalText(input)
-	if u64 > Uint64(^uint(0)) || err == ErrUint64Range {
+	if u64 > Uint64(^uint(0)) || errors.Is(err, ErrUint64Range) {
 		return ErrUintRange
 	} else if err != nil {
 		return err
@@ -373,13 +376,6 @@ func checkNumberText(input []byte) (raw []byte, err error) {
 	return input, nil
 }
 
-func wrapTypeError(err error, typ reflect.Type) error {
-	if _, ok := err.(*decError); ok {
-		return &json.UnmarshalTypeError{Value: err.Error(), Type: typ}
-	}
-	return err
-}
-
 func errNonString(typ reflect.Type) error {
 	return &json.UnmarshalTypeError{Value: "non-string", Type: typ}
 }
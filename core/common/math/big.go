@@ -53,7 +53,7 @@ func NewHexOrDecimal256(x int64) *HexOrDecimal256 {
 func (i *HexOrDecimal256) UnmarshalText(input []byte) error {
 	bigint, ok := ParseBig256(string(input))
 	if !ok {
-		return fmt.Errorf("invalid hex or decimal integer %q", input)
+		return integerError(input)
 	}
 	*i = HexOrDecimal256(*bigint)
 	return nil
@@ -82,7 +82,7 @@ func NewDecimal256(x int64) *Decimal256 {
 func (i *Decimal256) UnmarshalText(input []byte) error {
 	bigint, ok := ParseBig256(string(input))
 	if !ok {
-		return fmt.Errorf("invalid hex or decimal integer %q", input)
+		return integerError(input)
 	}
 	*i = Decimal256(*bigint)
 	return nil
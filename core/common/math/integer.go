@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"math/bits"
 	"strconv"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
 )
 
 // Integer limit values.
@@ -38,6 +40,16 @@ const (
 	MaxUint64 = 1<<64 - 1
 )
 
+// ErrInteger is returned when unmarshalling a malformed or out of range hex or
+// decimal integer.
+var ErrInteger = hexutil.NewErrorClass(hexutil.CodeSyntax, "invalid hex or decimal integer")
+
+// integerError returns the error of input not parsing as an integer.
+func integerError(input []byte) error {
+	err := fmt.Errorf("%w %q", ErrInteger, input)
+	return hexutil.AnnotateError(err, string(input), "0x prefixed hex or decimal integer")
+}
+
 // HexOrDecimal64 marshals uint64 as hex or decimal.
 type HexOrDecimal64 uint64
 
@@ -45,7 +57,7 @@ type HexOrDecimal64 uint64
 func (i *HexOrDecimal64) UnmarshalText(input []byte) error {
 	int, ok := ParseUint64(string(input))
 	if !ok {
-		return fmt.Errorf("invalid hex or decimal integer %q", input)
+		return integerError(input)
 	}
 	*i = HexOrDecimal64(int)
 	return nil
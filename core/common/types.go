@@ -22,9 +22,11 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash"
 	"math/big"
 	"math/rand"
 	"reflect"
+	"sync"
 
 	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
 
@@ -219,14 +221,14 @@ func BigToAddress(b *big.Int) Address {
 // HexToAddress returns Address with byte values of s.
 // If s is larger than len(h), s will be cropped from the left.
 func HexToAddress(s string) Address {
-	s = FFFAddressDecode(s)
+	s = decodeFFFAddress(s)
 	return BytesToAddress(FromHex(s))
 }
 
 // IsHexAddress verifies whether a string can represent a valid hex-encoded
 // Ethereum address or not.
 func IsHexAddress(s string) bool {
-	s = FFFAddressDecode(s)
+	s = decodeFFFAddress(s)
 	if has0xPrefix(s) {
 		s = s[2:]
 	}
@@ -246,18 +248,88 @@ func (a Address) Hex() string {
 
 // String implements fmt.Stringer.
 func (a Address) String() string {
-	return FFFAddressEncode(string(a.checksumHex()))
+	c := currentAddressCache()
+	if c == nil {
+		return a.fff()
+	}
+	if enc, ok := cachedAddress(c.encode, string(a[:])); ok {
+		return enc
+	}
+	enc := a.fff()
+	c.encode.Add(string(a[:]), enc)
+	return enc
+}
+
+// fff renders the FFF form of the address, with no allocations but the one of
+// the returned string.
+func (a Address) fff() string {
+	var buf [maxFFFLength]byte
+	return string(a.AppendFFF(buf[:0]))
+}
+
+// AppendFFF appends the FFF form of the address, as returned by String and Hex,
+// to dst. It doesn't allocate if dst has room for the address, so callers
+// rendering lots of addresses can reuse their buffers.
+func (a Address) AppendFFF(dst []byte) []byte {
+	recordAddressEncode()
+
+	var text [2 * AddressLength]byte
+	hex.Encode(text[:], a[:])
+
+	dst = append(dst, AddressPrefix()...)
+	return appendBase58(dst, text[:])
+}
+
+// AppendEIP55 appends the EIP-55 checksummed hex form of the address to dst,
+// unlike Hex which returns the FFF form. It doesn't allocate if dst has room
+// for the address.
+func (a Address) AppendEIP55(dst []byte) []byte {
+	var buf [2*AddressLength + 2]byte
+	a.encodeChecksumHex(&buf)
+	return append(dst, buf[:]...)
 }
 
 func (a *Address) checksumHex() []byte {
-	buf := a.hex()
+	buf := new([2*AddressLength + 2]byte)
+	a.encodeChecksumHex(buf)
+	return buf[:]
+}
+
+// checksumHasher is the reusable state of computing EIP-55 checksums.
+type checksumHasher struct {
+	state keccakState
+	text  [2 * AddressLength]byte
+	hash  [HashLength]byte
+}
+
+// keccakState wraps sha3.state, which can read out the hash without the
+// allocation of Sum.
+type keccakState interface {
+	hash.Hash
+	Read([]byte) (int, error)
+}
 
-	// compute checksum
-	sha := sha3.NewLegacyKeccak256()
-	sha.Write(buf[2:])
-	hash := sha.Sum(nil)
+var checksumHashers = sync.Pool{
+	New: func() interface{} {
+		return &checksumHasher{state: sha3.NewLegacyKeccak256().(keccakState)}
+	},
+}
+
+// encodeChecksumHex writes the EIP-55 checksummed hex form of the address into
+// buf. The hashing is done in pooled state, so buf doesn't escape.
+func (a *Address) encodeChecksumHex(buf *[2*AddressLength + 2]byte) {
+	h := checksumHashers.Get().(*checksumHasher)
+	defer checksumHashers.Put(h)
+
+	hex.Encode(h.text[:], a[:])
+	h.state.Reset()
+	h.state.Write(h.text[:])
+	h.state.Read(h.hash[:])
+
+	copy(buf[:2], "0x")
+	copy(buf[2:], h.text[:])
 	for i := 2; i < len(buf); i++ {
-		hashByte := hash[(i-2)/2]
+		hashByte := h.hash[(i-2)/2]
 		if i%2 == 0 {
 			hashByte = hashByte >> 4
 		} else {
@@ -267,7 +339,6 @@ func (a *Address) checksumHex() []byte {
 			buf[i] -= 32
 		}
 	}
-	return buf[:]
 }
 
 func (a Address) hex() []byte {
@@ -324,10 +395,19 @@ func (a Address) MarshalText() ([]byte, error) {
 
 // UnmarshalText parses a hash in hex syntax.
 func (a *Address) UnmarshalText(input []byte) error {
+	err := hexutil.AnnotateError(a.unmarshalText(input), string(input), expectAddress)
+	recordAddressUnmarshal(string(input), a, err)
+	return err
+}
+
+func (a *Address) unmarshalText(input []byte) error {
+	if err := CheckAddressNetwork(string(input)); err != nil {
+		return err
+	}
 	if !IsHexAddress(string(input)) {
 		return hexutil.UnmarshalFixedText("Address", input, a[:])
 	}
-	input = []byte(FFFAddressDecode(string(input)))
+	input = []byte(decodeFFFAddress(string(input)))
 	return hexutil.UnmarshalFixedText("Address", input, a[:])
 }
 
@@ -339,11 +419,26 @@ func (a *Address) UnmarshalText(input []byte) error {
 // }
 
 func (a *Address) UnmarshalJSON(input []byte) error {
+	err := a.unmarshalJSON(input)
+	if isString(input) {
+		recordAddressUnmarshal(string(input[1:len(input)-1]), a, err)
+	} else {
+		recordAddressUnmarshal(string(input), a, err)
+	}
+	return err
+}
+
+func (a *Address) unmarshalJSON(input []byte) error {
 	newS := string(input)
+	if isString(input) {
+		if err := CheckAddressNetwork(newS[1 : len(newS)-1]); err != nil {
+			return hexutil.AnnotateError(err, newS[1:len(newS)-1], expectAddress)
+		}
+	}
 	if !IsHexAddress(newS[1 : len(newS)-1]) {
 		return hexutil.UnmarshalFixedJSON(addressT, input, a[:])
 	}
-	input = []byte(`"` + FFFAddressDecode(newS[1:len(newS)-1]) + `"`)
+	input = []byte(`"` + decodeFFFAddress(newS[1:len(newS)-1]) + `"`)
 	return hexutil.UnmarshalFixedJSON(addressT, input, a[:])
 }
 
@@ -161,6 +161,46 @@ func BenchmarkAddressHex(b *testing.B) {
 	}
 }
 
+// Tests that the append APIs render the FFF and EIP-55 forms without
+// allocating.
+func TestAddressAppend(t *testing.T) {
+	addr := BytesToAddress(FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+
+	if have, want := string(addr.AppendEIP55(nil)), "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"; have != want {
+		t.Errorf("EIP-55 mismatch: have %s, want %s", have, want)
+	}
+	if have, want := string(addr.AppendFFF([]byte("to="))), "to="+FFFAddressEncode(string(addr.hex())); have != want {
+		t.Errorf("FFF mismatch: have %s, want %s", have, want)
+	}
+	buf := make([]byte, 0, 128)
+	if allocs := testing.AllocsPerRun(100, func() { buf = addr.AppendEIP55(buf[:0]) }); allocs != 0 {
+		t.Errorf("AppendEIP55 allocated %v times", allocs)
+	}
+	if allocs := testing.AllocsPerRun(100, func() { buf = addr.AppendFFF(buf[:0]) }); allocs != 0 {
+		t.Errorf("AppendFFF allocated %v times", allocs)
+	}
+}
+
+func BenchmarkAddressAppendFFF(b *testing.B) {
+	testAddr := BytesToAddress(FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	buf := make([]byte, 0, 128)
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		buf = testAddr.AppendFFF(buf[:0])
+	}
+}
+
+func BenchmarkAddressAppendEIP55(b *testing.B) {
+	testAddr := BytesToAddress(FromHex("0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"))
+	buf := make([]byte, 0, 128)
+
+	b.ReportAllocs()
+	for n := 0; n < b.N; n++ {
+		buf = testAddr.AppendEIP55(buf[:0])
+	}
+}
+
 func TestMixedcaseAccount_Address(t *testing.T) {
 
 	// https://github.com/ethereum/EIPs/blob/master/EIPS/eip-55.md
@@ -544,3 +584,67 @@ func TestHash_Format(t *testing.T) {
 		})
 	}
 }
+
+func TestAddressBinaryEncoding(t *testing.T) {
+	addr := HexToAddress("FFF6672WbdorrmkMpavk1S5ALpoN82XpSirbMWZicxhhqqNeromt65d6TE")
+
+	ssz, err := addr.MarshalSSZ()
+	if err != nil || !bytes.Equal(ssz, addr[:]) {
+		t.Fatalf("SSZ encoding mismatch: %x (%v)", ssz, err)
+	}
+	var dec Address
+	if err := dec.UnmarshalSSZ(ssz); err != nil || dec != addr {
+		t.Fatalf("SSZ round trip mismatch: %v (%v)", dec, err)
+	}
+	if err := dec.UnmarshalSSZ(ssz[1:]); err == nil {
+		t.Errorf("short SSZ address accepted")
+	}
+	if root, _ := addr.HashTreeRoot(); !bytes.Equal(root[:AddressLength], addr[:]) || root[AddressLength] != 0 {
+		t.Errorf("hash tree root mismatch: %x", root)
+	}
+	proto, err := addr.Marshal()
+	if err != nil || len(proto) != addr.Size() {
+		t.Fatalf("protobuf encoding mismatch: %x (%v)", proto, err)
+	}
+	dec = Address{}
+	if err := dec.Unmarshal(proto); err != nil || dec != addr {
+		t.Fatalf("protobuf round trip mismatch: %v (%v)", dec, err)
+	}
+	if err := dec.Unmarshal(nil); err != nil || dec != (Address{}) {
+		t.Errorf("empty protobuf field not decoded as zero address: %v (%v)", dec, err)
+	}
+	// The JSON form must keep the FFF display format
+	blob, err := json.Marshal(addr)
+	if err != nil || string(blob) != `"`+addr.String()+`"` {
+		t.Fatalf("JSON encoding mismatch: %s (%v)", blob, err)
+	}
+	dec = Address{}
+	if err := json.Unmarshal(blob, &dec); err != nil || dec != addr {
+		t.Fatalf("JSON round trip mismatch: %v (%v)", dec, err)
+	}
+}
+
+func TestHashBinaryEncoding(t *testing.T) {
+	hash := HexToHash("0x2c7536e3605d9c16a7a3d7b1898e529396a65c23a3a7ea0a6e96bbd8b4d4fa04")
+
+	ssz, _ := hash.MarshalSSZ()
+	var dec Hash
+	if err := dec.UnmarshalSSZ(ssz); err != nil || dec != hash {
+		t.Fatalf("SSZ round trip mismatch: %v (%v)", dec, err)
+	}
+	if root, _ := hash.HashTreeRoot(); root != hash {
+		t.Errorf("hash tree root mismatch: %x", root)
+	}
+	proto, _ := hash.Marshal()
+	dec = Hash{}
+	if err := dec.Unmarshal(proto); err != nil || dec != hash {
+		t.Fatalf("protobuf round trip mismatch: %v (%v)", dec, err)
+	}
+	if err := dec.Unmarshal(proto[:10]); err == nil {
+		t.Errorf("short protobuf hash accepted")
+	}
+	blob, _ := json.Marshal(hash)
+	if string(blob) != `"`+hash.Hex()+`"` {
+		t.Errorf("JSON encoding mismatch: %s", blob)
+	}
+}
@@ -0,0 +1,148 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode"
+)
+
+// ErrUnknownLocale is returned if a number format is looked up by an unknown
+// name.
+var ErrUnknownLocale = errors.New("unknown locale")
+
+// Locale is a convention of writing decimal numbers.
+type Locale struct {
+	Name  string
+	Group string // Separator between the thousands of the whole part, empty for none
+	Point string // Separator between the whole and the fractional part
+}
+
+// The supported number formats.
+var (
+	Plain   = Locale{"plain", "", "."}
+	English = Locale{"en", ",", "."}
+	German  = Locale{"de", ".", ","}
+	French  = Locale{"fr", "\u202f", ","}
+	Swiss   = Locale{"ch", "'", "."}
+)
+
+// Locales lists all the supported number formats.
+var Locales = []Locale{Plain, English, German, French, Swiss}
+
+// String implements fmt.Stringer.
+func (l Locale) String() string { return l.Name }
+
+// LookupLocale finds a number format by its name, case insensitively.
+func LookupLocale(name string) (Locale, error) {
+	for _, locale := range Locales {
+		if strings.EqualFold(locale.Name, name) {
+			return locale, nil
+		}
+	}
+	return Locale{}, fmt.Errorf("%w: %q", ErrUnknownLocale, name)
+}
+
+// Style describes how amounts are rendered for humans. The zero style with a
+// unit set renders amounts exactly, like Format.
+type Style struct {
+	Unit     Unit         // Denomination amounts are shown in
+	Decimals int          // Number of fractional digits to round to, negative for all
+	Mode     RoundingMode // Rounding of the digits beyond Decimals, RoundExact keeps them
+	Pad      bool         // Whether to pad the fractional part to Decimals with zeros
+	Suffix   bool         // Whether to append the name of the unit
+	Locale   Locale       // Separators of the rendered number, Plain if unset
+}
+
+// Format renders an amount of base units in the style.
+func (s Style) Format(wei *big.Int) string {
+	// Round the amount to the displayed digits if allowed, or keep them all
+	value, decimals := wei, s.Unit.Decimals
+	if s.Decimals >= 0 && s.Decimals < s.Unit.Decimals && s.Mode != RoundExact {
+		var err error
+		if value, err = FromWei(wei, Unit{Decimals: s.Unit.Decimals - s.Decimals}, s.Mode); err != nil {
+			value = wei // unknown rounding mode, render exactly
+		} else {
+			decimals = s.Decimals
+		}
+	}
+	digits := new(big.Int).Abs(value).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-decimals], strings.TrimRight(digits[len(digits)-decimals:], "0")
+	if s.Pad && len(frac) < s.Decimals {
+		frac += strings.Repeat("0", s.Decimals-len(frac))
+	}
+	var b strings.Builder
+	if value.Sign() < 0 {
+		b.WriteByte('-')
+	}
+	for i := 0; i < len(whole); i++ {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(s.Locale.Group)
+		}
+		b.WriteByte(whole[i])
+	}
+	if frac != "" {
+		b.WriteString(s.point())
+		b.WriteString(frac)
+	}
+	if s.Suffix {
+		b.WriteByte(' ')
+		b.WriteString(s.Unit.Name)
+	}
+	return b.String()
+}
+
+// Parse parses an amount rendered in the style back into base units, exactly.
+// The unit suffix is optional, amounts without one are in the unit of the
+// style. Misplaced group separators are rejected, so that amounts written in
+// another locale aren't silently misread.
+func (s Style) Parse(input string) (*big.Int, error) {
+	number := strings.TrimSpace(input)
+
+	// Split off the unit following the number
+	unit := s.Unit
+	if end := strings.LastIndexFunc(number, func(c rune) bool { return !unicode.IsLetter(c) }); end+1 < len(number) {
+		var err error
+		if unit, err = LookupUnit(number[end+1:]); err != nil {
+			return nil, err
+		}
+		number = strings.TrimSpace(number[:end+1])
+	}
+	sign := ""
+	if strings.HasPrefix(number, "-") || strings.HasPrefix(number, "+") {
+		sign, number = number[:1], number[1:]
+	}
+	if strings.Count(number, s.point()) > 1 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, input)
+	}
+	whole, frac := number, ""
+	if point := strings.Index(number, s.point()); point >= 0 {
+		whole, frac = number[:point], number[point+len(s.point()):]
+	}
+	// Drop the group separators after checking they're all in place
+	if s.Locale.Group != "" && strings.Contains(whole, s.Locale.Group) {
+		groups := strings.Split(whole, s.Locale.Group)
+		for i, group := range groups {
+			if i == 0 && (len(group) == 0 || len(group) > 3) || i > 0 && len(group) != 3 {
+				return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, input)
+			}
+		}
+		whole = strings.Join(groups, "")
+	}
+	if !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, input)
+	}
+	return ToWei(sign+whole+"."+frac, unit, RoundExact)
+}
+
+// point returns the decimal point of the style.
+func (s Style) point() string {
+	if s.Locale.Point == "" {
+		return "."
+	}
+	return s.Locale.Point
+}
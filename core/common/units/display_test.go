@@ -0,0 +1,109 @@
+package units
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestStyleFormat(t *testing.T) {
+	tests := []struct {
+		wei   string
+		style Style
+		want  string
+	}{
+		{"1234567891200000000000", Style{Unit: FFF}, "1234.5678912"},
+		{"1234567891200000000000", Style{Unit: FFF, Locale: English}, "1,234.5678912"},
+		{"1234567891200000000000", Style{Unit: FFF, Locale: German, Suffix: true}, "1.234,5678912 FFF"},
+		{"1234567891200000000000", Style{Unit: FFF, Locale: French}, "1\u202f234,5678912"},
+		{"1234567891200000000000", Style{Unit: FFF, Locale: Swiss}, "1'234.5678912"},
+		{"1234567891200000000000", Style{Unit: FFF, Decimals: 2}, "1234.5678912"},
+		{"1234567891200000000000", Style{Unit: FFF, Decimals: 2, Mode: RoundHalfEven}, "1234.57"},
+		{"1234567891200000000000", Style{Unit: FFF, Decimals: 0, Mode: RoundDown}, "1234"},
+		{"1500000000000000000", Style{Unit: FFF, Decimals: 4, Mode: RoundHalfEven}, "1.5"},
+		{"1500000000000000000", Style{Unit: FFF, Decimals: 4, Mode: RoundHalfEven, Pad: true}, "1.5000"},
+		{"1500000000000000000", Style{Unit: FFF, Decimals: 4, Pad: true}, "1.5000"},
+		{"1", Style{Unit: FFF, Decimals: 6, Mode: RoundHalfEven}, "0"},
+		{"1", Style{Unit: FFF}, "0.000000000000000001"},
+		{"-1", Style{Unit: FFF, Decimals: 6, Mode: RoundHalfEven}, "0"},
+		{"-2500000000000000000000", Style{Unit: FFF, Decimals: 0, Mode: RoundHalfUp, Locale: English}, "-2,500"},
+		{"123456789", Style{Unit: Wei, Locale: English, Suffix: true}, "123,456,789 wei"},
+		{"123456", Style{Unit: Wei, Locale: English}, "123,456"},
+		{"0", Style{Unit: GWei, Decimals: 2, Pad: true, Suffix: true}, "0.00 gwei"},
+	}
+	for _, tt := range tests {
+		wei, _ := new(big.Int).SetString(tt.wei, 10)
+		if have := tt.style.Format(wei); have != tt.want {
+			t.Errorf("%s %+v: format mismatch: have %q, want %q", tt.wei, tt.style, have, tt.want)
+		}
+	}
+}
+
+func TestStyleParse(t *testing.T) {
+	tests := []struct {
+		input string
+		style Style
+		want  string
+		err   error
+	}{
+		{input: "1,234.5", style: Style{Unit: FFF, Locale: English}, want: "1234500000000000000000"},
+		{input: "1.234,5 FFF", style: Style{Unit: FFF, Locale: German}, want: "1234500000000000000000"},
+		{input: "1.234,5 gwei", style: Style{Unit: FFF, Locale: German}, want: "1234500000000"},
+		{input: "1\u202f234,5", style: Style{Unit: FFF, Locale: French}, want: "1234500000000000000000"},
+		{input: "-1'000", style: Style{Unit: Wei, Locale: Swiss}, want: "-1000"},
+		{input: "1000.25", style: Style{Unit: FFF, Locale: English}, want: "1000250000000000000000"},
+		{input: "1,5", style: Style{Unit: FFF, Locale: English}, err: ErrInvalidAmount},
+		{input: "1.5", style: Style{Unit: FFF, Locale: German}, err: ErrInvalidAmount},
+		{input: "1234,567", style: Style{Unit: FFF, Locale: English}, err: ErrInvalidAmount},
+		{input: ",123", style: Style{Unit: FFF, Locale: English}, err: ErrInvalidAmount},
+		{input: "1,2,3", style: Style{Unit: FFF, Locale: German}, err: ErrInvalidAmount},
+		{input: "1.5 wei", style: Style{Unit: FFF}, err: ErrPrecisionLoss},
+		{input: "1.5 ether", style: Style{Unit: FFF}, err: ErrUnknownUnit},
+		{input: "FFF", style: Style{Unit: FFF}, err: ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		have, err := tt.style.Parse(tt.input)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%q: error mismatch: have %v, want %v", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: failed to parse: %v", tt.input, err)
+			continue
+		}
+		if have.String() != tt.want {
+			t.Errorf("%q: value mismatch: have %v, want %s", tt.input, have, tt.want)
+		}
+	}
+}
+
+// Tests that amounts rendered without rounding parse back exactly in every
+// locale and unit.
+func TestStyleRoundTrip(t *testing.T) {
+	amounts := []string{"0", "1", "-1", "999", "1000", "-123456789012345678901234567890", "1500000000000000000"}
+	for _, locale := range Locales {
+		for _, unit := range Units {
+			for _, pad := range []bool{false, true} {
+				style := Style{Unit: unit, Decimals: 3, Pad: pad, Suffix: pad, Locale: locale}
+				for _, amount := range amounts {
+					wei, _ := new(big.Int).SetString(amount, 10)
+					text := style.Format(wei)
+					if back, err := style.Parse(text); err != nil || back.Cmp(wei) != 0 {
+						t.Errorf("%s %s %s: round trip mismatch of %q: have %v, %v", amount, unit, locale, text, back, err)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLookupLocale(t *testing.T) {
+	if locale, err := LookupLocale("DE"); err != nil || locale != German {
+		t.Errorf("locale mismatch: have %v, %v, want %v", locale, err, German)
+	}
+	if _, err := LookupLocale("xx"); !errors.Is(err, ErrUnknownLocale) {
+		t.Errorf("error mismatch: have %v, want %v", err, ErrUnknownLocale)
+	}
+}
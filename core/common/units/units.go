@@ -0,0 +1,226 @@
+// Package units converts amounts of the FFF native token between its base unit
+// (wei) and the denominations above it, without ever going through floating
+// point.
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var (
+	// ErrInvalidAmount is returned if an amount can't be parsed.
+	ErrInvalidAmount = errors.New("invalid amount")
+
+	// ErrUnknownUnit is returned if an amount carries an unknown denomination.
+	ErrUnknownUnit = errors.New("unknown unit")
+
+	// ErrPrecisionLoss is returned if a conversion isn't exact and the rounding
+	// mode doesn't allow rounding.
+	ErrPrecisionLoss = errors.New("amount not representable without precision loss")
+)
+
+// Unit is a denomination of the native token, worth 10^Decimals base units.
+type Unit struct {
+	Name     string
+	Decimals int
+}
+
+// The denominations of the native token.
+var (
+	Wei      = Unit{"wei", 0}
+	KWei     = Unit{"kwei", 3}
+	MWei     = Unit{"mwei", 6}
+	GWei     = Unit{"gwei", 9}
+	MicroFFF = Unit{"microFFF", 12}
+	MilliFFF = Unit{"milliFFF", 15}
+	FFF      = Unit{"FFF", 18}
+)
+
+// Units lists all the denominations from the smallest to the largest.
+var Units = []Unit{Wei, KWei, MWei, GWei, MicroFFF, MilliFFF, FFF}
+
+// String implements fmt.Stringer.
+func (u Unit) String() string { return u.Name }
+
+// Multiplier returns the number of base units a unit is worth.
+func (u Unit) Multiplier() *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(u.Decimals)), nil)
+}
+
+// LookupUnit finds a denomination by its name, case insensitively.
+func LookupUnit(name string) (Unit, error) {
+	for _, unit := range Units {
+		if strings.EqualFold(unit.Name, name) {
+			return unit, nil
+		}
+	}
+	return Unit{}, fmt.Errorf("%w: %q", ErrUnknownUnit, name)
+}
+
+// RoundingMode selects how conversions treat digits below the base unit, or
+// below the target unit when converting to a larger one.
+type RoundingMode int
+
+const (
+	// RoundExact rejects conversions losing precision with ErrPrecisionLoss.
+	RoundExact RoundingMode = iota
+
+	// RoundHalfEven rounds to the nearest value, ties to the even one
+	// (banker's rounding).
+	RoundHalfEven
+
+	// RoundHalfUp rounds to the nearest value, ties away from zero.
+	RoundHalfUp
+
+	// RoundDown truncates towards zero.
+	RoundDown
+)
+
+// Parse parses an amount such as "1.5 FFF", "20gwei" or "1000" into base
+// units. Amounts without a unit are in the default unit; 0x prefixed hex
+// integers are always in base units. Digits below the base unit are handled
+// according to the rounding mode.
+func Parse(s string, def Unit, mode RoundingMode) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		value, ok := new(big.Int).SetString(s[2:], 16)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+		}
+		return value, nil
+	}
+	// Split off the unit following the number
+	end := strings.IndexFunc(s, func(c rune) bool {
+		return !('0' <= c && c <= '9' || c == '.' || c == '-' || c == '+')
+	})
+	unit := def
+	if end >= 0 {
+		var err error
+		if unit, err = LookupUnit(strings.TrimSpace(s[end:])); err != nil {
+			return nil, err
+		}
+		s = s[:end]
+	}
+	return ToWei(s, unit, mode)
+}
+
+// ToWei converts a decimal amount in the given unit into base units.
+func ToWei(amount string, unit Unit, mode RoundingMode) (*big.Int, error) {
+	number := strings.TrimSpace(amount)
+
+	negative := strings.HasPrefix(number, "-")
+	if negative || strings.HasPrefix(number, "+") {
+		number = number[1:]
+	}
+	whole, frac := number, ""
+	if dot := strings.IndexByte(number, '.'); dot >= 0 {
+		whole, frac = number[:dot], number[dot+1:]
+	}
+	if whole == "" && frac == "" || !isDigits(whole) || !isDigits(frac) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	var dropped string
+	if len(frac) > unit.Decimals {
+		frac, dropped = frac[:unit.Decimals], frac[unit.Decimals:]
+	}
+	digits := whole + frac + strings.Repeat("0", unit.Decimals-len(frac))
+
+	value, ok := new(big.Int).SetString("0"+digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidAmount, amount)
+	}
+	// Compare the dropped digits against half a base unit and round
+	half := -2
+	if dropped = strings.TrimRight(dropped, "0"); dropped != "" {
+		half = strings.Compare(dropped, "5")
+	}
+	if err := round(value, half, mode); err != nil {
+		return nil, err
+	}
+	if negative {
+		value.Neg(value)
+	}
+	return value, nil
+}
+
+// FromWei converts an amount of base units into an integer amount of the given
+// unit, rounding according to the mode.
+func FromWei(wei *big.Int, unit Unit, mode RoundingMode) (*big.Int, error) {
+	multiplier := unit.Multiplier()
+
+	value, rem := new(big.Int).QuoRem(new(big.Int).Abs(wei), multiplier, new(big.Int))
+	half := -2
+	if rem.Sign() != 0 {
+		half = rem.Mul(rem, big.NewInt(2)).Cmp(multiplier)
+	}
+	if err := round(value, half, mode); err != nil {
+		return nil, err
+	}
+	if wei.Sign() < 0 {
+		value.Neg(value)
+	}
+	return value, nil
+}
+
+// round adjusts the truncated magnitude of a value, given how the truncated
+// remainder compares to half a unit: -2 if there's none, otherwise -1, 0 or 1
+// for below, exactly and above half.
+func round(value *big.Int, half int, mode RoundingMode) error {
+	if half == -2 {
+		return nil
+	}
+	switch mode {
+	case RoundExact:
+		return ErrPrecisionLoss
+	case RoundDown:
+	case RoundHalfUp:
+		if half >= 0 {
+			value.Add(value, big.NewInt(1))
+		}
+	case RoundHalfEven:
+		if half > 0 || half == 0 && value.Bit(0) == 1 {
+			value.Add(value, big.NewInt(1))
+		}
+	default:
+		return fmt.Errorf("unknown rounding mode %d", mode)
+	}
+	return nil
+}
+
+// Format renders an amount of base units as an exact decimal in the given unit,
+// without trailing zeros.
+func Format(wei *big.Int, unit Unit) string {
+	digits := new(big.Int).Abs(wei).String()
+	if len(digits) <= unit.Decimals {
+		digits = strings.Repeat("0", unit.Decimals-len(digits)+1) + digits
+	}
+	whole, frac := digits[:len(digits)-unit.Decimals], strings.TrimRight(digits[len(digits)-unit.Decimals:], "0")
+
+	s := whole
+	if frac != "" {
+		s += "." + frac
+	}
+	if wei.Sign() < 0 {
+		s = "-" + s
+	}
+	return s
+}
+
+// FormatUnit renders an amount of base units in the given unit, followed by the
+// name of the unit.
+func FormatUnit(wei *big.Int, unit Unit) string {
+	return Format(wei, unit) + " " + unit.Name
+}
+
+// isDigits reports whether s consists of decimal digits only.
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
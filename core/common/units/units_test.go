@@ -0,0 +1,110 @@
+package units
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		mode  RoundingMode
+		want  string
+		err   error
+	}{
+		{input: "1.5 FFF", want: "1500000000000000000"},
+		{input: "1.5fff", want: "1500000000000000000"},
+		{input: "20 gwei", want: "20000000000"},
+		{input: ".25 milliFFF", want: "250000000000000"},
+		{input: "-3 kwei", want: "-3000"},
+		{input: "1000", want: "1000"},
+		{input: "0x3e8", want: "1000"},
+		{input: "1.000 wei", want: "1"},
+		{input: "1.5 wei", err: ErrPrecisionLoss},
+		{input: "1.5 wei", mode: RoundHalfEven, want: "2"},
+		{input: "2.5 wei", mode: RoundHalfEven, want: "2"},
+		{input: "2.51 wei", mode: RoundHalfEven, want: "3"},
+		{input: "2.5 wei", mode: RoundHalfUp, want: "3"},
+		{input: "-2.5 wei", mode: RoundHalfUp, want: "-3"},
+		{input: "2.49 wei", mode: RoundHalfUp, want: "2"},
+		{input: "2.9 wei", mode: RoundDown, want: "2"},
+		{input: "0.0000000015 gwei", mode: RoundHalfEven, want: "2"},
+		{input: "1.5 ether", err: ErrUnknownUnit},
+		{input: "1..5 FFF", err: ErrInvalidAmount},
+		{input: "FFF", err: ErrInvalidAmount},
+		{input: "0xzz", err: ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		have, err := Parse(tt.input, Wei, tt.mode)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("%q: error mismatch: have %v, want %v", tt.input, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: failed to parse: %v", tt.input, err)
+			continue
+		}
+		if have.String() != tt.want {
+			t.Errorf("%q: value mismatch: have %v, want %s", tt.input, have, tt.want)
+		}
+	}
+}
+
+func TestFromWei(t *testing.T) {
+	tests := []struct {
+		wei  int64
+		mode RoundingMode
+		want int64
+		err  error
+	}{
+		{wei: 3000000000, want: 3},
+		{wei: 2500000000, err: ErrPrecisionLoss},
+		{wei: 2500000000, mode: RoundHalfEven, want: 2},
+		{wei: 3500000000, mode: RoundHalfEven, want: 4},
+		{wei: 2500000001, mode: RoundHalfEven, want: 3},
+		{wei: 2500000000, mode: RoundHalfUp, want: 3},
+		{wei: -2500000000, mode: RoundHalfUp, want: -3},
+		{wei: 2999999999, mode: RoundDown, want: 2},
+	}
+	for _, tt := range tests {
+		have, err := FromWei(big.NewInt(tt.wei), GWei, tt.mode)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%d: error mismatch: have %v, want %v", tt.wei, err, tt.err)
+			continue
+		}
+		if err == nil && have.Int64() != tt.want {
+			t.Errorf("%d: value mismatch: have %v, want %d", tt.wei, have, tt.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		wei  string
+		unit Unit
+		want string
+	}{
+		{"1500000000000000000", FFF, "1.5"},
+		{"1000000000000000000", FFF, "1"},
+		{"1", FFF, "0.000000000000000001"},
+		{"0", FFF, "0"},
+		{"-20000000000", GWei, "-20"},
+		{"123", Wei, "123"},
+	}
+	for _, tt := range tests {
+		wei, _ := new(big.Int).SetString(tt.wei, 10)
+		if have := Format(wei, tt.unit); have != tt.want {
+			t.Errorf("%s %s: format mismatch: have %s, want %s", tt.wei, tt.unit, have, tt.want)
+		}
+		// Formatted amounts must parse back exactly
+		if back, err := ToWei(Format(wei, tt.unit), tt.unit, RoundExact); err != nil || back.Cmp(wei) != 0 {
+			t.Errorf("%s %s: round trip mismatch: have %v, %v", tt.wei, tt.unit, back, err)
+		}
+	}
+	if have := FormatUnit(big.NewInt(1500000000), GWei); have != "1.5 gwei" {
+		t.Errorf("unit format mismatch: have %s", have)
+	}
+}
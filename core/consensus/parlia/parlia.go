@@ -32,6 +32,7 @@ import (
 	"github.com/liuguodong24-8/3fcoin/core/consensus/misc"
 	"github.com/liuguodong24-8/3fcoin/core/core"
 	"github.com/liuguodong24-8/3fcoin/core/core/forkid"
+	"github.com/liuguodong24-8/3fcoin/core/core/rawdb"
 	"github.com/liuguodong24-8/3fcoin/core/core/state"
 	"github.com/liuguodong24-8/3fcoin/core/core/systemcontracts"
 	"github.com/liuguodong24-8/3fcoin/core/core/types"
@@ -467,9 +468,11 @@ func (p *Parlia) snapshot(chain consensus.ChainHeaderReader, number uint64, hash
 			}
 		}
 
-		// If we're at the genesis, snapshot the initial state.
-		if number == 0 {
-			checkpoint := chain.GetHeaderByNumber(number)
+		// If we're at the genesis, snapshot the initial state. Alternatively if we're
+		// at the checkpoint a state-sync seed was imported at, consider it trusted
+		// and snapshot the validator set it carries.
+		if number == 0 || (number%p.config.Epoch == 0 && hash == rawdb.ReadTrustedCheckpoint(p.db)) {
+			checkpoint := chain.GetHeader(hash, number)
 			if checkpoint != nil {
 				// get checkpoint data
 				hash := checkpoint.Hash()
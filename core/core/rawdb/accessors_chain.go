@@ -188,6 +188,24 @@ func WriteHeadFastBlockHash(db ethdb.KeyValueWriter, hash common.Hash) {
 	}
 }
 
+// ReadTrustedCheckpoint retrieves the hash of the checkpoint block a state-sync
+// seed was imported at, which consensus engines may bootstrap their state from.
+func ReadTrustedCheckpoint(db ethdb.KeyValueReader) common.Hash {
+	data, _ := db.Get(trustedCheckpointKey)
+	if len(data) == 0 {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// WriteTrustedCheckpoint stores the hash of the checkpoint block a state-sync
+// seed was imported at.
+func WriteTrustedCheckpoint(db ethdb.KeyValueWriter, hash common.Hash) {
+	if err := db.Put(trustedCheckpointKey, hash.Bytes()); err != nil {
+		log.Crit("Failed to store trusted checkpoint hash", "err", err)
+	}
+}
+
 // ReadLastPivotNumber retrieves the number of the last pivot block. If the node
 // full synced, the last pivot will always be nil.
 func ReadLastPivotNumber(db ethdb.KeyValueReader) *uint64 {
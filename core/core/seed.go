@@ -0,0 +1,158 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/math"
+	"github.com/liuguodong24-8/3fcoin/core/core/rawdb"
+	"github.com/liuguodong24-8/3fcoin/core/core/state"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/ethdb"
+)
+
+// SeedBlockFile is the name of the file holding the RLP encoded checkpoint block
+// of a state-sync seed.
+const SeedBlockFile = "checkpoint.rlp"
+
+// ErrSeedChainAdvanced is returned when importing a state-sync seed into a chain
+// that already progressed past its genesis block.
+var ErrSeedChainAdvanced = errors.New("chain already advanced past the genesis")
+
+// SeedStateFile returns the name of the file holding the gzipped JSON dump of
+// the state of a state-sync seed taken at the given block.
+func SeedStateFile(number uint64) string {
+	return fmt.Sprintf("state-%d.json.gz", number)
+}
+
+// SeedCheckpoint is the trusted block a state-sync seed was taken at. Nodes
+// importing the seed verify the state against it.
+type SeedCheckpoint struct {
+	Number          uint64                `json:"number"`
+	Hash            common.Hash           `json:"hash"`
+	Root            common.Hash           `json:"stateRoot"`
+	TotalDifficulty *math.HexOrDecimal256 `json:"totalDifficulty"`
+}
+
+// SeedFile is a single downloadable file of a state-sync seed.
+type SeedFile struct {
+	Name   string `json:"name"`
+	URL    string `json:"url,omitempty"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// SeedManifest describes a state-sync seed, allowing new nodes to fetch and
+// verify the artifacts before importing them instead of syncing from scratch.
+type SeedManifest struct {
+	Network    string         `json:"network"`
+	ChainID    *big.Int       `json:"chainId"`
+	Genesis    common.Hash    `json:"genesisHash"`
+	Checkpoint SeedCheckpoint `json:"checkpoint"`
+	Files      []SeedFile     `json:"files"`
+	InfoHash   string         `json:"infoHash"`
+}
+
+// DumpAlloc converts a state dump into the allocation recreating it.
+func DumpAlloc(dump *state.Dump) (GenesisAlloc, error) {
+	alloc := make(GenesisAlloc, len(dump.Accounts))
+	for addr, account := range dump.Accounts {
+		if account.SecureKey != nil {
+			return nil, fmt.Errorf("account %x has no preimage", account.SecureKey)
+		}
+		balance, ok := new(big.Int).SetString(account.Balance, 10)
+		if !ok {
+			return nil, fmt.Errorf("account %x: invalid balance %q", addr, account.Balance)
+		}
+		entry := GenesisAccount{
+			Code:    common.FromHex(account.Code),
+			Balance: balance,
+			Nonce:   account.Nonce,
+		}
+		if len(account.Storage) > 0 {
+			entry.Storage = make(map[common.Hash]common.Hash, len(account.Storage))
+			for key, value := range account.Storage {
+				entry.Storage[key] = common.BytesToHash(common.FromHex(value))
+			}
+		}
+		alloc[addr] = entry
+	}
+	return alloc, nil
+}
+
+// CommitCheckpoint writes a trusted checkpoint block and the state at it into a
+// freshly initialized database, making it the head of the chain so the node can
+// continue syncing from there instead of from the genesis. The state is rebuilt
+// from alloc and rejected unless it hashes to the root of the block. The block is
+// recorded as the trusted checkpoint, the only block besides the genesis that
+// consensus engines bootstrap their signer sets from.
+//
+// Importing the same checkpoint again is a no-op, while any other import into a
+// chain that progressed past its genesis fails with ErrSeedChainAdvanced.
+func CommitCheckpoint(db ethdb.Database, block *types.Block, td *big.Int, alloc GenesisAlloc) error {
+	if block.NumberU64() == 0 {
+		return errors.New("can't import the genesis as a checkpoint")
+	}
+	if td == nil || td.Sign() <= 0 {
+		return errors.New("checkpoint has no total difficulty")
+	}
+	genesis := rawdb.ReadCanonicalHash(db, 0)
+	if genesis == (common.Hash{}) {
+		return errors.New("database not initialized with a genesis")
+	}
+	if rawdb.ReadCanonicalHash(db, block.NumberU64()) == block.Hash() {
+		return nil
+	}
+	if rawdb.ReadHeadHeaderHash(db) != genesis || rawdb.ReadHeadBlockHash(db) != genesis {
+		return ErrSeedChainAdvanced
+	}
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	if err != nil {
+		return err
+	}
+	for addr, account := range alloc {
+		statedb.AddBalance(addr, account.Balance)
+		statedb.SetCode(addr, account.Code)
+		statedb.SetNonce(addr, account.Nonce)
+		for key, value := range account.Storage {
+			statedb.SetState(addr, key, value)
+		}
+	}
+	root := statedb.IntermediateRoot(false)
+	if root != block.Root() {
+		return fmt.Errorf("checkpoint state root mismatch: have %x, want %x", root, block.Root())
+	}
+	if _, _, err := statedb.Commit(nil); err != nil {
+		return err
+	}
+	if err := statedb.Database().TrieDB().Commit(root, true, nil); err != nil {
+		return err
+	}
+	rawdb.WriteTd(db, block.Hash(), block.NumberU64(), td)
+	rawdb.WriteBlock(db, block)
+	rawdb.WriteReceipts(db, block.Hash(), block.NumberU64(), nil)
+	rawdb.WriteCanonicalHash(db, block.Hash(), block.NumberU64())
+	rawdb.WriteHeadBlockHash(db, block.Hash())
+	rawdb.WriteHeadFastBlockHash(db, block.Hash())
+	rawdb.WriteHeadHeaderHash(db, block.Hash())
+	rawdb.WriteTrustedCheckpoint(db, block.Hash())
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/core/rawdb"
+	"github.com/liuguodong24-8/3fcoin/core/core/state"
+	"github.com/liuguodong24-8/3fcoin/core/core/types"
+	"github.com/liuguodong24-8/3fcoin/core/params"
+)
+
+// Tests that a checkpoint rebuilt from a state dump becomes the head of a fresh
+// chain, and that mismatching or late imports are rejected.
+func TestCommitCheckpoint(t *testing.T) {
+	var (
+		genesis = &Genesis{Config: params.TestChainConfig}
+		addr    = common.HexToAddress("0x0102030405060708090a0b0c0d0e0f1011121314")
+		slot    = common.HexToHash("0x01")
+	)
+	// Build the checkpoint state on a node of its own and dump it
+	srcdb := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(srcdb), nil)
+	statedb.AddBalance(addr, big.NewInt(1000))
+	statedb.SetNonce(addr, 3)
+	statedb.SetCode(addr, []byte{0x60, 0x00})
+	statedb.SetState(addr, slot, common.HexToHash("0x2a"))
+	root := statedb.IntermediateRoot(false)
+	statedb.Commit(nil)
+	statedb.Database().TrieDB().Commit(root, true, nil)
+
+	source, _ := state.New(root, state.NewDatabase(srcdb), nil)
+	dump := source.RawDump(false, false, false)
+
+	alloc, err := DumpAlloc(&dump)
+	if err != nil {
+		t.Fatalf("failed to convert dump: %v", err)
+	}
+	block := types.NewBlockWithHeader(&types.Header{
+		Number:     big.NewInt(200),
+		ParentHash: common.HexToHash("0xdeadbeef"),
+		Root:       root,
+		Difficulty: big.NewInt(2),
+	})
+	td := big.NewInt(401)
+
+	// Import the checkpoint into a fresh chain, twice to check idempotence
+	db := rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+	for i := 0; i < 2; i++ {
+		if err := CommitCheckpoint(db, block, td, alloc); err != nil {
+			t.Fatalf("import %d: failed to commit checkpoint: %v", i, err)
+		}
+	}
+	if head := rawdb.ReadHeadBlockHash(db); head != block.Hash() {
+		t.Errorf("head block mismatch: have %x, want %x", head, block.Hash())
+	}
+	if trusted := rawdb.ReadTrustedCheckpoint(db); trusted != block.Hash() {
+		t.Errorf("trusted checkpoint mismatch: have %x, want %x", trusted, block.Hash())
+	}
+	if have := rawdb.ReadTd(db, block.Hash(), block.NumberU64()); have == nil || have.Cmp(td) != 0 {
+		t.Errorf("total difficulty mismatch: have %v, want %v", have, td)
+	}
+	imported, err := state.New(root, state.NewDatabase(db), nil)
+	if err != nil {
+		t.Fatalf("failed to open imported state: %v", err)
+	}
+	if have := imported.GetState(addr, slot); have != common.HexToHash("0x2a") {
+		t.Errorf("storage mismatch: have %x, want %x", have, common.HexToHash("0x2a"))
+	}
+	// Tampered states and chains that moved on must be rejected
+	db = rawdb.NewMemoryDatabase()
+	genesis.MustCommit(db)
+
+	forged := GenesisAlloc{addr: {Balance: big.NewInt(1000000)}}
+	if err := CommitCheckpoint(db, block, td, forged); err == nil {
+		t.Errorf("forged state accepted")
+	}
+	rawdb.WriteHeadHeaderHash(db, common.HexToHash("0x01"))
+	if err := CommitCheckpoint(db, block, td, alloc); !errors.Is(err, ErrSeedChainAdvanced) {
+		t.Errorf("import into advanced chain: have %v, want %v", err, ErrSeedChainAdvanced)
+	}
+}
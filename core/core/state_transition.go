@@ -109,14 +109,21 @@ func (result *ExecutionResult) Revert() []byte {
 	return common.CopyBytes(result.ReturnData)
 }
 
-// IntrinsicGas computes the 'intrinsic gas' for a message with the given data.
-func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028 bool) (uint64, error) {
+// IntrinsicGas computes the 'intrinsic gas' for a message with the given data,
+// applying the overrides of the chain gas schedule, if any.
+func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation bool, isHomestead, isEIP2028 bool, schedule *params.GasScheduleConfig) (uint64, error) {
 	// Set the starting gas for the raw transaction
 	var gas uint64
 	if isContractCreation && isHomestead {
 		gas = params.TxGasContractCreation
+		if schedule != nil && schedule.TxGasContractCreation != 0 {
+			gas = schedule.TxGasContractCreation
+		}
 	} else {
 		gas = params.TxGas
+		if schedule != nil && schedule.TxGas != 0 {
+			gas = schedule.TxGas
+		}
 	}
 	// Bump the required gas by the amount of transactional data
 	if len(data) > 0 {
@@ -132,16 +139,25 @@ func IntrinsicGas(data []byte, accessList types.AccessList, isContractCreation b
 		if isEIP2028 {
 			nonZeroGas = params.TxDataNonZeroGasEIP2028
 		}
+		zeroGas := params.TxDataZeroGas
+		if schedule != nil {
+			if schedule.TxDataNonZeroGas != 0 {
+				nonZeroGas = schedule.TxDataNonZeroGas
+			}
+			if schedule.TxDataZeroGas != 0 {
+				zeroGas = schedule.TxDataZeroGas
+			}
+		}
 		if (math.MaxUint64-gas)/nonZeroGas < nz {
 			return 0, ErrGasUintOverflow
 		}
 		gas += nz * nonZeroGas
 
 		z := uint64(len(data)) - nz
-		if (math.MaxUint64-gas)/params.TxDataZeroGas < z {
+		if (math.MaxUint64-gas)/zeroGas < z {
 			return 0, ErrGasUintOverflow
 		}
-		gas += z * params.TxDataZeroGas
+		gas += z * zeroGas
 	}
 	if accessList != nil {
 		gas += uint64(len(accessList)) * params.TxAccessListAddressGas
@@ -247,7 +263,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 	contractCreation := msg.To() == nil
 
 	// Check clauses 4-5, subtract intrinsic gas if everything is correct
-	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul)
+	gas, err := IntrinsicGas(st.data, st.msg.AccessList(), contractCreation, homestead, istanbul, st.evm.ChainConfig().GasSchedule)
 	if err != nil {
 		return nil, err
 	}
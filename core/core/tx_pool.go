@@ -293,6 +293,7 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		reorgShutdownCh: make(chan struct{}),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
 	}
+	pool.gasPrice = pool.gasPriceFloor(pool.gasPrice)
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
 		log.Info("Setting new local account", "address", addr)
@@ -472,6 +473,7 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	pool.mu.Lock()
 	defer pool.mu.Unlock()
 
+	price = pool.gasPriceFloor(price)
 	pool.gasPrice = price
 	for _, tx := range pool.priced.Cap(price) {
 		pool.removeTx(tx.Hash(), false)
@@ -479,6 +481,15 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// gasPriceFloor raises a gas price to the minimum set by the gas schedule of the
+// chain, if it's lower.
+func (pool *TxPool) gasPriceFloor(price *big.Int) *big.Int {
+	if schedule := pool.chainconfig.GasSchedule; schedule != nil && schedule.MinGasPrice != nil && price.Cmp(schedule.MinGasPrice) < 0 {
+		return new(big.Int).Set(schedule.MinGasPrice)
+	}
+	return price
+}
+
 // Nonce returns the next nonce of an account, with all transactions executable
 // by the pool already applied on top.
 func (pool *TxPool) Nonce(addr common.Address) uint64 {
@@ -605,7 +616,7 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return ErrInsufficientFunds
 	}
 	// Ensure the transaction has more gas than the basic tx fee.
-	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
+	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul, pool.chainconfig.GasSchedule)
 	if err != nil {
 		return err
 	}
@@ -1426,6 +1426,32 @@ func TestTransactionPoolRepricingKeepsLocals(t *testing.T) {
 	validate()
 }
 
+// Tests that the price limit of the pool can't undercut the minimum gas price of
+// the chain gas schedule.
+func TestTransactionPoolGasPriceFloor(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := *params.TestChainConfig
+	config.GasSchedule = &params.GasScheduleConfig{MinGasPrice: big.NewInt(5)}
+	pool := NewTxPool(testTxPoolConfig, &config, blockchain)
+	defer pool.Stop()
+
+	if price := pool.GasPrice(); price.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("initial gas price mismatch: have %v, want %v", price, 5)
+	}
+	pool.SetGasPrice(big.NewInt(2))
+	if price := pool.GasPrice(); price.Cmp(big.NewInt(5)) != 0 {
+		t.Errorf("lowered gas price mismatch: have %v, want %v", price, 5)
+	}
+	pool.SetGasPrice(big.NewInt(10))
+	if price := pool.GasPrice(); price.Cmp(big.NewInt(10)) != 0 {
+		t.Errorf("raised gas price mismatch: have %v, want %v", price, 10)
+	}
+}
+
 // Tests that when the pool reaches its global transaction limit, underpriced
 // transactions are gradually shifted out for more expensive ones and any gapped
 // pending transactions are moved into the queue.
@@ -131,6 +131,39 @@ func init() {
 	}
 }
 
+// RepriceablePrecompiles maps the names the gas schedule of a chain reprices the
+// precompiled contracts by to their addresses.
+var RepriceablePrecompiles = map[string]common.Address{
+	"ecrecover":      common.BytesToAddress([]byte{1}),
+	"sha256":         common.BytesToAddress([]byte{2}),
+	"ripemd160":      common.BytesToAddress([]byte{3}),
+	"identity":       common.BytesToAddress([]byte{4}),
+	"bn256Add":       common.BytesToAddress([]byte{6}),
+	"bn256ScalarMul": common.BytesToAddress([]byte{7}),
+}
+
+// repricedContract is a precompiled contract with a linear price set by the gas
+// schedule of the chain: a base cost plus a cost per 32 byte word of input.
+type repricedContract struct {
+	PrecompiledContract
+	pricing *params.PrecompilePricing
+}
+
+func (c *repricedContract) RequiredGas(input []byte) uint64 {
+	return c.pricing.Base + uint64(len(input)+31)/32*c.pricing.Word
+}
+
+// repricePrecompile applies the gas schedule of the chain to the precompiled
+// contract at addr.
+func repricePrecompile(schedule *params.GasScheduleConfig, addr common.Address, p PrecompiledContract) PrecompiledContract {
+	for name, pricing := range schedule.Precompiles {
+		if pricing != nil && RepriceablePrecompiles[name] == addr {
+			return &repricedContract{p, pricing}
+		}
+	}
+	return p
+}
+
 // ActivePrecompiles returns the precompiles enabled with the current configuration.
 func ActivePrecompiles(rules params.Rules) []common.Address {
 	switch {
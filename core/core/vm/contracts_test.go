@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/params"
 )
 
 // precompiledTest defines the input/output pairs for precompiled contract tests.
@@ -272,6 +273,22 @@ func TestPrecompileBlake2FMalformedInput(t *testing.T) {
 
 func TestPrecompiledEcrecover(t *testing.T) { testJson("ecRecover", "01", t) }
 
+// Tests that the gas schedule of the chain reprices the precompiles it names and
+// leaves the others alone.
+func TestRepricePrecompile(t *testing.T) {
+	schedule := &params.GasScheduleConfig{
+		Precompiles: map[string]*params.PrecompilePricing{"sha256": {Base: 100, Word: 10}},
+	}
+	sha := repricePrecompile(schedule, common.BytesToAddress([]byte{2}), &sha256hash{})
+	if gas := sha.RequiredGas(make([]byte, 33)); gas != 120 {
+		t.Errorf("repriced gas mismatch: have %d, want %d", gas, 120)
+	}
+	ripemd := repricePrecompile(schedule, common.BytesToAddress([]byte{3}), &ripemd160hash{})
+	if gas, want := ripemd.RequiredGas(make([]byte, 33)), (&ripemd160hash{}).RequiredGas(make([]byte, 33)); gas != want {
+		t.Errorf("default gas mismatch: have %d, want %d", gas, want)
+	}
+}
+
 func testJson(name, addr string, t *testing.T) {
 	tests, err := loadJson(name)
 	if err != nil {
@@ -62,6 +62,9 @@ func (evm *EVM) precompile(addr common.Address) (PrecompiledContract, bool) {
 		precompiles = PrecompiledContractsHomestead
 	}
 	p, ok := precompiles[addr]
+	if ok && evm.chainConfig.GasSchedule != nil {
+		p = repricePrecompile(evm.chainConfig.GasSchedule, addr, p)
+	}
 	return p, ok
 }
 
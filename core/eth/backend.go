@@ -28,6 +28,7 @@ import (
 
 	"github.com/liuguodong24-8/3fcoin/core/accounts"
 	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/fffenc"
 	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
 	"github.com/liuguodong24-8/3fcoin/core/consensus"
 	"github.com/liuguodong24-8/3fcoin/core/consensus/clique"
@@ -139,6 +140,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		return nil, genesisErr
 	}
 	log.Info("Initialised chain configuration", "config", chainConfig)
+	if err := fffenc.Activate(fffenc.FromChainConfig(chainConfig)); err != nil {
+		return nil, err
+	}
 
 	if err := pruner.RecoverPruning(stack.ResolvePath(""), chainDb, stack.ResolvePath(config.TrieCleanCacheJournal), config.TriesInMemory); err != nil {
 		log.Error("Failed to recover state", "error", err)
@@ -23,6 +23,7 @@ import (
 
 	"github.com/liuguodong24-8/3fcoin/core/accounts"
 	"github.com/liuguodong24-8/3fcoin/core/common"
+	"github.com/liuguodong24-8/3fcoin/core/common/fffenc"
 	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
 	"github.com/liuguodong24-8/3fcoin/core/common/mclock"
 	"github.com/liuguodong24-8/3fcoin/core/consensus"
@@ -93,6 +94,9 @@ func New(stack *node.Node, config *ethconfig.Config) (*LightEthereum, error) {
 		return nil, genesisErr
 	}
 	log.Info("Initialised chain configuration", "config", chainConfig)
+	if err := fffenc.Activate(fffenc.FromChainConfig(chainConfig)); err != nil {
+		return nil, err
+	}
 
 	peers := newServerPeerSet()
 	leth := &LightEthereum{
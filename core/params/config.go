@@ -331,16 +331,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), "", nil, new(EthashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), "", nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), new(EthashConfig), nil, nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), "", nil, new(EthashConfig), nil, nil}
 
 	TestRules = TestChainConfig.Rules(new(big.Int))
 )
@@ -429,6 +429,10 @@ type ChainConfig struct {
 	MirrorSyncBlock *big.Int `json:"mirrorSyncBlock,omitempty" toml:",omitempty"` // mirrorSyncBlock switch block (nil = no fork, 0 = already activated)
 	BrunoBlock      *big.Int `json:"brunoBlock,omitempty" toml:",omitempty"`      // brunoBlock switch block (nil = no fork, 0 = already activated)
 
+	AddressPrefix string `json:"addressPrefix,omitempty" toml:",omitempty"` // Network prefix of FFF encoded addresses (empty = FFF)
+
+	GasSchedule *GasScheduleConfig `json:"gasSchedule,omitempty" toml:",omitempty"` // Chain specific gas schedule overrides (nil = Ethereum defaults)
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty" toml:",omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty" toml:",omitempty"`
@@ -465,6 +469,36 @@ func (b *ParliaConfig) String() string {
 	return "parlia"
 }
 
+// GasScheduleConfig holds the chain specific overrides of the gas schedule, for
+// networks tuning their fees. Zero values keep the Ethereum defaults. The intrinsic
+// gas and precompile prices are consensus rules, the minimum gas price is a floor
+// of the transaction pool.
+type GasScheduleConfig struct {
+	TxGas                 uint64 `json:"txGas,omitempty"`                 // Intrinsic gas of a transaction not creating a contract
+	TxGasContractCreation uint64 `json:"txGasContractCreation,omitempty"` // Intrinsic gas of a transaction creating a contract
+	TxDataZeroGas         uint64 `json:"txDataZeroGas,omitempty"`         // Gas per zero byte of transaction data
+	TxDataNonZeroGas      uint64 `json:"txDataNonZeroGas,omitempty"`      // Gas per non-zero byte of transaction data
+
+	MinGasPrice *big.Int                      `json:"minGasPrice,omitempty"` // Lowest gas price nodes accept and mine (nil = node default)
+	Precompiles map[string]*PrecompilePricing `json:"precompiles,omitempty"` // Repriced precompiled contracts, keyed by name
+}
+
+// PrecompilePricing is the linear price of a precompiled contract: a base cost
+// plus a cost per 32 byte word of input.
+type PrecompilePricing struct {
+	Base uint64 `json:"base"`
+	Word uint64 `json:"word"`
+}
+
+// FFFAddressPrefix returns the network prefix used to encode addresses on this
+// chain, falling back to the mainnet prefix if none is configured.
+func (c *ChainConfig) FFFAddressPrefix() string {
+	if c.AddressPrefix == "" {
+		return common.FFFHeader
+	}
+	return c.AddressPrefix
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -16,7 +16,11 @@
 
 package rpc
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
+)
 
 // HTTPError is returned by client operations when the HTTP status code of the
 // response is not a 2xx status.
@@ -96,8 +100,24 @@ func (e *invalidMessageError) ErrorCode() int { return -32700 }
 func (e *invalidMessageError) Error() string { return e.message }
 
 // unable to decode supplied params, or an invalid number of parameters
-type invalidParamsError struct{ message string }
+type invalidParamsError struct {
+	message string
+	data    interface{}
+}
+
+// newInvalidParamsError creates an invalid params error from the error decoding
+// the params failed with. Hex, number and address parse errors are passed on to
+// the client as error data, telling which input was rejected and why.
+func newInvalidParamsError(err error) *invalidParamsError {
+	e := &invalidParamsError{message: err.Error()}
+	if perr, ok := hexutil.AsParseError(err); ok {
+		e.data = perr
+	}
+	return e
+}
 
 func (e *invalidParamsError) ErrorCode() int { return -32602 }
 
 func (e *invalidParamsError) Error() string { return e.message }
+
+func (e *invalidParamsError) ErrorData() interface{} { return e.data }
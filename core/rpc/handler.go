@@ -335,7 +335,7 @@ func (h *handler) handleCall(cp *callProc, msg *jsonrpcMessage) *jsonrpcMessage
 	}
 	args, err := parsePositionalArguments(msg.Params, callb.argTypes)
 	if err != nil {
-		return msg.errorResponse(&invalidParamsError{err.Error()})
+		return msg.errorResponse(newInvalidParamsError(err))
 	}
 	start := time.Now()
 	answer := h.runMethod(cp.ctx, msg, callb, args)
@@ -365,7 +365,7 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	// Subscription method name is first argument.
 	name, err := parseSubscriptionName(msg.Params)
 	if err != nil {
-		return msg.errorResponse(&invalidParamsError{err.Error()})
+		return msg.errorResponse(newInvalidParamsError(err))
 	}
 	namespace := msg.namespace()
 	callb := h.reg.subscription(namespace, name)
@@ -377,7 +377,7 @@ func (h *handler) handleSubscribe(cp *callProc, msg *jsonrpcMessage) *jsonrpcMes
 	argTypes := append([]reflect.Type{stringType}, callb.argTypes...)
 	args, err := parsePositionalArguments(msg.Params, argTypes)
 	if err != nil {
-		return msg.errorResponse(&invalidParamsError{err.Error()})
+		return msg.errorResponse(newInvalidParamsError(err))
 	}
 	args = args[1:]
 
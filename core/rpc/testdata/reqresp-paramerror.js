@@ -0,0 +1,11 @@
+// This test checks that malformed hex arguments are rejected with error data
+// describing the problem.
+
+--> {"jsonrpc": "2.0", "id": 1, "method": "test_echoQuantity", "params": ["0x1f"]}
+<-- {"jsonrpc":"2.0","id":1,"result":"0x1f"}
+
+--> {"jsonrpc": "2.0", "id": 2, "method": "test_echoQuantity", "params": ["0x01"]}
+<-- {"jsonrpc":"2.0","id":2,"error":{"code":-32602,"message":"invalid argument 0: json: cannot unmarshal hex number with leading zero digits into Go value of type hexutil.Uint64","data":{"code":"leading-zero","input":"0x01","expected":"0x prefixed hex number without leading zero digits"}}}
+
+--> {"jsonrpc": "2.0", "id": 3, "method": "test_echoQuantity", "params": ["1f"]}
+<-- {"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"invalid argument 0: json: cannot unmarshal hex string without 0x prefix into Go value of type hexutil.Uint64","data":{"code":"missing-prefix","input":"1f","expected":"0x prefixed hex number without leading zero digits"}}}
@@ -23,6 +23,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/liuguodong24-8/3fcoin/core/common/hexutil"
 )
 
 func newTestServer() *Server {
@@ -76,6 +78,10 @@ func (s *testService) Echo(str string, i int, args *echoArgs) echoResult {
 	return echoResult{str, i, args}
 }
 
+func (s *testService) EchoQuantity(q hexutil.Uint64) hexutil.Uint64 {
+	return q
+}
+
 func (s *testService) EchoWithCtx(ctx context.Context, str string, i int, args *echoArgs) echoResult {
 	return echoResult{str, i, args}
 }